@@ -0,0 +1,181 @@
+package fakechain
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/core/consensus"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	paymenttypes "github.com/bnb-chain/greenfield/x/payment/types"
+	permissiontypes "github.com/bnb-chain/greenfield/x/permission/types"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+const (
+	FakeChainCodeSpace = "FakeChain"
+	// DefaultBlockInterval defines the default interval of the simulated block clock.
+	DefaultBlockInterval = time.Second
+)
+
+var (
+	ErrNoSuchBucket        = gfsperrors.Register(FakeChainCodeSpace, http.StatusNotFound, 500101, "no such bucket")
+	ErrNoSuchObject        = gfsperrors.Register(FakeChainCodeSpace, http.StatusNotFound, 500102, "no such object")
+	ErrNoSuchSP            = gfsperrors.Register(FakeChainCodeSpace, http.StatusNotFound, 500103, "no such storage provider")
+	ErrNoSuchStreamRecord  = gfsperrors.Register(FakeChainCodeSpace, http.StatusNotFound, 500104, "no such stream record")
+	ErrRepeatedBucket      = gfsperrors.Register(FakeChainCodeSpace, http.StatusBadRequest, 500105, "bucket already exists")
+	ErrRepeatedObject      = gfsperrors.Register(FakeChainCodeSpace, http.StatusBadRequest, 500106, "object already exists")
+	ErrInvalidObjectStatus = gfsperrors.Register(FakeChainCodeSpace, http.StatusBadRequest, 500107, "object status mismatch")
+	ErrInvalidOperator     = gfsperrors.Register(FakeChainCodeSpace, http.StatusBadRequest, 500108, "operator has no right to send the msg")
+	ErrSealTimeout         = gfsperrors.Register(FakeChainCodeSpace, http.StatusInternalServerError, 500109, "seal failed")
+	ErrChainClosed         = gfsperrors.Register(FakeChainCodeSpace, http.StatusInternalServerError, 500110, "fake chain closed")
+	ErrNoVersionedParams   = gfsperrors.Register(FakeChainCodeSpace, http.StatusNotFound, 500111, "no versioned params found")
+)
+
+var _ consensus.Consensus = &FakeChain{}
+
+// FakeChainConfig defines the fake chain configuration.
+type FakeChainConfig struct {
+	// BlockInterval is the interval of producing block, if it is zero, the
+	// block clock is manual, block is only produced by calling ProduceBlock.
+	BlockInterval time.Duration
+	// StorageParams is the genesis storage params, default is the greenfield
+	// default storage params.
+	StorageParams *storagetypes.Params
+}
+
+// Permission defines the object permission granted to an account, the empty
+// object name stands for all objects in the bucket.
+type Permission struct {
+	Account string                       `json:"account"`
+	Bucket  string                       `json:"bucket"`
+	Object  string                       `json:"object"`
+	Actions []permissiontypes.ActionType `json:"actions"`
+}
+
+// paramsVersion is the storage params that are effective from the effective time.
+type paramsVersion struct {
+	effectiveTime int64
+	params        *storagetypes.Params
+}
+
+// FakeChain is an in-memory implementation of consensus.Consensus that holds
+// a scriptable ledger of accounts, buckets, objects, SPs, storage params,
+// payment stream records and permissions. Txs are applied on the block that
+// is produced by the simulated block clock, it is used to run SP locally
+// without a greenfield node.
+type FakeChain struct {
+	mux sync.RWMutex
+
+	height    uint64
+	blockTime time.Time
+	// newBlock is closed and replaced when a block is produced, used to
+	// notify the block waiters.
+	newBlock chan struct{}
+	pending  []func() error
+
+	accounts      map[string]struct{}
+	sps           []*sptypes.StorageProvider
+	params        []*paramsVersion
+	buckets       map[string]*storagetypes.BucketInfo
+	objects       map[string]*storagetypes.ObjectInfo
+	objectNames   map[string]string
+	streamRecords map[string]*paymenttypes.StreamRecord
+	permissions   []*Permission
+	nextBucketID  uint64
+	nextObjectID  uint64
+
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewFakeChain returns the FakeChain instance and starts the block clock.
+func NewFakeChain(cfg *FakeChainConfig) *FakeChain {
+	if cfg == nil {
+		cfg = &FakeChainConfig{BlockInterval: DefaultBlockInterval}
+	}
+	params := cfg.StorageParams
+	if params == nil {
+		defaultParams := storagetypes.DefaultParams()
+		params = &defaultParams
+	}
+	chain := &FakeChain{
+		height:        1,
+		blockTime:     time.Now(),
+		newBlock:      make(chan struct{}),
+		accounts:      make(map[string]struct{}),
+		params:        []*paramsVersion{{effectiveTime: 0, params: params}},
+		buckets:       make(map[string]*storagetypes.BucketInfo),
+		objects:       make(map[string]*storagetypes.ObjectInfo),
+		objectNames:   make(map[string]string),
+		streamRecords: make(map[string]*paymenttypes.StreamRecord),
+		nextBucketID:  1,
+		nextObjectID:  1,
+		interval:      cfg.BlockInterval,
+		stopCh:        make(chan struct{}),
+	}
+	if chain.interval > 0 {
+		go chain.clock()
+	}
+	return chain
+}
+
+// clock produces block at the fixed interval until the chain is closed.
+func (c *FakeChain) clock() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.ProduceBlock()
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// ProduceBlock applies the pending txs and increases the block height, returns
+// the new block height.
+func (c *FakeChain) ProduceBlock() uint64 {
+	c.mux.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.height++
+	c.blockTime = time.Now()
+	for _, tx := range pending {
+		if err := tx(); err != nil {
+			log.Warnw("failed to apply tx in fake chain", "height", c.height, "error", err)
+		}
+	}
+	height := c.height
+	notify := c.newBlock
+	c.newBlock = make(chan struct{})
+	c.mux.Unlock()
+	close(notify)
+	return height
+}
+
+// WaitBlock blocks until the next block is produced or the ctx is done.
+func (c *FakeChain) WaitBlock(ctx context.Context) error {
+	c.mux.RLock()
+	notify := c.newBlock
+	c.mux.RUnlock()
+	select {
+	case <-notify:
+		return nil
+	case <-c.stopCh:
+		return ErrChainClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the block clock.
+func (c *FakeChain) Close() error {
+	c.stopOnce.Do(func() { close(c.stopCh) })
+	return nil
+}
@@ -0,0 +1,153 @@
+package fakechain
+
+import (
+	"strconv"
+
+	sdkmath "cosmossdk.io/math"
+
+	paymenttypes "github.com/bnb-chain/greenfield/x/payment/types"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// AddAccount creates the account in the ledger.
+func (c *FakeChain) AddAccount(account string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.accounts[account] = struct{}{}
+}
+
+// AddStorageProvider adds or replaces the SP info by operator address.
+func (c *FakeChain) AddStorageProvider(sp *sptypes.StorageProvider) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.accounts[sp.GetOperatorAddress()] = struct{}{}
+	for i, exist := range c.sps {
+		if exist.GetOperatorAddress() == sp.GetOperatorAddress() {
+			c.sps[i] = sp
+			return
+		}
+	}
+	c.sps = append(c.sps, sp)
+}
+
+// SetStorageParams replaces the storage params, the params are effective from
+// the current block time, the former params are kept as the history versions.
+func (c *FakeChain) SetStorageParams(params *storagetypes.Params) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	effectiveTime := c.blockTime.Unix()
+	if last := c.params[len(c.params)-1]; last.effectiveTime >= effectiveTime {
+		last.params = params
+		return
+	}
+	c.params = append(c.params, &paramsVersion{effectiveTime: effectiveTime, params: params})
+}
+
+// SetStreamRecord adds or replaces the payment stream record by account.
+func (c *FakeChain) SetStreamRecord(record *paymenttypes.StreamRecord) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.streamRecords[record.GetAccount()] = record
+}
+
+// GrantPermission grants the actions on the bucket or object to the account.
+func (c *FakeChain) GrantPermission(permission *Permission) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.permissions = append(c.permissions, permission)
+}
+
+// CreateBucket creates the bucket in the ledger, the bucket id and create
+// time are assigned by the ledger, the owner account is created implicitly.
+func (c *FakeChain) CreateBucket(bucket *storagetypes.BucketInfo) (*storagetypes.BucketInfo, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if _, ok := c.buckets[bucket.GetBucketName()]; ok {
+		return nil, ErrRepeatedBucket
+	}
+	if c.spByOperator(bucket.GetPrimarySpAddress()) == nil {
+		return nil, ErrNoSuchSP
+	}
+	bucket.Id = sdkmath.NewUint(c.nextBucketID)
+	bucket.CreateAt = c.blockTime.Unix()
+	bucket.BucketStatus = storagetypes.BUCKET_STATUS_CREATED
+	if bucket.GetPaymentAddress() == "" {
+		bucket.PaymentAddress = bucket.GetOwner()
+	}
+	c.nextBucketID++
+	c.accounts[bucket.GetOwner()] = struct{}{}
+	c.buckets[bucket.GetBucketName()] = bucket
+	return bucket, nil
+}
+
+// CreateObject creates the object with created status in the ledger, the
+// object id and create time are assigned by the ledger.
+func (c *FakeChain) CreateObject(object *storagetypes.ObjectInfo) (*storagetypes.ObjectInfo, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	bucket, ok := c.buckets[object.GetBucketName()]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+	if bucket.GetBucketStatus() == storagetypes.BUCKET_STATUS_DISCONTINUED {
+		return nil, ErrNoSuchBucket
+	}
+	if _, ok = c.objectNames[objectKey(object.GetBucketName(), object.GetObjectName())]; ok {
+		return nil, ErrRepeatedObject
+	}
+	if object.GetOwner() == "" {
+		object.Owner = bucket.GetOwner()
+	}
+	object.Id = sdkmath.NewUint(c.nextObjectID)
+	object.CreateAt = c.blockTime.Unix()
+	object.ObjectStatus = storagetypes.OBJECT_STATUS_CREATED
+	c.nextObjectID++
+	c.accounts[object.GetOwner()] = struct{}{}
+	c.objects[object.Id.String()] = object
+	c.objectNames[objectKey(object.GetBucketName(), object.GetObjectName())] = object.Id.String()
+	return object, nil
+}
+
+// DeleteObject removes the object from the ledger.
+func (c *FakeChain) DeleteObject(bucket, object string) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	key := objectKey(bucket, object)
+	id, ok := c.objectNames[key]
+	if !ok {
+		return ErrNoSuchObject
+	}
+	delete(c.objectNames, key)
+	delete(c.objects, id)
+	return nil
+}
+
+// spByOperator returns the SP info by operator address, the caller should
+// hold the lock.
+func (c *FakeChain) spByOperator(operator string) *sptypes.StorageProvider {
+	for _, sp := range c.sps {
+		if sp.GetOperatorAddress() == operator {
+			return sp
+		}
+	}
+	return nil
+}
+
+// objectByName returns the object info by bucket and object name, the caller
+// should hold the lock.
+func (c *FakeChain) objectByName(bucket, object string) *storagetypes.ObjectInfo {
+	id, ok := c.objectNames[objectKey(bucket, object)]
+	if !ok {
+		return nil
+	}
+	return c.objects[id]
+}
+
+func objectKey(bucket, object string) string {
+	return bucket + "/" + object
+}
+
+func formatID(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
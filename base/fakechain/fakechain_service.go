@@ -0,0 +1,208 @@
+package fakechain
+
+import (
+	"context"
+
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	paymenttypes "github.com/bnb-chain/greenfield/x/payment/types"
+	permissiontypes "github.com/bnb-chain/greenfield/x/permission/types"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// CurrentHeight returns the current block height of the fake chain.
+func (c *FakeChain) CurrentHeight(ctx context.Context) (uint64, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.height, nil
+}
+
+// HasAccount returns an indication of the existence of address.
+func (c *FakeChain) HasAccount(ctx context.Context, account string) (bool, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	_, ok := c.accounts[account]
+	return ok, nil
+}
+
+// QuerySPInfo returns the list of storage provider info.
+func (c *FakeChain) QuerySPInfo(ctx context.Context) ([]*sptypes.StorageProvider, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	spInfos := make([]*sptypes.StorageProvider, 0, len(c.sps))
+	for _, sp := range c.sps {
+		spInfo := *sp
+		spInfos = append(spInfos, &spInfo)
+	}
+	return spInfos, nil
+}
+
+// QueryStorageParams returns storage params.
+func (c *FakeChain) QueryStorageParams(ctx context.Context) (*storagetypes.Params, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	params := *c.params[len(c.params)-1].params
+	return &params, nil
+}
+
+// QueryStorageParamsByTimestamp returns the storage params that are effective at the timestamp.
+func (c *FakeChain) QueryStorageParamsByTimestamp(ctx context.Context, timestamp int64) (*storagetypes.Params, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	for i := len(c.params) - 1; i >= 0; i-- {
+		if c.params[i].effectiveTime <= timestamp {
+			params := *c.params[i].params
+			return &params, nil
+		}
+	}
+	log.CtxErrorw(ctx, "failed to query storage params by timestamp", "timestamp", timestamp,
+		"error", ErrNoVersionedParams)
+	return nil, ErrNoVersionedParams
+}
+
+// QueryBucketInfo returns the bucket info by name.
+func (c *FakeChain) QueryBucketInfo(ctx context.Context, bucket string) (*storagetypes.BucketInfo, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		log.CtxErrorw(ctx, "failed to query bucket", "bucket_name", bucket, "error", ErrNoSuchBucket)
+		return nil, ErrNoSuchBucket
+	}
+	info := *bucketInfo
+	return &info, nil
+}
+
+// QueryObjectInfo returns the object info by name.
+func (c *FakeChain) QueryObjectInfo(ctx context.Context, bucket, object string) (*storagetypes.ObjectInfo, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	objectInfo := c.objectByName(bucket, object)
+	if objectInfo == nil {
+		log.CtxErrorw(ctx, "failed to query object", "bucket_name", bucket, "object_name", object, "error", ErrNoSuchObject)
+		return nil, ErrNoSuchObject
+	}
+	info := *objectInfo
+	return &info, nil
+}
+
+// QueryObjectInfoByID returns the object info by id.
+func (c *FakeChain) QueryObjectInfoByID(ctx context.Context, objectID string) (*storagetypes.ObjectInfo, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	objectInfo, ok := c.objects[objectID]
+	if !ok {
+		log.CtxErrorw(ctx, "failed to query object", "object_id", objectID, "error", ErrNoSuchObject)
+		return nil, ErrNoSuchObject
+	}
+	info := *objectInfo
+	return &info, nil
+}
+
+// QueryBucketInfoAndObjectInfo returns bucket info and object info, if not found, return the corresponding error code
+func (c *FakeChain) QueryBucketInfoAndObjectInfo(ctx context.Context, bucket, object string) (
+	*storagetypes.BucketInfo, *storagetypes.ObjectInfo, error) {
+	bucketInfo, err := c.QueryBucketInfo(ctx, bucket)
+	if err != nil {
+		return nil, nil, err
+	}
+	objectInfo, err := c.QueryObjectInfo(ctx, bucket, object)
+	if err != nil {
+		return bucketInfo, nil, err
+	}
+	return bucketInfo, objectInfo, nil
+}
+
+// QueryPaymentStreamRecord returns the steam record info by account.
+func (c *FakeChain) QueryPaymentStreamRecord(ctx context.Context, account string) (*paymenttypes.StreamRecord, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	record, ok := c.streamRecords[account]
+	if !ok {
+		log.CtxErrorw(ctx, "failed to query stream record", "account", account, "error", ErrNoSuchStreamRecord)
+		return nil, ErrNoSuchStreamRecord
+	}
+	streamRecord := *record
+	return &streamRecord, nil
+}
+
+// VerifyGetObjectPermission verifies get object permission, the owner and the
+// public read object are always allowed.
+func (c *FakeChain) VerifyGetObjectPermission(ctx context.Context, account, bucket, object string) (bool, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		return false, ErrNoSuchBucket
+	}
+	objectInfo := c.objectByName(bucket, object)
+	if objectInfo == nil {
+		return false, ErrNoSuchObject
+	}
+	if objectInfo.GetOwner() == account {
+		return true, nil
+	}
+	visibility := objectInfo.GetVisibility()
+	if visibility == storagetypes.VISIBILITY_TYPE_INHERIT || visibility == storagetypes.VISIBILITY_TYPE_UNSPECIFIED {
+		visibility = bucketInfo.GetVisibility()
+	}
+	if visibility == storagetypes.VISIBILITY_TYPE_PUBLIC_READ {
+		return true, nil
+	}
+	return c.allowed(account, bucket, object, permissiontypes.ACTION_GET_OBJECT), nil
+}
+
+// VerifyPutObjectPermission verifies put object permission, the bucket owner is
+// always allowed.
+func (c *FakeChain) VerifyPutObjectPermission(ctx context.Context, account, bucket, object string) (bool, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	bucketInfo, ok := c.buckets[bucket]
+	if !ok {
+		return false, ErrNoSuchBucket
+	}
+	if bucketInfo.GetOwner() == account {
+		return true, nil
+	}
+	return c.allowed(account, bucket, "", permissiontypes.ACTION_CREATE_OBJECT), nil
+}
+
+// ListenObjectSeal returns an indication of the object is sealed before the
+// timeoutHeight blocks are produced.
+func (c *FakeChain) ListenObjectSeal(ctx context.Context, objectID uint64, timeoutHeight int) (bool, error) {
+	for i := 0; i < timeoutHeight; i++ {
+		if err := c.WaitBlock(ctx); err != nil {
+			log.CtxErrorw(ctx, "failed to listen seal object", "object_id", objectID, "error", err)
+			return false, err
+		}
+		objectInfo, err := c.QueryObjectInfoByID(ctx, formatID(objectID))
+		if err != nil {
+			continue
+		}
+		if objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_SEALED {
+			log.CtxDebugw(ctx, "succeed to listen object stat")
+			return true, nil
+		}
+	}
+	log.CtxErrorw(ctx, "seal object timeout", "object_id", objectID)
+	return false, ErrSealTimeout
+}
+
+// allowed returns an indication whether the action is granted to the account,
+// the caller should hold the lock.
+func (c *FakeChain) allowed(account, bucket, object string, action permissiontypes.ActionType) bool {
+	for _, permission := range c.permissions {
+		if permission.Account != account || permission.Bucket != bucket {
+			continue
+		}
+		if permission.Object != "" && permission.Object != object {
+			continue
+		}
+		for _, granted := range permission.Actions {
+			if granted == action || granted == permissiontypes.ACTION_TYPE_ALL {
+				return true
+			}
+		}
+	}
+	return false
+}
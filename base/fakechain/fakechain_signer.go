@@ -0,0 +1,102 @@
+package fakechain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// BroadcastSealObject checks the MsgSealObject and puts it into the pending txs,
+// the object is sealed when the next block is produced. The operator of msg must
+// be the seal address of the bucket's primary SP. Returns the fake tx hash.
+func (c *FakeChain) BroadcastSealObject(ctx context.Context, msg *storagetypes.MsgSealObject) ([]byte, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	bucketInfo, ok := c.buckets[msg.GetBucketName()]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+	if err := c.checkOperator(bucketInfo, msg.GetOperator(), func(sp *sptypes.StorageProvider) string {
+		return sp.GetSealAddress()
+	}); err != nil {
+		log.CtxErrorw(ctx, "failed to check seal operator", "seal_info", msg.String(), "error", err)
+		return nil, err
+	}
+	if c.objectByName(msg.GetBucketName(), msg.GetObjectName()) == nil {
+		return nil, ErrNoSuchObject
+	}
+	c.pending = append(c.pending, func() error {
+		objectInfo := c.objectByName(msg.GetBucketName(), msg.GetObjectName())
+		if objectInfo == nil {
+			return ErrNoSuchObject
+		}
+		if objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_CREATED {
+			return ErrInvalidObjectStatus
+		}
+		objectInfo.ObjectStatus = storagetypes.OBJECT_STATUS_SEALED
+		objectInfo.SecondarySpAddresses = msg.GetSecondarySpAddresses()
+		return nil
+	})
+	return c.txHash(msg.String()), nil
+}
+
+// BroadcastDiscontinueBucket checks the MsgDiscontinueBucket and puts it into the
+// pending txs, the bucket and objects in it are discontinued when the next block
+// is produced. The operator of msg must be the gc address of the bucket's primary
+// SP. Returns the fake tx hash.
+func (c *FakeChain) BroadcastDiscontinueBucket(ctx context.Context, msg *storagetypes.MsgDiscontinueBucket) ([]byte, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	bucketInfo, ok := c.buckets[msg.GetBucketName()]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+	if err := c.checkOperator(bucketInfo, msg.GetOperator(), func(sp *sptypes.StorageProvider) string {
+		return sp.GetGcAddress()
+	}); err != nil {
+		log.CtxErrorw(ctx, "failed to check gc operator", "discontinue_bucket", msg.String(), "error", err)
+		return nil, err
+	}
+	c.pending = append(c.pending, func() error {
+		bucket, exist := c.buckets[msg.GetBucketName()]
+		if !exist {
+			return ErrNoSuchBucket
+		}
+		bucket.BucketStatus = storagetypes.BUCKET_STATUS_DISCONTINUED
+		for _, object := range c.objects {
+			if object.GetBucketName() == msg.GetBucketName() {
+				object.ObjectStatus = storagetypes.OBJECT_STATUS_DISCONTINUED
+			}
+		}
+		return nil
+	})
+	return c.txHash(msg.String()), nil
+}
+
+// checkOperator checks the operator is the address of bucket's primary SP that
+// is chosen by addr, the caller should hold the lock.
+func (c *FakeChain) checkOperator(bucket *storagetypes.BucketInfo, operator string,
+	addr func(sp *sptypes.StorageProvider) string) error {
+	sp := c.spByOperator(bucket.GetPrimarySpAddress())
+	if sp == nil {
+		return ErrNoSuchSP
+	}
+	if addr(sp) != operator {
+		return ErrInvalidOperator
+	}
+	return nil
+}
+
+// txHash returns the fake tx hash that is calculated by the msg and the number
+// of pending txs, the caller should hold the lock.
+func (c *FakeChain) txHash(msg string) []byte {
+	nonce := make([]byte, 16)
+	binary.BigEndian.PutUint64(nonce, c.height)
+	binary.BigEndian.PutUint64(nonce[8:], uint64(len(c.pending)))
+	hash := sha256.Sum256(append([]byte(msg), nonce...))
+	return hash[:]
+}
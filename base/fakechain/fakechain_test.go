@@ -0,0 +1,175 @@
+package fakechain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	permissiontypes "github.com/bnb-chain/greenfield/x/permission/types"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+const (
+	mockOperator = "0x0000000000000000000000000000000000000001"
+	mockSealer   = "0x0000000000000000000000000000000000000002"
+	mockGc       = "0x0000000000000000000000000000000000000003"
+	mockOwner    = "0x0000000000000000000000000000000000000004"
+	mockReader   = "0x0000000000000000000000000000000000000005"
+)
+
+func setupFakeChain(t *testing.T) (*FakeChain, *storagetypes.ObjectInfo) {
+	chain := NewFakeChain(&FakeChainConfig{})
+	chain.AddStorageProvider(&sptypes.StorageProvider{
+		OperatorAddress: mockOperator,
+		SealAddress:     mockSealer,
+		GcAddress:       mockGc,
+	})
+	_, err := chain.CreateBucket(&storagetypes.BucketInfo{
+		Owner:            mockOwner,
+		BucketName:       "mock-bucket",
+		Visibility:       storagetypes.VISIBILITY_TYPE_PRIVATE,
+		PrimarySpAddress: mockOperator,
+	})
+	assert.Nil(t, err)
+	object, err := chain.CreateObject(&storagetypes.ObjectInfo{
+		BucketName:  "mock-bucket",
+		ObjectName:  "mock-object",
+		PayloadSize: 1024,
+		Visibility:  storagetypes.VISIBILITY_TYPE_INHERIT,
+	})
+	assert.Nil(t, err)
+	return chain, object
+}
+
+func TestFakeChainSealObject(t *testing.T) {
+	chain, object := setupFakeChain(t)
+	defer chain.Close()
+	ctx := context.Background()
+
+	_, err := chain.BroadcastSealObject(ctx, &storagetypes.MsgSealObject{
+		Operator:   mockGc,
+		BucketName: "mock-bucket",
+		ObjectName: "mock-object",
+	})
+	assert.Equal(t, ErrInvalidOperator, err)
+
+	_, err = chain.BroadcastSealObject(ctx, &storagetypes.MsgSealObject{
+		Operator:             mockSealer,
+		BucketName:           "mock-bucket",
+		ObjectName:           "mock-object",
+		SecondarySpAddresses: []string{mockOperator},
+	})
+	assert.Nil(t, err)
+	info, err := chain.QueryObjectInfo(ctx, "mock-bucket", "mock-object")
+	assert.Nil(t, err)
+	assert.Equal(t, storagetypes.OBJECT_STATUS_CREATED, info.GetObjectStatus())
+
+	sealed := make(chan bool)
+	go func() {
+		ok, _ := chain.ListenObjectSeal(ctx, object.Id.Uint64(), 3)
+		sealed <- ok
+	}()
+	for {
+		select {
+		case ok := <-sealed:
+			assert.True(t, ok)
+			info, err = chain.QueryObjectInfoByID(ctx, object.Id.String())
+			assert.Nil(t, err)
+			assert.Equal(t, storagetypes.OBJECT_STATUS_SEALED, info.GetObjectStatus())
+			assert.Equal(t, []string{mockOperator}, info.GetSecondarySpAddresses())
+			return
+		default:
+			chain.ProduceBlock()
+		}
+	}
+}
+
+func TestFakeChainListenObjectSealTimeout(t *testing.T) {
+	chain, object := setupFakeChain(t)
+	defer chain.Close()
+
+	result := make(chan error)
+	go func() {
+		_, err := chain.ListenObjectSeal(context.Background(), object.Id.Uint64(), 2)
+		result <- err
+	}()
+	for {
+		select {
+		case err := <-result:
+			assert.Equal(t, ErrSealTimeout, err)
+			return
+		default:
+			chain.ProduceBlock()
+		}
+	}
+}
+
+func TestFakeChainDiscontinueBucket(t *testing.T) {
+	chain, _ := setupFakeChain(t)
+	defer chain.Close()
+	ctx := context.Background()
+
+	_, err := chain.BroadcastDiscontinueBucket(ctx, &storagetypes.MsgDiscontinueBucket{
+		Operator:   mockGc,
+		BucketName: "mock-bucket",
+	})
+	assert.Nil(t, err)
+	chain.ProduceBlock()
+	bucket, object, err := chain.QueryBucketInfoAndObjectInfo(ctx, "mock-bucket", "mock-object")
+	assert.Nil(t, err)
+	assert.Equal(t, storagetypes.BUCKET_STATUS_DISCONTINUED, bucket.GetBucketStatus())
+	assert.Equal(t, storagetypes.OBJECT_STATUS_DISCONTINUED, object.GetObjectStatus())
+}
+
+func TestFakeChainVerifyPermission(t *testing.T) {
+	chain, _ := setupFakeChain(t)
+	defer chain.Close()
+	ctx := context.Background()
+
+	allowed, err := chain.VerifyGetObjectPermission(ctx, mockOwner, "mock-bucket", "mock-object")
+	assert.Nil(t, err)
+	assert.True(t, allowed)
+	allowed, err = chain.VerifyGetObjectPermission(ctx, mockReader, "mock-bucket", "mock-object")
+	assert.Nil(t, err)
+	assert.False(t, allowed)
+
+	chain.GrantPermission(&Permission{
+		Account: mockReader,
+		Bucket:  "mock-bucket",
+		Actions: []permissiontypes.ActionType{permissiontypes.ACTION_GET_OBJECT},
+	})
+	allowed, err = chain.VerifyGetObjectPermission(ctx, mockReader, "mock-bucket", "mock-object")
+	assert.Nil(t, err)
+	assert.True(t, allowed)
+	allowed, err = chain.VerifyPutObjectPermission(ctx, mockReader, "mock-bucket", "new-object")
+	assert.Nil(t, err)
+	assert.False(t, allowed)
+}
+
+func TestFakeChainQueryStorageParamsByTimestamp(t *testing.T) {
+	chain, object := setupFakeChain(t)
+	defer chain.Close()
+	ctx := context.Background()
+
+	genesis, err := chain.QueryStorageParams(ctx)
+	assert.Nil(t, err)
+	updated := *genesis
+	updated.VersionedParams.MaxSegmentSize = genesis.VersionedParams.GetMaxSegmentSize() * 2
+	chain.blockTime = chain.blockTime.Add(time.Minute)
+	chain.SetStorageParams(&updated)
+
+	params, err := chain.QueryStorageParamsByTimestamp(ctx, object.GetCreateAt())
+	assert.Nil(t, err)
+	assert.Equal(t, genesis.VersionedParams, params.VersionedParams)
+	params, err = chain.QueryStorageParamsByTimestamp(ctx, chain.blockTime.Unix())
+	assert.Nil(t, err)
+	assert.Equal(t, updated.VersionedParams, params.VersionedParams)
+	params, err = chain.QueryStorageParams(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, updated.VersionedParams, params.VersionedParams)
+	_, err = chain.QueryStorageParamsByTimestamp(ctx, -1)
+	assert.Equal(t, ErrNoVersionedParams, err)
+}
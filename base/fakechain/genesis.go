@@ -0,0 +1,71 @@
+package fakechain
+
+import (
+	"encoding/json"
+	"os"
+
+	paymenttypes "github.com/bnb-chain/greenfield/x/payment/types"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// Genesis defines the initial ledger of the fake chain, it is loaded from the
+// json file for scripting the local develop environment. The objects are created
+// with the status in the genesis, the ids of buckets and objects are reassigned.
+type Genesis struct {
+	Accounts         []string                     `json:"accounts"`
+	StorageProviders []*sptypes.StorageProvider   `json:"storage_providers"`
+	StorageParams    *storagetypes.Params         `json:"storage_params"`
+	Buckets          []*storagetypes.BucketInfo   `json:"buckets"`
+	Objects          []*storagetypes.ObjectInfo   `json:"objects"`
+	StreamRecords    []*paymenttypes.StreamRecord `json:"stream_records"`
+	Permissions      []*Permission                `json:"permissions"`
+}
+
+// LoadGenesis loads the genesis from the json file.
+func LoadGenesis(file string) (*Genesis, error) {
+	bz, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	genesis := &Genesis{}
+	if err = json.Unmarshal(bz, genesis); err != nil {
+		return nil, err
+	}
+	return genesis, nil
+}
+
+// ImportGenesis writes the genesis into the ledger, the SPs must be imported
+// before the buckets that use them as primary SP.
+func (c *FakeChain) ImportGenesis(genesis *Genesis) error {
+	for _, account := range genesis.Accounts {
+		c.AddAccount(account)
+	}
+	for _, sp := range genesis.StorageProviders {
+		c.AddStorageProvider(sp)
+	}
+	if genesis.StorageParams != nil {
+		c.SetStorageParams(genesis.StorageParams)
+	}
+	for _, bucket := range genesis.Buckets {
+		if _, err := c.CreateBucket(bucket); err != nil {
+			return err
+		}
+	}
+	for _, object := range genesis.Objects {
+		status := object.GetObjectStatus()
+		if _, err := c.CreateObject(object); err != nil {
+			return err
+		}
+		c.mux.Lock()
+		object.ObjectStatus = status
+		c.mux.Unlock()
+	}
+	for _, record := range genesis.StreamRecords {
+		c.SetStreamRecord(record)
+	}
+	for _, permission := range genesis.Permissions {
+		c.GrantPermission(permission)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package gfspapp
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspclient"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfspserver"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+const (
+	// SpAdminToken defines env variable name for the token of admin service.
+	SpAdminToken = "SP_ADMIN_TOKEN"
+	// DefaultDrainTimeout defines the default seconds to wait for the in-flight work
+	// to finish when draining.
+	DefaultDrainTimeout = 60
+	// DrainCheckInterval defines the interval of checking in-flight work when draining.
+	DrainCheckInterval = 100 * time.Millisecond
+)
+
+var (
+	ErrAdminUnauthorized = gfsperrors.Register(BaseCodeSpace, http.StatusUnauthorized, 990001, "admin token is invalid or admin service is disabled")
+	ErrNodeDraining      = gfsperrors.Register(BaseCodeSpace, http.StatusServiceUnavailable, 990002, "node is draining, try other node later")
+	ErrTaskCanceled      = gfsperrors.Register(BaseCodeSpace, http.StatusGone, 990003, "task has been canceled by admin")
+	ErrMigrateBucket     = gfsperrors.Register(BaseCodeSpace, http.StatusBadRequest, 990005, "invalid bucket or source sp to migrate")
+	ErrSPExitNotStarted  = gfsperrors.Register(BaseCodeSpace, http.StatusNotFound, 990006, "sp exit has not been started")
+)
+
+var _ gfspserver.GfSpAdminServiceServer = &GfSpBaseApp{}
+
+func (g *GfSpBaseApp) GfSpPauseTask(
+	ctx context.Context,
+	req *gfspserver.GfSpPauseTaskRequest) (
+	*gfspserver.GfSpPauseTaskResponse, error) {
+	if err := g.checkAdminToken(ctx); err != nil {
+		return &gfspserver.GfSpPauseTaskResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	err := g.manager.PauseDispatchTask(ctx, coretask.TType(req.GetTaskType()), req.GetPause())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to pause task", "task_type", req.GetTaskType(), "error", err)
+		return &gfspserver.GfSpPauseTaskResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	return &gfspserver.GfSpPauseTaskResponse{}, nil
+}
+
+func (g *GfSpBaseApp) GfSpCancelTask(
+	ctx context.Context,
+	req *gfspserver.GfSpCancelTaskRequest) (
+	*gfspserver.GfSpCancelTaskResponse, error) {
+	if err := g.checkAdminToken(ctx); err != nil {
+		return &gfspserver.GfSpCancelTaskResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	// the task is canceled both in the manager queues and the in-flight work of
+	// this node, it is enough to find the task in any of them.
+	canceled := g.inflight.Cancel(req.GetTaskKey())
+	err := g.manager.CancelTask(ctx, coretask.TKey(req.GetTaskKey()))
+	if err != nil && !canceled {
+		log.CtxErrorw(ctx, "failed to cancel task", "task_key", req.GetTaskKey(), "error", err)
+		return &gfspserver.GfSpCancelTaskResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	log.CtxInfow(ctx, "succeed to cancel task", "task_key", req.GetTaskKey())
+	return &gfspserver.GfSpCancelTaskResponse{}, nil
+}
+
+func (g *GfSpBaseApp) GfSpDrain(
+	ctx context.Context,
+	req *gfspserver.GfSpDrainRequest) (
+	*gfspserver.GfSpDrainResponse, error) {
+	if err := g.checkAdminToken(ctx); err != nil {
+		return &gfspserver.GfSpDrainResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	if !req.GetDrain() {
+		g.inflight.SetDraining(false)
+		log.CtxInfow(ctx, "succeed to undrain node")
+		return &gfspserver.GfSpDrainResponse{Inflight: g.inflight.Count()}, nil
+	}
+	timeout := req.GetTimeout()
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	g.inflight.SetDraining(true)
+	inflight := g.inflight.Wait(ctx, time.Duration(timeout)*time.Second)
+	log.CtxInfow(ctx, "finish to drain node", "inflight", inflight)
+	return &gfspserver.GfSpDrainResponse{Inflight: inflight}, nil
+}
+
+func (g *GfSpBaseApp) GfSpAdminStatus(
+	ctx context.Context,
+	req *gfspserver.GfSpAdminStatusRequest) (
+	*gfspserver.GfSpAdminStatusResponse, error) {
+	if err := g.checkAdminToken(ctx); err != nil {
+		return &gfspserver.GfSpAdminStatusResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	resp := &gfspserver.GfSpAdminStatusResponse{
+		Draining: g.inflight.Draining(),
+		Inflight: g.inflight.Count(),
+	}
+	// the manager is not started in this node if the query fails
+	if taskTypes, err := g.manager.QueryPausedTaskTypes(ctx); err == nil {
+		for _, taskType := range taskTypes {
+			resp.PausedTaskTypes = append(resp.PausedTaskTypes, int32(taskType))
+		}
+	}
+	return resp, nil
+}
+
+func (g *GfSpBaseApp) GfSpReloadConfig(
+	ctx context.Context,
+	req *gfspserver.GfSpReloadConfigRequest) (
+	*gfspserver.GfSpReloadConfigResponse, error) {
+	if err := g.checkAdminToken(ctx); err != nil {
+		return &gfspserver.GfSpReloadConfigResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	version, err := g.ReloadConfig(ctx)
+	if err != nil {
+		return &gfspserver.GfSpReloadConfigResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	return &gfspserver.GfSpReloadConfigResponse{Version: version}, nil
+}
+
+func (g *GfSpBaseApp) GfSpMigrateBucket(
+	ctx context.Context,
+	req *gfspserver.GfSpMigrateBucketRequest) (
+	*gfspserver.GfSpMigrateBucketResponse, error) {
+	if err := g.checkAdminToken(ctx); err != nil {
+		return &gfspserver.GfSpMigrateBucketResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	if req.GetBucketName() == "" || req.GetSrcSpEndpoint() == "" {
+		log.CtxErrorw(ctx, "failed to migrate bucket, bucket name or source sp endpoint is empty")
+		return &gfspserver.GfSpMigrateBucketResponse{Err: ErrMigrateBucket}, nil
+	}
+	// the source sp exports the bucket only if the bucket owner approves migrating to this sp
+	if len(req.GetOwnerSignature()) == 0 || req.GetApprovalExpiry() < time.Now().Unix() {
+		log.CtxErrorw(ctx, "failed to migrate bucket, no valid approval of the bucket owner")
+		return &gfspserver.GfSpMigrateBucketResponse{Err: ErrMigrateBucket}, nil
+	}
+	bucketInfo, err := g.chain.QueryBucketInfo(ctx, req.GetBucketName())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query bucket info", "bucket_name", req.GetBucketName(), "error", err)
+		return &gfspserver.GfSpMigrateBucketResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	task := &gfsptask.GfSpMigrateBucketTask{}
+	task.InitMigrateBucketTask(bucketInfo, req.GetSrcSpEndpoint(), g.OperateAddress(),
+		g.TaskPriority(task), g.TaskTimeout(task, 0), g.TaskMaxRetry(task))
+	task.SetOwnerSignature(req.GetOwnerSignature())
+	task.SetApprovalExpiry(req.GetApprovalExpiry())
+	ctx = log.WithValue(ctx, log.CtxKeyTask, task.Key().String())
+	if err = g.manager.HandleCreateMigrateBucketTask(ctx, task); err != nil {
+		log.CtxErrorw(ctx, "failed to create migrate bucket task", "error", err)
+		return &gfspserver.GfSpMigrateBucketResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	log.CtxInfow(ctx, "succeed to create migrate bucket task", "info", task.Info())
+	return &gfspserver.GfSpMigrateBucketResponse{TaskKey: task.Key().String()}, nil
+}
+
+func (g *GfSpBaseApp) GfSpSPExit(
+	ctx context.Context,
+	req *gfspserver.GfSpSPExitRequest) (
+	*gfspserver.GfSpSPExitResponse, error) {
+	if err := g.checkAdminToken(ctx); err != nil {
+		return &gfspserver.GfSpSPExitResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	task := &gfsptask.GfSpSPExitTask{}
+	task.InitSPExitTask(g.OperateAddress(), g.TaskPriority(task), g.TaskTimeout(task, 0), g.TaskMaxRetry(task))
+	ctx = log.WithValue(ctx, log.CtxKeyTask, task.Key().String())
+	if err := g.manager.HandleCreateSPExitTask(ctx, task); err != nil {
+		log.CtxErrorw(ctx, "failed to create sp exit task", "error", err)
+		return &gfspserver.GfSpSPExitResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	g.setSPExiting()
+	log.CtxInfow(ctx, "succeed to create sp exit task", "info", task.Info())
+	return &gfspserver.GfSpSPExitResponse{TaskKey: task.Key().String()}, nil
+}
+
+func (g *GfSpBaseApp) GfSpQuerySPExit(
+	ctx context.Context,
+	req *gfspserver.GfSpQuerySPExitRequest) (
+	*gfspserver.GfSpQuerySPExitResponse, error) {
+	if err := g.checkAdminToken(ctx); err != nil {
+		return &gfspserver.GfSpQuerySPExitResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	progress, err := g.gfSpDB.GetSPExitProgress(g.OperateAddress())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get sp exit progress", "error", err)
+		return &gfspserver.GfSpQuerySPExitResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	if progress == nil {
+		return &gfspserver.GfSpQuerySPExitResponse{Err: ErrSPExitNotStarted}, nil
+	}
+	records, err := g.gfSpDB.ListFailedSPExitRecords(int(req.GetLimit()))
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list failed sp exit records", "error", err)
+		return &gfspserver.GfSpQuerySPExitResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	resp := &gfspserver.GfSpQuerySPExitResponse{
+		Exiting:               true,
+		Finished:              progress.Finished,
+		LastSecondaryObjectId: progress.LastSecondaryObjectID,
+		LastPrimaryBucketId:   progress.LastPrimaryBucketID,
+		HandoverObjectNumber:  progress.HandoverObjectNumber,
+		FailedObjectNumber:    progress.FailedObjectNumber,
+		HandoverBucketNumber:  progress.HandoverBucketNumber,
+		FailedBucketNumber:    progress.FailedBucketNumber,
+		StartTime:             progress.StartTime,
+		UpdateTime:            progress.UpdateTime,
+	}
+	for _, record := range records {
+		resp.FailedRecords = append(resp.FailedRecords, &gfspserver.GfSpSPExitRecord{
+			ResourceType:  record.ResourceType,
+			ResourceId:    record.ResourceID,
+			ResourceName:  record.ResourceName,
+			DestSpAddress: record.DestSpAddress,
+			ErrorMessage:  record.ErrorMessage,
+			UpdateTime:    record.UpdateTime,
+		})
+	}
+	return resp, nil
+}
+
+func (g *GfSpBaseApp) GfSpQuerySPScores(
+	ctx context.Context,
+	req *gfspserver.GfSpQuerySPScoresRequest) (
+	*gfspserver.GfSpQuerySPScoresResponse, error) {
+	if err := g.checkAdminToken(ctx); err != nil {
+		return &gfspserver.GfSpQuerySPScoresResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	scores, err := g.gfSpDB.ListSPScores()
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list sp scores", "error", err)
+		return &gfspserver.GfSpQuerySPScoresResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	resp := &gfspserver.GfSpQuerySPScoresResponse{}
+	for _, score := range scores {
+		resp.Scores = append(resp.Scores, &gfspserver.GfSpSPScore{
+			SpAddress:        score.SpAddress,
+			Score:            score.Score(),
+			ReplicateSucceed: score.ReplicateSucceed,
+			ReplicateFailed:  score.ReplicateFailed,
+			Throughput:       score.Throughput(),
+			PingRtt:          score.PingRTT,
+			P2PFailure:       score.P2PFailure,
+			UpdateTime:       score.UpdateTime,
+		})
+	}
+	return resp, nil
+}
+
+// BeginInflightTask registers the task executed by the modules of this node, the task is
+// canceled by the cancel request and waited by the drain request, the returned done func
+// must be called after the task finishes. ErrNodeDraining is returned when draining.
+func (g *GfSpBaseApp) BeginInflightTask(key string, cancel func()) (func(), error) {
+	return g.inflight.Begin(key, cancel)
+}
+
+// Draining returns whether the node is draining, the modules should not take new tasks.
+func (g *GfSpBaseApp) Draining() bool {
+	return g.inflight.Draining()
+}
+
+// checkAdminToken authenticates the admin request by the token in grpc metadata.
+func (g *GfSpBaseApp) checkAdminToken(ctx context.Context) error {
+	if g.adminToken == "" {
+		log.CtxError(ctx, "admin service is disabled, the admin token is not configured")
+		return ErrAdminUnauthorized
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ErrAdminUnauthorized
+	}
+	tokens := md.Get(gfspclient.AdminTokenMetadataKey)
+	if len(tokens) != 1 || subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(g.adminToken)) != 1 {
+		log.CtxErrorw(ctx, "failed to authenticate admin request", "remote", RpcRemoteAddress(ctx))
+		return ErrAdminUnauthorized
+	}
+	return nil
+}
+
+// inflightTracker tracks the in-flight work of the node, including the upload, receive and
+// download requests and the tasks executed by the executor,
+// the work can be canceled by task key, and no new work is accepted when draining.
+type inflightTracker struct {
+	mux      sync.Mutex
+	draining bool
+	nextID   uint64
+	tasks    map[uint64]*inflightTask
+}
+
+type inflightTask struct {
+	key    string
+	cancel func()
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{tasks: make(map[uint64]*inflightTask)}
+}
+
+// Begin registers the in-flight work, cancel is called if the work is canceled by admin,
+// the returned done func must be called after the work finishes.
+func (t *inflightTracker) Begin(key string, cancel func()) (func(), error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.draining {
+		return nil, ErrNodeDraining
+	}
+	t.nextID++
+	id := t.nextID
+	t.tasks[id] = &inflightTask{key: key, cancel: cancel}
+	return func() {
+		t.mux.Lock()
+		defer t.mux.Unlock()
+		delete(t.tasks, id)
+	}, nil
+}
+
+// Cancel cancels the in-flight work of the task key, returns whether any work is canceled.
+func (t *inflightTracker) Cancel(key string) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	canceled := false
+	for _, task := range t.tasks {
+		if task.key == key {
+			task.cancel()
+			canceled = true
+		}
+	}
+	return canceled
+}
+
+// SetDraining sets whether to reject the new work.
+func (t *inflightTracker) SetDraining(draining bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.draining = draining
+}
+
+// Draining returns whether the new work is rejected.
+func (t *inflightTracker) Draining() bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.draining
+}
+
+// Count returns the number of in-flight work.
+func (t *inflightTracker) Count() int64 {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return int64(len(t.tasks))
+}
+
+// Wait blocks until all the in-flight work finishes or timeout, returns the number of
+// unfinished work.
+func (t *inflightTracker) Wait(ctx context.Context, timeout time.Duration) int64 {
+	ticker := time.NewTicker(DrainCheckInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		if t.Count() == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return t.Count()
+		case <-deadline:
+			return t.Count()
+		case <-ticker.C:
+		}
+	}
+}
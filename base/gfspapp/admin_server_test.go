@@ -0,0 +1,55 @@
+package gfspapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInflightTrackerCancel(t *testing.T) {
+	tracker := newInflightTracker()
+	var canceled []string
+	doneA, err := tracker.Begin("task-a", func() { canceled = append(canceled, "task-a") })
+	require.NoError(t, err)
+	doneB, err := tracker.Begin("task-b", func() { canceled = append(canceled, "task-b") })
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), tracker.Count())
+
+	assert.True(t, tracker.Cancel("task-a"))
+	assert.False(t, tracker.Cancel("task-c"))
+	assert.Equal(t, []string{"task-a"}, canceled)
+
+	doneA()
+	doneB()
+	assert.Equal(t, int64(0), tracker.Count())
+	// the finished task is not canceled again
+	assert.False(t, tracker.Cancel("task-a"))
+}
+
+func TestInflightTrackerDrain(t *testing.T) {
+	app := &GfSpBaseApp{inflight: newInflightTracker()}
+	done, err := app.BeginInflightTask("task-a", func() {})
+	require.NoError(t, err)
+
+	app.inflight.SetDraining(true)
+	assert.True(t, app.Draining())
+	_, err = app.BeginInflightTask("task-b", func() {})
+	assert.Equal(t, ErrNodeDraining, err)
+
+	// the unfinished task is returned after timeout
+	assert.Equal(t, int64(1), app.inflight.Wait(context.Background(), 2*DrainCheckInterval))
+
+	go func() {
+		time.Sleep(DrainCheckInterval)
+		done()
+	}()
+	assert.Equal(t, int64(0), app.inflight.Wait(context.Background(), time.Minute))
+
+	app.inflight.SetDraining(false)
+	done, err = app.BeginInflightTask("task-b", func() {})
+	require.NoError(t, err)
+	done()
+}
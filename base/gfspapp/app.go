@@ -2,11 +2,13 @@ package gfspapp
 
 import (
 	"context"
+	"sync"
 	"syscall"
 
 	"google.golang.org/grpc"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspclient"
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	"github.com/bnb-chain/greenfield-storage-provider/core/consensus"
 	corelifecycle "github.com/bnb-chain/greenfield-storage-provider/core/lifecycle"
 	"github.com/bnb-chain/greenfield-storage-provider/core/module"
@@ -54,6 +56,8 @@ type GfSpBaseApp struct {
 	appCancel context.CancelFunc
 	services  []corelifecycle.Service
 
+	// taskMux protects the task speeds, timeouts and retries that can be reloaded.
+	taskMux        sync.RWMutex
 	uploadSpeed    int64
 	downloadSpeed  int64
 	replicateSpeed int64
@@ -63,6 +67,7 @@ type GfSpBaseApp struct {
 	gcObjectTimeout   int64
 	gcZombieTimeout   int64
 	gcMetaTimeout     int64
+	migrateTimeout    int64
 
 	sealObjectRetry     int64
 	replicateRetry      int64
@@ -70,6 +75,23 @@ type GfSpBaseApp struct {
 	gcObjectRetry       int64
 	gcZombieRetry       int64
 	gcMetaRetry         int64
+	migrateRetry        int64
+
+	adminToken string
+	inflight   *inflightTracker
+
+	// spExitMux protects the cached exiting state of the sp.
+	spExitMux       sync.Mutex
+	spExiting       bool
+	spExitCheckTime int64
+
+	// paramsMux protects the cached versioned params that are verified on greenfield.
+	paramsMux       sync.RWMutex
+	versionedParams []*spdb.VersionedParams
+
+	reloadMux    sync.Mutex
+	config       *gfspconfig.GfSpConfig
+	configLoader gfspconfig.ConfigLoader
 }
 
 // AppID returns the GfSpBaseApp ID, the default value is prefix(gfsp) add
@@ -145,9 +167,11 @@ func (g *GfSpBaseApp) Start(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	g.Signals(syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP).
-		StartServices(ctx).
-		Wait(ctx)
+	g.recordActiveConfig()
+	g.Signals(syscall.SIGINT, syscall.SIGTERM).
+		StartServices(ctx)
+	g.ReloadSignals(syscall.SIGHUP)
+	g.Wait(ctx)
 	g.close(ctx)
 	return nil
 }
@@ -1,6 +1,7 @@
 package gfspapp
 
 import (
+	"errors"
 	"math"
 	"os"
 	"strings"
@@ -107,12 +108,19 @@ func DefaultStaticOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
 	app.gcObjectTimeout = cfg.Task.GcObjectTaskTimeout
 	app.gcZombieTimeout = cfg.Task.GcZombieTaskTimeout
 	app.gcMetaTimeout = cfg.Task.GcMetaTaskTimeout
+	app.migrateTimeout = cfg.Task.MigrateBucketTaskTimeout
 	app.sealObjectRetry = cfg.Task.SealObjectTaskRetry
 	app.replicateRetry = cfg.Task.ReplicateTaskRetry
 	app.receiveConfirmRetry = cfg.Task.ReceiveConfirmTaskRetry
 	app.gcObjectRetry = cfg.Task.GcObjectTaskRetry
 	app.gcZombieRetry = cfg.Task.GcZombieTaskRetry
 	app.gcMetaRetry = cfg.Task.GcMetaTaskRetry
+	app.migrateRetry = cfg.Task.MigrateBucketTaskRetry
+	if val, ok := os.LookupEnv(SpAdminToken); ok {
+		cfg.Admin.Token = val
+	}
+	app.adminToken = cfg.Admin.Token
+	app.inflight = newInflightTracker()
 	app.approver = &coremodule.NullModular{}
 	app.authorizer = &coremodule.NullModular{}
 	app.downloader = &coremodule.NilModular{}
@@ -216,6 +224,10 @@ func DefaultGfSpDBOption(app *GfSpBaseApp, cfg *gfspconfig.GfSpConfig) error {
 	}
 	dbCfg := &cfg.SpDB
 	db, err := sqldb.NewSpDB(dbCfg)
+	if errors.Is(err, sqldb.ErrUnknownSchemaVersion) {
+		log.Errorw("refuse to run against the spdb migrated by newer release", "error", err)
+		return err
+	}
 	if err != nil {
 		log.Warnw("if not use spdb, please ignore: failed to new spdb", "error", err)
 		return nil
@@ -484,6 +496,17 @@ func NewGfSpBaseApp(cfg *gfspconfig.GfSpConfig, opts ...gfspconfig.Option) (*GfS
 		return nil, err
 	}
 	app := &GfSpBaseApp{}
+	if cfg.Customize.ConfigLoader != nil {
+		// the reloaded config is compared with the config loaded in the same way
+		// to find the changed fields, the default values are not filled in it.
+		rawCfg, err := cfg.Customize.ConfigLoader()
+		if err != nil {
+			log.Errorw("failed to load config by config loader", "error", err)
+			return nil, err
+		}
+		app.config = rawCfg
+		app.configLoader = cfg.Customize.ConfigLoader
+	}
 	for _, opt := range gfspBaseAppDefaultOptions {
 		err := opt(app, cfg)
 		if err != nil {
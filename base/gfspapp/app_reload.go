@@ -0,0 +1,137 @@
+package gfspapp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	corelifecycle "github.com/bnb-chain/greenfield-storage-provider/core/lifecycle"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+var ErrReloadUnsupported = gfsperrors.Register(BaseCodeSpace, http.StatusNotImplemented, 990004, "config reload is unsupported, no config loader")
+
+// ReloadSignals reloads the config when receives the signals.
+func (g *GfSpBaseApp) ReloadSignals(sigs ...os.Signal) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, sigs...)
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-g.appCtx.Done():
+				return
+			case sig := <-sigCh:
+				log.Infow("received signal to reload config", "signal", sig.String())
+				if _, err := g.ReloadConfig(g.appCtx); err != nil {
+					log.Errorw("failed to reload config", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// ReloadConfig loads the config by the config loader, applies the reloadable subset
+// of the config to the base app and the services implement corelifecycle.Reloadable,
+// and records the active config to sp db. Returns the version of active config.
+// The changes of the fields can not be reloaded are rejected before applying.
+func (g *GfSpBaseApp) ReloadConfig(ctx context.Context) (string, error) {
+	g.reloadMux.Lock()
+	defer g.reloadMux.Unlock()
+	if g.configLoader == nil {
+		return "", ErrReloadUnsupported
+	}
+	newCfg, err := g.configLoader()
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to load config", "error", err)
+		return "", err
+	}
+	changed := g.config.ChangedFields(newCfg)
+	if err = g.config.CheckReloadable(newCfg); err != nil {
+		log.CtxErrorw(ctx, "failed to reload config", "error", err)
+		return "", err
+	}
+	if len(changed) == 0 {
+		log.CtxInfow(ctx, "no config changed, skip to reload")
+		return configVersion(g.config), nil
+	}
+
+	// keep the loaded config, the services fill the default values in newCfg.
+	rawCfg := *newCfg
+	newCfg.Customize = g.config.Customize
+	g.reloadTaskConfig(&newCfg.Task)
+	if newCfg.Log.Level != g.config.Log.Level {
+		level, err := log.ParseLevel(newCfg.Log.Level)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to parse log level", "level", newCfg.Log.Level, "error", err)
+			return "", err
+		}
+		log.SetLevel(level)
+	}
+	for _, service := range g.services {
+		reloadable, ok := service.(corelifecycle.Reloadable)
+		if !ok {
+			continue
+		}
+		if err = reloadable.ReloadConfig(ctx, newCfg); err != nil {
+			// the services reloaded before are not rolled back, the next reload
+			// applies the whole reloadable subset again.
+			log.CtxErrorw(ctx, "failed to reload service config", "service_name", service.Name(), "error", err)
+			return "", fmt.Errorf("failed to reload %s config: %w", service.Name(), err)
+		}
+	}
+	g.config = &rawCfg
+	version := g.recordActiveConfig()
+	log.CtxInfow(ctx, "succeed to reload config", "changed_fields", changed, "version", version)
+	return version, nil
+}
+
+// reloadTaskConfig applies the task speeds, timeouts and retries.
+func (g *GfSpBaseApp) reloadTaskConfig(cfg *gfspconfig.TaskConfig) {
+	g.taskMux.Lock()
+	defer g.taskMux.Unlock()
+	g.uploadSpeed = cfg.UploadTaskSpeed
+	g.downloadSpeed = cfg.DownloadTaskSpeed
+	g.replicateSpeed = cfg.ReplicateTaskSpeed
+	g.receiveSpeed = cfg.ReceiveTaskSpeed
+	g.sealObjectTimeout = cfg.SealObjectTaskTimeout
+	g.gcObjectTimeout = cfg.GcObjectTaskTimeout
+	g.gcZombieTimeout = cfg.GcZombieTaskTimeout
+	g.gcMetaTimeout = cfg.GcMetaTaskTimeout
+	g.migrateTimeout = cfg.MigrateBucketTaskTimeout
+	g.sealObjectRetry = cfg.SealObjectTaskRetry
+	g.replicateRetry = cfg.ReplicateTaskRetry
+	g.receiveConfirmRetry = cfg.ReceiveConfirmTaskRetry
+	g.gcObjectRetry = cfg.GcObjectTaskRetry
+	g.gcZombieRetry = cfg.GcZombieTaskRetry
+	g.gcMetaRetry = cfg.GcMetaTaskRetry
+	g.migrateRetry = cfg.MigrateBucketTaskRetry
+}
+
+// recordActiveConfig records the active config without secrets to the service config
+// table of sp db, returns the version of active config.
+func (g *GfSpBaseApp) recordActiveConfig() string {
+	if g.config == nil {
+		return ""
+	}
+	version := configVersion(g.config)
+	if g.gfSpDB == nil {
+		return version
+	}
+	if err := g.gfSpDB.SetAllServiceConfigs(version, g.config.RedactedString()); err != nil {
+		log.Errorw("failed to record active config", "version", version, "error", err)
+	}
+	return version
+}
+
+// configVersion returns the sha256 hash of the config without secrets as the version.
+func configVersion(cfg *gfspconfig.GfSpConfig) string {
+	hash := sha256.Sum256([]byte(cfg.RedactedString()))
+	return hex.EncodeToString(hash[:])
+}
@@ -28,6 +28,14 @@ func (g *GfSpBaseApp) GfSpDownloadObject(
 		return &gfspserver.GfSpDownloadObjectResponse{Err: ErrDownloadTaskDangling}, nil
 	}
 	ctx = log.WithValue(ctx, log.CtxKeyTask, task.Key().String())
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done, err := g.inflight.Begin(task.Key().String(), cancel)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to begin download object", "error", err)
+		return &gfspserver.GfSpDownloadObjectResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	defer done()
 	span, err := g.downloader.ReserveResource(ctx, task.EstimateLimit().ScopeStat())
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to reserve download resource", "error", err)
@@ -41,6 +41,7 @@ func (g *GfSpBaseApp) newRpcServer(options ...grpc.ServerOption) {
 	gfspserver.RegisterGfSpSignServiceServer(g.server, g)
 	gfspserver.RegisterGfSpUploadServiceServer(g.server, g)
 	gfspserver.RegisterGfSpQueryTaskServiceServer(g.server, g)
+	gfspserver.RegisterGfSpAdminServiceServer(g.server, g)
 	reflection.Register(g.server)
 }
 
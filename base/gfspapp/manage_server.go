@@ -34,6 +34,13 @@ func (g *GfSpBaseApp) GfSpBeginTask(
 	case *gfspserver.GfSpBeginTaskRequest_UploadObjectTask:
 		err := g.OnBeginUploadObjectTask(ctx, task.UploadObjectTask)
 		return &gfspserver.GfSpBeginTaskResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	case *gfspserver.GfSpBeginTaskRequest_MigrateBucketTask:
+		ctx = log.WithValue(ctx, log.CtxKeyTask, task.MigrateBucketTask.Key().String())
+		err := g.manager.HandleCreateMigrateBucketTask(ctx, task.MigrateBucketTask)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to begin migrate bucket task", "info", task.MigrateBucketTask.Info(), "error", err)
+		}
+		return &gfspserver.GfSpBeginTaskResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
 	default:
 		return &gfspserver.GfSpBeginTaskResponse{Err: ErrUnsupportedTaskType}, nil
 	}
@@ -99,6 +106,18 @@ func (g *GfSpBaseApp) GfSpAskTask(
 		resp.Response = &gfspserver.GfSpAskTaskResponse_GcMetaTask{
 			GcMetaTask: t,
 		}
+	case *gfsptask.GfSpMigrateBucketTask:
+		resp.Response = &gfspserver.GfSpAskTaskResponse_MigrateBucketTask{
+			MigrateBucketTask: t,
+		}
+	case *gfsptask.GfSpSPExitTask:
+		resp.Response = &gfspserver.GfSpAskTaskResponse_SpExitTask{
+			SpExitTask: t,
+		}
+	case *gfsptask.GfSpRepairPieceTask:
+		resp.Response = &gfspserver.GfSpAskTaskResponse_RepairPieceTask{
+			RepairPieceTask: t,
+		}
 	default:
 		log.CtxErrorw(ctx, "[BUG] Unsupported task type to dispatch")
 		return &gfspserver.GfSpAskTaskResponse{Err: ErrUnsupportedTaskType}, nil
@@ -230,6 +249,27 @@ func (g *GfSpBaseApp) GfSpReportTask(
 		log.CtxInfow(ctx, "begin to handle reported task", "info", task.Info())
 
 		err = g.manager.HandleChallengePieceTask(ctx, t.ChallengePieceTask)
+	case *gfspserver.GfSpReportTaskRequest_MigrateBucketTask:
+		task := t.MigrateBucketTask
+		ctx = log.WithValue(ctx, log.CtxKeyTask, task.Key().String())
+		task.SetAddress(RpcRemoteAddress(ctx))
+		log.CtxInfow(ctx, "begin to handle reported task", "info", task.Info())
+
+		err = g.manager.HandleMigrateBucketTask(ctx, t.MigrateBucketTask)
+	case *gfspserver.GfSpReportTaskRequest_SpExitTask:
+		task := t.SpExitTask
+		ctx = log.WithValue(ctx, log.CtxKeyTask, task.Key().String())
+		task.SetAddress(RpcRemoteAddress(ctx))
+		log.CtxInfow(ctx, "begin to handle reported task", "info", task.Info())
+
+		err = g.manager.HandleSPExitTask(ctx, t.SpExitTask)
+	case *gfspserver.GfSpReportTaskRequest_RepairPieceTask:
+		task := t.RepairPieceTask
+		ctx = log.WithValue(ctx, log.CtxKeyTask, task.Key().String())
+		task.SetAddress(RpcRemoteAddress(ctx))
+		log.CtxInfow(ctx, "begin to handle reported task", "info", task.Info())
+
+		err = g.manager.HandleRepairPieceTask(ctx, t.RepairPieceTask)
 	default:
 		log.CtxErrorw(ctx, "receive unsupported task type")
 		return &gfspserver.GfSpReportTaskResponse{Err: ErrUnsupportedTaskType}, nil
@@ -12,6 +12,7 @@ import (
 
 var (
 	ErrReplicatePieceApprovalTaskDangling = gfsperrors.Register(BaseCodeSpace, http.StatusInternalServerError, 990701, "OoooH... request lost")
+	ErrReplicatePieceByP2PTaskDangling    = gfsperrors.Register(BaseCodeSpace, http.StatusInternalServerError, 990702, "OoooH... request lost")
 )
 
 var _ gfspserver.GfSpP2PServiceServer = &GfSpBaseApp{}
@@ -49,3 +50,43 @@ func (g *GfSpBaseApp) GfSpQueryP2PBootstrap(ctx context.Context,
 	}
 	return &gfspserver.GfSpQueryP2PNodeResponse{Nodes: nodes}, nil
 }
+
+func (g *GfSpBaseApp) GfSpQueryP2PSelfNode(ctx context.Context,
+	req *gfspserver.GfSpQueryP2PNodeRequest) (
+	*gfspserver.GfSpQueryP2PNodeResponse, error) {
+	node, err := g.p2p.HandleQuerySelfNode(ctx)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query p2p self node", "error", err)
+		return &gfspserver.GfSpQueryP2PNodeResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	return &gfspserver.GfSpQueryP2PNodeResponse{Nodes: []string{node}}, nil
+}
+
+func (g *GfSpBaseApp) GfSpQueryUnhealthySp(ctx context.Context,
+	req *gfspserver.GfSpQueryUnhealthySpRequest) (
+	*gfspserver.GfSpQueryUnhealthySpResponse, error) {
+	sps, err := g.p2p.HandleQueryUnhealthySp(ctx)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query unhealthy sp", "error", err)
+		return &gfspserver.GfSpQueryUnhealthySpResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	return &gfspserver.GfSpQueryUnhealthySpResponse{SpAddresses: sps}, nil
+}
+
+func (g *GfSpBaseApp) GfSpReplicatePieceByP2P(ctx context.Context,
+	req *gfspserver.GfSpReplicatePieceByP2PRequest) (
+	*gfspserver.GfSpReplicatePieceByP2PResponse, error) {
+	approval := req.GetReplicatePieceApprovalTask()
+	receive := req.GetReceivePieceTask()
+	if approval == nil || receive == nil {
+		log.CtxError(ctx, "failed to replicate piece by p2p, task pointer dangling")
+		return nil, ErrReplicatePieceByP2PTaskDangling
+	}
+	ctx = log.WithValue(ctx, log.CtxKeyTask, receive.Key().String())
+	integrity, signature, err := g.p2p.HandleReplicatePiece(ctx, approval, receive, req.GetPieceData())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to replicate piece by p2p", "error", err)
+		return &gfspserver.GfSpReplicatePieceByP2PResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	return &gfspserver.GfSpReplicatePieceByP2PResponse{IntegrityHash: integrity, Signature: signature}, nil
+}
@@ -27,6 +27,14 @@ func (g *GfSpBaseApp) GfSpReplicatePiece(
 		return &gfspserver.GfSpReplicatePieceResponse{Err: ErrReceiveTaskDangling}, nil
 	}
 	ctx = log.WithValue(ctx, log.CtxKeyTask, task.Key().String())
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done, err := g.inflight.Begin(task.Key().String(), cancel)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to begin receive piece", "error", err)
+		return &gfspserver.GfSpReplicatePieceResponse{Err: gfsperrors.MakeGfSpError(err)}, nil
+	}
+	defer done()
 	span, err := g.receiver.ReserveResource(ctx, task.EstimateLimit().ScopeStat())
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to reserve resource", "error", err)
@@ -76,6 +76,12 @@ func (g *GfSpBaseApp) GfSpSign(
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to sign replicate piece task", "error", err)
 		}
+	case *gfspserver.GfSpSignRequest_GfspMigrateBucketTask:
+		ctx = log.WithValue(ctx, log.CtxKeyTask, t.GfspMigrateBucketTask.Key().String())
+		signature, err = g.signer.SignMigrateBucketTask(ctx, t.GfspMigrateBucketTask)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to sign migrate bucket task", "error", err)
+		}
 	}
 	return &gfspserver.GfSpSignResponse{
 		Err:           gfsperrors.MakeGfSpError(err),
@@ -0,0 +1,41 @@
+package gfspapp
+
+import (
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+// SPExitCheckInterval defines the seconds between checking the exiting state of the
+// sp in SPDB, the state is shared by all the nodes of the sp through the exit progress.
+const SPExitCheckInterval int64 = 10
+
+// SPExiting returns whether the sp is exiting, the exiting sp refuses to create the new
+// buckets and objects and does not serve as secondary sp any more.
+func (g *GfSpBaseApp) SPExiting() bool {
+	g.spExitMux.Lock()
+	defer g.spExitMux.Unlock()
+	if g.spExiting || g.gfSpDB == nil {
+		return g.spExiting
+	}
+	now := time.Now().Unix()
+	if now-g.spExitCheckTime < SPExitCheckInterval {
+		return false
+	}
+	g.spExitCheckTime = now
+	progress, err := g.gfSpDB.GetSPExitProgress(g.operateAddress)
+	if err != nil {
+		log.Errorw("failed to get sp exit progress", "error", err)
+		return false
+	}
+	// the sp never comes back after it starts to exit
+	g.spExiting = progress != nil
+	return g.spExiting
+}
+
+// setSPExiting marks the sp is exiting without waiting for the next check.
+func (g *GfSpBaseApp) setSPExiting() {
+	g.spExitMux.Lock()
+	defer g.spExitMux.Unlock()
+	g.spExiting = true
+}
@@ -39,6 +39,11 @@ const (
 	MinGCMetaTime int64 = 300
 	// MaxGCMetaTime defines the max timeout to gc meta.
 	MaxGCMetaTime int64 = 600
+	// MinMigrateBucketTime defines the min timeout to migrate one object of the bucket,
+	// the migrate bucket task reports the progress after each object.
+	MinMigrateBucketTime int64 = 600
+	// MaxMigrateBucketTime defines the max timeout to migrate one object of the bucket.
+	MaxMigrateBucketTime int64 = 3600
 
 	// NotUseRetry defines the default task max retry.
 	NotUseRetry int64 = 0
@@ -58,11 +63,17 @@ const (
 	MinGCObjectRetry = 3
 	// MaxGCObjectRetry defines the min retry number to gc object.
 	MaxGCObjectRetry = 5
+	// MinMigrateBucketRetry defines the min retry number to migrate bucket.
+	MinMigrateBucketRetry = 3
+	// MaxMigrateBucketRetry defines the max retry number to migrate bucket.
+	MaxMigrateBucketRetry = 10
 )
 
 // TaskTimeout returns the task timeout by task type and some task need payload size
 // to compute, example: upload, download, etc.
 func (g *GfSpBaseApp) TaskTimeout(task coretask.Task, size uint64) int64 {
+	g.taskMux.RLock()
+	defer g.taskMux.RUnlock()
 	switch task.Type() {
 	case coretask.TypeTaskCreateBucketApproval:
 		return NotUseTimeout
@@ -79,7 +90,9 @@ func (g *GfSpBaseApp) TaskTimeout(task coretask.Task, size uint64) int64 {
 			return MaxUploadTime
 		}
 		return timeout
-	case coretask.TypeTaskReplicatePiece:
+	// the repair piece task replicates the pieces of one secondary sp, it shares the
+	// timeout and retry of replicating
+	case coretask.TypeTaskReplicatePiece, coretask.TypeTaskRepairPiece:
 		timeout := int64(size) / (g.replicateSpeed + 1)
 		if timeout < MinReplicateTime {
 			return MinReplicateTime
@@ -147,12 +160,24 @@ func (g *GfSpBaseApp) TaskTimeout(task coretask.Task, size uint64) int64 {
 			return MaxGCMetaTime
 		}
 		return g.gcMetaTimeout
+	// the sp exit task reports the progress after each object as the migrate bucket task,
+	// it shares the timeout and retry of migrating
+	case coretask.TypeTaskMigrateBucket, coretask.TypeTaskSPExit:
+		if g.migrateTimeout < MinMigrateBucketTime {
+			return MinMigrateBucketTime
+		}
+		if g.migrateTimeout > MaxMigrateBucketTime {
+			return MaxMigrateBucketTime
+		}
+		return g.migrateTimeout
 	}
 	return NotUseTimeout
 }
 
 // TaskMaxRetry returns the task max retry by task type.
 func (g *GfSpBaseApp) TaskMaxRetry(task coretask.Task) int64 {
+	g.taskMux.RLock()
+	defer g.taskMux.RUnlock()
 	switch task.Type() {
 	case coretask.TypeTaskCreateBucketApproval:
 		return NotUseRetry
@@ -162,7 +187,7 @@ func (g *GfSpBaseApp) TaskMaxRetry(task coretask.Task) int64 {
 		return NotUseRetry
 	case coretask.TypeTaskUpload:
 		return NotUseRetry
-	case coretask.TypeTaskReplicatePiece:
+	case coretask.TypeTaskReplicatePiece, coretask.TypeTaskRepairPiece:
 		if g.replicateRetry < MinReplicateRetry {
 			return MinReplicateRetry
 		}
@@ -214,6 +239,14 @@ func (g *GfSpBaseApp) TaskMaxRetry(task coretask.Task) int64 {
 			return MaxGCObjectRetry
 		}
 		return g.gcMetaRetry
+	case coretask.TypeTaskMigrateBucket, coretask.TypeTaskSPExit:
+		if g.migrateRetry < MinMigrateBucketRetry {
+			return MinMigrateBucketRetry
+		}
+		if g.migrateRetry > MaxMigrateBucketRetry {
+			return MaxMigrateBucketRetry
+		}
+		return g.migrateRetry
 	}
 	return 0
 }
@@ -246,6 +279,12 @@ func (g *GfSpBaseApp) TaskPriority(task coretask.Task) coretask.TPriority {
 		return coretask.UnSchedulingPriority
 	case coretask.TypeTaskGCMeta:
 		return coretask.UnSchedulingPriority
+	case coretask.TypeTaskMigrateBucket:
+		return coretask.DefaultSmallerPriority
+	case coretask.TypeTaskSPExit:
+		return coretask.DefaultSmallerPriority
+	case coretask.TypeTaskRepairPiece:
+		return coretask.DefaultSmallerPriority
 	}
 	return coretask.UnKnownTaskPriority
 }
@@ -32,9 +32,13 @@ func (g *GfSpBaseApp) GfSpUploadObject(stream gfspserver.GfSpUploadService_GfSpU
 		ctx, cancel   = context.WithCancel(context.Background())
 		err           error
 		receiveSize   int
+		done          func()
 	)
 	defer func() {
 		defer cancel()
+		if done != nil {
+			done()
+		}
 		if span != nil {
 			span.Done()
 		}
@@ -91,6 +95,15 @@ func (g *GfSpBaseApp) GfSpUploadObject(stream gfspserver.GfSpUploadService_GfSpU
 					return
 				}
 				ctx = log.WithValue(ctx, log.CtxKeyTask, task.Key().String())
+				done, err = g.inflight.Begin(task.Key().String(), func() {
+					pWrite.CloseWithError(ErrTaskCanceled)
+				})
+				if err != nil {
+					log.CtxErrorw(ctx, "failed to begin upload object", "error", err)
+					pWrite.CloseWithError(err)
+					cancel()
+					return
+				}
 				span, err = g.uploader.ReserveResource(ctx, task.EstimateLimit().ScopeStat())
 				if err != nil {
 					log.CtxErrorw(ctx, "failed to reserve resource", "error", err)
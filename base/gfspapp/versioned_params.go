@@ -0,0 +1,138 @@
+package gfspapp
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// VersionedParamsSafeDelay defines the seconds that the timestamp must be before now to
+// cache the params of it, the params of the recent timestamp may be changed by the block
+// that has not been synced by the greenfield node.
+const VersionedParamsSafeDelay int64 = 60
+
+// QueryStorageParamsByTimestamp returns the storage params that are effective at the
+// timestamp, it is used to resolve the versioned params of the object by its create time,
+// only the versioned params are filled in the returned params.
+// The params are resolved from the memory cache, SPDB and greenfield in turn. The verified
+// params are cached as the range [EffectiveTime, EndTime], the range of the same params is
+// extended when the params at a later timestamp are verified, it assumes that the params
+// never change back to the former ones(A->B->A) between two verified timestamps.
+func (g *GfSpBaseApp) QueryStorageParamsByTimestamp(
+	ctx context.Context,
+	timestamp int64) (
+	*storagetypes.Params, error) {
+	if params := g.cachedVersionedParams(timestamp); params != nil {
+		return params, nil
+	}
+	var (
+		cached *spdb.VersionedParams
+		err    error
+	)
+	if g.gfSpDB != nil {
+		cached, err = g.gfSpDB.GetVersionedParamsByTimestamp(timestamp)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to get versioned params from db", "timestamp", timestamp,
+				"error", err)
+		}
+		if cached != nil && timestamp <= cached.EndTime {
+			g.cacheVersionedParams(cached)
+			return toStorageParams(cached), nil
+		}
+	}
+	params, err := g.chain.QueryStorageParamsByTimestamp(ctx, timestamp)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query storage params by timestamp", "timestamp", timestamp,
+			"error", err)
+		return nil, err
+	}
+	verified := &spdb.VersionedParams{
+		EffectiveTime:           timestamp,
+		EndTime:                 timestamp,
+		MaxSegmentSize:          params.VersionedParams.GetMaxSegmentSize(),
+		RedundantDataChunkNum:   params.VersionedParams.GetRedundantDataChunkNum(),
+		RedundantParityChunkNum: params.VersionedParams.GetRedundantParityChunkNum(),
+		MinChargeSize:           params.VersionedParams.GetMinChargeSize(),
+	}
+	if timestamp > time.Now().Unix()-VersionedParamsSafeDelay {
+		return toStorageParams(verified), nil
+	}
+	if cached != nil && toStorageParams(cached).VersionedParams == params.VersionedParams {
+		verified.EffectiveTime = cached.EffectiveTime
+	}
+	if g.gfSpDB != nil {
+		if err = g.gfSpDB.SetVersionedParams(verified); err != nil {
+			log.CtxErrorw(ctx, "failed to set versioned params to db", "timestamp", timestamp,
+				"error", err)
+		}
+	}
+	g.cacheVersionedParams(verified)
+	return toStorageParams(verified), nil
+}
+
+// SyncVersionedParams verifies the params that are effective before the safe delay, and
+// reloads all the params verified in SPDB to the memory cache, so the params verified by
+// the other processes of this SP are cached instead of being resolved on the first use.
+func (g *GfSpBaseApp) SyncVersionedParams(ctx context.Context) error {
+	if _, err := g.QueryStorageParamsByTimestamp(ctx, time.Now().Unix()-VersionedParamsSafeDelay); err != nil {
+		return err
+	}
+	if g.gfSpDB == nil {
+		return nil
+	}
+	verified, err := g.gfSpDB.ListVersionedParams()
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list versioned params from db", "error", err)
+		return err
+	}
+	g.paramsMux.Lock()
+	defer g.paramsMux.Unlock()
+	g.versionedParams = verified
+	return nil
+}
+
+// cachedVersionedParams returns the params in the memory cache that are verified at the
+// timestamp, returns nil if there is no one.
+func (g *GfSpBaseApp) cachedVersionedParams(timestamp int64) *storagetypes.Params {
+	g.paramsMux.RLock()
+	defer g.paramsMux.RUnlock()
+	idx := sort.Search(len(g.versionedParams), func(i int) bool {
+		return g.versionedParams[i].EffectiveTime > timestamp
+	})
+	if idx == 0 || timestamp > g.versionedParams[idx-1].EndTime {
+		return nil
+	}
+	return toStorageParams(g.versionedParams[idx-1])
+}
+
+// cacheVersionedParams sets(maybe overwrites) the params in the memory cache by the
+// effective time, the cache is sorted by the effective time.
+func (g *GfSpBaseApp) cacheVersionedParams(params *spdb.VersionedParams) {
+	g.paramsMux.Lock()
+	defer g.paramsMux.Unlock()
+	idx := sort.Search(len(g.versionedParams), func(i int) bool {
+		return g.versionedParams[i].EffectiveTime >= params.EffectiveTime
+	})
+	if idx < len(g.versionedParams) && g.versionedParams[idx].EffectiveTime == params.EffectiveTime {
+		g.versionedParams[idx] = params
+		return
+	}
+	g.versionedParams = append(g.versionedParams, nil)
+	copy(g.versionedParams[idx+1:], g.versionedParams[idx:])
+	g.versionedParams[idx] = params
+}
+
+func toStorageParams(params *spdb.VersionedParams) *storagetypes.Params {
+	return &storagetypes.Params{
+		VersionedParams: storagetypes.VersionedParams{
+			MaxSegmentSize:          params.MaxSegmentSize,
+			RedundantDataChunkNum:   params.RedundantDataChunkNum,
+			RedundantParityChunkNum: params.RedundantParityChunkNum,
+			MinChargeSize:           params.MinChargeSize,
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package gfspapp
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/core/consensus"
+	"github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+// mockParamsConsensus returns the same params at any timestamp.
+type mockParamsConsensus struct {
+	consensus.NullConsensus
+	maxSegmentSize uint64
+}
+
+func (m *mockParamsConsensus) QueryStorageParamsByTimestamp(context.Context, int64) (*storagetypes.Params, error) {
+	return &storagetypes.Params{VersionedParams: storagetypes.VersionedParams{MaxSegmentSize: m.maxSegmentSize}}, nil
+}
+
+// mockParamsDB stores the versioned params in memory.
+type mockParamsDB struct {
+	spdb.SPDB
+	params []*spdb.VersionedParams
+}
+
+func (m *mockParamsDB) GetVersionedParamsByTimestamp(timestamp int64) (*spdb.VersionedParams, error) {
+	var latest *spdb.VersionedParams
+	for _, params := range m.params {
+		if params.EffectiveTime <= timestamp {
+			latest = params
+		}
+	}
+	return latest, nil
+}
+
+func (m *mockParamsDB) SetVersionedParams(params *spdb.VersionedParams) error {
+	m.params = append(m.params, params)
+	sort.Slice(m.params, func(i, j int) bool { return m.params[i].EffectiveTime < m.params[j].EffectiveTime })
+	return nil
+}
+
+func (m *mockParamsDB) ListVersionedParams() ([]*spdb.VersionedParams, error) {
+	return m.params, nil
+}
+
+func TestGfSpBaseAppSyncVersionedParams(t *testing.T) {
+	// the former params are verified by the other process of this sp
+	db := &mockParamsDB{params: []*spdb.VersionedParams{{EffectiveTime: 10, EndTime: 20, MaxSegmentSize: 8}}}
+	app := &GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{Customize: &gfspconfig.Customize{
+		Consensus: &mockParamsConsensus{maxSegmentSize: 16}, GfSpDB: db}}
+	require.NoError(t, DefaultGfSpConsensusOption(app, cfg))
+	require.NoError(t, DefaultGfSpDBOption(app, cfg))
+	assert.Nil(t, app.cachedVersionedParams(15))
+
+	require.NoError(t, app.SyncVersionedParams(context.Background()))
+	params := app.cachedVersionedParams(15)
+	require.NotNil(t, params)
+	assert.Equal(t, uint64(8), params.VersionedParams.GetMaxSegmentSize())
+	// the latest params before the safe delay are verified on greenfield
+	require.Len(t, app.versionedParams, 2)
+	assert.Equal(t, uint64(16), app.versionedParams[1].MaxSegmentSize)
+	assert.LessOrEqual(t, app.versionedParams[1].EndTime, time.Now().Unix()-VersionedParamsSafeDelay)
+	assert.Equal(t, db.params, app.versionedParams)
+}
@@ -0,0 +1,256 @@
+package gfspclient
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfspserver"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+// AdminTokenMetadataKey defines the grpc metadata key of the admin token.
+const AdminTokenMetadataKey = "gfsp-admin-token"
+
+func adminContext(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AdminTokenMetadataKey, token)
+}
+
+func (s *GfSpClient) PauseTask(
+	ctx context.Context,
+	endpoint string,
+	token string,
+	taskType coretask.TType,
+	pause bool) error {
+	conn, connErr := s.Connection(ctx, endpoint)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect gfsp server", "error", connErr)
+		return ErrRpcUnknown
+	}
+	defer conn.Close()
+	req := &gfspserver.GfSpPauseTaskRequest{
+		TaskType: int32(taskType),
+		Pause:    pause,
+	}
+	resp, err := gfspserver.NewGfSpAdminServiceClient(conn).GfSpPauseTask(adminContext(ctx, token), req)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to pause task", "error", err)
+		return ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return resp.GetErr()
+	}
+	return nil
+}
+
+func (s *GfSpClient) CancelTask(
+	ctx context.Context,
+	endpoint string,
+	token string,
+	key coretask.TKey) error {
+	conn, connErr := s.Connection(ctx, endpoint)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect gfsp server", "error", connErr)
+		return ErrRpcUnknown
+	}
+	defer conn.Close()
+	req := &gfspserver.GfSpCancelTaskRequest{
+		TaskKey: key.String(),
+	}
+	resp, err := gfspserver.NewGfSpAdminServiceClient(conn).GfSpCancelTask(adminContext(ctx, token), req)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to cancel task", "error", err)
+		return ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return resp.GetErr()
+	}
+	return nil
+}
+
+// Drain makes the node reject new upload, receive and download work and waits for
+// the in-flight work to finish, returns the number of unfinished work.
+func (s *GfSpClient) Drain(
+	ctx context.Context,
+	endpoint string,
+	token string,
+	drain bool,
+	timeout int64) (
+	int64, error) {
+	conn, connErr := s.Connection(ctx, endpoint)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect gfsp server", "error", connErr)
+		return 0, ErrRpcUnknown
+	}
+	defer conn.Close()
+	req := &gfspserver.GfSpDrainRequest{
+		Drain:   drain,
+		Timeout: timeout,
+	}
+	resp, err := gfspserver.NewGfSpAdminServiceClient(conn).GfSpDrain(adminContext(ctx, token), req)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to drain node", "error", err)
+		return 0, ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return 0, resp.GetErr()
+	}
+	return resp.GetInflight(), nil
+}
+
+func (s *GfSpClient) AdminStatus(
+	ctx context.Context,
+	endpoint string,
+	token string) (
+	*gfspserver.GfSpAdminStatusResponse, error) {
+	conn, connErr := s.Connection(ctx, endpoint)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect gfsp server", "error", connErr)
+		return nil, ErrRpcUnknown
+	}
+	defer conn.Close()
+	resp, err := gfspserver.NewGfSpAdminServiceClient(conn).GfSpAdminStatus(
+		adminContext(ctx, token), &gfspserver.GfSpAdminStatusRequest{})
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to query admin status", "error", err)
+		return nil, ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return nil, resp.GetErr()
+	}
+	return resp, nil
+}
+
+// ReloadConfig makes the node reload the config file and apply the reloadable subset,
+// returns the version of the active config.
+func (s *GfSpClient) ReloadConfig(
+	ctx context.Context,
+	endpoint string,
+	token string) (
+	string, error) {
+	conn, connErr := s.Connection(ctx, endpoint)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect gfsp server", "error", connErr)
+		return "", ErrRpcUnknown
+	}
+	defer conn.Close()
+	resp, err := gfspserver.NewGfSpAdminServiceClient(conn).GfSpReloadConfig(
+		adminContext(ctx, token), &gfspserver.GfSpReloadConfigRequest{})
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to reload config", "error", err)
+		return "", ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return "", resp.GetErr()
+	}
+	return resp.GetVersion(), nil
+}
+
+// MigrateBucket makes the node migrate the bucket from the source SP to this SP, returns
+// the key of the migrate bucket task that can be used to query the progress.
+func (s *GfSpClient) MigrateBucket(
+	ctx context.Context,
+	endpoint string,
+	token string,
+	bucketName string,
+	srcSpEndpoint string,
+	ownerSignature []byte,
+	approvalExpiry int64) (
+	string, error) {
+	conn, connErr := s.Connection(ctx, endpoint)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect gfsp server", "error", connErr)
+		return "", ErrRpcUnknown
+	}
+	defer conn.Close()
+	req := &gfspserver.GfSpMigrateBucketRequest{
+		BucketName:     bucketName,
+		SrcSpEndpoint:  srcSpEndpoint,
+		OwnerSignature: ownerSignature,
+		ApprovalExpiry: approvalExpiry,
+	}
+	resp, err := gfspserver.NewGfSpAdminServiceClient(conn).GfSpMigrateBucket(adminContext(ctx, token), req)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to migrate bucket", "error", err)
+		return "", ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return "", resp.GetErr()
+	}
+	return resp.GetTaskKey(), nil
+}
+
+// SPExit makes the sp start to exit, the secondary pieces and the primary buckets of
+// the sp are handed over to other sps in background, returns the key of the sp exit task.
+func (s *GfSpClient) SPExit(
+	ctx context.Context,
+	endpoint string,
+	token string) (
+	string, error) {
+	conn, connErr := s.Connection(ctx, endpoint)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect gfsp server", "error", connErr)
+		return "", ErrRpcUnknown
+	}
+	defer conn.Close()
+	resp, err := gfspserver.NewGfSpAdminServiceClient(conn).GfSpSPExit(adminContext(ctx, token),
+		&gfspserver.GfSpSPExitRequest{})
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to start sp exit", "error", err)
+		return "", ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return "", resp.GetErr()
+	}
+	return resp.GetTaskKey(), nil
+}
+
+// QuerySPExit returns the progress of the sp exit and at most limit failed records.
+func (s *GfSpClient) QuerySPExit(
+	ctx context.Context,
+	endpoint string,
+	token string,
+	limit int32) (
+	*gfspserver.GfSpQuerySPExitResponse, error) {
+	conn, connErr := s.Connection(ctx, endpoint)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect gfsp server", "error", connErr)
+		return nil, ErrRpcUnknown
+	}
+	defer conn.Close()
+	resp, err := gfspserver.NewGfSpAdminServiceClient(conn).GfSpQuerySPExit(adminContext(ctx, token),
+		&gfspserver.GfSpQuerySPExitRequest{Limit: limit})
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to query sp exit", "error", err)
+		return nil, ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return nil, resp.GetErr()
+	}
+	return resp, nil
+}
+
+// QuerySPScores returns the scores of the secondary sp candidates.
+func (s *GfSpClient) QuerySPScores(
+	ctx context.Context,
+	endpoint string,
+	token string) (
+	[]*gfspserver.GfSpSPScore, error) {
+	conn, connErr := s.Connection(ctx, endpoint)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect gfsp server", "error", connErr)
+		return nil, ErrRpcUnknown
+	}
+	defer conn.Close()
+	resp, err := gfspserver.NewGfSpAdminServiceClient(conn).GfSpQuerySPScores(adminContext(ctx, token),
+		&gfspserver.GfSpQuerySPScoresRequest{})
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to query sp scores", "error", err)
+		return nil, ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return nil, resp.GetErr()
+	}
+	return resp.GetScores(), nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
@@ -95,3 +96,82 @@ func (s *GfSpClient) DoneReplicatePieceToSecondary(
 	}
 	return integrity, signature, nil
 }
+
+// MigrateBucketFromSource requests the source SP to export the migrating bucket, the
+// returned body is the stream of migrate frames and must be closed by the caller.
+func (s *GfSpClient) MigrateBucketFromSource(
+	ctx context.Context,
+	endpoint string,
+	migrate coretask.MigrateBucketTask) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+model.MigrateBucketPath, nil)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to connect gateway", "endpoint", endpoint, "error", err)
+		return nil, err
+	}
+	migrateTask := migrate.(*gfsptask.GfSpMigrateBucketTask)
+	migrateMsg, err := json.Marshal(migrateTask)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(model.GnfdMigrateBucketMsgHeader, hex.EncodeToString(migrateMsg))
+	resp, err := s.HttpClient(ctx).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to migrate bucket, StatusCode(%d) Endpoint(%s)", resp.StatusCode, endpoint)
+	}
+	return resp.Body, nil
+}
+
+// NotifyMigrateBucket notifies the destination SP to migrate the bucket from this SP,
+// it is used by the exiting primary SP to hand over its buckets.
+func (s *GfSpClient) NotifyMigrateBucket(
+	ctx context.Context,
+	endpoint string,
+	migrate coretask.MigrateBucketTask) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+model.NotifyMigrateBucketPath, nil)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to connect gateway", "endpoint", endpoint, "error", err)
+		return err
+	}
+	migrateTask := migrate.(*gfsptask.GfSpMigrateBucketTask)
+	migrateMsg, err := json.Marshal(migrateTask)
+	if err != nil {
+		return err
+	}
+	req.Header.Add(model.GnfdMigrateBucketMsgHeader, hex.EncodeToString(migrateMsg))
+	resp, err := s.HttpClient(ctx).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to notify migrate bucket, StatusCode(%d) Endpoint(%s)", resp.StatusCode, endpoint)
+	}
+	return nil
+}
+
+// QueryP2PNodeFromSP queries the p2p node info of the SP by its endpoint, the format is
+// the same as the bootstrap node.
+func (s *GfSpClient) QueryP2PNodeFromSP(ctx context.Context, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+model.P2PNodePath, nil)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to connect gateway", "endpoint", endpoint, "error", err)
+		return "", err
+	}
+	resp, err := s.HttpClient(ctx).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to query p2p node, StatusCode(%d) Endpoint(%s)", resp.StatusCode, endpoint)
+	}
+	node := resp.Header.Get(model.GnfdP2PNodeHeader)
+	if node == "" {
+		return "", fmt.Errorf("failed to query p2p node, empty node Endpoint(%s)", endpoint)
+	}
+	return node, nil
+}
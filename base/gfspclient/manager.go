@@ -35,6 +35,30 @@ func (s *GfSpClient) CreateUploadObject(
 	return nil
 }
 
+func (s *GfSpClient) CreateMigrateBucket(
+	ctx context.Context,
+	task coretask.MigrateBucketTask) error {
+	conn, connErr := s.ManagerConn(ctx)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect manager", "error", connErr)
+		return ErrRpcUnknown
+	}
+	req := &gfspserver.GfSpBeginTaskRequest{
+		Request: &gfspserver.GfSpBeginTaskRequest_MigrateBucketTask{
+			MigrateBucketTask: task.(*gfsptask.GfSpMigrateBucketTask),
+		},
+	}
+	resp, err := gfspserver.NewGfSpManageServiceClient(conn).GfSpBeginTask(ctx, req)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to create migrate bucket task", "error", err)
+		return ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return resp.GetErr()
+	}
+	return nil
+}
+
 func (s *GfSpClient) AskTask(
 	ctx context.Context,
 	limit corercmgr.Limit) (
@@ -68,6 +92,12 @@ func (s *GfSpClient) AskTask(
 		return t.GcZombiePieceTask, nil
 	case *gfspserver.GfSpAskTaskResponse_GcMetaTask:
 		return t.GcMetaTask, nil
+	case *gfspserver.GfSpAskTaskResponse_MigrateBucketTask:
+		return t.MigrateBucketTask, nil
+	case *gfspserver.GfSpAskTaskResponse_SpExitTask:
+		return t.SpExitTask, nil
+	case *gfspserver.GfSpAskTaskResponse_RepairPieceTask:
+		return t.RepairPieceTask, nil
 	default:
 		return nil, ErrTypeMismatch
 	}
@@ -119,6 +149,18 @@ func (s *GfSpClient) ReportTask(
 		req.Request = &gfspserver.GfSpReportTaskRequest_ChallengePieceTask{
 			ChallengePieceTask: t,
 		}
+	case *gfsptask.GfSpMigrateBucketTask:
+		req.Request = &gfspserver.GfSpReportTaskRequest_MigrateBucketTask{
+			MigrateBucketTask: t,
+		}
+	case *gfsptask.GfSpSPExitTask:
+		req.Request = &gfspserver.GfSpReportTaskRequest_SpExitTask{
+			SpExitTask: t,
+		}
+	case *gfsptask.GfSpRepairPieceTask:
+		req.Request = &gfspserver.GfSpReportTaskRequest_RepairPieceTask{
+			RepairPieceTask: t,
+		}
 	}
 	resp, err := gfspserver.NewGfSpManageServiceClient(conn).GfSpReportTask(ctx, req)
 	if err != nil {
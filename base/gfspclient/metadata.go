@@ -196,6 +196,74 @@ func (s *GfSpClient) ListExpiredBucketsBySp(ctx context.Context, createAt int64,
 	return resp.GetBuckets(), nil
 }
 
+// ListObjectsBySecondarySp list objects which store the pieces in the specific sp as secondary
+func (s *GfSpClient) ListObjectsBySecondarySp(ctx context.Context, secondarySpAddress string, startAfterObjectID uint64, limit int64, opts ...grpc.DialOption) ([]*types.Object, error) {
+	conn, err := s.Connection(ctx, s.metadataEndpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	req := &types.GfSpListObjectsBySecondarySpRequest{
+		SecondarySpAddress: secondarySpAddress,
+		StartAfterObjectId: startAfterObjectID,
+		Limit:              limit,
+	}
+
+	resp, err := types.NewGfSpMetadataServiceClient(conn).GfSpListObjectsBySecondarySp(ctx, req)
+	ctx = log.Context(ctx, resp)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to send list objects by secondary sp rpc", "error", err)
+		return nil, err
+	}
+	return resp.GetObjects(), nil
+}
+
+// GetPrefixStats get the stats of the directory and its immediate child directories in the bucket
+func (s *GfSpClient) GetPrefixStats(ctx context.Context, bucketName, prefix string, opts ...grpc.DialOption) (*types.GfSpGetPrefixStatsResponse, error) {
+	conn, err := s.Connection(ctx, s.metadataEndpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	req := &types.GfSpGetPrefixStatsRequest{
+		BucketName: bucketName,
+		Prefix:     prefix,
+	}
+
+	resp, err := types.NewGfSpMetadataServiceClient(conn).GfSpGetPrefixStats(ctx, req)
+	ctx = log.Context(ctx, resp)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to send get prefix stats rpc", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListBucketsByPrimarySp list buckets whose primary sp is the specific sp
+func (s *GfSpClient) ListBucketsByPrimarySp(ctx context.Context, primarySpAddress string, startAfterBucketID uint64, limit int64, opts ...grpc.DialOption) ([]*types.Bucket, error) {
+	conn, err := s.Connection(ctx, s.metadataEndpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	req := &types.GfSpListBucketsByPrimarySpRequest{
+		PrimarySpAddress:   primarySpAddress,
+		StartAfterBucketId: startAfterBucketID,
+		Limit:              limit,
+	}
+
+	resp, err := types.NewGfSpMetadataServiceClient(conn).GfSpListBucketsByPrimarySp(ctx, req)
+	ctx = log.Context(ctx, resp)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to send list buckets by primary sp rpc", "error", err)
+		return nil, err
+	}
+	return resp.GetBuckets(), nil
+}
+
 // GetObjectMeta get object metadata
 func (s *GfSpClient) GetObjectMeta(
 	ctx context.Context,
@@ -53,3 +53,91 @@ func (s *GfSpClient) QueryP2PBootstrap(ctx context.Context) ([]string, error) {
 	}
 	return resp.GetNodes(), nil
 }
+
+// QueryP2PSelfNode returns the p2p node info of self in the bootstrap node format.
+func (s *GfSpClient) QueryP2PSelfNode(ctx context.Context) (string, error) {
+	conn, connErr := s.P2PConn(ctx)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect p2p", "error", connErr)
+		return "", ErrRpcUnknown
+	}
+	resp, err := gfspserver.NewGfSpP2PServiceClient(conn).GfSpQueryP2PSelfNode(ctx, &gfspserver.GfSpQueryP2PNodeRequest{})
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to query p2p self node", "error", err)
+		return "", ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return "", resp.GetErr()
+	}
+	if len(resp.GetNodes()) == 0 {
+		return "", ErrRpcUnknown
+	}
+	return resp.GetNodes()[0], nil
+}
+
+// QueryUnhealthySp returns the operator addresses of the SPs that all the p2p peers of
+// them continuously fail to interact.
+func (s *GfSpClient) QueryUnhealthySp(ctx context.Context) ([]string, error) {
+	conn, connErr := s.P2PConn(ctx)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect p2p", "error", connErr)
+		return nil, ErrRpcUnknown
+	}
+	resp, err := gfspserver.NewGfSpP2PServiceClient(conn).GfSpQueryUnhealthySp(ctx, &gfspserver.GfSpQueryUnhealthySpRequest{})
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to query unhealthy sp", "error", err)
+		return nil, ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return nil, resp.GetErr()
+	}
+	return resp.GetSpAddresses(), nil
+}
+
+// ReplicatePieceToSecondaryByP2P replicates the piece to the approved secondary SP over
+// the p2p stream.
+func (s *GfSpClient) ReplicatePieceToSecondaryByP2P(
+	ctx context.Context,
+	approval coretask.ApprovalReplicatePieceTask,
+	receive coretask.ReceivePieceTask,
+	data []byte) error {
+	_, _, err := s.replicatePieceByP2P(ctx, approval, receive, data)
+	return err
+}
+
+// DoneReplicatePieceToSecondaryByP2P notifies the approved secondary SP over the p2p
+// stream that all the pieces are replicated, returns the integrity hash and signature.
+func (s *GfSpClient) DoneReplicatePieceToSecondaryByP2P(
+	ctx context.Context,
+	approval coretask.ApprovalReplicatePieceTask,
+	receive coretask.ReceivePieceTask) (
+	[]byte, []byte, error) {
+	return s.replicatePieceByP2P(ctx, approval, receive, nil)
+}
+
+func (s *GfSpClient) replicatePieceByP2P(
+	ctx context.Context,
+	approval coretask.ApprovalReplicatePieceTask,
+	receive coretask.ReceivePieceTask,
+	data []byte) (
+	[]byte, []byte, error) {
+	conn, connErr := s.P2PConn(ctx)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect p2p", "error", connErr)
+		return nil, nil, ErrRpcUnknown
+	}
+	req := &gfspserver.GfSpReplicatePieceByP2PRequest{
+		ReplicatePieceApprovalTask: approval.(*gfsptask.GfSpReplicatePieceApprovalTask),
+		ReceivePieceTask:           receive.(*gfsptask.GfSpReceivePieceTask),
+		PieceData:                  data,
+	}
+	resp, err := gfspserver.NewGfSpP2PServiceClient(conn).GfSpReplicatePieceByP2P(ctx, req)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to replicate piece by p2p", "error", err)
+		return nil, nil, ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return nil, nil, resp.GetErr()
+	}
+	return resp.GetIntegrityHash(), resp.GetSignature(), nil
+}
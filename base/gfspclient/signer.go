@@ -188,6 +188,31 @@ func (s *GfSpClient) SignReceiveTask(
 	return resp.GetSignature(), nil
 }
 
+func (s *GfSpClient) SignMigrateBucketTask(
+	ctx context.Context,
+	migrateTask coretask.MigrateBucketTask) (
+	[]byte, error) {
+	conn, connErr := s.SignerConn(ctx)
+	if connErr != nil {
+		log.CtxErrorw(ctx, "client failed to connect signer", "error", connErr)
+		return nil, ErrRpcUnknown
+	}
+	req := &gfspserver.GfSpSignRequest{
+		Request: &gfspserver.GfSpSignRequest_GfspMigrateBucketTask{
+			GfspMigrateBucketTask: migrateTask.(*gfsptask.GfSpMigrateBucketTask),
+		},
+	}
+	resp, err := gfspserver.NewGfSpSignServiceClient(conn).GfSpSign(ctx, req)
+	if err != nil {
+		log.CtxErrorw(ctx, "client failed to sign migrate bucket task", "error", err)
+		return nil, ErrRpcUnknown
+	}
+	if resp.GetErr() != nil {
+		return nil, resp.GetErr()
+	}
+	return resp.GetSignature(), nil
+}
+
 func (s *GfSpClient) SignP2PPingMsg(
 	ctx context.Context,
 	ping *gfspp2p.GfSpPing) (
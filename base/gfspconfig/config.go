@@ -16,6 +16,10 @@ import (
 
 type Option = func(cfg *GfSpConfig) error
 
+// ConfigLoader loads the latest GfSp configuration, it is used to reload the
+// configuration without restart.
+type ConfigLoader = func() (*GfSpConfig, error)
+
 // Customize defines the interface for developer to customize own implement, the GfSp base
 // app will call the customized implement.
 type Customize struct {
@@ -29,6 +33,7 @@ type Customize struct {
 	NewTQueueWithLimit             coretaskqueue.NewTQueueWithLimit
 	NewStrategyTQueueFunc          coretaskqueue.NewTQueueOnStrategy
 	NewStrategyTQueueWithLimitFunc coretaskqueue.NewTQueueOnStrategyWithLimit
+	ConfigLoader                   ConfigLoader
 }
 
 // GfSpConfig defines the GfSp configuration.
@@ -48,10 +53,12 @@ type GfSpConfig struct {
 	Bucket         BucketConfig
 	Gateway        GatewayConfig
 	Executor       ExecutorConfig
+	Auditor        AuditorConfig
 	P2P            P2PConfig
 	Parallel       ParallelConfig
 	Task           TaskConfig
 	Monitor        MonitorConfig
+	Admin          AdminConfig
 	Rcmgr          RcmgrConfig
 	Log            LogConfig
 	Metadata       MetadataConfig
@@ -113,6 +120,9 @@ type ApprovalConfig struct {
 	BucketApprovalTimeoutHeight uint64
 	ObjectApprovalTimeoutHeight uint64
 	ReplicatePieceTimeoutHeight uint64
+	// PolicyFile is the toml file of the approval policy, the approver refuses the
+	// creation of buckets and objects that the policy does not allow.
+	PolicyFile string
 }
 
 type BucketConfig struct {
@@ -125,6 +135,29 @@ type BucketConfig struct {
 type GatewayConfig struct {
 	Domain      string
 	HttpAddress string
+	S3          S3Config
+	// MigrateBucketExportSpeed limits the bytes per second of exporting the pieces of
+	// the migrating bucket to the destination SP.
+	MigrateBucketExportSpeed int64
+}
+
+// S3Config defines the optional S3 compatible read api of the gateway, the requests
+// are authenticated by AWS Signature Version 4 with the configured access keys.
+type S3Config struct {
+	Enable      bool
+	HttpAddress string
+	// Domain enables the virtual-hosted style requests, e.g. bucket.s3.example.com.
+	Domain string
+	// Region is checked against the credential scope if it is not empty.
+	Region     string
+	AccessKeys []S3AccessKeyConfig
+}
+
+// S3AccessKeyConfig maps the S3 access key to the greenfield account.
+type S3AccessKeyConfig struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Account         string
 }
 
 type ExecutorConfig struct {
@@ -135,6 +168,38 @@ type ExecutorConfig struct {
 	ListenSealTimeoutHeight      int
 	ListenSealRetryTimeout       int
 	MaxListenSealRetry           int
+	// SPExitHandoverSpeed limits the bytes per second of handing over the secondary
+	// pieces to other SPs when the SP exits.
+	SPExitHandoverSpeed int64
+	// GCObjectParallel is the number of objects that are deleted concurrently by the
+	// gc object task, the concurrency is also bounded by the resource manager.
+	GCObjectParallel int
+	// SecondaryDistinctFunding ranks the secondary SP candidates that share the funding
+	// address with a higher ranked candidate behind the others.
+	SecondaryDistinctFunding bool
+	// SecondaryRegions maps the SP operator address to its region, the secondary SP
+	// candidates in the region of a higher ranked candidate are ranked behind the others.
+	SecondaryRegions map[string]string
+	// ReplicateOverP2P prefers replicating the pieces to the secondary SPs over the p2p
+	// stream, it falls back to http if the secondary SP does not support it.
+	ReplicateOverP2P bool
+	// P2PReplicateFallbackInterval is the seconds of replicating to the secondary SP
+	// over http after the p2p replicate to it fails.
+	P2PReplicateFallbackInterval int64
+}
+
+// AuditorConfig defines the self challenge auditor of manager, it samples the sealed
+// objects stored in this SP and challenges them against this SP.
+type AuditorConfig struct {
+	Enable bool
+	// SampleRate is the probability of auditing each scanned object, in (0, 1].
+	SampleRate float64
+	// AuditInterval is the interval in seconds of the audit rounds.
+	AuditInterval int
+	// AuditScanNumber is the max number of scanned objects in each round for each role.
+	AuditScanNumber int64
+	// AutoRepair enables the repair hooks of the findings.
+	AutoRepair bool
 }
 
 type P2PConfig struct {
@@ -143,6 +208,30 @@ type P2PConfig struct {
 	P2PAntAddress string
 	P2PBootstrap  []string
 	P2PPingPeriod int
+	// SecondaryApproval defines the strategy of approving the replicate piece requests
+	// as secondary SP.
+	SecondaryApproval SecondaryApprovalConfig
+}
+
+// SecondaryApprovalConfig defines the conditions that the SP refuses the replicate
+// piece approval requests as secondary SP, the zero value does not restrict anything.
+type SecondaryApprovalConfig struct {
+	// DenySPs refuses the requests of the SP operator addresses in the list.
+	DenySPs []string
+	// MaxApprovalPerSP is the max number of approvals for each asking SP in the quota
+	// window.
+	MaxApprovalPerSP int
+	// ApprovalQuotaWindow is the quota window in seconds of MaxApprovalPerSP.
+	ApprovalQuotaWindow int
+	// MaxReceiveQueueDepth refuses the requests if the receiving piece tasks of the
+	// receiver reach the depth.
+	MaxReceiveQueueDepth int
+	// MinFreeStoreSize refuses the requests if the free bytes of the piece store are
+	// less than the value, it only works for the file piece store.
+	MinFreeStoreSize uint64
+	// MinRemainingMemory refuses the requests if the remaining memory of the resource
+	// manager system scope is less than the value.
+	MinRemainingMemory int64
 }
 
 type ParallelConfig struct {
@@ -156,6 +245,9 @@ type ParallelConfig struct {
 	GlobalGCObjectParallel             int
 	GlobalGCZombieParallel             int
 	GlobalGCMetaParallel               int
+	GlobalMigrateBucketParallel        int
+	GlobalRepairPieceParallel          int
+	GlobalRepairPieceScanInterval      int
 	GlobalDownloadObjectTaskCacheSize  int
 	GlobalChallengePieceTaskCacheSize  int
 	GlobalBatchGcObjectTimeInterval    int
@@ -176,20 +268,22 @@ type ParallelConfig struct {
 }
 
 type TaskConfig struct {
-	UploadTaskSpeed         int64
-	DownloadTaskSpeed       int64
-	ReplicateTaskSpeed      int64
-	ReceiveTaskSpeed        int64
-	SealObjectTaskTimeout   int64
-	GcObjectTaskTimeout     int64
-	GcZombieTaskTimeout     int64
-	GcMetaTaskTimeout       int64
-	SealObjectTaskRetry     int64
-	ReplicateTaskRetry      int64
-	ReceiveConfirmTaskRetry int64
-	GcObjectTaskRetry       int64
-	GcZombieTaskRetry       int64
-	GcMetaTaskRetry         int64
+	UploadTaskSpeed          int64
+	DownloadTaskSpeed        int64
+	ReplicateTaskSpeed       int64
+	ReceiveTaskSpeed         int64
+	SealObjectTaskTimeout    int64
+	GcObjectTaskTimeout      int64
+	GcZombieTaskTimeout      int64
+	GcMetaTaskTimeout        int64
+	MigrateBucketTaskTimeout int64
+	SealObjectTaskRetry      int64
+	ReplicateTaskRetry       int64
+	ReceiveConfirmTaskRetry  int64
+	GcObjectTaskRetry        int64
+	GcZombieTaskRetry        int64
+	GcMetaTaskRetry          int64
+	MigrateBucketTaskRetry   int64
 }
 
 type MonitorConfig struct {
@@ -199,6 +293,11 @@ type MonitorConfig struct {
 	PProfHttpAddress   string
 }
 
+type AdminConfig struct {
+	// Token authenticates the admin service, the admin service is disabled if it is empty.
+	Token string
+}
+
 type RcmgrConfig struct {
 	DisableRcmgr bool
 	GfSpLimiter  *gfsplimit.GfSpLimiter
@@ -210,12 +309,40 @@ type LogConfig struct {
 }
 
 type BlockSyncerConfig struct {
-	Modules        []string
+	Modules []string
+	// Dialect is the dialect of Dsn and DsnSwitched, one of mysql, postgres and sqlite,
+	// the default is mysql. The Dsn of sqlite is the path of db file.
+	Dialect        string
 	Dsn            string
 	DsnSwitched    string
 	RecreateTables bool
 	Workers        uint
 	EnableDualDB   bool
+	// RecordDir is the directory to record the blocks fetched from the chain to, the
+	// recorded blocks can be replayed by ReplayDir.
+	RecordDir string
+	// ReplayDir is the directory of the recorded blocks, the block syncer replays the
+	// blocks from it instead of fetching from the chain if it is set.
+	ReplayDir string
+	// NotificationIntervalSec is the interval of delivering the notifications in the outbox,
+	// the notifications are delivered only if the notification module is configured.
+	NotificationIntervalSec int64
+	// NotificationMaxAttempts is the max attempts of delivering a notification to a webhook,
+	// the notification is moved to the dead letter table after that.
+	NotificationMaxAttempts int
+	// NotificationBackoffSec is the backoff of retrying the failed delivery, it is doubled
+	// on every attempt.
+	NotificationBackoffSec int64
+	// NotificationMaxEventAgeSec is the max age of the events to deliver, the older events,
+	// e.g. the ones indexed again after the tables are recreated, are not delivered.
+	NotificationMaxEventAgeSec int64
+	// NotificationDeliverParallel is the max number of the notifications posted concurrently,
+	// so a slow webhook does not hold up the deliveries to the others.
+	NotificationDeliverParallel int
+	// NotificationAllowPrivateNetwork allows posting the notifications to the webhooks in the
+	// loopback, private and link-local networks, the webhooks are registered by the users, so
+	// it is only for the SP whose users are trusted, e.g. the webhooks of its own services.
+	NotificationAllowPrivateNetwork bool
 }
 
 type MetadataConfig struct {
@@ -0,0 +1,128 @@
+package gfspconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	// ErrNotReloadable is returned if the changed config fields can not be applied
+	// without restart.
+	ErrNotReloadable = errors.New("config fields can not be reloaded, restart is required")
+	// ErrInvalidReloadConfig is returned if the reloaded config is not the GfSp config.
+	ErrInvalidReloadConfig = errors.New("reloaded config is not the GfSp config")
+)
+
+// reloadableFields defines the config fields that can be applied to the running SP
+// without restart, the sub fields of the struct field are all reloadable.
+var reloadableFields = map[string]bool{
+	"Approval.PolicyFile":                          true,
+	"Parallel.GlobalCreateBucketApprovalParallel":  true,
+	"Parallel.GlobalCreateObjectApprovalParallel":  true,
+	"Parallel.GlobalMaxUploadingParallel":          true,
+	"Parallel.GlobalUploadObjectParallel":          true,
+	"Parallel.GlobalReplicatePieceParallel":        true,
+	"Parallel.GlobalSealObjectParallel":            true,
+	"Parallel.GlobalReceiveObjectParallel":         true,
+	"Parallel.GlobalGCObjectParallel":              true,
+	"Parallel.GlobalGCZombieParallel":              true,
+	"Parallel.GlobalGCMetaParallel":                true,
+	"Parallel.GlobalMigrateBucketParallel":         true,
+	"Parallel.GlobalRepairPieceParallel":           true,
+	"Parallel.GlobalDownloadObjectTaskCacheSize":   true,
+	"Parallel.GlobalChallengePieceTaskCacheSize":   true,
+	"Parallel.UploadObjectParallelPerNode":         true,
+	"Parallel.ReceivePieceParallelPerNode":         true,
+	"Parallel.DownloadObjectParallelPerNode":       true,
+	"Parallel.ChallengePieceParallelPerNode":       true,
+	"Parallel.AskReplicateApprovalParallelPerNode": true,
+	"Parallel.QuerySPParallelPerNode":              true,
+	"Task":                                         true,
+	"APIRateLimiter":                               true,
+	"Log.Level":                                    true,
+	"Bucket.FreeQuotaPerBucket":                    true,
+	"Gateway.S3.AccessKeys":                        true,
+}
+
+// IsReloadableField returns whether the dotted config field, e.g. Log.Level, can be
+// applied without restart.
+func IsReloadableField(field string) bool {
+	for {
+		if reloadableFields[field] {
+			return true
+		}
+		idx := strings.LastIndex(field, ".")
+		if idx < 0 {
+			return false
+		}
+		field = field[:idx]
+	}
+}
+
+// ChangedFields returns the dotted names of the config fields that are different
+// between cfg and newCfg, the Customize is ignored.
+func (cfg *GfSpConfig) ChangedFields(newCfg *GfSpConfig) []string {
+	var changed []string
+	diffFields("", reflect.ValueOf(*cfg), reflect.ValueOf(*newCfg), &changed)
+	return changed
+}
+
+func diffFields(prefix string, oldValue, newValue reflect.Value, changed *[]string) {
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if !field.IsExported() || field.Name == "Customize" {
+			continue
+		}
+		name := prefix + field.Name
+		if field.Type.Kind() == reflect.Struct {
+			diffFields(name+".", oldValue.Field(i), newValue.Field(i), changed)
+			continue
+		}
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			*changed = append(*changed, name)
+		}
+	}
+}
+
+// CheckReloadable returns ErrNotReloadable with the changed fields if newCfg changes
+// any field that can not be applied without restart.
+func (cfg *GfSpConfig) CheckReloadable(newCfg *GfSpConfig) error {
+	var rejected []string
+	for _, field := range cfg.ChangedFields(newCfg) {
+		if !IsReloadableField(field) {
+			rejected = append(rejected, field)
+		}
+	}
+	if len(rejected) != 0 {
+		return fmt.Errorf("%w: %s", ErrNotReloadable, strings.Join(rejected, ", "))
+	}
+	return nil
+}
+
+// RedactedString returns the GfSp configuration without the private keys, passwords
+// and tokens, it is used to record the active configuration.
+func (cfg *GfSpConfig) RedactedString() string {
+	redacted := *cfg
+	redacted.Customize = nil
+	redacted.SpAccount = SpAccountConfig{SpOperateAddress: cfg.SpAccount.SpOperateAddress}
+	redacted.SpDB.Passwd = ""
+	redacted.BsDB.Passwd = ""
+	redacted.BsDBBackup.Passwd = ""
+	redacted.P2P.P2PPrivateKey = ""
+	redacted.BlockSyncer.Dsn = ""
+	redacted.BlockSyncer.DsnSwitched = ""
+	redacted.Admin.Token = ""
+	redacted.Gateway.S3.AccessKeys = make([]S3AccessKeyConfig, len(cfg.Gateway.S3.AccessKeys))
+	for i, key := range cfg.Gateway.S3.AccessKeys {
+		redacted.Gateway.S3.AccessKeys[i] = S3AccessKeyConfig{AccessKeyID: key.AccessKeyID, Account: key.Account}
+	}
+	bz, err := toml.Marshal(&redacted)
+	if err != nil {
+		return ""
+	}
+	return string(bz)
+}
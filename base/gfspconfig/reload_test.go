@@ -0,0 +1,28 @@
+package gfspconfig
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckReloadable(t *testing.T) {
+	cfg := &GfSpConfig{}
+	cfg.Log.Level = "debug"
+	cfg.Parallel.GlobalMaxUploadingParallel = 1024
+
+	newCfg := *cfg
+	newCfg.Log.Level = "info"
+	newCfg.Parallel.GlobalMaxUploadingParallel = 2048
+	newCfg.Task.UploadTaskSpeed = 1
+	assert.Equal(t, []string{"Parallel.GlobalMaxUploadingParallel", "Task.UploadTaskSpeed", "Log.Level"},
+		cfg.ChangedFields(&newCfg))
+	assert.Nil(t, cfg.CheckReloadable(&newCfg))
+
+	newCfg.Log.Path = "./sp.log"
+	newCfg.SpDB.Address = "localhost:3306"
+	err := cfg.CheckReloadable(&newCfg)
+	assert.True(t, errors.Is(err, ErrNotReloadable))
+	assert.Contains(t, err.Error(), "SpDB.Address, Log.Path")
+}
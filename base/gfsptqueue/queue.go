@@ -43,9 +43,19 @@ func (t *GfSpTQueue) Len() int {
 
 // Cap returns the capacity of queue.
 func (t *GfSpTQueue) Cap() int {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
 	return t.cap
 }
 
+// SetCap resets the capacity of queue.
+func (t *GfSpTQueue) SetCap(cap int) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.cap = cap
+	metrics.QueueCapGauge.WithLabelValues(t.name).Set(float64(t.cap))
+}
+
 // Has returns an indicator whether the task in queue.
 func (t *GfSpTQueue) Has(key coretask.TKey) bool {
 	t.mux.RLock()
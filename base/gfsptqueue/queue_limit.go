@@ -42,9 +42,18 @@ func (t *GfSpTQueueWithLimit) Len() int {
 
 // Cap returns the capacity of queue.
 func (t *GfSpTQueueWithLimit) Cap() int {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
 	return t.cap
 }
 
+// SetCap resets the capacity of queue.
+func (t *GfSpTQueueWithLimit) SetCap(cap int) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.cap = cap
+}
+
 // Has returns an indicator whether the task in queue.
 func (t *GfSpTQueueWithLimit) Has(key coretask.TKey) bool {
 	t.mux.RLock()
@@ -291,6 +291,8 @@ func (m *GfSpReplicatePieceApprovalTask) GetSignBytes() []byte {
 		StorageParams: m.GetStorageParams(),
 		Task:          &GfSpTask{CreateTime: m.GetCreateTime()},
 		ExpiredHeight: m.GetExpiredHeight(),
+		Refused:       m.GetRefused(),
+		RefuseReason:  m.GetRefuseReason(),
 	}
 	bz := ModuleCdc.MustMarshalJSON(fakeMsg)
 	return sdk.MustSortJSON(bz)
@@ -437,3 +439,11 @@ func (m *GfSpReplicatePieceApprovalTask) SetApprovedSpEndpoint(endpoint string)
 func (m *GfSpReplicatePieceApprovalTask) SetApprovedSpApprovalAddress(address string) {
 	m.ApprovedSpApprovalAddress = address
 }
+
+func (m *GfSpReplicatePieceApprovalTask) SetRefused(refused bool) {
+	m.Refused = refused
+}
+
+func (m *GfSpReplicatePieceApprovalTask) SetRefuseReason(reason string) {
+	m.RefuseReason = reason
+}
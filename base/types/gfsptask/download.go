@@ -147,10 +147,37 @@ func (m *GfSpDownloadObjectTask) SetError(err error) {
 }
 
 func (m *GfSpDownloadObjectTask) GetSize() int64 {
-	if m.GetHigh() < m.GetLow() {
-		return 0
+	var size int64
+	for _, r := range m.GetRanges() {
+		if r.High >= r.Low {
+			size += r.High - r.Low + 1
+		}
+	}
+	return size
+}
+
+func (m *GfSpDownloadObjectTask) SetRanges(ranges []coretask.TRange) {
+	m.DownloadRanges = nil
+	for i, r := range ranges {
+		if i == 0 || r.Low < m.Low {
+			m.Low = r.Low
+		}
+		if i == 0 || r.High > m.High {
+			m.High = r.High
+		}
+		m.DownloadRanges = append(m.DownloadRanges, &GfSpDownloadRange{Low: r.Low, High: r.High})
+	}
+}
+
+func (m *GfSpDownloadObjectTask) GetRanges() []coretask.TRange {
+	if len(m.GetDownloadRanges()) == 0 {
+		return []coretask.TRange{{Low: m.GetLow(), High: m.GetHigh()}}
+	}
+	ranges := make([]coretask.TRange, 0, len(m.GetDownloadRanges()))
+	for _, r := range m.GetDownloadRanges() {
+		ranges = append(ranges, coretask.TRange{Low: r.GetLow(), High: r.GetHigh()})
 	}
-	return m.GetHigh() - m.GetLow() + 1
+	return ranges
 }
 
 func (m *GfSpDownloadObjectTask) SetObjectInfo(object *storagetypes.ObjectInfo) {
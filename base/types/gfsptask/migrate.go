@@ -0,0 +1,239 @@
+package gfsptask
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	corercmgr "github.com/bnb-chain/greenfield-storage-provider/core/rcmgr"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+var _ coretask.MigrateBucketTask = &GfSpMigrateBucketTask{}
+
+// ErrMigrateFrameTooLarge is returned if the size of migrate frame exceeds the limit.
+var ErrMigrateFrameTooLarge = errors.New("migrate frame exceeds the max size")
+
+func (m *GfSpMigrateBucketTask) InitMigrateBucketTask(
+	bucket *storagetypes.BucketInfo,
+	srcSpEndpoint, destSpOperatorAddress string,
+	priority coretask.TPriority,
+	timeout int64,
+	retry int64) {
+	m.Reset()
+	m.Task = &GfSpTask{}
+	m.SetBucketInfo(bucket)
+	m.SetSrcSpEndpoint(srcSpEndpoint)
+	m.SetDestSpOperatorAddress(destSpOperatorAddress)
+	m.SetPriority(priority)
+	m.SetCreateTime(time.Now().Unix())
+	m.SetUpdateTime(time.Now().Unix())
+	m.SetTimeout(timeout)
+	m.SetMaxRetry(retry)
+}
+
+func (m *GfSpMigrateBucketTask) Key() coretask.TKey {
+	return GfSpMigrateBucketTaskKey(m.GetBucketInfo().GetBucketName(), m.GetBucketInfo().Id.String())
+}
+
+func (m *GfSpMigrateBucketTask) Type() coretask.TType {
+	return coretask.TypeTaskMigrateBucket
+}
+
+func (m *GfSpMigrateBucketTask) Info() string {
+	return fmt.Sprintf("key[%s], type[%s], priority[%d], limit[%s], src[%s], dest[%s], last_object[%s], migrated[%d], finished[%t], %s",
+		m.Key(), coretask.TaskTypeName(m.Type()), m.GetPriority(), m.EstimateLimit().String(),
+		m.GetSrcSpEndpoint(), m.GetDestSpOperatorAddress(), m.GetLastMigratedObjectName(),
+		m.GetMigratedObjectNumber(), m.GetFinished(), m.GetTask().Info())
+}
+
+func (m *GfSpMigrateBucketTask) GetAddress() string {
+	return m.GetTask().GetAddress()
+}
+
+func (m *GfSpMigrateBucketTask) SetAddress(address string) {
+	m.GetTask().SetAddress(address)
+}
+
+func (m *GfSpMigrateBucketTask) GetCreateTime() int64 {
+	return m.GetTask().GetCreateTime()
+}
+
+func (m *GfSpMigrateBucketTask) SetCreateTime(time int64) {
+	m.GetTask().SetCreateTime(time)
+}
+
+func (m *GfSpMigrateBucketTask) GetUpdateTime() int64 {
+	return m.GetTask().GetUpdateTime()
+}
+
+func (m *GfSpMigrateBucketTask) SetUpdateTime(time int64) {
+	m.GetTask().SetUpdateTime(time)
+}
+
+func (m *GfSpMigrateBucketTask) GetTimeout() int64 {
+	return m.GetTask().GetTimeout()
+}
+
+func (m *GfSpMigrateBucketTask) SetTimeout(time int64) {
+	m.GetTask().SetTimeout(time)
+}
+
+func (m *GfSpMigrateBucketTask) ExceedTimeout() bool {
+	return m.GetTask().ExceedTimeout()
+}
+
+func (m *GfSpMigrateBucketTask) GetRetry() int64 {
+	return m.GetTask().GetRetry()
+}
+
+func (m *GfSpMigrateBucketTask) IncRetry() {
+	m.GetTask().IncRetry()
+}
+
+func (m *GfSpMigrateBucketTask) SetRetry(retry int) {
+	m.GetTask().SetRetry(retry)
+}
+
+func (m *GfSpMigrateBucketTask) GetMaxRetry() int64 {
+	return m.GetTask().GetMaxRetry()
+}
+
+func (m *GfSpMigrateBucketTask) SetMaxRetry(limit int64) {
+	m.GetTask().SetMaxRetry(limit)
+}
+
+func (m *GfSpMigrateBucketTask) ExceedRetry() bool {
+	return m.GetTask().ExceedRetry()
+}
+
+func (m *GfSpMigrateBucketTask) Expired() bool {
+	return m.GetTask().Expired()
+}
+
+func (m *GfSpMigrateBucketTask) GetPriority() coretask.TPriority {
+	return m.GetTask().GetPriority()
+}
+
+func (m *GfSpMigrateBucketTask) SetPriority(priority coretask.TPriority) {
+	m.GetTask().SetPriority(priority)
+}
+
+func (m *GfSpMigrateBucketTask) EstimateLimit() corercmgr.Limit {
+	return LimitEstimateByPriority(m.GetPriority())
+}
+
+func (m *GfSpMigrateBucketTask) Error() error {
+	return m.GetTask().Error()
+}
+
+func (m *GfSpMigrateBucketTask) SetError(err error) {
+	m.GetTask().SetError(err)
+}
+
+func (m *GfSpMigrateBucketTask) SetBucketInfo(bucket *storagetypes.BucketInfo) {
+	m.BucketInfo = bucket
+}
+
+func (m *GfSpMigrateBucketTask) SetSrcSpEndpoint(endpoint string) {
+	m.SrcSpEndpoint = endpoint
+}
+
+func (m *GfSpMigrateBucketTask) SetDestSpOperatorAddress(address string) {
+	m.DestSpOperatorAddress = address
+}
+
+func (m *GfSpMigrateBucketTask) SetLastMigratedObjectName(object string) {
+	m.LastMigratedObjectName = object
+}
+
+func (m *GfSpMigrateBucketTask) SetMigratedObjectNumber(number uint64) {
+	m.MigratedObjectNumber = number
+}
+
+func (m *GfSpMigrateBucketTask) SetFinished(finished bool) {
+	m.Finished = finished
+}
+
+func (m *GfSpMigrateBucketTask) SetSignature(signature []byte) {
+	m.Signature = signature
+}
+
+func (m *GfSpMigrateBucketTask) SetOwnerSignature(signature []byte) {
+	m.OwnerSignature = signature
+}
+
+func (m *GfSpMigrateBucketTask) SetApprovalExpiry(expiry int64) {
+	m.ApprovalExpiry = expiry
+}
+
+func (m *GfSpMigrateBucketTask) GetApprovalSignBytes() []byte {
+	return MigrateBucketApprovalSignBytes(m.GetBucketInfo().Id.Uint64(), m.GetDestSpOperatorAddress(),
+		m.GetApprovalExpiry())
+}
+
+// MigrateBucketApprovalSignBytes returns the bytes for the bucket owner to sign to approve
+// migrating the bucket to the destination SP until the expiry unix time.
+func MigrateBucketApprovalSignBytes(bucketID uint64, destSpOperatorAddress string, expiry int64) []byte {
+	return []byte(fmt.Sprintf("GreenfieldMigrateBucket\n%d\n%s\n%d", bucketID, destSpOperatorAddress, expiry))
+}
+
+func (m *GfSpMigrateBucketTask) GetSignBytes() []byte {
+	fakeMsg := &GfSpMigrateBucketTask{
+		BucketInfo:             m.GetBucketInfo(),
+		Task:                   &GfSpTask{CreateTime: m.GetCreateTime(), UpdateTime: m.GetUpdateTime()},
+		SrcSpEndpoint:          m.GetSrcSpEndpoint(),
+		DestSpOperatorAddress:  m.GetDestSpOperatorAddress(),
+		LastMigratedObjectName: m.GetLastMigratedObjectName(),
+		OwnerSignature:         m.GetOwnerSignature(),
+		ApprovalExpiry:         m.GetApprovalExpiry(),
+	}
+	bz := ModuleCdc.MustMarshalJSON(fakeMsg)
+	return sdk.MustSortJSON(bz)
+}
+
+// WriteMigrateFrame writes the frame of bucket migration stream, the frame is encoded
+// as the uvarint length followed by the proto bytes.
+func WriteMigrateFrame(w io.Writer, frame *GfSpMigrateFrame) error {
+	data, err := frame.Marshal()
+	if err != nil {
+		return err
+	}
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(len(data)))
+	if _, err = w.Write(size[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ReadMigrateFrame reads the frame of bucket migration stream that is written by
+// WriteMigrateFrame, returns io.EOF if the stream ends at the frame boundary.
+func ReadMigrateFrame(r *bufio.Reader, maxSize uint64) (*GfSpMigrateFrame, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSize {
+		return nil, ErrMigrateFrameTooLarge
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	frame := &GfSpMigrateFrame{}
+	if err = frame.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
@@ -0,0 +1,160 @@
+package gfsptask
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsplimit"
+	corercmgr "github.com/bnb-chain/greenfield-storage-provider/core/rcmgr"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+var _ coretask.RepairPieceTask = &GfSpRepairPieceTask{}
+
+func (m *GfSpRepairPieceTask) InitRepairPieceTask(
+	object *storagetypes.ObjectInfo,
+	params *storagetypes.Params,
+	replicateIdx uint32,
+	unhealthySpAddress string,
+	priority coretask.TPriority,
+	timeout int64,
+	retry int64) {
+	m.Reset()
+	m.Task = &GfSpTask{}
+	m.SetObjectInfo(object)
+	m.SetStorageParams(params)
+	m.SetReplicateIdx(replicateIdx)
+	m.SetUnhealthySpAddress(unhealthySpAddress)
+	m.SetPriority(priority)
+	m.SetCreateTime(time.Now().Unix())
+	m.SetUpdateTime(time.Now().Unix())
+	m.SetTimeout(timeout)
+	m.SetMaxRetry(retry)
+}
+
+func (m *GfSpRepairPieceTask) Key() coretask.TKey {
+	return GfSpRepairPieceTaskKey(
+		m.GetObjectInfo().GetBucketName(),
+		m.GetObjectInfo().GetObjectName(),
+		m.GetObjectInfo().Id.String(),
+		m.GetReplicateIdx())
+}
+
+func (m *GfSpRepairPieceTask) Type() coretask.TType {
+	return coretask.TypeTaskRepairPiece
+}
+
+func (m *GfSpRepairPieceTask) Info() string {
+	return fmt.Sprintf("key[%s], type[%s], priority[%d], limit[%s], unhealthy_sp[%s], dest_sp[%s], %s",
+		m.Key(), coretask.TaskTypeName(m.Type()), m.GetPriority(), m.EstimateLimit().String(),
+		m.GetUnhealthySpAddress(), m.GetDestSpOperatorAddress(), m.GetTask().Info())
+}
+
+func (m *GfSpRepairPieceTask) GetAddress() string {
+	return m.GetTask().GetAddress()
+}
+
+func (m *GfSpRepairPieceTask) SetAddress(address string) {
+	m.GetTask().SetAddress(address)
+}
+
+func (m *GfSpRepairPieceTask) GetCreateTime() int64 {
+	return m.GetTask().GetCreateTime()
+}
+
+func (m *GfSpRepairPieceTask) SetCreateTime(time int64) {
+	m.GetTask().SetCreateTime(time)
+}
+
+func (m *GfSpRepairPieceTask) GetUpdateTime() int64 {
+	return m.GetTask().GetUpdateTime()
+}
+
+func (m *GfSpRepairPieceTask) SetUpdateTime(time int64) {
+	m.GetTask().SetUpdateTime(time)
+}
+
+func (m *GfSpRepairPieceTask) GetTimeout() int64 {
+	return m.GetTask().GetTimeout()
+}
+
+func (m *GfSpRepairPieceTask) SetTimeout(time int64) {
+	m.GetTask().SetTimeout(time)
+}
+
+func (m *GfSpRepairPieceTask) ExceedTimeout() bool {
+	return m.GetTask().ExceedTimeout()
+}
+
+func (m *GfSpRepairPieceTask) GetRetry() int64 {
+	return m.GetTask().GetRetry()
+}
+
+func (m *GfSpRepairPieceTask) IncRetry() {
+	m.GetTask().IncRetry()
+}
+
+func (m *GfSpRepairPieceTask) SetRetry(retry int) {
+	m.GetTask().SetRetry(retry)
+}
+
+func (m *GfSpRepairPieceTask) GetMaxRetry() int64 {
+	return m.GetTask().GetMaxRetry()
+}
+
+func (m *GfSpRepairPieceTask) SetMaxRetry(limit int64) {
+	m.GetTask().SetMaxRetry(limit)
+}
+
+func (m *GfSpRepairPieceTask) ExceedRetry() bool {
+	return m.GetTask().ExceedRetry()
+}
+
+func (m *GfSpRepairPieceTask) Expired() bool {
+	return m.GetTask().Expired()
+}
+
+func (m *GfSpRepairPieceTask) GetPriority() coretask.TPriority {
+	return m.GetTask().GetPriority()
+}
+
+func (m *GfSpRepairPieceTask) SetPriority(priority coretask.TPriority) {
+	m.GetTask().SetPriority(priority)
+}
+
+// EstimateLimit estimates the memory of a segment and its EC pieces, the pieces are
+// regenerated and replicated segment by segment.
+func (m *GfSpRepairPieceTask) EstimateLimit() corercmgr.Limit {
+	l := &gfsplimit.GfSpLimit{Memory: int64(m.GetStorageParams().VersionedParams.GetMaxSegmentSize()) * 2}
+	l.Add(LimitEstimateByPriority(m.GetPriority()))
+	return l
+}
+
+func (m *GfSpRepairPieceTask) Error() error {
+	return m.GetTask().Error()
+}
+
+func (m *GfSpRepairPieceTask) SetError(err error) {
+	m.GetTask().SetError(err)
+}
+
+func (m *GfSpRepairPieceTask) SetObjectInfo(object *storagetypes.ObjectInfo) {
+	m.ObjectInfo = object
+}
+
+func (m *GfSpRepairPieceTask) SetStorageParams(param *storagetypes.Params) {
+	m.StorageParams = param
+}
+
+func (m *GfSpRepairPieceTask) SetReplicateIdx(idx uint32) {
+	m.ReplicateIdx = idx
+}
+
+func (m *GfSpRepairPieceTask) SetUnhealthySpAddress(address string) {
+	m.UnhealthySpAddress = address
+}
+
+func (m *GfSpRepairPieceTask) SetDestSpOperatorAddress(address string) {
+	m.DestSpOperatorAddress = address
+}
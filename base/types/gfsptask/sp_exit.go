@@ -0,0 +1,159 @@
+package gfsptask
+
+import (
+	"fmt"
+	"time"
+
+	corercmgr "github.com/bnb-chain/greenfield-storage-provider/core/rcmgr"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+)
+
+var _ coretask.SPExitTask = &GfSpSPExitTask{}
+
+func (m *GfSpSPExitTask) InitSPExitTask(
+	spOperatorAddress string,
+	priority coretask.TPriority,
+	timeout int64,
+	retry int64) {
+	m.Reset()
+	m.Task = &GfSpTask{}
+	m.SetSpOperatorAddress(spOperatorAddress)
+	m.SetPriority(priority)
+	m.SetCreateTime(time.Now().Unix())
+	m.SetUpdateTime(time.Now().Unix())
+	m.SetTimeout(timeout)
+	m.SetMaxRetry(retry)
+}
+
+func (m *GfSpSPExitTask) Key() coretask.TKey {
+	return GfSpSPExitTaskKey(m.GetSpOperatorAddress())
+}
+
+func (m *GfSpSPExitTask) Type() coretask.TType {
+	return coretask.TypeTaskSPExit
+}
+
+func (m *GfSpSPExitTask) Info() string {
+	return fmt.Sprintf("key[%s], type[%s], priority[%d], limit[%s], last_object[%d], last_bucket[%d], "+
+		"handover_object[%d], failed_object[%d], handover_bucket[%d], failed_bucket[%d], finished[%t], %s",
+		m.Key(), coretask.TaskTypeName(m.Type()), m.GetPriority(), m.EstimateLimit().String(),
+		m.GetLastSecondaryObjectId(), m.GetLastPrimaryBucketId(), m.GetHandoverObjectNumber(),
+		m.GetFailedObjectNumber(), m.GetHandoverBucketNumber(), m.GetFailedBucketNumber(),
+		m.GetFinished(), m.GetTask().Info())
+}
+
+func (m *GfSpSPExitTask) GetAddress() string {
+	return m.GetTask().GetAddress()
+}
+
+func (m *GfSpSPExitTask) SetAddress(address string) {
+	m.GetTask().SetAddress(address)
+}
+
+func (m *GfSpSPExitTask) GetCreateTime() int64 {
+	return m.GetTask().GetCreateTime()
+}
+
+func (m *GfSpSPExitTask) SetCreateTime(time int64) {
+	m.GetTask().SetCreateTime(time)
+}
+
+func (m *GfSpSPExitTask) GetUpdateTime() int64 {
+	return m.GetTask().GetUpdateTime()
+}
+
+func (m *GfSpSPExitTask) SetUpdateTime(time int64) {
+	m.GetTask().SetUpdateTime(time)
+}
+
+func (m *GfSpSPExitTask) GetTimeout() int64 {
+	return m.GetTask().GetTimeout()
+}
+
+func (m *GfSpSPExitTask) SetTimeout(time int64) {
+	m.GetTask().SetTimeout(time)
+}
+
+func (m *GfSpSPExitTask) ExceedTimeout() bool {
+	return m.GetTask().ExceedTimeout()
+}
+
+func (m *GfSpSPExitTask) GetRetry() int64 {
+	return m.GetTask().GetRetry()
+}
+
+func (m *GfSpSPExitTask) IncRetry() {
+	m.GetTask().IncRetry()
+}
+
+func (m *GfSpSPExitTask) SetRetry(retry int) {
+	m.GetTask().SetRetry(retry)
+}
+
+func (m *GfSpSPExitTask) GetMaxRetry() int64 {
+	return m.GetTask().GetMaxRetry()
+}
+
+func (m *GfSpSPExitTask) SetMaxRetry(limit int64) {
+	m.GetTask().SetMaxRetry(limit)
+}
+
+func (m *GfSpSPExitTask) ExceedRetry() bool {
+	return m.GetTask().ExceedRetry()
+}
+
+func (m *GfSpSPExitTask) Expired() bool {
+	return m.GetTask().Expired()
+}
+
+func (m *GfSpSPExitTask) GetPriority() coretask.TPriority {
+	return m.GetTask().GetPriority()
+}
+
+func (m *GfSpSPExitTask) SetPriority(priority coretask.TPriority) {
+	m.GetTask().SetPriority(priority)
+}
+
+func (m *GfSpSPExitTask) EstimateLimit() corercmgr.Limit {
+	return LimitEstimateByPriority(m.GetPriority())
+}
+
+func (m *GfSpSPExitTask) Error() error {
+	return m.GetTask().Error()
+}
+
+func (m *GfSpSPExitTask) SetError(err error) {
+	m.GetTask().SetError(err)
+}
+
+func (m *GfSpSPExitTask) SetSpOperatorAddress(address string) {
+	m.SpOperatorAddress = address
+}
+
+func (m *GfSpSPExitTask) SetLastSecondaryObjectId(id uint64) {
+	m.LastSecondaryObjectId = id
+}
+
+func (m *GfSpSPExitTask) SetLastPrimaryBucketId(id uint64) {
+	m.LastPrimaryBucketId = id
+}
+
+func (m *GfSpSPExitTask) SetHandoverObjectNumber(number uint64) {
+	m.HandoverObjectNumber = number
+}
+
+func (m *GfSpSPExitTask) SetFailedObjectNumber(number uint64) {
+	m.FailedObjectNumber = number
+}
+
+func (m *GfSpSPExitTask) SetHandoverBucketNumber(number uint64) {
+	m.HandoverBucketNumber = number
+}
+
+func (m *GfSpSPExitTask) SetFailedBucketNumber(number uint64) {
+	m.FailedBucketNumber = number
+}
+
+func (m *GfSpSPExitTask) SetFinished(finished bool) {
+	m.Finished = finished
+}
@@ -109,6 +109,7 @@ func LimitEstimateByPriority(priority coretask.TPriority) rcmgr.Limit {
 func RegisterCodec(cdc *codec.LegacyAmino) {
 	cdc.RegisterConcrete(&GfSpReplicatePieceApprovalTask{}, "p2p/ReplicatePieceApprovalTask", nil)
 	cdc.RegisterConcrete(&GfSpReceivePieceTask{}, "secondary/ReceivePieceTask", nil)
+	cdc.RegisterConcrete(&GfSpMigrateBucketTask{}, "migrate/MigrateBucketTask", nil)
 }
 
 var (
@@ -18,6 +18,9 @@ const (
 	KeyPrefixGfSpReplicatePieceTask         = "Replicating"
 	KeyPrefixGfSpSealObjectTask             = "Sealing"
 	KeyPrefixGfSpReceivePieceTask           = "ReceivePiece"
+	KeyPrefixGfSpMigrateBucketTask          = "MigrateBucket"
+	KeyPrefixGfSpSPExitTask                 = "SPExit"
+	KeyPrefixGfSpRepairPieceTask            = "RepairPiece"
 )
 
 var (
@@ -78,6 +81,19 @@ func GfSpGfSpGCMetaTaskKey(time int64) task.TKey {
 	return task.TKey(KeyPrefixGfSpGfSpGCMetaTask + CombineKey(fmt.Sprint(time)))
 }
 
+func GfSpMigrateBucketTaskKey(bucket, id string) task.TKey {
+	return task.TKey(KeyPrefixGfSpMigrateBucketTask + CombineKey(bucket, id))
+}
+
+func GfSpSPExitTaskKey(spOperatorAddress string) task.TKey {
+	return task.TKey(KeyPrefixGfSpSPExitTask + CombineKey(spOperatorAddress))
+}
+
+func GfSpRepairPieceTaskKey(bucket, object, id string, replicateIdx uint32) task.TKey {
+	return task.TKey(KeyPrefixGfSpRepairPieceTask + CombineKey(bucket, object, id,
+		"rIdx:"+fmt.Sprint(replicateIdx)))
+}
+
 func CombineKey(field ...string) string {
 	key := ""
 	for _, f := range field {
@@ -476,6 +476,7 @@ func (m *GfSpReceivePieceTask) GetSignBytes() []byte {
 		PieceIdx:      m.GetPieceIdx(),
 		PieceSize:     m.GetPieceSize(),
 		PieceChecksum: m.GetPieceChecksum(),
+		Handover:      m.GetHandover(),
 	}
 	bz := ModuleCdc.MustMarshalJSON(fakeMsg)
 	return sdk.MustSortJSON(bz)
@@ -617,6 +618,10 @@ func (m *GfSpReceivePieceTask) SetSealed(seal bool) {
 	m.Sealed = seal
 }
 
+func (m *GfSpReceivePieceTask) SetHandover(handover bool) {
+	m.Handover = handover
+}
+
 func (m *GfSpReceivePieceTask) SetSignature(signature []byte) {
 	m.Signature = signature
 }
@@ -0,0 +1,403 @@
+package command
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspclient"
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/cmd/utils"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+var adminTokenFlag = &cli.StringFlag{
+	Name:    "token",
+	Usage:   "The token of admin service, defaults to the admin token in config file",
+	EnvVars: []string{gfspapp.SpAdminToken},
+}
+
+var taskTypeFlag = &cli.StringFlag{
+	Name:     "t",
+	Usage:    "The task type to pause or resume dispatching, e.g. ReplicatePieceTask, GCObjectTask",
+	Required: true,
+}
+
+var taskKeyFlag = &cli.StringFlag{
+	Name:     "k",
+	Usage:    "The full key of task to cancel",
+	Required: true,
+}
+
+var drainTimeoutFlag = &cli.Int64Flag{
+	Name:  "timeout",
+	Usage: "The seconds to wait for the in-flight work to finish",
+	Value: gfspapp.DefaultDrainTimeout,
+}
+
+var migrateBucketFlag = &cli.StringFlag{
+	Name:     "b",
+	Usage:    "The name of bucket to migrate to this SP",
+	Required: true,
+}
+
+var migrateSrcSpFlag = &cli.StringFlag{
+	Name:     "src",
+	Usage:    "The endpoint of the SP that the bucket migrates from, e.g. https://gnfd-sp.example.com",
+	Required: true,
+}
+
+var migrateOwnerSignatureFlag = &cli.StringFlag{
+	Name:     "owner-signature",
+	Usage:    "The hex encoded signature of the bucket owner that approves migrating the bucket to this SP",
+	Required: true,
+}
+
+var migrateApprovalExpiryFlag = &cli.Int64Flag{
+	Name:     "approval-expiry",
+	Usage:    "The unix time until which the approval of the bucket owner is valid",
+	Required: true,
+}
+
+var spExitFailedLimitFlag = &cli.IntFlag{
+	Name:  "n",
+	Usage: "The max number of the failed objects and buckets to show",
+	Value: 20,
+}
+
+var adminFlags = []cli.Flag{
+	utils.ConfigFileFlag,
+	endpointFlag,
+	adminTokenFlag,
+}
+
+var AdminPauseTaskCmd = &cli.Command{
+	Action:      adminPauseTaskAction(true),
+	Name:        "admin.pause",
+	Usage:       "Pause dispatching the task type in manager",
+	Category:    "ADMIN COMMANDS",
+	Flags:       append(adminFlags, taskTypeFlag),
+	Description: `The admin.pause command stops the manager dispatching the task type to executor, the tasks stay in the queue.`,
+}
+
+var AdminResumeTaskCmd = &cli.Command{
+	Action:      adminPauseTaskAction(false),
+	Name:        "admin.resume",
+	Usage:       "Resume dispatching the task type in manager",
+	Category:    "ADMIN COMMANDS",
+	Flags:       append(adminFlags, taskTypeFlag),
+	Description: `The admin.resume command resumes dispatching the task type paused by admin.pause.`,
+}
+
+var AdminCancelTaskCmd = &cli.Command{
+	Action:   adminCancelTaskAction,
+	Name:     "admin.cancel",
+	Usage:    "Cancel the task by task key",
+	Category: "ADMIN COMMANDS",
+	Flags:    append(adminFlags, taskKeyFlag),
+	Description: `The admin.cancel command removes the task from the manager queues and cancels
+the in-flight work of the task, the later report of the task is rejected. The task
+key can be found by query.task command.`,
+}
+
+var AdminDrainCmd = &cli.Command{
+	Action:   adminDrainAction(true),
+	Name:     "admin.drain",
+	Usage:    "Reject new upload, receive and download work and wait for the in-flight work",
+	Category: "ADMIN COMMANDS",
+	Flags:    append(adminFlags, drainTimeoutFlag),
+	Description: `The admin.drain command makes the node reject new upload, receive and download
+work and waits for the in-flight work to finish, it is used before rolling upgrades.`,
+}
+
+var AdminUndrainCmd = &cli.Command{
+	Action:      adminDrainAction(false),
+	Name:        "admin.undrain",
+	Usage:       "Accept new upload, receive and download work again",
+	Category:    "ADMIN COMMANDS",
+	Flags:       adminFlags,
+	Description: `The admin.undrain command makes the drained node accept new work again.`,
+}
+
+var AdminStatusCmd = &cli.Command{
+	Action:      adminStatusAction,
+	Name:        "admin.status",
+	Usage:       "Show the draining status, in-flight work and paused task types",
+	Category:    "ADMIN COMMANDS",
+	Flags:       adminFlags,
+	Description: `The admin.status command shows the admin status of the node.`,
+}
+
+var AdminReloadConfigCmd = &cli.Command{
+	Action:   adminReloadConfigAction,
+	Name:     "admin.reload",
+	Usage:    "Reload the config file without restart",
+	Category: "ADMIN COMMANDS",
+	Flags:    adminFlags,
+	Description: `The admin.reload command makes the node reload the config file, the same as
+sending SIGHUP to the node. Only the reloadable fields, e.g. the parallel, task, rate
+limiter and log level, can be changed, the reload is rejected if other fields change.`,
+}
+
+var AdminMigrateBucketCmd = &cli.Command{
+	Action:   adminMigrateBucketAction,
+	Name:     "admin.migrate.bucket",
+	Usage:    "Migrate the pieces of bucket from the source SP to this SP",
+	Category: "ADMIN COMMANDS",
+	Flags: append(adminFlags, migrateBucketFlag, migrateSrcSpFlag, migrateOwnerSignatureFlag,
+		migrateApprovalExpiryFlag),
+	Description: `The admin.migrate.bucket command makes this SP pull the segment pieces and integrity
+meta of all objects in the bucket from the source SP, and store them after verifying
+against the checksums on the greenfield. The migration resumes from the last migrated
+object if it was interrupted, the progress can be found by query.task command with the
+returned task key. Switching the primary SP of the bucket on the greenfield is not
+included.
+
+The source SP exports the bucket only with the approval of the bucket owner, which is the
+signature of "GreenfieldMigrateBucket\n{bucket id}\n{operator address of this SP}\n{approval
+expiry}" signed by the owner account.`,
+}
+
+var AdminSPExitCmd = &cli.Command{
+	Action:   adminSPExitAction,
+	Name:     "admin.sp.exit",
+	Usage:    "Start to exit this SP gracefully",
+	Category: "ADMIN COMMANDS",
+	Flags:    adminFlags,
+	Description: `The admin.sp.exit command makes this SP stop accepting the new buckets and objects,
+stop serving as secondary SP of the new objects, and hand over its data to other SPs in
+background: the pieces that this SP stores as secondary are replicated to another SP,
+and the SPs are notified to migrate the buckets that this SP stores as primary. The exit
+resumes from the last handed over object or bucket if it was interrupted, the progress
+can be found by admin.sp.exit.status command. Updating the secondary and primary SPs on
+the greenfield is not included.`,
+}
+
+var AdminSPExitStatusCmd = &cli.Command{
+	Action:   adminSPExitStatusAction,
+	Name:     "admin.sp.exit.status",
+	Usage:    "Show the progress of exiting this SP",
+	Category: "ADMIN COMMANDS",
+	Flags:    append(adminFlags, spExitFailedLimitFlag),
+	Description: `The admin.sp.exit.status command shows the number of handed over and failed objects
+and buckets of the exiting SP, and lists the failed ones.`,
+}
+
+var AdminSPScoresCmd = &cli.Command{
+	Action:   adminSPScoresAction,
+	Name:     "admin.sp.scores",
+	Usage:    "Show the scores of the secondary SP candidates",
+	Category: "ADMIN COMMANDS",
+	Flags:    adminFlags,
+	Description: `The admin.sp.scores command shows the scores that this SP ranks the secondary SP
+candidates by, the score is built from the replicate success rate and throughput, the
+ping round trip time and the p2p failures.`,
+}
+
+// loadAdminEndpoint returns the grpc endpoint and admin token of the node.
+func loadAdminEndpoint(ctx *cli.Context) (string, string, error) {
+	endpoint := gfspapp.DefaultGrpcAddress
+	token := ""
+	if ctx.IsSet(utils.ConfigFileFlag.Name) {
+		cfg := &gfspconfig.GfSpConfig{}
+		err := utils.LoadConfig(ctx.String(utils.ConfigFileFlag.Name), cfg)
+		if err != nil {
+			log.Errorw("failed to load config file", "error", err)
+			return "", "", err
+		}
+		if cfg.GrpcAddress != "" {
+			endpoint = cfg.GrpcAddress
+		}
+		token = cfg.Admin.Token
+	}
+	if ctx.IsSet(endpointFlag.Name) {
+		endpoint = ctx.String(endpointFlag.Name)
+	}
+	if ctx.IsSet(adminTokenFlag.Name) {
+		token = ctx.String(adminTokenFlag.Name)
+	}
+	if token == "" {
+		return "", "", fmt.Errorf("admin token should be set")
+	}
+	return endpoint, token, nil
+}
+
+func parseTaskType(name string) (coretask.TType, error) {
+	for taskType, typeName := range coretask.TypeTaskMap {
+		if taskType != coretask.TypeTaskUnknown && strings.EqualFold(typeName, name) {
+			return taskType, nil
+		}
+	}
+	return coretask.TypeTaskUnknown, fmt.Errorf("unknown task type: %s", name)
+}
+
+func adminPauseTaskAction(pause bool) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		endpoint, token, err := loadAdminEndpoint(ctx)
+		if err != nil {
+			return err
+		}
+		taskType, err := parseTaskType(ctx.String(taskTypeFlag.Name))
+		if err != nil {
+			return err
+		}
+		client := &gfspclient.GfSpClient{}
+		if err = client.PauseTask(context.Background(), endpoint, token, taskType, pause); err != nil {
+			return err
+		}
+		fmt.Printf("succeed to set %s dispatching, pause: %v\n", coretask.TaskTypeName(taskType), pause)
+		return nil
+	}
+}
+
+func adminCancelTaskAction(ctx *cli.Context) error {
+	endpoint, token, err := loadAdminEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	key := ctx.String(taskKeyFlag.Name)
+	client := &gfspclient.GfSpClient{}
+	if err = client.CancelTask(context.Background(), endpoint, token, coretask.TKey(key)); err != nil {
+		return err
+	}
+	fmt.Printf("succeed to cancel task: %s\n", key)
+	return nil
+}
+
+func adminDrainAction(drain bool) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		endpoint, token, err := loadAdminEndpoint(ctx)
+		if err != nil {
+			return err
+		}
+		client := &gfspclient.GfSpClient{}
+		inflight, err := client.Drain(context.Background(), endpoint, token, drain,
+			ctx.Int64(drainTimeoutFlag.Name))
+		if err != nil {
+			return err
+		}
+		if drain && inflight != 0 {
+			return fmt.Errorf("drain timeout, %d in-flight work is unfinished", inflight)
+		}
+		fmt.Printf("succeed to set draining: %v, in-flight work: %d\n", drain, inflight)
+		return nil
+	}
+}
+
+func adminStatusAction(ctx *cli.Context) error {
+	endpoint, token, err := loadAdminEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	client := &gfspclient.GfSpClient{}
+	status, err := client.AdminStatus(context.Background(), endpoint, token)
+	if err != nil {
+		return err
+	}
+	var pausedTaskTypes []string
+	for _, taskType := range status.GetPausedTaskTypes() {
+		pausedTaskTypes = append(pausedTaskTypes, coretask.TaskTypeName(coretask.TType(taskType)))
+	}
+	fmt.Printf("draining: %v\nin-flight work: %d\npaused task types: %s\n", status.GetDraining(),
+		status.GetInflight(), strings.Join(pausedTaskTypes, ", "))
+	return nil
+}
+
+func adminReloadConfigAction(ctx *cli.Context) error {
+	endpoint, token, err := loadAdminEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	client := &gfspclient.GfSpClient{}
+	version, err := client.ReloadConfig(context.Background(), endpoint, token)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("succeed to reload config, active config version: %s\n", version)
+	return nil
+}
+
+func adminMigrateBucketAction(ctx *cli.Context) error {
+	endpoint, token, err := loadAdminEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	ownerSignature, err := hex.DecodeString(ctx.String(migrateOwnerSignatureFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to decode owner signature: %v", err)
+	}
+	client := &gfspclient.GfSpClient{}
+	key, err := client.MigrateBucket(context.Background(), endpoint, token,
+		ctx.String(migrateBucketFlag.Name), ctx.String(migrateSrcSpFlag.Name), ownerSignature,
+		ctx.Int64(migrateApprovalExpiryFlag.Name))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("succeed to create migrate bucket task: %s\n", key)
+	return nil
+}
+
+func adminSPExitAction(ctx *cli.Context) error {
+	endpoint, token, err := loadAdminEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	client := &gfspclient.GfSpClient{}
+	key, err := client.SPExit(context.Background(), endpoint, token)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("succeed to create sp exit task: %s\n", key)
+	return nil
+}
+
+func adminSPExitStatusAction(ctx *cli.Context) error {
+	endpoint, token, err := loadAdminEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	client := &gfspclient.GfSpClient{}
+	status, err := client.QuerySPExit(context.Background(), endpoint, token,
+		int32(ctx.Int(spExitFailedLimitFlag.Name)))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("finished: %v\nstart time: %s\nupdate time: %s\n", status.GetFinished(),
+		time.Unix(status.GetStartTime(), 0).Format(time.RFC3339),
+		time.Unix(status.GetUpdateTime(), 0).Format(time.RFC3339))
+	fmt.Printf("secondary objects: handover[%d], failed[%d], last object id[%d]\n",
+		status.GetHandoverObjectNumber(), status.GetFailedObjectNumber(), status.GetLastSecondaryObjectId())
+	fmt.Printf("primary buckets: handover[%d], failed[%d], last bucket id[%d]\n",
+		status.GetHandoverBucketNumber(), status.GetFailedBucketNumber(), status.GetLastPrimaryBucketId())
+	for _, record := range status.GetFailedRecords() {
+		fmt.Printf("failed %s[%d] %s, dest sp: %s, error: %s\n", record.GetResourceType(),
+			record.GetResourceId(), record.GetResourceName(), record.GetDestSpAddress(), record.GetErrorMessage())
+	}
+	return nil
+}
+
+func adminSPScoresAction(ctx *cli.Context) error {
+	endpoint, token, err := loadAdminEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	client := &gfspclient.GfSpClient{}
+	scores, err := client.QuerySPScores(context.Background(), endpoint, token)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-42s  %6s  %8s  %8s  %14s  %8s  %6s  %s\n", "sp", "score", "succeed", "failed",
+		"throughput", "rtt(ms)", "p2p", "update time")
+	for _, score := range scores {
+		fmt.Printf("%-42s  %6.2f  %8d  %8d  %14.0f  %8d  %6d  %s\n", score.GetSpAddress(), score.GetScore(),
+			score.GetReplicateSucceed(), score.GetReplicateFailed(), score.GetThroughput(), score.GetPingRtt(),
+			score.GetP2PFailure(), time.Unix(score.GetUpdateTime(), 0).Format(time.RFC3339))
+	}
+	return nil
+}
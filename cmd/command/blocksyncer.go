@@ -0,0 +1,85 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/cmd/utils"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/util"
+)
+
+var reconcileFromFlag = &cli.Uint64Flag{
+	Name:     "from",
+	Usage:    "The first block height to process again, the heights from it to the current synced height are processed",
+	Required: true,
+}
+
+var reconcileModulesFlag = &cli.StringFlag{
+	Name:     "modules",
+	Usage:    "The comma separated modules to reconcile, e.g. object,prefixtree",
+	Required: true,
+}
+
+var reconcileApplyFlag = &cli.BoolFlag{
+	Name:  "apply",
+	Usage: "Apply the corrections to the live tables, only show the differences by default",
+}
+
+var BlockSyncerReconcileCmd = &cli.Command{
+	Action:   blockSyncerReconcileAction,
+	Name:     "blocksyncer.reconcile",
+	Usage:    "Process the latest blocks again and diff the result against the live tables",
+	Category: "BLOCK SYNCER COMMANDS",
+	Flags: []cli.Flag{
+		utils.ConfigFileFlag,
+		reconcileFromFlag,
+		reconcileModulesFlag,
+		reconcileApplyFlag,
+	},
+	Description: `The blocksyncer.reconcile command processes the events from the from height to
+the current synced height again into the shadow schema of the current master block syncer db,
+and shows the differences of the shadow and the live tables. The block syncer db keeps no
+history, so the shadow tables are seeded by the live tables as of the current synced height,
+and the command always ends at it. The rows written by the events of these heights are
+corrected, the corrupted columns that no event of these heights writes are neither reported
+nor corrected. Only the mysql block syncer db is supported. The live tables are corrected by
+the differences if the apply flag is set, the block syncer must be stopped before applying.`,
+}
+
+func blockSyncerReconcileAction(ctx *cli.Context) error {
+	if !ctx.IsSet(utils.ConfigFileFlag.Name) {
+		return fmt.Errorf("config file is required to reconcile")
+	}
+	cfg := &gfspconfig.GfSpConfig{}
+	if err := utils.LoadConfig(ctx.String(utils.ConfigFileFlag.Name), cfg); err != nil {
+		log.Errorw("failed to load config file", "error", err)
+		return err
+	}
+	if err := blocksyncer.CheckReconcileDialect(cfg); err != nil {
+		return err
+	}
+	if len(cfg.Chain.ChainAddress) == 0 {
+		return fmt.Errorf("chain address is required to reconcile")
+	}
+	diffs, err := blocksyncer.Reconcile(context.Background(), cfg, &blocksyncer.ReconcileOptions{
+		From:    ctx.Uint64(reconcileFromFlag.Name),
+		Modules: util.SplitByComma(ctx.String(reconcileModulesFlag.Name)),
+		Apply:   ctx.Bool(reconcileApplyFlag.Name),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-32s  %10s  %10s  %10s\n", "TABLE", "MISSING", "STALE", "CHANGED")
+	for _, diff := range diffs {
+		fmt.Printf("%-32s  %10d  %10d  %10d\n", diff.Table, diff.Missing, diff.Stale, diff.Changed)
+	}
+	if ctx.Bool(reconcileApplyFlag.Name) {
+		fmt.Println("the differences are applied to the live tables")
+	}
+	return nil
+}
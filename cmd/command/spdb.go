@@ -0,0 +1,140 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/cmd/utils"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/store/sqldb"
+)
+
+var targetVersionFlag = &cli.UintFlag{
+	Name:  "v",
+	Usage: "The target schema version, up defaults to the latest version, down defaults to the previous version",
+}
+
+var SpDBMigrateCmd = &cli.Command{
+	Name:     "spdb.migrate",
+	Usage:    "Show, apply or revert the versioned schema migrations of sp db",
+	Category: "SPDB COMMANDS",
+	Description: `The spdb.migrate command manages the schema of sp db, the applied
+migrations are recorded in the schema_version table.`,
+	Subcommands: []*cli.Command{
+		{
+			Action: spDBMigrateStatusAction,
+			Name:   "status",
+			Usage:  "Show the current schema version and the applied status of migrations",
+			Flags: []cli.Flag{
+				utils.ConfigFileFlag,
+			},
+		},
+		{
+			Action: spDBMigrateUpAction,
+			Name:   "up",
+			Usage:  "Apply the pending migrations until the target version",
+			Flags: []cli.Flag{
+				utils.ConfigFileFlag,
+				targetVersionFlag,
+			},
+		},
+		{
+			Action: spDBMigrateDownAction,
+			Name:   "down",
+			Usage:  "Revert the applied migrations newer than the target version",
+			Flags: []cli.Flag{
+				utils.ConfigFileFlag,
+				targetVersionFlag,
+			},
+		},
+	},
+}
+
+// makeSpDBMigrator opens the sp db by the config file without migrating it.
+func makeSpDBMigrator(ctx *cli.Context) (*sqldb.Migrator, error) {
+	cfg := &gfspconfig.GfSpConfig{}
+	if ctx.IsSet(utils.ConfigFileFlag.Name) {
+		if err := utils.LoadConfig(ctx.String(utils.ConfigFileFlag.Name), cfg); err != nil {
+			log.Errorw("failed to load config file", "error", err)
+			return nil, err
+		}
+	}
+	sqldb.LoadDBConfigFromEnv(&cfg.SpDB)
+	sqldb.OverrideConfigVacancy(&cfg.SpDB)
+	db, err := sqldb.OpenDB(&cfg.SpDB)
+	if err != nil {
+		return nil, err
+	}
+	return sqldb.NewMigrator(db), nil
+}
+
+func spDBMigrateStatusAction(ctx *cli.Context) error {
+	migrator, err := makeSpDBMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	current, err := migrator.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current version: %d, latest version: %d\n", current, migrator.LatestVersion())
+	for _, migration := range status {
+		appliedTime := "pending"
+		if migration.Applied {
+			appliedTime = migration.AppliedTime.String()
+		}
+		fmt.Printf("%6d  %-40s  %s\n", migration.Version, migration.Description, appliedTime)
+	}
+	if current > migrator.LatestVersion() {
+		return sqldb.ErrUnknownSchemaVersion
+	}
+	return nil
+}
+
+func spDBMigrateUpAction(ctx *cli.Context) error {
+	migrator, err := makeSpDBMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	if err = migrator.Up(uint32(ctx.Uint(targetVersionFlag.Name))); err != nil {
+		return err
+	}
+	return printSpDBVersion(migrator)
+}
+
+func spDBMigrateDownAction(ctx *cli.Context) error {
+	migrator, err := makeSpDBMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	current, err := migrator.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return fmt.Errorf("no migration has been applied")
+	}
+	target := current - 1
+	if ctx.IsSet(targetVersionFlag.Name) {
+		target = uint32(ctx.Uint(targetVersionFlag.Name))
+	}
+	if err = migrator.Down(target); err != nil {
+		return err
+	}
+	return printSpDBVersion(migrator)
+}
+
+func printSpDBVersion(migrator *sqldb.Migrator) error {
+	current, err := migrator.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current version: %d, latest version: %d\n", current, migrator.LatestVersion())
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/hex"
+	"os"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/fakechain"
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/cmd/utils"
+	mpiecestore "github.com/bnb-chain/greenfield-storage-provider/model/piecestore"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/signer"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield/sdk/keys"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
+)
+
+// makeFakeChain makes the fake chain for running SP locally without greenfield
+// node. The missing SP private keys are generated, the SP is registered to the
+// fake chain as in service, and the piece store is replaced by the memory store.
+func makeFakeChain(ctx *cli.Context, cfg *gfspconfig.GfSpConfig) (*fakechain.FakeChain, error) {
+	privateKeys := []struct {
+		env string
+		key *string
+	}{
+		{signer.SpOperatorPrivKey, &cfg.SpAccount.OperatorPrivateKey},
+		{signer.SpFundingPrivKey, &cfg.SpAccount.FundingPrivateKey},
+		{signer.SpSealPrivKey, &cfg.SpAccount.SealPrivateKey},
+		{signer.SpApprovalPrivKey, &cfg.SpAccount.ApprovalPrivateKey},
+		{signer.SpGcPrivKey, &cfg.SpAccount.GcPrivateKey},
+	}
+	addresses := make([]string, len(privateKeys))
+	for i, privateKey := range privateKeys {
+		if val, ok := os.LookupEnv(privateKey.env); ok {
+			*privateKey.key = val
+		}
+		if *privateKey.key == "" {
+			key, err := crypto.GenerateKey()
+			if err != nil {
+				return nil, err
+			}
+			*privateKey.key = hex.EncodeToString(crypto.FromECDSA(key))
+			log.Warnw("generate private key for fake chain", "env", privateKey.env)
+		}
+		km, err := keys.NewPrivateKeyManager(*privateKey.key)
+		if err != nil {
+			return nil, err
+		}
+		addresses[i] = km.GetAddr().String()
+	}
+	cfg.SpAccount.SpOperateAddress = addresses[0]
+	endpoint := cfg.Gateway.Domain
+	if endpoint == "" {
+		endpoint = cfg.Gateway.HttpAddress
+	}
+
+	chain := fakechain.NewFakeChain(&fakechain.FakeChainConfig{BlockInterval: fakechain.DefaultBlockInterval})
+	chain.AddStorageProvider(&sptypes.StorageProvider{
+		OperatorAddress: addresses[0],
+		FundingAddress:  addresses[1],
+		SealAddress:     addresses[2],
+		ApprovalAddress: addresses[3],
+		GcAddress:       addresses[4],
+		Status:          sptypes.STATUS_IN_SERVICE,
+		Endpoint:        endpoint,
+	})
+	if ctx.IsSet(utils.FakeChainGenesisFlag.Name) {
+		genesis, err := fakechain.LoadGenesis(ctx.String(utils.FakeChainGenesisFlag.Name))
+		if err != nil {
+			chain.Close()
+			return nil, err
+		}
+		if err = chain.ImportGenesis(genesis); err != nil {
+			chain.Close()
+			return nil, err
+		}
+	}
+	cfg.PieceStore.Store.Storage = mpiecestore.MemoryStore
+	log.Infow("succeed to make fake chain", "sp_operator_address", cfg.SpAccount.SpOperateAddress)
+	return chain, nil
+}
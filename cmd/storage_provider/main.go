@@ -61,6 +61,11 @@ var (
 		utils.PProfDisableFlag,
 		utils.PProfHTTPFlag,
 	}
+
+	devFlags = []cli.Flag{
+		utils.FakeChainFlag,
+		utils.FakeChainGenesisFlag,
+	}
 )
 
 func init() {
@@ -75,6 +80,7 @@ func init() {
 		logFlags,
 		metricsFlags,
 		pprofFlags,
+		devFlags,
 	)
 	app.Commands = []*cli.Command{
 		// config category commands
@@ -83,6 +89,22 @@ func init() {
 		command.QueryTaskCmd,
 		// p2p category commands
 		command.P2PCreateKeysCmd,
+		// spdb category commands
+		command.SpDBMigrateCmd,
+		// block syncer category commands
+		command.BlockSyncerReconcileCmd,
+		// admin category commands
+		command.AdminPauseTaskCmd,
+		command.AdminResumeTaskCmd,
+		command.AdminCancelTaskCmd,
+		command.AdminDrainCmd,
+		command.AdminUndrainCmd,
+		command.AdminStatusCmd,
+		command.AdminReloadConfigCmd,
+		command.AdminMigrateBucketCmd,
+		command.AdminSPExitCmd,
+		command.AdminSPExitStatusCmd,
+		command.AdminSPScoresCmd,
 		// miscellaneous category commands
 		VersionCmd,
 		command.ListModularCmd,
@@ -148,8 +170,19 @@ func registerModular() {
 	gfspapp.RegisterModular(blocksyncer.BlockSyncerModularName, blocksyncer.BlockSyncerModularDescription, blocksyncer.NewBlockSyncerModular)
 }
 
-// initLog inits the log configuration from config file and command flags.
-func initLog(ctx *cli.Context, cfg *gfspconfig.GfSpConfig) error {
+// loadConfig loads the configuration in the same way as starting, it is used to
+// reload the configuration without restart.
+func loadConfig(ctx *cli.Context) (*gfspconfig.GfSpConfig, error) {
+	cfg, err := makeConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	makeLogConfig(ctx, cfg)
+	return cfg, nil
+}
+
+// makeLogConfig fills the log configuration by default values and command flags.
+func makeLogConfig(ctx *cli.Context, cfg *gfspconfig.GfSpConfig) {
 	if cfg.Log.Level == "" {
 		// TODO:: change to info
 		cfg.Log.Level = "debug"
@@ -166,6 +199,11 @@ func initLog(ctx *cli.Context, cfg *gfspconfig.GfSpConfig) error {
 	if ctx.IsSet(utils.LogStdOutputFlag.Name) {
 		cfg.Log.Path = ""
 	}
+}
+
+// initLog inits the log configuration from config file and command flags.
+func initLog(ctx *cli.Context, cfg *gfspconfig.GfSpConfig) error {
+	makeLogConfig(ctx, cfg)
 	level, err := log.ParseLevel(cfg.Log.Level)
 	if err != nil {
 		return err
@@ -197,7 +235,20 @@ func storageProvider(ctx *cli.Context) error {
 		log.Errorw("failed to make gf-sp env", "error", err)
 		return nil
 	}
-	gfsp, err := gfspapp.NewGfSpBaseApp(cfg)
+	opts := []gfspconfig.Option{
+		gfspconfig.CustomizeConfigLoader(func() (*gfspconfig.GfSpConfig, error) {
+			return loadConfig(ctx)
+		}),
+	}
+	if ctx.Bool(utils.FakeChainFlag.Name) {
+		chain, err := makeFakeChain(ctx, cfg)
+		if err != nil {
+			log.Errorw("failed to make fake chain", "error", err)
+			return err
+		}
+		opts = append(opts, gfspconfig.CustomizeConsensus(chain))
+	}
+	gfsp, err := gfspapp.NewGfSpBaseApp(cfg, opts...)
 	if err != nil {
 		log.Errorw("failed to init gf-sp app", "error", err)
 		return err
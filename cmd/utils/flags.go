@@ -17,6 +17,7 @@ const (
 	DatabaseCategory        = "DATABASE"
 	ResourceManagerCategory = "RESOURCE MANAGER"
 	PerfCategory            = "PERFORMANCE TUNING"
+	DevCategory             = "DEVELOPMENT"
 )
 
 var (
@@ -34,6 +35,18 @@ var (
 		Usage:    "Services to be started list, e.g. -server gateway, uploader, receiver...",
 	}
 
+	// development flags
+	FakeChainFlag = &cli.BoolFlag{
+		Name:     "fake-chain",
+		Category: DevCategory,
+		Usage:    "Run on the in-memory fake chain and memory piece store without greenfield node",
+	}
+	FakeChainGenesisFlag = &cli.StringFlag{
+		Name:     "fake-chain.genesis",
+		Category: DevCategory,
+		Usage:    "The json file path of fake chain genesis that includes accounts, buckets, objects etc.",
+	}
+
 	// resource manager flags
 	DisableResourceManagerFlag = &cli.BoolFlag{
 		Name:     "rcmgr.disable",
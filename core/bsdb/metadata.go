@@ -37,6 +37,12 @@ type MetadataDB interface {
 	ListDeletedObjectsByBlockNumberRange(startBlockNumber int64, endBlockNumber int64, isFullList bool) ([]*bsdb.Object, error)
 	// ListExpiredBucketsBySp list expired buckets by sp
 	ListExpiredBucketsBySp(createAt int64, primarySpAddress string, limit int64) ([]*bsdb.Bucket, error)
+	// ListObjectsBySecondarySp list objects by the secondary sp in the ascending order of object id
+	ListObjectsBySecondarySp(secondarySpAddress string, startAfterObjectID uint64, limit int64) ([]*bsdb.Object, error)
+	// GetPrefixStats get the stats of the directory and its immediate child directories by bucket name
+	GetPrefixStats(bucketName, prefix string) (*bsdb.SlashPrefixTreeNode, []*bsdb.SlashPrefixTreeNode, error)
+	// ListBucketsByPrimarySp list buckets by the primary sp in the ascending order of bucket id
+	ListBucketsByPrimarySp(primarySpAddress string, startAfterBucketID uint64, limit int64) ([]*bsdb.Bucket, error)
 	// GetObjectByName get object info by an object name
 	GetObjectByName(objectName string, bucketName string, isFullList bool) (*bsdb.Object, error)
 	// GetSwitchDBSignal check if there is a signal to switch the database
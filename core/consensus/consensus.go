@@ -19,6 +19,9 @@ type Consensus interface {
 	QuerySPInfo(ctx context.Context) ([]*sptypes.StorageProvider, error)
 	// QueryStorageParams returns the storage params.
 	QueryStorageParams(ctx context.Context) (params *storagetypes.Params, err error)
+	// QueryStorageParamsByTimestamp returns the storage params that are effective at the
+	// timestamp, it is used to get the versioned params of the object by its create time.
+	QueryStorageParamsByTimestamp(ctx context.Context, timestamp int64) (params *storagetypes.Params, err error)
 	// QueryBucketInfo returns the bucket info by bucket name.
 	QueryBucketInfo(ctx context.Context, bucket string) (*storagetypes.BucketInfo, error)
 	// QueryObjectInfo returns the object info by bucket and object name.
@@ -53,6 +56,9 @@ func (*NullConsensus) QuerySPInfo(context.Context) ([]*sptypes.StorageProvider,
 func (*NullConsensus) QueryStorageParams(context.Context) (*storagetypes.Params, error) {
 	return nil, nil
 }
+func (*NullConsensus) QueryStorageParamsByTimestamp(context.Context, int64) (*storagetypes.Params, error) {
+	return nil, nil
+}
 func (*NullConsensus) QueryBucketInfo(context.Context, string) (*storagetypes.BucketInfo, error) {
 	return nil, nil
 }
@@ -15,6 +15,15 @@ type Service interface {
 	Stop(ctx context.Context) error
 }
 
+// Reloadable is the interface to the service that supports applying the changed
+// configuration without restart.
+type Reloadable interface {
+	// ReloadConfig applies the reloadable subset of the new configuration to the
+	// running service, the cfg is the configuration type of the application, e.g.
+	// *gfspconfig.GfSpConfig.
+	ReloadConfig(ctx context.Context, cfg interface{}) error
+}
+
 // Lifecycle is the interface to the service life cycle management subsystem.
 // The ServiceLifecycle tracks the Service life cycle, listens to the signal
 // of the process for graceful exit.
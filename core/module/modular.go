@@ -45,6 +45,10 @@ const (
 	AuthOpTypeGetBucketQuota
 	// AuthOpTypeListBucketReadRecord defines the ListBucketReadRecord operator
 	AuthOpTypeListBucketReadRecord
+	// AuthOpTypeListObjects defines the ListObjects operator
+	AuthOpTypeListObjects
+	// AuthOpTypeManageNotification defines the ManageNotification operator
+	AuthOpTypeManageNotification
 )
 
 // Authorizer is the interface to authority verification modular.
@@ -110,7 +114,7 @@ type Downloader interface {
 // TaskExecutor is the interface to handle background task, it will ask task from
 // manager modular, handle the task and report the result or status to the manager
 // modular includes: ReplicatePieceTask, SealObjectTask, ReceivePieceTask, GCObjectTask
-// GCZombiePieceTask, GCMetaTask.
+// GCZombiePieceTask, GCMetaTask, MigrateBucketTask, SPExitTask, RepairPieceTask.
 type TaskExecutor interface {
 	Modular
 	// AskTask asks the task by remaining limit from manager modular.
@@ -131,6 +135,17 @@ type TaskExecutor interface {
 	HandleGCZombiePieceTask(ctx context.Context, task task.GCZombiePieceTask)
 	// HandleGCMetaTask handles the GCMetaTask that is asked from manager modular.
 	HandleGCMetaTask(ctx context.Context, task task.GCMetaTask)
+	// HandleMigrateBucketTask handles the MigrateBucketTask that is asked from manager
+	// modular. It pulls the pieces of the bucket from the source SP and stores them
+	// after verification.
+	HandleMigrateBucketTask(ctx context.Context, task task.MigrateBucketTask)
+	// HandleSPExitTask handles the SPExitTask that is asked from manager modular. It
+	// hands over the secondary pieces and the primary buckets of this SP to other SPs.
+	HandleSPExitTask(ctx context.Context, task task.SPExitTask)
+	// HandleRepairPieceTask handles the RepairPieceTask that is asked from manager
+	// modular. It regenerates the pieces of the unhealthy secondary SP and replicates
+	// them to a replacement SP.
+	HandleRepairPieceTask(ctx context.Context, task task.RepairPieceTask)
 	// ReportTask reports the result or status of running task to manager modular.
 	ReportTask(ctx context.Context, task task.Task) error
 }
@@ -176,12 +191,36 @@ type Manager interface {
 	// HandleGCMetaTask handles the result or status GCMetaTask, the request comes
 	// from TaskExecutor.
 	HandleGCMetaTask(ctx context.Context, task task.GCMetaTask) error
+	// HandleCreateMigrateBucketTask handles the request of migrating bucket to this
+	// SP, the migration resumes from the persisted progress if the bucket has been
+	// migrated partially.
+	HandleCreateMigrateBucketTask(ctx context.Context, task task.MigrateBucketTask) error
+	// HandleMigrateBucketTask handles the result or status of MigrateBucketTask, the
+	// request comes from TaskExecutor.
+	HandleMigrateBucketTask(ctx context.Context, task task.MigrateBucketTask) error
+	// HandleCreateSPExitTask handles the request of starting the exit of this SP, the
+	// exit resumes from the persisted progress if it has been started before.
+	HandleCreateSPExitTask(ctx context.Context, task task.SPExitTask) error
+	// HandleSPExitTask handles the result or status of SPExitTask, the request comes
+	// from TaskExecutor.
+	HandleSPExitTask(ctx context.Context, task task.SPExitTask) error
+	// HandleRepairPieceTask handles the result or status of RepairPieceTask, the
+	// request comes from TaskExecutor.
+	HandleRepairPieceTask(ctx context.Context, task task.RepairPieceTask) error
 	// HandleDownloadObjectTask handles the result DownloadObjectTask, the request comes
 	// from Downloader.
 	HandleDownloadObjectTask(ctx context.Context, task task.DownloadObjectTask) error
 	// HandleChallengePieceTask handles the result ChallengePieceTask, the request comes
 	// from Downloader.
 	HandleChallengePieceTask(ctx context.Context, task task.ChallengePieceTask) error
+	// PauseDispatchTask pauses or resumes dispatching the type of task to TaskExecutor,
+	// the tasks are kept in the queue during pausing.
+	PauseDispatchTask(ctx context.Context, taskType task.TType, pause bool) error
+	// QueryPausedTaskTypes queries the types of task that dispatching is paused.
+	QueryPausedTaskTypes(ctx context.Context) ([]task.TType, error)
+	// CancelTask removes the task from the queue by task key, the later report of
+	// the canceled task will get the ErrCanceledTask.
+	CancelTask(ctx context.Context, key task.TKey) error
 }
 
 // P2P is the interface to the interaction of control information between Sps.
@@ -194,6 +233,17 @@ type P2P interface {
 		min, max int32, timeout int64) ([]task.ApprovalReplicatePieceTask, error)
 	// HandleQueryBootstrap handles the query p2p node bootstrap node info.
 	HandleQueryBootstrap(ctx context.Context) ([]string, error)
+	// HandleQuerySelfNode handles the query p2p node info of self, the format is the
+	// same as the bootstrap node, it is used by other SPs to discover the p2p node.
+	HandleQuerySelfNode(ctx context.Context) (string, error)
+	// HandleQueryUnhealthySp handles the query of the SPs that all the p2p peers of
+	// them continuously fail to interact.
+	HandleQueryUnhealthySp(ctx context.Context) ([]string, error)
+	// HandleReplicatePiece replicates the piece to the approved secondary SP over p2p
+	// stream, the piece index of the receive task less than zero means done replicate
+	// piece, and the integrity hash and the signature are returned.
+	HandleReplicatePiece(ctx context.Context, approval task.ApprovalReplicatePieceTask,
+		receive task.ReceivePieceTask, data []byte) ([]byte, []byte, error)
 	// QueryTasks queries replicate piece approval tasks that running on p2p by task
 	// sub key.
 	QueryTasks(ctx context.Context, subKey task.TKey) ([]task.Task, error)
@@ -229,6 +279,9 @@ type Signer interface {
 	// SignReceivePieceTask signs the ReceivePieceTask for replicating pieces data
 	// between SPs.
 	SignReceivePieceTask(ctx context.Context, task task.ReceivePieceTask) ([]byte, error)
+	// SignMigrateBucketTask signs the MigrateBucketTask for pulling the pieces of
+	// migrating bucket from the source SP.
+	SignMigrateBucketTask(ctx context.Context, task task.MigrateBucketTask) ([]byte, error)
 	// SignIntegrityHash signs the integrity hash of object for sealing object.
 	SignIntegrityHash(ctx context.Context, objectID uint64, hash [][]byte) ([]byte, []byte, error)
 	// SignP2PPingMsg signs the ping msg for p2p node probing.
@@ -89,12 +89,34 @@ func (*NullModular) HandleGCZombiePieceTask(context.Context, task.GCZombiePieceT
 	return ErrNilModular
 }
 func (*NullModular) HandleGCMetaTask(context.Context, task.GCMetaTask) error { return ErrNilModular }
+func (*NullModular) HandleCreateMigrateBucketTask(context.Context, task.MigrateBucketTask) error {
+	return ErrNilModular
+}
+func (*NullModular) HandleMigrateBucketTask(context.Context, task.MigrateBucketTask) error {
+	return ErrNilModular
+}
+func (*NullModular) HandleCreateSPExitTask(context.Context, task.SPExitTask) error {
+	return ErrNilModular
+}
+func (*NullModular) HandleSPExitTask(context.Context, task.SPExitTask) error {
+	return ErrNilModular
+}
+func (*NullModular) HandleRepairPieceTask(context.Context, task.RepairPieceTask) error {
+	return ErrNilModular
+}
 func (*NullModular) HandleDownloadObjectTask(context.Context, task.DownloadObjectTask) error {
 	return ErrNilModular
 }
 func (*NullModular) HandleChallengePieceTask(context.Context, task.ChallengePieceTask) error {
 	return ErrNilModular
 }
+func (*NullModular) PauseDispatchTask(context.Context, task.TType, bool) error {
+	return ErrNilModular
+}
+func (*NullModular) QueryPausedTaskTypes(context.Context) ([]task.TType, error) {
+	return nil, ErrNilModular
+}
+func (*NullModular) CancelTask(context.Context, task.TKey) error { return ErrNilModular }
 func (*NullModular) VerifyAuthorize(context.Context, AuthOpType, string, string, string) (bool, error) {
 	return false, ErrNilModular
 }
@@ -138,10 +160,20 @@ func (*NilModular) HandleReceivePieceTask(context.Context, task.ReceivePieceTask
 func (*NilModular) HandleGCObjectTask(context.Context, task.GCObjectTask)             {}
 func (*NilModular) HandleGCZombiePieceTask(context.Context, task.GCZombiePieceTask)   {}
 func (*NilModular) HandleGCMetaTask(context.Context, task.GCMetaTask)                 {}
+func (*NilModular) HandleMigrateBucketTask(context.Context, task.MigrateBucketTask)   {}
+func (*NilModular) HandleSPExitTask(context.Context, task.SPExitTask)                 {}
+func (*NilModular) HandleRepairPieceTask(context.Context, task.RepairPieceTask)       {}
 func (*NilModular) HandleReplicatePieceApproval(context.Context, task.ApprovalReplicatePieceTask, int32, int32, int64) ([]task.ApprovalReplicatePieceTask, error) {
 	return nil, ErrNilModular
 }
 func (*NilModular) HandleQueryBootstrap(context.Context) ([]string, error) { return nil, ErrNilModular }
+func (*NilModular) HandleQuerySelfNode(context.Context) (string, error)    { return "", ErrNilModular }
+func (*NilModular) HandleQueryUnhealthySp(context.Context) ([]string, error) {
+	return nil, ErrNilModular
+}
+func (*NilModular) HandleReplicatePiece(context.Context, task.ApprovalReplicatePieceTask, task.ReceivePieceTask, []byte) ([]byte, []byte, error) {
+	return nil, nil, ErrNilModular
+}
 
 func (*NilModular) SignCreateBucketApproval(context.Context, *storagetypes.MsgCreateBucket) ([]byte, error) {
 	return nil, ErrNilModular
@@ -155,6 +187,9 @@ func (*NilModular) SignReplicatePieceApproval(context.Context, task.ApprovalRepl
 func (*NilModular) SignReceivePieceTask(context.Context, task.ReceivePieceTask) ([]byte, error) {
 	return nil, ErrNilModular
 }
+func (*NilModular) SignMigrateBucketTask(context.Context, task.MigrateBucketTask) ([]byte, error) {
+	return nil, ErrNilModular
+}
 func (*NilModular) SignIntegrityHash(ctx context.Context, objectID uint64, hash [][]byte) ([]byte, []byte, error) {
 	return nil, nil, ErrNilModular
 }
@@ -38,4 +38,8 @@ type PieceStore interface {
 	// DeletePiece deletes the piece data from piece store, it can delete
 	// segment or ec piece data.
 	DeletePiece(ctx context.Context, key string) error
+	// DeletePieces deletes the pieces from piece store in batch, the missing
+	// pieces are regarded as deleted, returns the errors of the pieces that
+	// are failed to delete.
+	DeletePieces(ctx context.Context, keys []string) map[string]error
 }
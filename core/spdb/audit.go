@@ -0,0 +1,28 @@
+package spdb
+
+// AuditFinding defines the problem found by the self challenge auditor on the object that
+// this SP stores, the redundancy idx is -1 if this SP is the primary SP of the object.
+type AuditFinding struct {
+	ObjectID      uint64
+	BucketName    string
+	ObjectName    string
+	RedundancyIdx int32
+	SegmentIdx    uint32
+	FindingType   string
+	Detail        string
+	Repaired      bool
+	UpdateTime    int64
+}
+
+const (
+	// AuditMissingPiece is the finding type of the piece that is not found in piece store.
+	AuditMissingPiece = "missing_piece"
+	// AuditPieceHashMismatch is the finding type of the piece whose hash mismatches the
+	// piece checksum in the integrity meta.
+	AuditPieceHashMismatch = "piece_hash_mismatch"
+	// AuditMissingIntegrity is the finding type of the object without integrity meta.
+	AuditMissingIntegrity = "missing_integrity"
+	// AuditStaleIntegrity is the finding type of the integrity meta whose integrity hash
+	// mismatches the piece checksums or the checksum on greenfield.
+	AuditStaleIntegrity = "stale_integrity"
+)
@@ -21,3 +21,22 @@ package spdb
 //	UpdateAuthKey(userAddress string, domain string, oldNonce int32, newNonce int32, newPublicKey string, newExpiryDate time.Time) error
 //	InsertAuthKey(newRecord *OffChainAuthKeyTable) error
 //}
+
+// OffChainAuthPublicKey defines the EDDSA public key registered by the user for the app domain.
+type OffChainAuthPublicKey struct {
+	// UserAddress is the account who registers the public key.
+	UserAddress string
+	// Domain is the app domain where the public key is used.
+	Domain string
+	// PublicKey is the hex encoded EDDSA public key.
+	PublicKey string
+	// ExpiryDate is the unix time after which the public key is invalid.
+	ExpiryDate int64
+}
+
+// OffChainAuthKeyDB interface queries the EDDSA public keys of the off-chain auth
+type OffChainAuthKeyDB interface {
+	// GetOffChainAuthPublicKey return the public key registered by the user for the domain,
+	// notice maybe return (nil, nil) while there is no registered public key
+	GetOffChainAuthPublicKey(userAddress string, domain string) (*OffChainAuthPublicKey, error)
+}
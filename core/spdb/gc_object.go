@@ -8,3 +8,13 @@ type GCObjectInfoDB interface {
 }
 
 */
+
+// GCFailedPiece defines the piece that gc object task failed to delete, the piece is retried
+// by the later gc object tasks instead of blocking the block range of the task.
+type GCFailedPiece struct {
+	PieceKey         string
+	ObjectID         uint64
+	RetryCount       int
+	ErrorDescription string
+	UpdateTime       int64
+}
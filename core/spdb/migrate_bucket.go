@@ -0,0 +1,17 @@
+package spdb
+
+// MigrateBucketProgress defines the progress of migrating the bucket to this SP, the
+// objects are migrated in the order of object name, LastMigratedObjectName is the cursor.
+// OwnerSignature and ApprovalExpiry are the approval of the bucket owner, they are empty
+// if the bucket is handed over by the exiting primary SP.
+type MigrateBucketProgress struct {
+	BucketName             string
+	BucketID               uint64
+	SrcSpEndpoint          string
+	LastMigratedObjectName string
+	MigratedObjectNumber   uint64
+	Finished               bool
+	UpdateTime             int64
+	OwnerSignature         []byte
+	ApprovalExpiry         int64
+}
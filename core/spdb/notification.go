@@ -0,0 +1,61 @@
+package spdb
+
+// define the event types of the object lifecycle notification
+const (
+	// NotificationEventCreateObject defines the event type of creating object
+	NotificationEventCreateObject = "create_object"
+	// NotificationEventSealObject defines the event type of sealing object
+	NotificationEventSealObject = "seal_object"
+	// NotificationEventDeleteObject defines the event type of deleting object
+	NotificationEventDeleteObject = "delete_object"
+	// NotificationEventCancelCreateObject defines the event type of canceling to create object
+	NotificationEventCancelCreateObject = "cancel_create_object"
+	// NotificationEventRejectSealObject defines the event type of rejecting to seal object
+	NotificationEventRejectSealObject = "reject_seal_object"
+)
+
+// NotificationEventTypes are all the event types that can be subscribed.
+var NotificationEventTypes = []string{
+	NotificationEventCreateObject,
+	NotificationEventSealObject,
+	NotificationEventDeleteObject,
+	NotificationEventCancelCreateObject,
+	NotificationEventRejectSealObject,
+}
+
+// NotificationSubscription defines the subscription of the object lifecycle events, the
+// events of the bucket or of the objects owned by the owner are delivered to the url.
+type NotificationSubscription struct {
+	// ID is the unique id of the subscription.
+	ID uint64
+	// Account is the address of the account that manages the subscription.
+	Account string
+	// BucketName is the bucket that the events belong to, it is empty if the subscription
+	// is of the owner.
+	BucketName string
+	// Owner is the owner of the objects that the events belong to, it is empty if the
+	// subscription is of the bucket.
+	Owner string
+	// URL is the http webhook that the events are delivered to.
+	URL string
+	// Secret is the key of signing the delivered payloads in HMAC-SHA256.
+	Secret string
+	// EventTypes are the subscribed event types, all the event types are subscribed if
+	// it is empty.
+	EventTypes []string
+	// CreateTime is the unix time of creating the subscription.
+	CreateTime int64
+}
+
+// Subscribe returns an indicator whether the event type is subscribed.
+func (s *NotificationSubscription) Subscribe(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package spdb
+
+// P2PPeer defines the p2p peer known by the p2p node, it is persisted to reconnect the
+// peers when the p2p node restarts without the bootstrap nodes.
+type P2PPeer struct {
+	// PeerID is the libp2p peer id encoded in base58.
+	PeerID string
+	// SpAddress is the operator address of the SP that the peer belongs to, it is
+	// empty if the peer does not belong to a known SP.
+	SpAddress string
+	// Addrs are the multi addresses of the peer.
+	Addrs []string
+	// FailCount is the continuous fail count of interacting with the peer.
+	FailCount int
+	// LastSeen is the unix time of the last interaction with the peer.
+	LastSeen int64
+}
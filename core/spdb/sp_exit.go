@@ -0,0 +1,48 @@
+package spdb
+
+// SPExitProgress defines the progress of handing over the data of the exiting SP, the
+// objects and buckets are handed over in the order of id, the last ids are the cursors.
+type SPExitProgress struct {
+	SpAddress             string
+	LastSecondaryObjectID uint64
+	LastPrimaryBucketID   uint64
+	HandoverObjectNumber  uint64
+	FailedObjectNumber    uint64
+	HandoverBucketNumber  uint64
+	FailedBucketNumber    uint64
+	Finished              bool
+	StartTime             int64
+	UpdateTime            int64
+}
+
+// SPExitRecord defines the result of handing over an object or a bucket of the exiting SP.
+type SPExitRecord struct {
+	ResourceType  string
+	ResourceID    uint64
+	ResourceName  string
+	DestSpAddress string
+	Succeed       bool
+	ErrorMessage  string
+	UpdateTime    int64
+}
+
+const (
+	// SPExitObjectResource is the resource type of the object that this SP is secondary.
+	SPExitObjectResource = "object"
+	// SPExitBucketResource is the resource type of the bucket that this SP is primary.
+	SPExitBucketResource = "bucket"
+)
+
+// HandoverPiece defines the pieces of the replicate idx that this SP received from an
+// exiting secondary SP. This SP is not listed as secondary of the object on greenfield,
+// the pieces are backed by the exiting SP that is still listed at the replicate idx only,
+// so the record is checked to gc the pieces once they are not backed any more.
+type HandoverPiece struct {
+	ObjectID       uint64
+	ReplicateIdx   uint32
+	SrcSpAddress   string
+	SegmentCount   uint32
+	RedundancyType int32
+	Done           bool
+	UpdateTime     int64
+}
@@ -0,0 +1,63 @@
+package spdb
+
+import (
+	"math"
+)
+
+const (
+	// SPScoreRefThroughput is the replicate throughput in bytes per second that scores
+	// the half of the throughput weight.
+	SPScoreRefThroughput = 10 * 1024 * 1024
+	// SPScoreRefPingRTT is the ping round trip time in milliseconds that scores the half
+	// of the rtt weight.
+	SPScoreRefPingRTT = 100
+	// SPScoreMaxP2PFailure is the p2p failure count that loses all the p2p weight.
+	SPScoreMaxP2PFailure = 10
+)
+
+// SPScore defines the observed quality of an SP serving as secondary SP of this SP, it
+// is built from the replicate results and the p2p interactions, and is used to rank the
+// secondary SP candidates.
+type SPScore struct {
+	SpAddress string
+	// ReplicateSucceed and ReplicateFailed are the numbers of the replicate results.
+	ReplicateSucceed uint64
+	ReplicateFailed  uint64
+	// ReplicateBytes and ReplicateCost are the total bytes and milliseconds of the
+	// replicated pieces, they are used to compute the throughput.
+	ReplicateBytes uint64
+	ReplicateCost  int64
+	// PingRTT is the latest average ping round trip time in milliseconds of the peers.
+	PingRTT int64
+	// P2PFailure is the min continuous failure count of the peers.
+	P2PFailure int64
+	UpdateTime int64
+}
+
+// Throughput returns the replicate throughput in bytes per second, returns the
+// reference throughput if there is no replicate.
+func (s *SPScore) Throughput() float64 {
+	if s.ReplicateCost <= 0 {
+		return SPScoreRefThroughput
+	}
+	return float64(s.ReplicateBytes) * 1000 / float64(s.ReplicateCost)
+}
+
+// Score returns the score in [0, 100], 40% of it is the replicate success rate, 30% is
+// the throughput, 20% is the ping rtt, and 10% is the p2p failures. The unknown factor
+// scores the neutral value, so the new SP has a chance to be picked.
+func (s *SPScore) Score() float64 {
+	successRate := 1.0
+	if total := s.ReplicateSucceed + s.ReplicateFailed; total != 0 {
+		successRate = float64(s.ReplicateSucceed) / float64(total)
+	}
+	throughput := s.Throughput()
+	throughputScore := throughput / (throughput + SPScoreRefThroughput)
+	rtt := float64(s.PingRTT)
+	if s.PingRTT <= 0 {
+		rtt = SPScoreRefPingRTT
+	}
+	rttScore := SPScoreRefPingRTT / (rtt + SPScoreRefPingRTT)
+	p2pScore := 1 - math.Min(float64(s.P2PFailure), SPScoreMaxP2PFailure)/SPScoreMaxP2PFailure
+	return 100 * (0.4*successRate + 0.3*throughputScore + 0.2*rttScore + 0.1*p2pScore)
+}
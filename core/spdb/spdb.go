@@ -84,6 +84,13 @@ type GCObjectInfoDB interface {
 	SetGCObjectProgress(taskKey string, deletingBlockID uint64, deletingObjectID uint64) error
 	DeleteGCObjectProgress(taskKey string) error
 	GetAllGCObjectTask(taskKey string) []task.GCObjectTask
+	// SetGCFailedPieces set(maybe overwrite) the pieces that gc object task failed to delete
+	SetGCFailedPieces(pieces []*GCFailedPiece) error
+	// ListGCFailedPieces return the earliest updated pieces whose retry count is less than
+	// the max retry, the limit is the max number
+	ListGCFailedPieces(maxRetry int, limit int) ([]*GCFailedPiece, error)
+	// DeleteGCFailedPieces delete the failed pieces that are deleted by retry
+	DeleteGCFailedPieces(pieceKeys []string) error
 }
 
 // StorageParamDB interface
@@ -92,6 +99,100 @@ type StorageParamDB interface {
 	GetStorageParams() (*storagetypes.Params, error)
 	// SetStorageParams set(maybe overwrite) storage params
 	SetStorageParams(params *storagetypes.Params) error
+	// GetVersionedParamsByTimestamp return the cached versioned params with the latest
+	// effective time that is not after the timestamp, return nil if there is no one
+	GetVersionedParamsByTimestamp(timestamp int64) (*VersionedParams, error)
+	// SetVersionedParams set(maybe overwrite) the versioned params by the effective time
+	SetVersionedParams(params *VersionedParams) error
+	// ListVersionedParams return all the cached versioned params in the order of effective time
+	ListVersionedParams() ([]*VersionedParams, error)
+}
+
+// ServiceConfigDB interface
+type ServiceConfigDB interface {
+	// GetAllServiceConfigs returns the version and content of the active service config
+	GetAllServiceConfigs() (string, string, error)
+	// SetAllServiceConfigs sets the version and content of the active service config
+	SetAllServiceConfigs(version, config string) error
+}
+
+// MigrateBucketDB interface records the progress of migrating bucket to this SP
+type MigrateBucketDB interface {
+	// SetMigrateBucketProgress set(maybe overwrite) the progress of migrating bucket
+	SetMigrateBucketProgress(progress *MigrateBucketProgress) error
+	// GetMigrateBucketProgress return the progress of migrating bucket by bucket name,
+	// notice maybe return (nil, nil) while the bucket has not been migrated
+	GetMigrateBucketProgress(bucketName string) (*MigrateBucketProgress, error)
+	// ListUnfinishedMigrateBucketProgress return the progress of all unfinished migrating buckets
+	ListUnfinishedMigrateBucketProgress() ([]*MigrateBucketProgress, error)
+}
+
+// SPExitDB interface records the progress and the result of handing over the data of
+// the exiting SP
+type SPExitDB interface {
+	// SetSPExitProgress set(maybe overwrite) the progress of sp exit
+	SetSPExitProgress(progress *SPExitProgress) error
+	// GetSPExitProgress return the progress of sp exit by the sp operator address,
+	// notice maybe return (nil, nil) while the sp is not exiting
+	GetSPExitProgress(spAddress string) (*SPExitProgress, error)
+	// SetSPExitRecord set(maybe overwrite) the handover result of the object or bucket
+	SetSPExitRecord(record *SPExitRecord) error
+	// GetSPExitRecord return the handover result of the object or bucket by the resource type and id,
+	// notice maybe return (nil, nil) while the resource has not been handed over
+	GetSPExitRecord(resourceType string, resourceID uint64) (*SPExitRecord, error)
+	// ListFailedSPExitRecords return the failed handover results, the limit is the max number
+	ListFailedSPExitRecords(limit int) ([]*SPExitRecord, error)
+	// SetHandoverPiece set(maybe overwrite) the record of the pieces received from the exiting SP
+	SetHandoverPiece(piece *HandoverPiece) error
+	// ListHandoverPieces return the earliest updated handover pieces whose update time is
+	// before the updateTime, the limit is the max number
+	ListHandoverPieces(updateTime int64, limit int) ([]*HandoverPiece, error)
+	// DeleteHandoverPiece delete the record of the handover pieces by the object id and replicate idx
+	DeleteHandoverPiece(objectID uint64, replicateIdx uint32) error
+}
+
+// AuditDB interface records the findings of the self challenge auditor
+type AuditDB interface {
+	// SetAuditFinding set(maybe overwrite) the finding of the object, the findings are
+	// identified by the object id, redundancy idx and finding type
+	SetAuditFinding(finding *AuditFinding) error
+	// ListAuditFindings return the latest updated findings, the limit is the max number
+	ListAuditFindings(limit int) ([]*AuditFinding, error)
+}
+
+// SPScoreDB interface records the observed quality of the secondary SPs
+type SPScoreDB interface {
+	// UpdateSPReplicateScore accumulates the replicate result of the sp, the bytes and cost
+	// in milliseconds are the replicated pieces, returns the updated score
+	UpdateSPReplicateScore(spAddress string, succeed bool, bytes uint64, cost int64) (*SPScore, error)
+	// UpdateSPP2PScore set(maybe overwrite) the ping rtt in milliseconds and the p2p failure
+	// count of the sp, returns the updated score
+	UpdateSPP2PScore(spAddress string, pingRTT int64, p2pFailure int64) (*SPScore, error)
+	// ListSPScores return the scores of all the sps
+	ListSPScores() ([]*SPScore, error)
+}
+
+// P2PPeerDB interface persists the peers of the p2p node
+type P2PPeerDB interface {
+	// SetP2PPeers replaces all the persisted peers with the peers
+	SetP2PPeers(peers []*P2PPeer) error
+	// ListP2PPeers return all the persisted peers
+	ListP2PPeers() ([]*P2PPeer, error)
+}
+
+// NotificationDB interface manages the subscriptions of the object lifecycle events
+type NotificationDB interface {
+	// CreateNotificationSubscription creates the subscription and returns the subscription id
+	CreateNotificationSubscription(subscription *NotificationSubscription) (uint64, error)
+	// DeleteNotificationSubscription deletes the subscription of the account by id
+	DeleteNotificationSubscription(id uint64, account string) error
+	// GetNotificationSubscription return the subscription by id,
+	// notice maybe return (nil, nil) while there is no subscription
+	GetNotificationSubscription(id uint64) (*NotificationSubscription, error)
+	// ListNotificationSubscriptions return the subscriptions managed by the account
+	ListNotificationSubscriptions(account string) ([]*NotificationSubscription, error)
+	// ListNotificationSubscriptionsByTarget return the subscriptions of the bucket or of the owner
+	ListNotificationSubscriptionsByTarget(bucketName string, owner string) ([]*NotificationSubscription, error)
 }
 
 type SPDB interface {
@@ -102,5 +203,12 @@ type SPDB interface {
 	SPInfoDB
 	GCObjectInfoDB
 	StorageParamDB
-	// OffChainAuthKey
+	ServiceConfigDB
+	MigrateBucketDB
+	SPExitDB
+	AuditDB
+	SPScoreDB
+	P2PPeerDB
+	NotificationDB
+	OffChainAuthKeyDB
 }
@@ -10,3 +10,14 @@ type StorageParamDB interface {
 }
 
 */
+
+// VersionedParams defines the storage params that are verified to be effective on greenfield
+// from the effective time to the end time, both are unix timestamps in seconds.
+type VersionedParams struct {
+	EffectiveTime           int64
+	EndTime                 int64
+	MaxSegmentSize          uint64
+	RedundantDataChunkNum   uint32
+	RedundantParityChunkNum uint32
+	MinChargeSize           uint64
+}
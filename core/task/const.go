@@ -42,6 +42,15 @@ const (
 	TypeTaskGCZombiePiece
 	// TypeTaskGCMeta defines the type of collecting SP metadata task.
 	TypeTaskGCMeta
+	// TypeTaskMigrateBucket defines the type of migrating bucket from the source SP
+	// to this SP task.
+	TypeTaskMigrateBucket
+	// TypeTaskSPExit defines the type of handing over the data of this SP to other
+	// SPs before this SP exits task.
+	TypeTaskSPExit
+	// TypeTaskRepairPiece defines the type of regenerating the pieces of the unhealthy
+	// secondary SP and replicating them to a replacement SP task.
+	TypeTaskRepairPiece
 )
 
 var TypeTaskMap = map[TType]string{
@@ -58,6 +67,9 @@ var TypeTaskMap = map[TType]string{
 	TypeTaskGCObject:               "GCObjectTask",
 	TypeTaskGCZombiePiece:          "GCZombiePieceTask",
 	TypeTaskGCMeta:                 "GCMetaTask",
+	TypeTaskMigrateBucket:          "MigrateBucketTask",
+	TypeTaskSPExit:                 "SPExitTask",
+	TypeTaskRepairPiece:            "RepairPieceTask",
 }
 
 func TaskTypeName(taskType TType) string {
@@ -112,3 +124,10 @@ const (
 	// THighPriorityLevel defines the high task priority level.
 	THighPriorityLevel
 )
+
+// TRange defines the inclusive byte range [Low, High] of the object payload data, it is
+// used by the download object task with multiple ranges.
+type TRange struct {
+	Low  int64
+	High int64
+}
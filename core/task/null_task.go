@@ -20,6 +20,9 @@ var _ ChallengePieceTask = (*NullTask)(nil)
 var _ GCTask = (*NullTask)(nil)
 var _ GCZombiePieceTask = (*NullTask)(nil)
 var _ GCMetaTask = (*NullTask)(nil)
+var _ MigrateBucketTask = (*NullTask)(nil)
+var _ SPExitTask = (*NullTask)(nil)
+var _ RepairPieceTask = (*NullTask)(nil)
 
 type NullTask struct{}
 
@@ -77,11 +80,17 @@ func (*NullTask) GetApprovedSpEndpoint() string
 func (*NullTask) SetApprovedSpEndpoint(string)                                               {}
 func (*NullTask) GetApprovedSpApprovalAddress() string                                       { return "" }
 func (*NullTask) SetApprovedSpApprovalAddress(string)                                        {}
+func (*NullTask) GetRefused() bool                                                           { return false }
+func (*NullTask) SetRefused(bool)                                                            {}
+func (*NullTask) GetRefuseReason() string                                                    { return "" }
+func (*NullTask) SetRefuseReason(string)                                                     {}
 func (*NullTask) InitUploadObjectTask(*storagetypes.ObjectInfo, *storagetypes.Params, int64) {}
 func (*NullTask) InitReplicatePieceTask(*storagetypes.ObjectInfo, *storagetypes.Params, TPriority, int64, int64) {
 }
 func (*NullTask) GetSealed() bool                 { return false }
 func (*NullTask) SetSealed(bool)                  {}
+func (*NullTask) GetHandover() bool               { return false }
+func (*NullTask) SetHandover(bool)                {}
 func (*NullTask) GetSecondarySignature() [][]byte { return nil }
 func (*NullTask) SetSecondarySignature([][]byte)  {}
 func (*NullTask) InitSealObjectTask(*storagetypes.ObjectInfo, *storagetypes.Params, TPriority, [][]byte, int64, int64) {
@@ -105,6 +114,8 @@ func (*NullTask) GetUserAddress() string                  { return "" }
 func (*NullTask) GetSize() int64                          { return 0 }
 func (*NullTask) GetLow() int64                           { return 0 }
 func (*NullTask) GetHigh() int64                          { return 0 }
+func (*NullTask) SetRanges([]TRange)                      {}
+func (*NullTask) GetRanges() []TRange                     { return nil }
 func (*NullTask) InitChallengePieceTask(*storagetypes.ObjectInfo, *storagetypes.BucketInfo, TPriority, string, int32, uint32, int64, int64) {
 }
 func (*NullTask) SetBucketInfo(*storagetypes.BucketInfo) {}
@@ -120,3 +131,34 @@ func (*NullTask) SetPieceHash([][]byte)                  {}
 func (*NullTask) GetPieceDataSize() int64                { return 0 }
 func (*NullTask) SetPieceDataSize(int64)                 {}
 func (*NullTask) GetSignBytes() []byte                   { return nil }
+func (*NullTask) InitMigrateBucketTask(*storagetypes.BucketInfo, string, string, TPriority, int64, int64) {
+}
+func (*NullTask) GetSrcSpEndpoint() string                       { return "" }
+func (*NullTask) SetSrcSpEndpoint(string)                        {}
+func (*NullTask) GetDestSpOperatorAddress() string               { return "" }
+func (*NullTask) SetDestSpOperatorAddress(string)                {}
+func (*NullTask) GetLastMigratedObjectName() string              { return "" }
+func (*NullTask) SetLastMigratedObjectName(string)               {}
+func (*NullTask) GetMigratedObjectNumber() uint64                { return 0 }
+func (*NullTask) SetMigratedObjectNumber(uint64)                 {}
+func (*NullTask) GetFinished() bool                              { return false }
+func (*NullTask) SetFinished(bool)                               {}
+func (*NullTask) InitSPExitTask(string, TPriority, int64, int64) {}
+func (*NullTask) GetSpOperatorAddress() string                   { return "" }
+func (*NullTask) SetSpOperatorAddress(string)                    {}
+func (*NullTask) GetLastSecondaryObjectId() uint64               { return 0 }
+func (*NullTask) SetLastSecondaryObjectId(uint64)                {}
+func (*NullTask) GetLastPrimaryBucketId() uint64                 { return 0 }
+func (*NullTask) SetLastPrimaryBucketId(uint64)                  {}
+func (*NullTask) GetHandoverObjectNumber() uint64                { return 0 }
+func (*NullTask) SetHandoverObjectNumber(uint64)                 {}
+func (*NullTask) GetFailedObjectNumber() uint64                  { return 0 }
+func (*NullTask) SetFailedObjectNumber(uint64)                   {}
+func (*NullTask) GetHandoverBucketNumber() uint64                { return 0 }
+func (*NullTask) SetHandoverBucketNumber(uint64)                 {}
+func (*NullTask) GetFailedBucketNumber() uint64                  { return 0 }
+func (*NullTask) SetFailedBucketNumber(uint64)                   {}
+func (*NullTask) InitRepairPieceTask(*storagetypes.ObjectInfo, *storagetypes.Params, uint32, string, TPriority, int64, int64) {
+}
+func (*NullTask) GetUnhealthySpAddress() string { return "" }
+func (*NullTask) SetUnhealthySpAddress(string)  {}
@@ -38,6 +38,14 @@ import (
 //	stands the collection of piece store space by deleting zombie pieces data that
 //	dues to any exception, the piece data meta is not on the greenfield, GCMetaTask
 //	stands the collection of the SP meta store space by deleting the expired data.
+//	The MigrateBucketTask records the information of moving the primary SP role of
+//	a bucket from the source SP to this SP, this SP pulls the segment pieces and the
+//	integrity meta of all objects in the bucket from the source SP, verifies them
+//	against the checksums on the greenfield and stores them as the primary SP.
+//	The SPExitTask records the information of handing over the secondary pieces and
+//	the primary buckets of this SP to other SPs before this SP exits.
+//	The RepairPieceTask records the information of regenerating the pieces of the
+//	unhealthy secondary SP from the primary SP and replicating them to a replacement SP.
 //
 // Task Priority:
 //
@@ -244,6 +252,14 @@ type ApprovalReplicatePieceTask interface {
 	GetApprovedSpApprovalAddress() string
 	// SetApprovedSpApprovalAddress sets the approved SP's approval address.
 	SetApprovedSpApprovalAddress(string)
+	// GetRefused returns whether the secondary SP refuses the approval request.
+	GetRefused() bool
+	// SetRefused sets whether the secondary SP refuses the approval request.
+	SetRefused(bool)
+	// GetRefuseReason returns the reason why the secondary SP refuses.
+	GetRefuseReason() string
+	// SetRefuseReason sets the reason why the secondary SP refuses.
+	SetRefuseReason(string)
 	// GetSignBytes returns the bytes from the task for initiated and approved SPs
 	// to sign.
 	GetSignBytes() []byte
@@ -352,6 +368,12 @@ type ReceivePieceTask interface {
 	GetSealed() bool
 	// SetSealed sets the object of receiving piece data whether is successfully sealed.
 	SetSealed(bool)
+	// GetHandover returns an indicator whether the piece data is handed over from the
+	// SP that stores it, the handover pieces are kept by the receiver without
+	// confirming the receiver is the secondary SP on greenfield.
+	GetHandover() bool
+	// SetHandover sets whether the piece data is handed over.
+	SetHandover(bool)
 }
 
 // The SealObjectTask is the interface to  record the information for sealing object to
@@ -396,12 +418,19 @@ type DownloadObjectTask interface {
 	GetUserAddress() string
 	// SetUserAddress sets the user account of downloading object.
 	SetUserAddress(string)
-	// GetSize returns the download payload data size, high - low + 1.
+	// GetSize returns the download payload data size, high - low + 1, it is the sum
+	// of the ranges size if the multiple ranges are set.
 	GetSize() int64
 	// GetLow returns the start offset of download payload data.
 	GetLow() int64
 	// GetHigh returns the end offset of download payload data.
 	GetHigh() int64
+	// SetRanges sets the multiple ranges of download payload data, the low and high
+	// are reset to cover all the ranges.
+	SetRanges([]TRange)
+	// GetRanges returns the ranges of download payload data in order, it is the only
+	// [low, high] range if the multiple ranges are not set.
+	GetRanges() []TRange
 }
 
 // ChallengePieceTask is the interface to record the information for get challenge
@@ -510,3 +539,130 @@ type GCMetaTask interface {
 	// deleted object id and the number that has been deleted.
 	SetGCMetaStatus(uint64, uint64)
 }
+
+// The MigrateBucketTask is the interface to record the information for migrating the
+// primary SP role of a bucket from the source SP to this SP. The objects are migrated
+// in the ascending order of object name, the last migrated object name is the resume
+// point if the migration is interrupted.
+type MigrateBucketTask interface {
+	Task
+	// InitMigrateBucketTask inits the MigrateBucketTask by bucket info, the endpoint
+	// of source SP, the operator address of destination SP, priority, timeout and max
+	// retry.
+	InitMigrateBucketTask(bucket *storagetypes.BucketInfo, srcSpEndpoint, destSpOperatorAddress string,
+		priority TPriority, timeout int64, retry int64)
+	// GetBucketInfo returns the bucket info of migrating bucket.
+	GetBucketInfo() *storagetypes.BucketInfo
+	// SetBucketInfo sets the bucket info of migrating bucket.
+	SetBucketInfo(*storagetypes.BucketInfo)
+	// GetSrcSpEndpoint returns the endpoint of the SP that the bucket migrates from.
+	GetSrcSpEndpoint() string
+	// SetSrcSpEndpoint sets the endpoint of the SP that the bucket migrates from.
+	SetSrcSpEndpoint(string)
+	// GetDestSpOperatorAddress returns the operator address of the SP that the bucket
+	// migrates to, the source SP uses it to verify the signature of the task.
+	GetDestSpOperatorAddress() string
+	// SetDestSpOperatorAddress sets the operator address of the SP that the bucket
+	// migrates to.
+	SetDestSpOperatorAddress(string)
+	// GetLastMigratedObjectName returns the name of the last migrated object.
+	GetLastMigratedObjectName() string
+	// SetLastMigratedObjectName sets the name of the last migrated object.
+	SetLastMigratedObjectName(string)
+	// GetMigratedObjectNumber returns the number of migrated objects.
+	GetMigratedObjectNumber() uint64
+	// SetMigratedObjectNumber sets the number of migrated objects.
+	SetMigratedObjectNumber(uint64)
+	// GetFinished returns whether all objects in the bucket have been migrated.
+	GetFinished() bool
+	// SetFinished sets whether all objects in the bucket have been migrated.
+	SetFinished(bool)
+	// GetSignature returns the signature of the destination SP.
+	GetSignature() []byte
+	// SetSignature sets the signature of the destination SP.
+	SetSignature([]byte)
+	// GetSignBytes returns the bytes from the task for the destination SP to sign.
+	GetSignBytes() []byte
+	// GetOwnerSignature returns the signature of the bucket owner that approves migrating
+	// the bucket to the destination SP.
+	GetOwnerSignature() []byte
+	// SetOwnerSignature sets the signature of the bucket owner.
+	SetOwnerSignature([]byte)
+	// GetApprovalExpiry returns the unix time until which the approval of the bucket owner is valid.
+	GetApprovalExpiry() int64
+	// SetApprovalExpiry sets the unix time until which the approval of the bucket owner is valid.
+	SetApprovalExpiry(int64)
+	// GetApprovalSignBytes returns the bytes for the bucket owner to sign.
+	GetApprovalSignBytes() []byte
+}
+
+// The SPExitTask is the interface to record the information for handing over the data
+// of this SP to other SPs before this SP exits. The secondary pieces are replicated to
+// the SPs that approve to take over them, and the buckets that this SP is primary are
+// migrated to the destination SPs. The objects and buckets are handed over in the
+// ascending order of id, the last handed over id is the resume point.
+type SPExitTask interface {
+	Task
+	// InitSPExitTask inits the SPExitTask by the operator address of the exiting SP,
+	// priority, timeout and max retry.
+	InitSPExitTask(spOperatorAddress string, priority TPriority, timeout int64, retry int64)
+	// GetSpOperatorAddress returns the operator address of the exiting SP.
+	GetSpOperatorAddress() string
+	// SetSpOperatorAddress sets the operator address of the exiting SP.
+	SetSpOperatorAddress(string)
+	// GetLastSecondaryObjectId returns the id of the last handed over object that
+	// this SP is secondary.
+	GetLastSecondaryObjectId() uint64
+	// SetLastSecondaryObjectId sets the id of the last handed over object.
+	SetLastSecondaryObjectId(uint64)
+	// GetLastPrimaryBucketId returns the id of the last handed over bucket that this
+	// SP is primary.
+	GetLastPrimaryBucketId() uint64
+	// SetLastPrimaryBucketId sets the id of the last handed over bucket.
+	SetLastPrimaryBucketId(uint64)
+	// GetHandoverObjectNumber returns the number of objects handed over successfully.
+	GetHandoverObjectNumber() uint64
+	// SetHandoverObjectNumber sets the number of objects handed over successfully.
+	SetHandoverObjectNumber(uint64)
+	// GetFailedObjectNumber returns the number of objects failed to hand over.
+	GetFailedObjectNumber() uint64
+	// SetFailedObjectNumber sets the number of objects failed to hand over.
+	SetFailedObjectNumber(uint64)
+	// GetHandoverBucketNumber returns the number of buckets handed over successfully.
+	GetHandoverBucketNumber() uint64
+	// SetHandoverBucketNumber sets the number of buckets handed over successfully.
+	SetHandoverBucketNumber(uint64)
+	// GetFailedBucketNumber returns the number of buckets failed to hand over.
+	GetFailedBucketNumber() uint64
+	// SetFailedBucketNumber sets the number of buckets failed to hand over.
+	SetFailedBucketNumber(uint64)
+	// GetFinished returns whether all objects and buckets have been handed over.
+	GetFinished() bool
+	// SetFinished sets whether all objects and buckets have been handed over.
+	SetFinished(bool)
+}
+
+// The RepairPieceTask is the interface to record the information for repairing the
+// pieces of the unhealthy secondary SP. The primary SP regenerates the pieces of the
+// replicate idx from the local segments and replicates them to a replacement SP that
+// approves to take over them.
+type RepairPieceTask interface {
+	ObjectTask
+	// InitRepairPieceTask inits the RepairPieceTask by ObjectInfo, Params, the replicate
+	// idx and the operator address of the unhealthy secondary SP, priority, timeout and
+	// max retry.
+	InitRepairPieceTask(object *storagetypes.ObjectInfo, params *storagetypes.Params, replicateIdx uint32,
+		unhealthySpAddress string, priority TPriority, timeout int64, retry int64)
+	// GetReplicateIdx returns the replicate idx of the pieces to repair.
+	GetReplicateIdx() uint32
+	// SetReplicateIdx sets the replicate idx of the pieces to repair.
+	SetReplicateIdx(uint32)
+	// GetUnhealthySpAddress returns the operator address of the unhealthy secondary SP.
+	GetUnhealthySpAddress() string
+	// SetUnhealthySpAddress sets the operator address of the unhealthy secondary SP.
+	SetUnhealthySpAddress(string)
+	// GetDestSpOperatorAddress returns the operator address of the replacement SP.
+	GetDestSpOperatorAddress() string
+	// SetDestSpOperatorAddress sets the operator address of the replacement SP.
+	SetDestSpOperatorAddress(string)
+}
@@ -37,6 +37,9 @@ type TQueue interface {
 	Len() int
 	// Cap returns the capacity of queue.
 	Cap() int
+	// SetCap resets the capacity of queue, the tasks exceed the new capacity are
+	// kept, but no new task can be pushed until the queue len less than capacity.
+	SetCap(int)
 	// ScanTask scans all tasks, and call the func one by one task.
 	ScanTask(func(task.Task))
 }
@@ -58,6 +61,9 @@ type TQueueWithLimit interface {
 	Len() int
 	// Cap returns the capacity of queue.
 	Cap() int
+	// SetCap resets the capacity of queue, the tasks exceed the new capacity are
+	// kept, but no new task can be pushed until the queue len less than capacity.
+	SetCap(int)
 	// ScanTask scans all tasks, and call the func one by one task.
 	ScanTask(func(task.Task))
 }
@@ -123,6 +129,7 @@ func (*NilQueue) Has(task.TKey) bool                         { return false }
 func (*NilQueue) Push(task.Task) error                       { return nil }
 func (*NilQueue) Len() int                                   { return 0 }
 func (*NilQueue) Cap() int                                   { return 0 }
+func (*NilQueue) SetCap(int)                                 {}
 func (*NilQueue) ScanTask(func(task.Task))                   {}
 func (*NilQueue) TopByLimit(rcmgr.Limit) task.Task           { return nil }
 func (*NilQueue) PopByLimit(rcmgr.Limit) task.Task           { return nil }
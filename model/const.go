@@ -64,6 +64,28 @@ const (
 	ContentTypeJSONHeaderValue = "application/json"
 	// ContentTypeXMLHeaderValue is used to indicate xml
 	ContentTypeXMLHeaderValue = "application/xml"
+	// AcceptRangesHeader is used to indicate the server supports range requests
+	AcceptRangesHeader = "Accept-Ranges"
+	// AcceptRangesBytesValue is used to indicate the range unit is bytes
+	AcceptRangesBytesValue = "bytes"
+	// ETagHeader is the identifier of the object content
+	ETagHeader = "ETag"
+	// LastModifiedHeader is the time when the object content was last modified
+	LastModifiedHeader = "Last-Modified"
+	// CacheControlHeader is used to indicate the caching policy of the object
+	CacheControlHeader = "Cache-Control"
+	// IfMatchHeader makes the request conditional on the ETag matching
+	IfMatchHeader = "If-Match"
+	// IfNoneMatchHeader makes the request conditional on the ETag not matching
+	IfNoneMatchHeader = "If-None-Match"
+	// IfModifiedSinceHeader makes the request conditional on the object modified after the time
+	IfModifiedSinceHeader = "If-Modified-Since"
+	// IfUnmodifiedSinceHeader makes the request conditional on the object not modified after the time
+	IfUnmodifiedSinceHeader = "If-Unmodified-Since"
+	// IfRangeHeader makes the range request conditional, the whole object is sent if the condition fails
+	IfRangeHeader = "If-Range"
+	// ContentTypeMultipartByteRangesValue is used to indicate the response of multiple ranges
+	ContentTypeMultipartByteRangesValue = "multipart/byteranges"
 	// ContentDispositionHeader is used to indicate the media disposition of the resource
 	ContentDispositionHeader = "Content-Disposition"
 	// ContentDispositionAttachmentValue is used to indicate attachment
@@ -114,14 +136,42 @@ const (
 	GetBucketMetaQuery = "bucket-meta"
 	// GetObjectMetaQuery defines get object metadata query, which is used to route request
 	GetObjectMetaQuery = "object-meta"
+	// GetPrefixStatsQuery defines get prefix stats query, which is used to route request
+	GetPrefixStatsQuery = "prefix-stats"
 	// StartTimestampUs defines start timestamp in microsecond, which is used by list read record, [start_ts,end_ts)
 	StartTimestampUs = "start-timestamp"
 	// EndTimestampUs defines end timestamp in microsecond, which is used by list read record, [start_ts,end_ts)
 	EndTimestampUs = "end-timestamp"
+	// ShareLinkExpiryQuery defines the unix timestamp in second until which the signed share link is valid
+	ShareLinkExpiryQuery = "expiry-timestamp"
+	// ShareLinkSignatureQuery defines the hex encoded signature of the signed share link
+	ShareLinkSignatureQuery = "signature"
+	// ShareLinkSignAlgorithmQuery defines the sign algorithm of the signed share link, defaults to ECDSA-secp256k1
+	ShareLinkSignAlgorithmQuery = "sign-algorithm"
+	// ShareLinkSignerQuery defines the account who signs the share link
+	ShareLinkSignerQuery = "signer"
+	// ShareLinkAppDomainQuery defines the app domain where the EDDSA public key of the share link signer is registered
+	ShareLinkAppDomainQuery = "app-domain"
 	// ChallengePath defines challenge path style suffix
 	ChallengePath = "/greenfield/admin/v1/challenge"
 	// ReplicateObjectPiecePath defines replicate-object path style
 	ReplicateObjectPiecePath = "/greenfield/receiver/v1/replicate-piece"
+	// MigrateBucketPath defines the path to export the bucket to the migrating destination SP
+	MigrateBucketPath = "/greenfield/migrate/v1/export-bucket"
+	// NotifyMigrateBucketPath defines the path to notify the SP to migrate the bucket from the exiting primary SP
+	NotifyMigrateBucketPath = "/greenfield/migrate/v1/notify-bucket"
+	// P2PNodePath defines the path to query the p2p node info of the SP
+	P2PNodePath = "/greenfield/p2p/v1/node"
+	// NotificationSubscriptionPath defines the path to manage the subscriptions of the object lifecycle notification
+	NotificationSubscriptionPath = "/greenfield/admin/v1/notification/subscription"
+	// NotificationBucketQuery defines the bucket of the subscription
+	NotificationBucketQuery = "bucket"
+	// NotificationURLQuery defines the webhook url of the subscription
+	NotificationURLQuery = "url"
+	// NotificationEventsQuery defines the comma separated event types of the subscription
+	NotificationEventsQuery = "events"
+	// NotificationSubscriptionIDQuery defines the id of the subscription
+	NotificationSubscriptionIDQuery = "id"
 	// AuthRequestNoncePath defines path to request auth nonce
 	AuthRequestNoncePath = "/auth/request_nonce"
 	// AuthUpdateKeyPath defines path to update user public key
@@ -134,6 +184,8 @@ const (
 	GnfdReceiveMsgHeader = "X-Gnfd-Receive-Msg"
 	// GnfdReplicatePieceApprovalHeader defines secondary approved msg for replicating piece
 	GnfdReplicatePieceApprovalHeader = "X-Gnfd-Replicate-Piece-Approval-Msg"
+	// GnfdMigrateBucketMsgHeader defines the migrate bucket task signed by the destination SP
+	GnfdMigrateBucketMsgHeader = "X-Gnfd-Migrate-Bucket-Msg"
 	// GnfdObjectIDHeader defines object id
 	GnfdObjectIDHeader = "X-Gnfd-Object-ID"
 	// GnfdPieceIndexHeader defines piece idx, which is used by challenge
@@ -154,11 +206,46 @@ const (
 	GnfdReplicateApproval = "X-Gnfd-Replicate-Approval"
 	// GnfdIntegrityHashSignatureHeader defines integrity hash signature, which is used by receiver
 	GnfdIntegrityHashSignatureHeader = "X-Gnfd-Integrity-Hash-Signature"
+	// GnfdP2PNodeHeader defines the p2p node info in the bootstrap node format, which is used by p2p discovery
+	GnfdP2PNodeHeader = "X-Gnfd-P2P-Node"
+	// GnfdNotificationEventIDHeader defines the unique id of the delivered notification event,
+	// the webhook dedupes the redelivered events by it
+	GnfdNotificationEventIDHeader = "X-Gnfd-Notification-Event-Id"
+	// GnfdNotificationSubscriptionIDHeader defines the id of the subscription that the notification is delivered for
+	GnfdNotificationSubscriptionIDHeader = "X-Gnfd-Notification-Subscription-Id"
+	// GnfdNotificationTimestampHeader defines the unix time of delivering the notification, which is signed
+	GnfdNotificationTimestampHeader = "X-Gnfd-Notification-Timestamp"
+	// GnfdNotificationSignatureHeader defines the hex encoded HMAC-SHA256 of the timestamp and the body of
+	// the notification, which is signed by the secret of the subscription
+	GnfdNotificationSignatureHeader = "X-Gnfd-Notification-Signature"
 	// GnfdUserAddressHeader defines the user address
 	GnfdUserAddressHeader = "X-Gnfd-User-Address"
 	// GnfdResponseXMLVersion defines the response xml version
 	GnfdResponseXMLVersion = "1.0"
 
+	// S3 compatible api headers and queries
+
+	// AmzDateHeader defines the signing time of the AWS Signature Version 4 request
+	AmzDateHeader = "X-Amz-Date"
+	// AmzContentSha256Header defines the hex encoded sha256 of the request payload
+	AmzContentSha256Header = "X-Amz-Content-Sha256"
+	// AmzAlgorithmQuery defines the sign algorithm of the presigned url
+	AmzAlgorithmQuery = "X-Amz-Algorithm"
+	// AmzCredentialQuery defines the access key and credential scope of the presigned url
+	AmzCredentialQuery = "X-Amz-Credential"
+	// AmzDateQuery defines the signing time of the presigned url
+	AmzDateQuery = "X-Amz-Date"
+	// AmzExpiresQuery defines the seconds the presigned url is valid for
+	AmzExpiresQuery = "X-Amz-Expires"
+	// AmzSignedHeadersQuery defines the signed headers of the presigned url
+	AmzSignedHeadersQuery = "X-Amz-SignedHeaders"
+	// AmzSignatureQuery defines the signature of the presigned url
+	AmzSignatureQuery = "X-Amz-Signature"
+	// S3ListTypeQuery defines the version of the list objects api, 2 is ListObjectsV2
+	S3ListTypeQuery = "list-type"
+	// S3EncodingTypeQuery defines the encoding of the object keys in the list response
+	S3EncodingTypeQuery = "encoding-type"
+
 	// off-chain-auth headers
 
 	// GnfdOffChainAuthAppDomainHeader defines the app domain from where user is trying to do the EDDSA auth interactions
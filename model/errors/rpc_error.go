@@ -140,4 +140,6 @@ func GRPCErrorToInnerError(err error) error {
 var (
 	// ErrBlockNotFound defines not found block data
 	ErrBlockNotFound = errors.New("failed to get block from map need retry")
+	// ErrBlockNotRecorded defines the block data is not recorded in the archive
+	ErrBlockNotRecorded = errors.New("block is not recorded in the archive")
 )
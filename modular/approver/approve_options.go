@@ -1,9 +1,12 @@
 package approver
 
 import (
+	"context"
+
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 )
 
 const (
@@ -45,6 +48,9 @@ func DefaultApprovalOptions(cfg *gfspconfig.GfSpConfig, approver *ApprovalModula
 		cfg.Approval.ObjectApprovalTimeoutHeight = DefaultObjectApprovalTimeoutHeight
 	}
 	approver.objectApprovalTimeoutHeight = cfg.Approval.ObjectApprovalTimeoutHeight
+	if err := approver.loadApprovalPolicy(cfg.Approval.PolicyFile); err != nil {
+		return err
+	}
 	if cfg.Parallel.GlobalCreateBucketApprovalParallel == 0 {
 		cfg.Parallel.GlobalCreateBucketApprovalParallel = DefaultCreateBucketApprovalParallel
 	}
@@ -59,3 +65,45 @@ func DefaultApprovalOptions(cfg *gfspconfig.GfSpConfig, approver *ApprovalModula
 		cfg.Parallel.GlobalCreateObjectApprovalParallel)
 	return nil
 }
+
+// ReloadConfig applies the reloaded capacities of approval queues.
+func (a *ApprovalModular) ReloadConfig(ctx context.Context, cfg interface{}) error {
+	newCfg, ok := cfg.(*gfspconfig.GfSpConfig)
+	if !ok {
+		return gfspconfig.ErrInvalidReloadConfig
+	}
+	if newCfg.Parallel.GlobalCreateBucketApprovalParallel == 0 {
+		newCfg.Parallel.GlobalCreateBucketApprovalParallel = DefaultCreateBucketApprovalParallel
+	}
+	if newCfg.Parallel.GlobalCreateObjectApprovalParallel == 0 {
+		newCfg.Parallel.GlobalCreateObjectApprovalParallel = DefaultCreateObjectApprovalParallel
+	}
+	a.bucketQueue.SetCap(newCfg.Parallel.GlobalCreateBucketApprovalParallel)
+	a.objectQueue.SetCap(newCfg.Parallel.GlobalCreateObjectApprovalParallel)
+	if err := a.loadApprovalPolicy(newCfg.Approval.PolicyFile); err != nil {
+		log.CtxErrorw(ctx, "failed to reload approval policy", "error", err)
+		return err
+	}
+	log.CtxInfow(ctx, "succeed to reload approver config")
+	return nil
+}
+
+// loadApprovalPolicy loads the approval policy file and replaces the rules, the empty
+// file path means no policy.
+func (a *ApprovalModular) loadApprovalPolicy(file string) error {
+	var policy *ApprovalPolicy
+	if file != "" {
+		var err error
+		if policy, err = LoadApprovalPolicy(file); err != nil {
+			log.Errorw("failed to load approval policy", "file", file, "error", err)
+			return err
+		}
+	}
+	rules, err := NewApprovalRules(a.baseApp, policy)
+	if err != nil {
+		log.Errorw("failed to new approval rules", "file", file, "error", err)
+		return err
+	}
+	a.SetApprovalRules(rules)
+	return nil
+}
@@ -0,0 +1,374 @@
+package approver
+
+import (
+	"context"
+	"mime"
+	"os"
+	"strings"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
+	metadatatypes "github.com/bnb-chain/greenfield-storage-provider/modular/metadata/types"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// ApprovalPolicy defines the rules of approving the creation of buckets and objects,
+// it is loaded from the policy file. The empty field does not restrict anything.
+type ApprovalPolicy struct {
+	// AllowAccounts only approves the accounts in the list if it is not empty.
+	AllowAccounts []string
+	// DenyAccounts refuses the accounts in the list.
+	DenyAccounts []string
+	// MaxPayloadSize defines the max payload size of the object for all accounts.
+	MaxPayloadSize uint64
+	// AccountMaxPayloadSize defines the max payload size of the object by the creator,
+	// it overrides the MaxPayloadSize.
+	AccountMaxPayloadSize map[string]uint64
+	// BucketMaxPayloadSize defines the max payload size of the object by the bucket
+	// name, it overrides the AccountMaxPayloadSize and MaxPayloadSize.
+	BucketMaxPayloadSize map[string]uint64
+	// AllowContentTypes only approves the objects of the media types in the list if
+	// it is not empty, the parameters of the content type are ignored, e.g. the object
+	// of "text/plain; charset=utf-8" is approved by "text/plain".
+	AllowContentTypes []string
+	// MaxAccountStoreSize defines the max total bytes that an account stores in the
+	// buckets whose primary SP is this SP.
+	MaxAccountStoreSize uint64
+	// AccountMaxStoreSize defines the max total stored bytes by account, it overrides
+	// the MaxAccountStoreSize.
+	AccountMaxStoreSize map[string]uint64
+	// MinStreamBalance defines the min static balance of the payment stream account
+	// in wei, the creation paid by the stream account with less balance is refused.
+	MinStreamBalance string
+	// AllowRedundancyTypes only approves the objects of the redundancy types in the
+	// list if it is not empty, e.g. "REDUNDANCY_EC_TYPE".
+	AllowRedundancyTypes []string
+}
+
+// LoadApprovalPolicy loads the approval policy from the toml file.
+func LoadApprovalPolicy(file string) (*ApprovalPolicy, error) {
+	bz, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	policy := &ApprovalPolicy{}
+	if err = toml.Unmarshal(bz, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ApprovalRule defines the rule of the approval policy that is evaluated in the
+// Pre*Approval hooks, it returns the refused error if the creation is not allowed,
+// every refused error is a predefined error with the inner code as the reason code.
+type ApprovalRule interface {
+	// Name returns the name of the rule.
+	Name() string
+	// CheckBucket checks the creation of the bucket.
+	CheckBucket(ctx context.Context, msg *storagetypes.MsgCreateBucket) error
+	// CheckObject checks the creation of the object.
+	CheckObject(ctx context.Context, msg *storagetypes.MsgCreateObject) error
+}
+
+// NewApprovalRules returns the rules of the approval policy, the rule is skipped if
+// the policy does not configure it.
+func NewApprovalRules(app *gfspapp.GfSpBaseApp, policy *ApprovalPolicy) ([]ApprovalRule, error) {
+	var rules []ApprovalRule
+	if policy == nil {
+		return rules, nil
+	}
+	if len(policy.AllowAccounts) != 0 || len(policy.DenyAccounts) != 0 {
+		rules = append(rules, &accountRule{
+			allow: toSet(policy.AllowAccounts),
+			deny:  toSet(policy.DenyAccounts),
+		})
+	}
+	if policy.MaxPayloadSize != 0 || len(policy.AccountMaxPayloadSize) != 0 ||
+		len(policy.BucketMaxPayloadSize) != 0 {
+		rules = append(rules, &payloadSizeRule{
+			max:        policy.MaxPayloadSize,
+			accountMax: toLowerKeys(policy.AccountMaxPayloadSize),
+			bucketMax:  policy.BucketMaxPayloadSize,
+		})
+	}
+	if len(policy.AllowContentTypes) != 0 {
+		allow := make(map[string]struct{}, len(policy.AllowContentTypes))
+		for _, contentType := range policy.AllowContentTypes {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil {
+				return nil, ErrInvalidPolicy
+			}
+			allow[mediaType] = struct{}{}
+		}
+		rules = append(rules, &contentTypeRule{allow: allow})
+	}
+	if policy.MaxAccountStoreSize != 0 || len(policy.AccountMaxStoreSize) != 0 {
+		rules = append(rules, &storeSizeRule{
+			getUserBuckets: func(ctx context.Context, account string) ([]*metadatatypes.Bucket, error) {
+				return app.GfSpClient().GetUserBuckets(ctx, account)
+			},
+			operateAddress: app.OperateAddress(),
+			max:            policy.MaxAccountStoreSize,
+			accountMax:     toLowerKeys(policy.AccountMaxStoreSize),
+		})
+	}
+	if policy.MinStreamBalance != "" {
+		balance, ok := sdkmath.NewIntFromString(policy.MinStreamBalance)
+		if !ok {
+			return nil, ErrInvalidPolicy
+		}
+		rules = append(rules, &streamBalanceRule{baseApp: app, min: balance})
+	}
+	if len(policy.AllowRedundancyTypes) != 0 {
+		allow := make(map[storagetypes.RedundancyType]struct{})
+		for _, name := range policy.AllowRedundancyTypes {
+			redundancyType, ok := storagetypes.RedundancyType_value[strings.ToUpper(name)]
+			if !ok {
+				return nil, ErrInvalidPolicy
+			}
+			allow[storagetypes.RedundancyType(redundancyType)] = struct{}{}
+		}
+		rules = append(rules, &redundancyTypeRule{allow: allow})
+	}
+	return rules, nil
+}
+
+func toSet(list []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(list))
+	for _, item := range list {
+		set[strings.ToLower(item)] = struct{}{}
+	}
+	return set
+}
+
+func toLowerKeys(m map[string]uint64) map[string]uint64 {
+	lower := make(map[string]uint64, len(m))
+	for key, value := range m {
+		lower[strings.ToLower(key)] = value
+	}
+	return lower
+}
+
+// accountRule refuses the accounts that are not in the allow list or in the deny list.
+type accountRule struct {
+	allow map[string]struct{}
+	deny  map[string]struct{}
+}
+
+func (r *accountRule) Name() string { return "account" }
+
+func (r *accountRule) check(account string) error {
+	account = strings.ToLower(account)
+	if _, ok := r.deny[account]; ok {
+		return ErrAccountRefused
+	}
+	if len(r.allow) == 0 {
+		return nil
+	}
+	if _, ok := r.allow[account]; !ok {
+		return ErrAccountRefused
+	}
+	return nil
+}
+
+func (r *accountRule) CheckBucket(ctx context.Context, msg *storagetypes.MsgCreateBucket) error {
+	return r.check(msg.GetCreator())
+}
+
+func (r *accountRule) CheckObject(ctx context.Context, msg *storagetypes.MsgCreateObject) error {
+	return r.check(msg.GetCreator())
+}
+
+// payloadSizeRule refuses the objects whose payload size exceeds the limit of the
+// bucket, the creator or the default in order.
+type payloadSizeRule struct {
+	max        uint64
+	accountMax map[string]uint64
+	bucketMax  map[string]uint64
+}
+
+func (r *payloadSizeRule) Name() string { return "payload_size" }
+
+func (r *payloadSizeRule) CheckBucket(ctx context.Context, msg *storagetypes.MsgCreateBucket) error {
+	return nil
+}
+
+func (r *payloadSizeRule) CheckObject(ctx context.Context, msg *storagetypes.MsgCreateObject) error {
+	limit, ok := r.bucketMax[msg.GetBucketName()]
+	if !ok {
+		if limit, ok = r.accountMax[strings.ToLower(msg.GetCreator())]; !ok {
+			limit = r.max
+		}
+	}
+	if limit != 0 && msg.GetPayloadSize() > limit {
+		return ErrPayloadSizeRefused
+	}
+	return nil
+}
+
+// contentTypeRule refuses the objects whose media type is not in the allow list, the
+// content type that can not be parsed is refused.
+type contentTypeRule struct {
+	allow map[string]struct{}
+}
+
+func (r *contentTypeRule) Name() string { return "content_type" }
+
+func (r *contentTypeRule) CheckBucket(ctx context.Context, msg *storagetypes.MsgCreateBucket) error {
+	return nil
+}
+
+func (r *contentTypeRule) CheckObject(ctx context.Context, msg *storagetypes.MsgCreateObject) error {
+	mediaType, _, err := mime.ParseMediaType(msg.GetContentType())
+	if err != nil {
+		return ErrContentTypeRefused
+	}
+	if _, ok := r.allow[mediaType]; !ok {
+		return ErrContentTypeRefused
+	}
+	return nil
+}
+
+// storeSizeRule refuses the creation if the total bytes that the creator stores in
+// this SP exceed the limit, the stored bytes are the charge size of the buckets whose
+// primary SP is this SP.
+type storeSizeRule struct {
+	getUserBuckets func(ctx context.Context, account string) ([]*metadatatypes.Bucket, error)
+	operateAddress string
+	max            uint64
+	accountMax     map[string]uint64
+}
+
+func (r *storeSizeRule) Name() string { return "store_size" }
+
+func (r *storeSizeRule) check(ctx context.Context, account string, size uint64) error {
+	limit, ok := r.accountMax[strings.ToLower(account)]
+	if !ok {
+		limit = r.max
+	}
+	if limit == 0 {
+		return nil
+	}
+	buckets, err := r.getUserBuckets(ctx, account)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get account buckets", "account", account, "error", err)
+		return ErrMetadata
+	}
+	var stored uint64
+	for _, bucket := range buckets {
+		if bucket.GetRemoved() || bucket.GetBucketInfo() == nil {
+			continue
+		}
+		if !strings.EqualFold(bucket.GetBucketInfo().GetPrimarySpAddress(), r.operateAddress) {
+			continue
+		}
+		stored += bucket.GetBucketInfo().GetBillingInfo().TotalChargeSize
+	}
+	if stored+size > limit || (size == 0 && stored >= limit) {
+		return ErrStoreSizeRefused
+	}
+	return nil
+}
+
+func (r *storeSizeRule) CheckBucket(ctx context.Context, msg *storagetypes.MsgCreateBucket) error {
+	return r.check(ctx, msg.GetCreator(), 0)
+}
+
+func (r *storeSizeRule) CheckObject(ctx context.Context, msg *storagetypes.MsgCreateObject) error {
+	return r.check(ctx, msg.GetCreator(), msg.GetPayloadSize())
+}
+
+// streamBalanceRule refuses the creation if the static balance of the payment stream
+// account is less than the min balance.
+type streamBalanceRule struct {
+	baseApp *gfspapp.GfSpBaseApp
+	min     sdkmath.Int
+}
+
+func (r *streamBalanceRule) Name() string { return "stream_balance" }
+
+func (r *streamBalanceRule) check(ctx context.Context, account string) error {
+	streamRecord, err := r.baseApp.Consensus().QueryPaymentStreamRecord(ctx, account)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query payment stream record", "account", account, "error", err)
+		return ErrConsensus
+	}
+	if streamRecord.StaticBalance.IsNil() || streamRecord.StaticBalance.LT(r.min) {
+		return ErrStreamBalanceRefused
+	}
+	return nil
+}
+
+func (r *streamBalanceRule) CheckBucket(ctx context.Context, msg *storagetypes.MsgCreateBucket) error {
+	account := msg.GetPaymentAddress()
+	if account == "" {
+		account = msg.GetCreator()
+	}
+	return r.check(ctx, account)
+}
+
+func (r *streamBalanceRule) CheckObject(ctx context.Context, msg *storagetypes.MsgCreateObject) error {
+	bucketInfo, err := r.baseApp.Consensus().QueryBucketInfo(ctx, msg.GetBucketName())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query bucket info", "bucket_name", msg.GetBucketName(), "error", err)
+		return ErrConsensus
+	}
+	return r.check(ctx, bucketInfo.GetPaymentAddress())
+}
+
+// redundancyTypeRule refuses the objects whose redundancy type is not in the allow list.
+type redundancyTypeRule struct {
+	allow map[storagetypes.RedundancyType]struct{}
+}
+
+func (r *redundancyTypeRule) Name() string { return "redundancy_type" }
+
+func (r *redundancyTypeRule) CheckBucket(ctx context.Context, msg *storagetypes.MsgCreateBucket) error {
+	return nil
+}
+
+func (r *redundancyTypeRule) CheckObject(ctx context.Context, msg *storagetypes.MsgCreateObject) error {
+	if _, ok := r.allow[msg.GetRedundancyType()]; !ok {
+		return ErrRedundancyTypeRefused
+	}
+	return nil
+}
+
+// checkBucketPolicy evaluates the rules of the approval policy for creating bucket.
+func (a *ApprovalModular) checkBucketPolicy(ctx context.Context, msg *storagetypes.MsgCreateBucket) error {
+	a.policyMux.RLock()
+	rules := a.rules
+	a.policyMux.RUnlock()
+	for _, rule := range rules {
+		if err := rule.CheckBucket(ctx, msg); err != nil {
+			log.CtxErrorw(ctx, "create bucket refused by approval policy", "rule", rule.Name(), "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// checkObjectPolicy evaluates the rules of the approval policy for creating object.
+func (a *ApprovalModular) checkObjectPolicy(ctx context.Context, msg *storagetypes.MsgCreateObject) error {
+	a.policyMux.RLock()
+	rules := a.rules
+	a.policyMux.RUnlock()
+	for _, rule := range rules {
+		if err := rule.CheckObject(ctx, msg); err != nil {
+			log.CtxErrorw(ctx, "create object refused by approval policy", "rule", rule.Name(), "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// SetApprovalRules replaces the rules of the approval policy, it is used to plug in
+// the customized rules.
+func (a *ApprovalModular) SetApprovalRules(rules []ApprovalRule) {
+	a.policyMux.Lock()
+	defer a.policyMux.Unlock()
+	a.rules = rules
+}
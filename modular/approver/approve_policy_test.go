@@ -0,0 +1,243 @@
+package approver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	paymenttypes "github.com/bnb-chain/greenfield/x/payment/types"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/core/consensus"
+	metadatatypes "github.com/bnb-chain/greenfield-storage-provider/modular/metadata/types"
+)
+
+const (
+	testAccount      = "0xAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	testOtherAccount = "0xBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB"
+	testSpAddress    = "0x1111111111111111111111111111111111111111"
+)
+
+func TestAccountRule(t *testing.T) {
+	cases := []struct {
+		name    string
+		allow   []string
+		deny    []string
+		account string
+		wantErr error
+	}{
+		{"denied", nil, []string{testAccount}, testAccount, ErrAccountRefused},
+		{"denied in other case", nil, []string{"0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}, testAccount, ErrAccountRefused},
+		{"not denied", nil, []string{testOtherAccount}, testAccount, nil},
+		{"allowed", []string{testAccount}, nil, testAccount, nil},
+		{"not allowed", []string{testOtherAccount}, nil, testAccount, ErrAccountRefused},
+		{"allowed and denied", []string{testAccount}, []string{testAccount}, testAccount, ErrAccountRefused},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rule := &accountRule{allow: toSet(c.allow), deny: toSet(c.deny)}
+			assert.Equal(t, c.wantErr, rule.CheckBucket(context.Background(),
+				&storagetypes.MsgCreateBucket{Creator: c.account}))
+			assert.Equal(t, c.wantErr, rule.CheckObject(context.Background(),
+				&storagetypes.MsgCreateObject{Creator: c.account}))
+		})
+	}
+}
+
+func TestPayloadSizeRule(t *testing.T) {
+	rule := &payloadSizeRule{
+		max:        100,
+		accountMax: toLowerKeys(map[string]uint64{testAccount: 200}),
+		bucketMax:  map[string]uint64{"bucket": 300, "unlimited": 0},
+	}
+	cases := []struct {
+		name        string
+		creator     string
+		bucket      string
+		payloadSize uint64
+		wantErr     error
+	}{
+		{"default limit", testOtherAccount, "other", 100, nil},
+		{"exceed default limit", testOtherAccount, "other", 101, ErrPayloadSizeRefused},
+		{"account limit", testAccount, "other", 200, nil},
+		{"exceed account limit", testAccount, "other", 201, ErrPayloadSizeRefused},
+		{"bucket limit overrides account limit", testAccount, "bucket", 300, nil},
+		{"exceed bucket limit", testAccount, "bucket", 301, ErrPayloadSizeRefused},
+		{"zero limit is unlimited", testAccount, "unlimited", 1000, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.wantErr, rule.CheckObject(context.Background(), &storagetypes.MsgCreateObject{
+				Creator: c.creator, BucketName: c.bucket, PayloadSize: c.payloadSize}))
+		})
+	}
+	assert.NoError(t, rule.CheckBucket(context.Background(), &storagetypes.MsgCreateBucket{Creator: testAccount}))
+}
+
+func TestContentTypeRule(t *testing.T) {
+	rules, err := NewApprovalRules(nil, &ApprovalPolicy{AllowContentTypes: []string{"text/plain", "Image/PNG"}})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	rule := rules[0]
+	cases := []struct {
+		name        string
+		contentType string
+		wantErr     error
+	}{
+		{"allowed", "text/plain", nil},
+		{"allowed with parameters", "text/plain; charset=utf-8", nil},
+		{"allowed in other case", "image/png", nil},
+		{"not allowed", "application/json", ErrContentTypeRefused},
+		{"not allowed by prefix", "text/plainx", ErrContentTypeRefused},
+		{"empty", "", ErrContentTypeRefused},
+		{"malformed", "text/", ErrContentTypeRefused},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.wantErr, rule.CheckObject(context.Background(),
+				&storagetypes.MsgCreateObject{ContentType: c.contentType}))
+		})
+	}
+}
+
+func TestStoreSizeRule(t *testing.T) {
+	newBucket := func(primary string, chargeSize uint64, removed bool) *metadatatypes.Bucket {
+		return &metadatatypes.Bucket{Removed: removed, BucketInfo: &storagetypes.BucketInfo{
+			PrimarySpAddress: primary, BillingInfo: storagetypes.BillingInfo{TotalChargeSize: chargeSize}}}
+	}
+	buckets := map[string][]*metadatatypes.Bucket{
+		// the removed bucket and the bucket of other sp are not counted
+		testAccount: {newBucket(testSpAddress, 60, false), newBucket(testSpAddress, 100, true),
+			newBucket(testOtherAccount, 100, false)},
+		testOtherAccount: {newBucket(testSpAddress, 100, false)},
+	}
+	getUserBuckets := func(_ context.Context, account string) ([]*metadatatypes.Bucket, error) {
+		if account == "" {
+			return nil, errors.New("metadata unavailable")
+		}
+		return buckets[account], nil
+	}
+	rule := &storeSizeRule{
+		getUserBuckets: getUserBuckets,
+		operateAddress: testSpAddress,
+		max:            100,
+		accountMax:     toLowerKeys(map[string]uint64{testOtherAccount: 0}),
+	}
+	cases := []struct {
+		name        string
+		creator     string
+		payloadSize uint64
+		wantErr     error
+	}{
+		{"under limit", testAccount, 40, nil},
+		{"exceed limit", testAccount, 41, ErrStoreSizeRefused},
+		{"zero account limit is unlimited", testOtherAccount, 1000, nil},
+		{"metadata error", "", 1, ErrMetadata},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.wantErr, rule.CheckObject(context.Background(),
+				&storagetypes.MsgCreateObject{Creator: c.creator, PayloadSize: c.payloadSize}))
+		})
+	}
+	// the bucket is refused once the stored size reaches the limit
+	assert.NoError(t, rule.CheckBucket(context.Background(), &storagetypes.MsgCreateBucket{Creator: testAccount}))
+	buckets[testAccount] = append(buckets[testAccount], newBucket(testSpAddress, 40, false))
+	assert.Equal(t, ErrStoreSizeRefused, rule.CheckBucket(context.Background(),
+		&storagetypes.MsgCreateBucket{Creator: testAccount}))
+}
+
+// mockPaymentConsensus returns the stream records and buckets of the test.
+type mockPaymentConsensus struct {
+	consensus.NullConsensus
+	balances map[string]int64
+}
+
+func (m *mockPaymentConsensus) QueryPaymentStreamRecord(_ context.Context, account string) (
+	*paymenttypes.StreamRecord, error) {
+	balance, ok := m.balances[account]
+	if !ok {
+		return nil, errors.New("no stream record")
+	}
+	return &paymenttypes.StreamRecord{Account: account, StaticBalance: sdkmath.NewInt(balance)}, nil
+}
+
+func (m *mockPaymentConsensus) QueryBucketInfo(_ context.Context, bucket string) (*storagetypes.BucketInfo, error) {
+	return &storagetypes.BucketInfo{BucketName: bucket, PaymentAddress: bucket}, nil
+}
+
+func TestStreamBalanceRule(t *testing.T) {
+	app := &gfspapp.GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{Customize: &gfspconfig.Customize{Consensus: &mockPaymentConsensus{
+		balances: map[string]int64{testAccount: 100, testOtherAccount: 99}}}}
+	require.NoError(t, gfspapp.DefaultGfSpConsensusOption(app, cfg))
+	rules, err := NewApprovalRules(app, &ApprovalPolicy{MinStreamBalance: "100"})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	rule := rules[0]
+	cases := []struct {
+		name    string
+		creator string
+		payment string
+		wantErr error
+	}{
+		{"enough balance", testAccount, "", nil},
+		{"insufficient balance", testOtherAccount, "", ErrStreamBalanceRefused},
+		{"balance of payment account", testOtherAccount, testAccount, nil},
+		{"no stream record", "", "", ErrConsensus},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.wantErr, rule.CheckBucket(context.Background(),
+				&storagetypes.MsgCreateBucket{Creator: c.creator, PaymentAddress: c.payment}))
+			// the payment account of the object is the one of the bucket
+			if c.payment != "" {
+				assert.Equal(t, c.wantErr, rule.CheckObject(context.Background(),
+					&storagetypes.MsgCreateObject{Creator: c.creator, BucketName: c.payment}))
+			}
+		})
+	}
+}
+
+func TestRedundancyTypeRule(t *testing.T) {
+	rules, err := NewApprovalRules(nil, &ApprovalPolicy{AllowRedundancyTypes: []string{"redundancy_ec_type"}})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	rule := rules[0]
+	cases := []struct {
+		name           string
+		redundancyType storagetypes.RedundancyType
+		wantErr        error
+	}{
+		{"allowed", storagetypes.REDUNDANCY_EC_TYPE, nil},
+		{"not allowed", storagetypes.REDUNDANCY_REPLICA_TYPE, ErrRedundancyTypeRefused},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.wantErr, rule.CheckObject(context.Background(),
+				&storagetypes.MsgCreateObject{RedundancyType: c.redundancyType}))
+		})
+	}
+}
+
+func TestNewApprovalRulesInvalidPolicy(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy *ApprovalPolicy
+	}{
+		{"invalid stream balance", &ApprovalPolicy{MinStreamBalance: "one"}},
+		{"invalid redundancy type", &ApprovalPolicy{AllowRedundancyTypes: []string{"REDUNDANCY_UNKNOWN"}}},
+		{"invalid content type", &ApprovalPolicy{AllowContentTypes: []string{"text/"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewApprovalRules(nil, c.policy)
+			assert.Equal(t, ErrInvalidPolicy, err)
+		})
+	}
+}
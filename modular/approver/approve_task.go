@@ -16,8 +16,18 @@ var (
 	ErrExceedBucketNumber = gfsperrors.Register(module.ApprovalModularName, http.StatusServiceUnavailable, 10002, "account buckets exceed the limit")
 	ErrRepeatedTask       = gfsperrors.Register(module.ApprovalModularName, http.StatusBadRequest, 10003, "ask approval request repeated")
 	ErrExceedQueue        = gfsperrors.Register(module.ApprovalModularName, http.StatusServiceUnavailable, 10004, "ask approval request exceed the limit, try again later")
-	ErrSigner             = gfsperrors.Register(module.ApprovalModularName, http.StatusInternalServerError, 11001, "server slipped away, try again later")
-	ErrConsensus          = gfsperrors.Register(module.ApprovalModularName, http.StatusInternalServerError, 15001, "server slipped away, try again later")
+	ErrSPExiting          = gfsperrors.Register(module.ApprovalModularName, http.StatusServiceUnavailable, 10005, "the sp is exiting, choose other sp")
+	ErrInvalidPolicy      = gfsperrors.Register(module.ApprovalModularName, http.StatusInternalServerError, 10006, "invalid approval policy")
+	// the errors refused by the approval policy, the inner code is the reason code
+	ErrAccountRefused        = gfsperrors.Register(module.ApprovalModularName, http.StatusForbidden, 10007, "account is refused by the sp approval policy")
+	ErrPayloadSizeRefused    = gfsperrors.Register(module.ApprovalModularName, http.StatusForbidden, 10008, "payload size exceeds the sp approval policy limit")
+	ErrContentTypeRefused    = gfsperrors.Register(module.ApprovalModularName, http.StatusForbidden, 10009, "content type is refused by the sp approval policy")
+	ErrStoreSizeRefused      = gfsperrors.Register(module.ApprovalModularName, http.StatusForbidden, 10010, "account stored size exceeds the sp approval policy limit")
+	ErrStreamBalanceRefused  = gfsperrors.Register(module.ApprovalModularName, http.StatusForbidden, 10011, "payment stream balance is less than the sp approval policy limit")
+	ErrRedundancyTypeRefused = gfsperrors.Register(module.ApprovalModularName, http.StatusForbidden, 10012, "redundancy type is refused by the sp approval policy")
+	ErrSigner                = gfsperrors.Register(module.ApprovalModularName, http.StatusInternalServerError, 11001, "server slipped away, try again later")
+	ErrConsensus             = gfsperrors.Register(module.ApprovalModularName, http.StatusInternalServerError, 15001, "server slipped away, try again later")
+	ErrMetadata              = gfsperrors.Register(module.ApprovalModularName, http.StatusInternalServerError, 15002, "server slipped away, try again later")
 )
 
 func (a *ApprovalModular) PreCreateBucketApproval(
@@ -27,6 +37,10 @@ func (a *ApprovalModular) PreCreateBucketApproval(
 		log.CtxErrorw(ctx, "failed to pre create bucket approval, pointer nil")
 		return ErrDanglingPointer
 	}
+	if a.baseApp.SPExiting() {
+		log.CtxErrorw(ctx, "failed to pre create bucket approval, the sp is exiting")
+		return ErrSPExiting
+	}
 	buckets, err := a.baseApp.GfSpClient().GetUserBucketsCount(ctx, task.GetCreateBucketInfo().GetCreator())
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to get account owns max bucket number", "error", err)
@@ -36,6 +50,9 @@ func (a *ApprovalModular) PreCreateBucketApproval(
 		log.CtxErrorw(ctx, "account owns bucket number exceed")
 		return ErrExceedBucketNumber
 	}
+	if err = a.checkBucketPolicy(ctx, task.GetCreateBucketInfo()); err != nil {
+		return err
+	}
 	if a.bucketQueue.Has(task.Key()) {
 		log.CtxErrorw(ctx, "failed to pre create bucket approval, task repeated")
 		return ErrRepeatedTask
@@ -88,11 +105,15 @@ func (a *ApprovalModular) PreCreateObjectApproval(
 		log.CtxErrorw(ctx, "failed to pre create object approval, pointer nil")
 		return ErrDanglingPointer
 	}
+	if a.baseApp.SPExiting() {
+		log.CtxErrorw(ctx, "failed to pre create object approval, the sp is exiting")
+		return ErrSPExiting
+	}
 	if a.objectQueue.Has(task.Key()) {
 		log.CtxErrorw(ctx, "failed to pre create object approval, task repeated")
 		return ErrRepeatedTask
 	}
-	return nil
+	return a.checkObjectPolicy(ctx, task.GetCreateObjectInfo())
 }
 
 func (a *ApprovalModular) HandleCreateObjectApprovalTask(
@@ -2,6 +2,7 @@ package approver
 
 import (
 	"context"
+	"sync"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/core/module"
@@ -26,6 +27,10 @@ type ApprovalModular struct {
 	// expired height equal to current block height + timeout height
 	bucketApprovalTimeoutHeight uint64
 	objectApprovalTimeoutHeight uint64
+
+	// defines the rules of the approval policy evaluated in the Pre*Approval hooks
+	rules     []ApprovalRule
+	policyMux sync.RWMutex
 }
 
 func (a *ApprovalModular) Name() string {
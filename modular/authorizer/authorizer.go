@@ -209,7 +209,8 @@ func (a *AuthorizeModular) VerifyAuthorize(
 			return false, ErrConsensus
 		}
 		return allow, nil
-	case coremodule.AuthOpTypeGetBucketQuota, coremodule.AuthOpTypeListBucketReadRecord:
+	case coremodule.AuthOpTypeGetBucketQuota, coremodule.AuthOpTypeListBucketReadRecord,
+		coremodule.AuthOpTypeManageNotification:
 		bucketInfo, err := a.baseApp.Consensus().QueryBucketInfo(ctx, bucket)
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to get bucket info from consensus", "error", err)
@@ -230,6 +231,30 @@ func (a *AuthorizeModular) VerifyAuthorize(
 			return false, ErrNoPermission
 		}
 		return true, nil
+	case coremodule.AuthOpTypeListObjects:
+		bucketInfo, err := a.baseApp.Consensus().QueryBucketInfo(ctx, bucket)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to get bucket info from consensus", "error", err)
+			// refer to https://github.com/bnb-chain/greenfield/blob/master/x/storage/types/errors.go
+			if strings.Contains(err.Error(), "No such bucket") {
+				return false, ErrNoSuchBucket
+			}
+			return false, ErrConsensus
+		}
+		if bucketInfo.GetPrimarySpAddress() != a.baseApp.OperateAddress() {
+			log.CtxErrorw(ctx, "sp operator address mismatch", "current", a.baseApp.OperateAddress(),
+				"require", bucketInfo.GetPrimarySpAddress())
+			return false, ErrMismatchSp
+		}
+		if bucketInfo.GetVisibility() == storagetypes.VISIBILITY_TYPE_PUBLIC_READ {
+			return true, nil
+		}
+		if bucketInfo.GetOwner() != account {
+			log.CtxErrorw(ctx, "only owner can list objects of the private bucket", "current", account,
+				"bucket_owner", bucketInfo.GetOwner())
+			return false, ErrNoPermission
+		}
+		return true, nil
 	case coremodule.AuthOpTypeChallengePiece:
 		//TODO:: only validator has permission to challenge piece
 		bucketInfo, objectInfo, err := a.baseApp.Consensus().QueryBucketInfoAndObjectInfo(ctx, bucket, object)
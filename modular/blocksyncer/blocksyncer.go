@@ -40,6 +40,9 @@ type BlockSyncerModular struct {
 	context   context.Context
 	scope     rcmgr.ResourceScope
 	baseApp   *gfspapp.GfSpBaseApp
+	recordDir string
+	replayDir string
+	notifier  *NotificationDeliverer
 }
 
 // Read concurrency required global variables
@@ -80,6 +83,10 @@ func (b *BlockSyncerModular) Start(ctx context.Context) error {
 
 	go MainService.serve(CtxMain)
 
+	if b.notifier != nil {
+		go b.notifier.Run(CtxMain)
+	}
+
 	//create backup blocksyncer
 	if NeedBackup {
 		ctxBackup := context.Background()
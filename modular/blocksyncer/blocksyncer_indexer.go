@@ -20,26 +20,35 @@ import (
 	"github.com/forbole/juno/v4/types"
 
 	"github.com/bnb-chain/greenfield-storage-provider/model/errors"
+	db "github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/database"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/metrics"
 )
 
-func NewIndexer(codec codec.Codec, proxy node.Node, db database.Database, modules []modules.Module, serviceName string) parser.Indexer {
+func NewIndexer(codec codec.Codec, proxy node.Node, db database.Database, modules []modules.Module,
+	modulesBuilder ModulesBuilder, serviceName string) parser.Indexer {
 	return &Impl{
-		codec:       codec,
-		Node:        proxy,
-		DB:          db,
-		Modules:     modules,
-		ServiceName: serviceName,
+		codec:          codec,
+		Node:           proxy,
+		DB:             db,
+		Modules:        modules,
+		ModulesBuilder: modulesBuilder,
+		ServiceName:    serviceName,
 	}
 }
 
+// ModulesBuilder builds the modules that write through the given database, it is used to
+// bind the modules to the transaction of a block.
+type ModulesBuilder func(db database.Database) []modules.Module
+
 type Impl struct {
 	Modules []modules.Module
 	codec   codec.Codec
 	Node    node.Node
 	DB      database.Database
 
+	ModulesBuilder ModulesBuilder
+
 	LatestBlockHeight atomic.Value
 	CatchUpFlag       atomic.Value
 
@@ -111,13 +120,44 @@ func (i *Impl) Process(height uint64) error {
 		}
 	}
 
-	beginBlockEvents := events.BeginBlockEvents
-	endBlockEvents := events.EndBlockEvents
+	if err = i.exportBlock(context.Background(), block, events, txs); err != nil {
+		return err
+	}
 
-	// 1. handle events in startBlock
+	blockMap.Delete(heightKey)
+	eventMap.Delete(heightKey)
+	txMap.Delete(heightKey)
+
+	return nil
+}
+
+// exportBlock exports the events of all the modules and the epoch of the block in one
+// database transaction, so the block is either fully exported or not exported at all and
+// will be processed again, the module handlers should be idempotent for the replay.
+func (i *Impl) exportBlock(ctx context.Context, block *coretypes.ResultBlock, events *coretypes.ResultBlockResults, txs []*types.Tx) error {
+	tx := db.Cast(i.DB).BeginTx(ctx)
+	if err := tx.Db.Error; err != nil {
+		log.Errorf("failed to begin block transaction: %s", err)
+		return err
+	}
+	txIndexer := &Impl{
+		Modules:     i.ModulesBuilder(tx),
+		codec:       i.codec,
+		Node:        i.Node,
+		DB:          tx,
+		ServiceName: i.ServiceName,
+	}
 
-	if len(beginBlockEvents) > 0 {
-		err = i.ExportEventsWithoutTx(context.Background(), block, beginBlockEvents)
+	var err error
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 1. handle events in startBlock
+	if len(events.BeginBlockEvents) > 0 {
+		err = txIndexer.ExportEventsWithoutTx(ctx, block, events.BeginBlockEvents)
 		if err != nil {
 			log.Errorf("failed to export events without tx: %s", err)
 			return err
@@ -125,31 +165,33 @@ func (i *Impl) Process(height uint64) error {
 	}
 
 	// 2. handle events in txs
-	err = i.ExportEventsInTxs(context.Background(), block, txs)
+	err = txIndexer.ExportEventsInTxs(ctx, block, txs)
 	if err != nil {
 		log.Errorf("failed to export events in txs: %s", err)
 		return err
 	}
 
 	// 3. handle events in endBlock
-	if len(endBlockEvents) > 0 {
-		err = i.ExportEventsWithoutTx(context.Background(), block, endBlockEvents)
+	if len(events.EndBlockEvents) > 0 {
+		err = txIndexer.ExportEventsWithoutTx(ctx, block, events.EndBlockEvents)
 		if err != nil {
 			log.Errorf("failed to export events without tx: %s", err)
 			return err
 		}
 	}
 
-	err = i.ExportEpoch(block)
+	// 4. record the sync progress with the events of the block
+	err = txIndexer.ExportEpoch(block)
 	if err != nil {
 		log.Errorf("failed to export epoch: %s", err)
 		return err
 	}
 
-	blockMap.Delete(heightKey)
-	eventMap.Delete(heightKey)
-	txMap.Delete(heightKey)
-
+	if err = tx.Commit(); err != nil {
+		log.Errorf("failed to commit block transaction: %s", err)
+		return err
+	}
+	metrics.BlockHeightLagGauge.WithLabelValues("blocksyncer").Set(float64(block.Block.Height))
 	return nil
 }
 
@@ -167,8 +209,6 @@ func (i *Impl) ExportEpoch(block *coretypes.ResultBlock) error {
 		return err
 	}
 
-	metrics.BlockHeightLagGauge.WithLabelValues("blocksyncer").Set(float64(block.Block.Height))
-
 	return nil
 }
 
@@ -0,0 +1,231 @@
+package blocksyncer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/forbole/juno/v4/common"
+	"github.com/forbole/juno/v4/database"
+	"github.com/forbole/juno/v4/database/mysql"
+	"github.com/forbole/juno/v4/models"
+	"github.com/forbole/juno/v4/modules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	db "github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/database"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/modules/prefixtree"
+	"github.com/bnb-chain/greenfield-storage-provider/store/bsdb"
+)
+
+const testBucketName = "test-bucket"
+
+var errKilled = errors.New("processing killed")
+
+// killModule fails the event of the type to simulate the process is killed in the middle
+// of a block, after the modules in front of it have handled the event.
+type killModule struct {
+	eventType string
+}
+
+func (m *killModule) Name() string { return "kill" }
+
+func (m *killModule) HandleEvent(_ context.Context, _ *coretypes.ResultBlock, _ common.Hash, event sdk.Event) error {
+	if event.Type == m.eventType {
+		return errKilled
+	}
+	return nil
+}
+
+func makeTestIndexer(t *testing.T, kill *killModule) (*Impl, *gorm.DB) {
+	gormDB, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "bsdb.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gormDB.Exec(`CREATE TABLE epoch (one_row_id BOOLEAN PRIMARY KEY DEFAULT TRUE,
+		block_height BIGINT, block_hash BLOB, update_time BIGINT)`).Error)
+	require.NoError(t, gormDB.Exec(`CREATE TABLE `+bsdb.PrefixTreeTableName+` (id INTEGER PRIMARY KEY AUTOINCREMENT,
+		path_name TEXT, full_name TEXT, name TEXT, is_object BOOLEAN, is_folder BOOLEAN, bucket_name TEXT,
+		object_id BLOB, object_name TEXT, object_count BIGINT DEFAULT 0, total_size BIGINT DEFAULT 0)`).Error)
+	indexer := &Impl{
+		DB: &db.DB{Database: &mysql.Database{Impl: database.Impl{Db: gormDB}}},
+		ModulesBuilder: func(tx database.Database) []modules.Module {
+			mods := []modules.Module{prefixtree.NewModule(db.Cast(tx))}
+			if kill.eventType != "" {
+				mods = append(mods, kill)
+			}
+			return mods
+		},
+	}
+	return indexer, gormDB
+}
+
+func makeTestBlock(t *testing.T, height int64, typedEvents ...proto.Message) (*coretypes.ResultBlock, *coretypes.ResultBlockResults) {
+	block := &coretypes.ResultBlock{
+		BlockID: tmtypes.BlockID{Hash: common.BigToHash(sdkmath.NewInt(height).BigInt()).Bytes()},
+		Block:   &tmtypes.Block{Header: tmtypes.Header{Height: height, Time: time.Now()}},
+	}
+	events := &coretypes.ResultBlockResults{Height: height}
+	for _, typedEvent := range typedEvents {
+		event, err := sdk.TypedEventToEvent(typedEvent)
+		require.NoError(t, err)
+		events.EndBlockEvents = append(events.EndBlockEvents, abci.Event(event))
+	}
+	return block, events
+}
+
+func createObjectEvent(id uint64, name string) *storagetypes.EventCreateObject {
+	return &storagetypes.EventCreateObject{BucketName: testBucketName, ObjectName: name, ObjectId: sdkmath.NewUint(id)}
+}
+
+func deleteObjectEvent(id uint64, name string) *storagetypes.EventDeleteObject {
+	return &storagetypes.EventDeleteObject{BucketName: testBucketName, ObjectName: name, ObjectId: sdkmath.NewUint(id)}
+}
+
+func prefixTreeNodes(t *testing.T, gormDB *gorm.DB) []string {
+	var nodes []*bsdb.SlashPrefixTreeNode
+	require.NoError(t, gormDB.Order("full_name").Find(&nodes).Error)
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.FullName)
+	}
+	return names
+}
+
+func epochHeight(t *testing.T, gormDB *gorm.DB) int64 {
+	var epoch models.Epoch
+	require.NoError(t, gormDB.Find(&epoch).Error)
+	return epoch.BlockHeight
+}
+
+func TestImpl_ExportBlockKilledMidBlock(t *testing.T) {
+	kill := &killModule{}
+	indexer, gormDB := makeTestIndexer(t, kill)
+	ctx := context.Background()
+
+	block, events := makeTestBlock(t, 1, createObjectEvent(1, "dir/a.txt"), createObjectEvent(2, "dir/b.txt"))
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	assert.Equal(t, []string{"dir/", "dir/a.txt", "dir/b.txt"}, prefixTreeNodes(t, gormDB))
+	assert.Equal(t, int64(1), epochHeight(t, gormDB))
+
+	// the prefix tree module has deleted the object when the process is killed, nothing
+	// of the block including the epoch should be committed
+	kill.eventType = prefixtree.EventDeleteObject
+	block, events = makeTestBlock(t, 2, createObjectEvent(3, "other/c.txt"), deleteObjectEvent(1, "dir/a.txt"))
+	assert.ErrorIs(t, indexer.exportBlock(ctx, block, events, nil), errKilled)
+	assert.Equal(t, []string{"dir/", "dir/a.txt", "dir/b.txt"}, prefixTreeNodes(t, gormDB))
+	assert.Equal(t, int64(1), epochHeight(t, gormDB))
+
+	// the block is processed again after restart
+	kill.eventType = ""
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	expected := []string{"dir/", "dir/b.txt", "other/", "other/c.txt"}
+	assert.Equal(t, expected, prefixTreeNodes(t, gormDB))
+	assert.Equal(t, int64(2), epochHeight(t, gormDB))
+
+	// replaying the committed block keeps the directory that still has a child
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	assert.Equal(t, expected, prefixTreeNodes(t, gormDB))
+	assert.Equal(t, int64(2), epochHeight(t, gormDB))
+}
+
+func directoryStats(t *testing.T, gormDB *gorm.DB) map[string][2]int64 {
+	var nodes []*bsdb.SlashPrefixTreeNode
+	require.NoError(t, gormDB.Where("is_object = ?", false).Find(&nodes).Error)
+	stats := make(map[string][2]int64, len(nodes))
+	for _, node := range nodes {
+		stats[node.FullName] = [2]int64{node.ObjectCount, node.TotalSize}
+	}
+	return stats
+}
+
+func TestImpl_ExportBlockPrefixTreeStats(t *testing.T) {
+	indexer, gormDB := makeTestIndexer(t, &killModule{})
+	ctx := context.Background()
+
+	objectA, objectB, objectC := createObjectEvent(1, "dir/a.txt"), createObjectEvent(2, "dir/sub/b.txt"), createObjectEvent(3, "c.txt")
+	objectA.PayloadSize, objectB.PayloadSize, objectC.PayloadSize = 10, 20, 40
+	block, events := makeTestBlock(t, 1, objectA, objectB, objectC)
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	expected := map[string][2]int64{"dir/": {2, 30}, "dir/sub/": {1, 20}}
+	assert.Equal(t, expected, directoryStats(t, gormDB))
+
+	// replaying the block does not count the objects again
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	assert.Equal(t, expected, directoryStats(t, gormDB))
+
+	// the deleted and the rejected objects are removed from the stats, and replaying does not
+	// remove them again
+	block, events = makeTestBlock(t, 2, deleteObjectEvent(1, "dir/a.txt"), &storagetypes.EventRejectSealObject{
+		BucketName: testBucketName, ObjectName: "c.txt", ObjectId: sdkmath.NewUint(3)})
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	assert.Equal(t, map[string][2]int64{"dir/": {1, 20}, "dir/sub/": {1, 20}}, directoryStats(t, gormDB))
+}
+
+func TestImpl_ExportBlockPrefixTreeStatsCancelReject(t *testing.T) {
+	indexer, gormDB := makeTestIndexer(t, &killModule{})
+	ctx := context.Background()
+
+	objects := []*storagetypes.EventCreateObject{createObjectEvent(1, "dir/a.txt"), createObjectEvent(2, "dir/sub/b.txt"),
+		createObjectEvent(3, "dir/sub/c.txt"), createObjectEvent(4, "dir/sub/d.txt")}
+	typedEvents := make([]proto.Message, 0, len(objects))
+	for i, object := range objects {
+		object.PayloadSize = 10 << i
+		typedEvents = append(typedEvents, object)
+	}
+	block, events := makeTestBlock(t, 1, typedEvents...)
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	assert.Equal(t, map[string][2]int64{"dir/": {4, 150}, "dir/sub/": {3, 140}}, directoryStats(t, gormDB))
+
+	// the canceled and the rejected objects are removed from the stats of all the parent
+	// directories, and replaying does not remove them again
+	block, events = makeTestBlock(t, 2,
+		&storagetypes.EventCancelCreateObject{BucketName: testBucketName, ObjectName: "dir/sub/b.txt", ObjectId: sdkmath.NewUint(2)},
+		&storagetypes.EventRejectSealObject{BucketName: testBucketName, ObjectName: "dir/sub/c.txt", ObjectId: sdkmath.NewUint(3)})
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	assert.Equal(t, map[string][2]int64{"dir/": {2, 90}, "dir/sub/": {1, 80}}, directoryStats(t, gormDB))
+}
+
+func TestDB_BackfillPrefixTreeStats(t *testing.T) {
+	indexer, gormDB := makeTestIndexer(t, &killModule{})
+	ctx := context.Background()
+	require.NoError(t, gormDB.Exec(`CREATE TABLE `+bsdb.ObjectTableName+` (object_id BLOB, payload_size BIGINT)`).Error)
+
+	objects := []*storagetypes.EventCreateObject{createObjectEvent(1, "dir/a.txt"), createObjectEvent(2, "dir/sub/b.txt"),
+		createObjectEvent(3, "c.txt"), createObjectEvent(4, "other/d.txt")}
+	typedEvents := make([]proto.Message, 0, len(objects))
+	for i, object := range objects {
+		object.PayloadSize = 10 << i
+		typedEvents = append(typedEvents, object)
+		require.NoError(t, gormDB.Exec(`INSERT INTO `+bsdb.ObjectTableName+` (object_id, payload_size) VALUES (?, ?)`,
+			common.BigToHash(object.ObjectId.BigInt()), object.PayloadSize).Error)
+	}
+	block, events := makeTestBlock(t, 1, typedEvents...)
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	expected := directoryStats(t, gormDB)
+	assert.Equal(t, map[string][2]int64{"dir/": {2, 30}, "dir/sub/": {1, 20}, "other/": {1, 80}}, expected)
+
+	// the stats of the table created before the stats columns are zero, and the directory
+	// without any object is reset
+	require.NoError(t, gormDB.Exec(`UPDATE `+bsdb.PrefixTreeTableName+` SET object_count = 0, total_size = 0`).Error)
+	require.NoError(t, gormDB.Exec(`INSERT INTO `+bsdb.PrefixTreeTableName+` (path_name, full_name, name, is_object,
+		is_folder, bucket_name, object_count, total_size) VALUES ('/', 'empty/', 'empty/', FALSE, TRUE, ?, 5, 5)`, testBucketName).Error)
+	require.NoError(t, db.Cast(indexer.DB).BackfillPrefixTreeStats(ctx))
+	expected["empty/"] = [2]int64{0, 0}
+	assert.Equal(t, expected, directoryStats(t, gormDB))
+	var objectNode bsdb.SlashPrefixTreeNode
+	require.NoError(t, gormDB.Where("full_name = ? AND is_object = ?", "other/d.txt", true).Take(&objectNode).Error)
+	assert.Equal(t, int64(1), objectNode.ObjectCount)
+	assert.Equal(t, int64(80), objectNode.TotalSize)
+}
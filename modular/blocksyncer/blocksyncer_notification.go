@@ -0,0 +1,347 @@
+package blocksyncer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	db "github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/database"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/store/bsdb"
+)
+
+const (
+	// DefaultNotificationIntervalSec defines the default interval of delivering the notifications
+	DefaultNotificationIntervalSec = 1
+	// DefaultNotificationMaxAttempts defines the default max attempts of delivering a notification
+	DefaultNotificationMaxAttempts = 8
+	// DefaultNotificationBackoffSec defines the default backoff of retrying the failed delivery
+	DefaultNotificationBackoffSec = 10
+	// DefaultNotificationMaxEventAgeSec defines the default max age of the events to deliver
+	DefaultNotificationMaxEventAgeSec = 24 * 60 * 60
+	// DefaultNotificationDeliverParallel defines the default max number of the notifications
+	// posted concurrently
+	DefaultNotificationDeliverParallel = 16
+	// NotificationMaxBackoff defines the max backoff of retrying the failed delivery
+	NotificationMaxBackoff = time.Hour
+	// NotificationBatchSize defines the max number of the events or deliveries handled in a round
+	NotificationBatchSize = 100
+	// NotificationDeliverTimeout defines the timeout of posting a notification to the webhook
+	NotificationDeliverTimeout = 10 * time.Second
+)
+
+// errNotificationAddressRefused is returned if the webhook resolves to a non public address.
+var errNotificationAddressRefused = errors.New("webhook address is not public")
+
+// notificationReservedPrefixes defines the reserved ranges that are global unicast but not
+// reachable on the internet, e.g. the shared address space of the carrier-grade NAT.
+var notificationReservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// NotificationDeliverer delivers the events in the outbox to the webhooks of the subscriptions.
+// The events are fanned out to the deliveries of the subscriptions first, then the deliveries
+// are posted with the payload signed by the secret of the subscription, the failed deliveries
+// are retried with exponential backoff and moved to the dead letter table after the max attempts.
+// The delivery is at least once, the webhooks dedupe the events by the event id.
+type NotificationDeliverer struct {
+	// bsdb returns the block syncer db of the main service, the main service may be switched
+	bsdb          func() *db.DB
+	subscriptions corespdb.NotificationDB
+	client        *http.Client
+	interval      time.Duration
+	maxAttempts   int
+	backoff       time.Duration
+	maxEventAge   time.Duration
+	parallel      int
+}
+
+// NewNotificationDeliverer returns an instance of NotificationDeliverer.
+func NewNotificationDeliverer(bsdb func() *db.DB, subscriptions corespdb.NotificationDB,
+	cfg *gfspconfig.BlockSyncerConfig) *NotificationDeliverer {
+	d := &NotificationDeliverer{
+		bsdb:          bsdb,
+		subscriptions: subscriptions,
+		client:        newNotificationClient(cfg.NotificationAllowPrivateNetwork),
+		interval:      time.Duration(cfg.NotificationIntervalSec) * time.Second,
+		maxAttempts:   cfg.NotificationMaxAttempts,
+		backoff:       time.Duration(cfg.NotificationBackoffSec) * time.Second,
+		maxEventAge:   time.Duration(cfg.NotificationMaxEventAgeSec) * time.Second,
+		parallel:      cfg.NotificationDeliverParallel,
+	}
+	if d.interval <= 0 {
+		d.interval = DefaultNotificationIntervalSec * time.Second
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = DefaultNotificationMaxAttempts
+	}
+	if d.backoff <= 0 {
+		d.backoff = DefaultNotificationBackoffSec * time.Second
+	}
+	if d.maxEventAge <= 0 {
+		d.maxEventAge = DefaultNotificationMaxEventAgeSec * time.Second
+	}
+	if d.parallel <= 0 {
+		d.parallel = DefaultNotificationDeliverParallel
+	}
+	return d
+}
+
+// newNotificationClient returns the client to post the notifications. The webhooks are
+// registered by the users, so the client refuses to connect to the non public addresses
+// unless the private network is allowed. The address is checked after it is resolved on
+// every dial, so neither the dns rebinding nor the redirects bypass the check, and the
+// proxy of the environment is not used because it would dial on behalf of the client.
+func newNotificationClient(allowPrivateNetwork bool) *http.Client {
+	dialer := &net.Dialer{Timeout: NotificationDeliverTimeout}
+	if !allowPrivateNetwork {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			if !isPublicNotificationAddress(addr) {
+				return fmt.Errorf("%w: %s", errNotificationAddressRefused, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: NotificationDeliverTimeout, Transport: transport}
+}
+
+// isPublicNotificationAddress returns whether the address is reachable on the internet, the
+// loopback, private, link-local, multicast, unspecified and reserved addresses are not.
+func isPublicNotificationAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range notificationReservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run delivers the notifications every interval until the context is done.
+func (d *NotificationDeliverer) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Infof("Receive cancel signal, notification deliverer will stop")
+			return
+		case <-ticker.C:
+			if err := d.deliverOnce(ctx, time.Now()); err != nil {
+				log.Errorw("failed to deliver notifications", "error", err)
+			}
+		}
+	}
+}
+
+// deliverOnce dispatches the new events and delivers the due deliveries.
+func (d *NotificationDeliverer) deliverOnce(ctx context.Context, now time.Time) error {
+	database := d.bsdb()
+	if err := d.dispatch(ctx, database, now); err != nil {
+		return err
+	}
+	return d.deliver(ctx, database, now)
+}
+
+// dispatch fans out the events that have not been dispatched to the deliveries of the
+// subscriptions of the bucket or of the owner, the events older than the max age are
+// dispatched without delivery. The event without owner is only dispatched to the bucket
+// subscriptions.
+func (d *NotificationDeliverer) dispatch(ctx context.Context, database *db.DB, now time.Time) error {
+	outboxes, err := database.ListUndispatchedNotificationOutbox(ctx, NotificationBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, outbox := range outboxes {
+		var deliveries []*bsdb.NotificationDelivery
+		if now.Sub(time.Unix(outbox.BlockTime, 0)) <= d.maxEventAge {
+			subscriptions, err := d.subscriptions.ListNotificationSubscriptionsByTarget(outbox.BucketName, outbox.Owner)
+			if err != nil {
+				return err
+			}
+			for _, subscription := range subscriptions {
+				if !subscription.Subscribe(outbox.EventType) {
+					continue
+				}
+				// the owner of the event is unknown if the object is missing, the owner
+				// subscriptions must not match it
+				if subscription.BucketName == "" && (outbox.Owner == "" || subscription.Owner != outbox.Owner) {
+					continue
+				}
+				deliveries = append(deliveries, &bsdb.NotificationDelivery{
+					OutboxID:       outbox.ID,
+					SubscriptionID: subscription.ID,
+					NextRetryAt:    now.Unix(),
+				})
+			}
+		}
+		if err = database.DispatchNotificationOutbox(ctx, outbox.ID, deliveries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notificationPost defines a due delivery to post and the result of posting it.
+type notificationPost struct {
+	delivery     *bsdb.NotificationDelivery
+	subscription *corespdb.NotificationSubscription
+	outbox       *bsdb.NotificationOutbox
+	err          error
+}
+
+// deliver posts the due deliveries to the webhooks, the delivery is deleted if it succeeds or
+// the subscription has been deleted, otherwise it is retried or moved to the dead letter table.
+// The deliveries are posted concurrently, and the results are recorded one by one after all
+// the posts return.
+func (d *NotificationDeliverer) deliver(ctx context.Context, database *db.DB, now time.Time) error {
+	deliveries, err := database.ListDueNotificationDeliveries(ctx, now.Unix(), NotificationBatchSize)
+	if err != nil {
+		return err
+	}
+	posts := make([]*notificationPost, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		subscription, err := d.subscriptions.GetNotificationSubscription(delivery.SubscriptionID)
+		if err != nil {
+			return err
+		}
+		outbox, err := database.GetNotificationOutbox(ctx, delivery.OutboxID)
+		if err != nil {
+			return err
+		}
+		if subscription == nil || outbox == nil {
+			if err = database.DeleteNotificationDelivery(ctx, delivery.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		posts = append(posts, &notificationPost{delivery: delivery, subscription: subscription, outbox: outbox})
+	}
+	d.postParallel(ctx, posts, now)
+
+	for _, p := range posts {
+		if p.err == nil {
+			if err = database.DeleteNotificationDelivery(ctx, p.delivery.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		p.delivery.Attempts++
+		p.delivery.LastError = p.err.Error()
+		log.CtxWarnw(ctx, "failed to deliver notification", "event_id", p.outbox.EventID,
+			"subscription_id", p.subscription.ID, "attempts", p.delivery.Attempts, "error", p.err)
+		if p.delivery.Attempts >= d.maxAttempts {
+			err = database.DeadLetterNotificationDelivery(ctx, p.delivery, now.Unix())
+		} else {
+			p.delivery.NextRetryAt = now.Add(d.retryBackoff(p.delivery.Attempts)).Unix()
+			err = database.UpdateNotificationDelivery(ctx, p.delivery)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postParallel posts the notifications concurrently, at most parallel posts are in flight.
+func (d *NotificationDeliverer) postParallel(ctx context.Context, posts []*notificationPost, now time.Time) {
+	var (
+		wg    sync.WaitGroup
+		limit = make(chan struct{}, d.parallel)
+	)
+	for _, p := range posts {
+		limit <- struct{}{}
+		wg.Add(1)
+		go func(p *notificationPost) {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+			p.err = d.post(ctx, p.subscription, p.outbox, now)
+		}(p)
+	}
+	wg.Wait()
+}
+
+// retryBackoff returns the backoff after the attempts, it is doubled on every attempt.
+func (d *NotificationDeliverer) retryBackoff(attempts int) time.Duration {
+	backoff := d.backoff
+	for i := 1; i < attempts && backoff < NotificationMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > NotificationMaxBackoff {
+		backoff = NotificationMaxBackoff
+	}
+	return backoff
+}
+
+// post posts the payload of the event to the webhook of the subscription, the response
+// with 2xx status code is regarded as success.
+func (d *NotificationDeliverer) post(ctx context.Context, subscription *corespdb.NotificationSubscription,
+	outbox *bsdb.NotificationOutbox, now time.Time) error {
+	body := []byte(outbox.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := now.Unix()
+	req.Header.Set(model.ContentTypeHeader, model.ContentTypeJSONHeaderValue)
+	req.Header.Set(model.GnfdNotificationEventIDHeader, outbox.EventID)
+	req.Header.Set(model.GnfdNotificationSubscriptionIDHeader, strconv.FormatUint(subscription.ID, 10))
+	req.Header.Set(model.GnfdNotificationTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(model.GnfdNotificationSignatureHeader, SignNotification(subscription.Secret, timestamp, body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responds status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignNotification returns the hex encoded HMAC-SHA256 of the timestamp and the body joined by
+// a dot, the webhook verifies the notification by signing it with the secret of the subscription.
+func SignNotification(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,283 @@
+package blocksyncer
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/forbole/juno/v4/common"
+	"github.com/forbole/juno/v4/database"
+	"github.com/forbole/juno/v4/modules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	db "github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/database"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/modules/notification"
+	"github.com/bnb-chain/greenfield-storage-provider/store/bsdb"
+)
+
+var testOwner = common.HexToAddress("0x76d244ce05c3de4bbc6fdd7f56379b145709ade9").String()
+
+// memNotificationDB keeps the subscriptions in memory.
+type memNotificationDB struct {
+	subscriptions []*corespdb.NotificationSubscription
+}
+
+func (m *memNotificationDB) CreateNotificationSubscription(subscription *corespdb.NotificationSubscription) (uint64, error) {
+	subscription.ID = uint64(len(m.subscriptions) + 1)
+	m.subscriptions = append(m.subscriptions, subscription)
+	return subscription.ID, nil
+}
+
+func (m *memNotificationDB) DeleteNotificationSubscription(id uint64, account string) error {
+	for i, subscription := range m.subscriptions {
+		if subscription.ID == id && subscription.Account == account {
+			m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *memNotificationDB) GetNotificationSubscription(id uint64) (*corespdb.NotificationSubscription, error) {
+	for _, subscription := range m.subscriptions {
+		if subscription.ID == id {
+			return subscription, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memNotificationDB) ListNotificationSubscriptions(account string) ([]*corespdb.NotificationSubscription, error) {
+	var subscriptions []*corespdb.NotificationSubscription
+	for _, subscription := range m.subscriptions {
+		if subscription.Account == account {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (m *memNotificationDB) ListNotificationSubscriptionsByTarget(bucketName string, owner string) ([]*corespdb.NotificationSubscription, error) {
+	var subscriptions []*corespdb.NotificationSubscription
+	for _, subscription := range m.subscriptions {
+		if (subscription.BucketName == bucketName && subscription.Owner == "") ||
+			(subscription.Owner == owner && subscription.BucketName == "") {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+// webhook records the verified notifications, it fails the requests while failing is set.
+type webhook struct {
+	mux      sync.Mutex
+	secret   string
+	failing  bool
+	received []string
+}
+
+func (h *webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	timestamp, _ := strconv.ParseInt(r.Header.Get(model.GnfdNotificationTimestampHeader), 10, 64)
+	if h.failing || r.Header.Get(model.GnfdNotificationSignatureHeader) != SignNotification(h.secret, timestamp, body) {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	h.received = append(h.received, r.Header.Get(model.GnfdNotificationEventIDHeader))
+}
+
+func countRows(t *testing.T, gormDB *gorm.DB, table string) int64 {
+	var count int64
+	require.NoError(t, gormDB.Table(table).Count(&count).Error)
+	return count
+}
+
+func TestNotificationDeliverer(t *testing.T) {
+	indexer, gormDB := makeTestIndexer(t, &killModule{})
+	bsDB := db.Cast(indexer.DB)
+	require.NoError(t, notification.NewModule(bsDB).PrepareTables())
+	require.NoError(t, gormDB.Exec(`CREATE TABLE objects (object_id BLOB, owner BLOB)`).Error)
+	require.NoError(t, gormDB.Exec(`INSERT INTO objects (object_id, owner) VALUES (?, ?)`,
+		common.BigToHash(big.NewInt(1)), common.HexToAddress(testOwner)).Error)
+	indexer.ModulesBuilder = func(tx database.Database) []modules.Module {
+		return []modules.Module{notification.NewModule(db.Cast(tx))}
+	}
+	ctx := context.Background()
+
+	bucketHook := &webhook{secret: "bucket-secret"}
+	bucketServer := httptest.NewServer(bucketHook)
+	defer bucketServer.Close()
+	ownerHook := &webhook{secret: "owner-secret", failing: true}
+	ownerServer := httptest.NewServer(ownerHook)
+	defer ownerServer.Close()
+	subscriptions := &memNotificationDB{}
+	_, _ = subscriptions.CreateNotificationSubscription(&corespdb.NotificationSubscription{
+		BucketName: testBucketName, URL: bucketServer.URL, Secret: bucketHook.secret})
+	_, _ = subscriptions.CreateNotificationSubscription(&corespdb.NotificationSubscription{
+		Owner: testOwner, URL: ownerServer.URL, Secret: ownerHook.secret,
+		EventTypes: []string{corespdb.NotificationEventDeleteObject}})
+	_, _ = subscriptions.CreateNotificationSubscription(&corespdb.NotificationSubscription{
+		BucketName: "other-bucket", URL: bucketServer.URL, Secret: bucketHook.secret})
+
+	// the replayed block does not write the events again
+	createObject := createObjectEvent(1, "dir/a.txt")
+	createObject.Owner = testOwner
+	block, events := makeTestBlock(t, 1, createObject, &storagetypes.EventDeleteObject{
+		BucketName: testBucketName, ObjectName: "dir/a.txt", ObjectId: createObject.ObjectId})
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	require.NoError(t, indexer.exportBlock(ctx, block, events, nil))
+	var outboxes []*bsdb.NotificationOutbox
+	require.NoError(t, gormDB.Order("id").Find(&outboxes).Error)
+	require.Len(t, outboxes, 2)
+	assert.Equal(t, corespdb.NotificationEventDeleteObject, outboxes[1].EventType)
+	assert.Equal(t, testOwner, outboxes[1].Owner)
+
+	deliverer := NewNotificationDeliverer(func() *db.DB { return bsDB }, subscriptions,
+		&gfspconfig.BlockSyncerConfig{NotificationMaxAttempts: 3, NotificationBackoffSec: 1,
+			NotificationAllowPrivateNetwork: true})
+	now := time.Now()
+	require.NoError(t, deliverer.deliverOnce(ctx, now))
+	assert.Equal(t, []string{outboxes[0].EventID, outboxes[1].EventID}, bucketHook.received)
+	// only the delete event is delivered to the owner subscription, and it fails
+	var deliveries []*bsdb.NotificationDelivery
+	require.NoError(t, gormDB.Find(&deliveries).Error)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, outboxes[1].ID, deliveries[0].OutboxID)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, now.Add(time.Second).Unix(), deliveries[0].NextRetryAt)
+
+	// the delivery is not retried before the backoff, then it is moved to the dead letter
+	// table after the max attempts
+	require.NoError(t, deliverer.deliverOnce(ctx, now))
+	require.NoError(t, gormDB.Find(&deliveries).Error)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	require.NoError(t, deliverer.deliverOnce(ctx, now.Add(time.Second)))
+	require.NoError(t, gormDB.Find(&deliveries).Error)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Equal(t, now.Add(3*time.Second).Unix(), deliveries[0].NextRetryAt)
+	require.NoError(t, deliverer.deliverOnce(ctx, now.Add(3*time.Second)))
+	assert.Equal(t, int64(0), countRows(t, gormDB, bsdb.NotificationDeliveryTableName))
+	var deadLetters []*bsdb.NotificationDeadLetter
+	require.NoError(t, gormDB.Find(&deadLetters).Error)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, 3, deadLetters[0].Attempts)
+	assert.Empty(t, ownerHook.received)
+	assert.Len(t, bucketHook.received, 2)
+}
+
+func TestNotificationDeliverer_DispatchUnknownOwner(t *testing.T) {
+	indexer, gormDB := makeTestIndexer(t, &killModule{})
+	bsDB := db.Cast(indexer.DB)
+	require.NoError(t, notification.NewModule(bsDB).PrepareTables())
+	ctx := context.Background()
+
+	subscriptions := &memNotificationDB{}
+	bucketID, _ := subscriptions.CreateNotificationSubscription(&corespdb.NotificationSubscription{
+		BucketName: testBucketName, URL: "http://bucket.example.com"})
+	// the subscription without owner must not match the events whose owner is unknown
+	_, _ = subscriptions.CreateNotificationSubscription(&corespdb.NotificationSubscription{
+		URL: "http://anonymous.example.com"})
+	require.NoError(t, bsDB.CreateNotificationOutbox(ctx, &bsdb.NotificationOutbox{
+		EventID: "event", EventType: corespdb.NotificationEventDeleteObject,
+		BucketName: testBucketName, BlockTime: time.Now().Unix()}))
+
+	deliverer := NewNotificationDeliverer(func() *db.DB { return bsDB }, subscriptions,
+		&gfspconfig.BlockSyncerConfig{})
+	require.NoError(t, deliverer.dispatch(ctx, bsDB, time.Now()))
+	var deliveries []*bsdb.NotificationDelivery
+	require.NoError(t, gormDB.Find(&deliveries).Error)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, bucketID, deliveries[0].SubscriptionID)
+}
+
+func TestNotificationDeliverer_RefusePrivateAddress(t *testing.T) {
+	hook := &webhook{secret: "secret"}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+	subscription := &corespdb.NotificationSubscription{ID: 1, URL: server.URL, Secret: hook.secret}
+	outbox := &bsdb.NotificationOutbox{EventID: "event", Payload: "{}"}
+
+	deliverer := NewNotificationDeliverer(nil, nil, &gfspconfig.BlockSyncerConfig{})
+	err := deliverer.post(context.Background(), subscription, outbox, time.Now())
+	assert.ErrorIs(t, err, errNotificationAddressRefused)
+	assert.Empty(t, hook.received)
+
+	deliverer = NewNotificationDeliverer(nil, nil, &gfspconfig.BlockSyncerConfig{NotificationAllowPrivateNetwork: true})
+	require.NoError(t, deliverer.post(context.Background(), subscription, outbox, time.Now()))
+	assert.Equal(t, []string{"event"}, hook.received)
+}
+
+func TestIsPublicNotificationAddress(t *testing.T) {
+	cases := []struct {
+		addr string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+	}
+	for _, tt := range cases {
+		assert.Equal(t, tt.want, isPublicNotificationAddress(netip.MustParseAddr(tt.addr)), tt.addr)
+	}
+}
+
+func TestNotificationDeliverer_PostParallel(t *testing.T) {
+	var inflight, maxInflight, received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInflight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInflight, max, current) {
+				break
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+		atomic.AddInt32(&received, 1)
+	}))
+	defer server.Close()
+
+	deliverer := NewNotificationDeliverer(nil, nil, &gfspconfig.BlockSyncerConfig{
+		NotificationDeliverParallel: 2, NotificationAllowPrivateNetwork: true})
+	var posts []*notificationPost
+	for i := 0; i < 6; i++ {
+		posts = append(posts, &notificationPost{
+			subscription: &corespdb.NotificationSubscription{ID: uint64(i), URL: server.URL},
+			outbox:       &bsdb.NotificationOutbox{EventID: strconv.Itoa(i), Payload: "{}"},
+		})
+	}
+	deliverer.postParallel(context.Background(), posts, time.Now())
+	for _, p := range posts {
+		assert.NoError(t, p.err)
+	}
+	assert.Equal(t, int32(6), atomic.LoadInt32(&received))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInflight))
+}
@@ -6,15 +6,20 @@ import (
 	"encoding/gob"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/forbole/juno/v4/cmd"
 	parsecmdtypes "github.com/forbole/juno/v4/cmd/parse/types"
+	junodatabase "github.com/forbole/juno/v4/database"
 	databaseconfig "github.com/forbole/juno/v4/database/config"
 	loggingconfig "github.com/forbole/juno/v4/log/config"
 	"github.com/forbole/juno/v4/modules"
 	"github.com/forbole/juno/v4/modules/messages"
+	modsregistrar "github.com/forbole/juno/v4/modules/registrar"
+	"github.com/forbole/juno/v4/node"
 	"github.com/forbole/juno/v4/node/remote"
 	"github.com/forbole/juno/v4/parser"
 	parserconfig "github.com/forbole/juno/v4/parser/config"
@@ -29,6 +34,7 @@ import (
 	"github.com/bnb-chain/greenfield-storage-provider/model/errors"
 	db "github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/database"
 	registrar "github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/modules"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/modules/notification"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 	"github.com/bnb-chain/greenfield-storage-provider/store/bsdb"
 )
@@ -36,9 +42,11 @@ import (
 func NewBlockSyncerModular(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig) (coremodule.Modular, error) {
 	junoCfg := makeBlockSyncerConfig(cfg)
 	MainService = &BlockSyncerModular{
-		config:  junoCfg,
-		name:    BlockSyncerModularName,
-		baseApp: app,
+		config:    junoCfg,
+		name:      BlockSyncerModularName,
+		baseApp:   app,
+		recordDir: cfg.BlockSyncer.RecordDir,
+		replayDir: cfg.BlockSyncer.ReplayDir,
 	}
 	blockMap = new(sync.Map)
 	eventMap = new(sync.Map)
@@ -62,16 +70,41 @@ func NewBlockSyncerModular(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig)
 	// when NeedBackup config true Or backup db is current master DB, init backup service
 	if NeedBackup || !mainServiceDB.IsMaster {
 		//create backup block syncer
-		if blockSyncerBackup, err := newBackupBlockSyncerService(junoCfg, mainDBIsMaster); err != nil {
+		if blockSyncerBackup, err := newBackupBlockSyncerService(junoCfg, mainDBIsMaster,
+			cfg.BlockSyncer.RecordDir, cfg.BlockSyncer.ReplayDir); err != nil {
 			return nil, err
 		} else {
 			BackupService = blockSyncerBackup
 		}
 	}
 
+	MainService.notifier = newNotificationDeliverer(app, cfg)
+
 	return MainService, nil
 }
 
+// newNotificationDeliverer returns the deliverer of the notifications in the outbox of the main
+// service, returns nil if the notification module is not configured or the spdb is unavailable.
+func newNotificationDeliverer(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig) *NotificationDeliverer {
+	configured := false
+	for _, name := range cfg.BlockSyncer.Modules {
+		if name == notification.ModuleName {
+			configured = true
+			break
+		}
+	}
+	if !configured {
+		return nil
+	}
+	if app.GfSpDB() == nil {
+		log.Warn("notification module is configured without spdb, notifications will not be delivered")
+		return nil
+	}
+	return NewNotificationDeliverer(func() *db.DB {
+		return db.Cast(MainService.parserCtx.Database)
+	}, app.GfSpDB(), &cfg.BlockSyncer)
+}
+
 // initClient initialize a juno client using given configs
 func (b *BlockSyncerModular) initClient() error {
 	// JunoConfig the runner
@@ -114,15 +147,51 @@ func (b *BlockSyncerModular) initClient() error {
 		log.Errorf("failed to GetParserContext err: %v", err)
 		return err
 	}
+	if ctx.Node, err = b.wrapBlockSource(ctx.Node); err != nil {
+		log.Errorf("failed to init block source err: %v", err)
+		return err
+	}
 	b.parserCtx = ctx
 	b.parserCtx.Indexer = NewIndexer(ctx.EncodingConfig.Marshaler,
 		ctx.Node,
 		ctx.Database,
 		ctx.Modules,
+		makeModulesBuilder(ctx, cmdCfg.GetRegistrar(), config.Cfg.Chain.Modules),
 		b.Name())
 	return nil
 }
 
+// wrapBlockSource serves the block data from the replayed archive if the replay directory is
+// set, otherwise records the block data fetched from the node if the record directory is set.
+func (b *BlockSyncerModular) wrapBlockSource(proxy node.Node) (node.Node, error) {
+	if b.replayDir != "" {
+		source, err := NewReplayBlockSource(b.replayDir)
+		if err != nil {
+			return nil, err
+		}
+		log.Infow("replay blocks from archive", "dir", b.replayDir)
+		return NewBlockSourceNode(proxy, source), nil
+	}
+	if b.recordDir != "" {
+		source, err := NewRecordBlockSource(proxy, b.recordDir)
+		if err != nil {
+			return nil, err
+		}
+		log.Infow("record blocks to archive", "dir", b.recordDir)
+		return NewBlockSourceNode(proxy, source), nil
+	}
+	return proxy, nil
+}
+
+// makeModulesBuilder returns the builder of the named modules, the modules are built by the
+// registrar in the same order as the names.
+func makeModulesBuilder(ctx *parser.Context, reg modsregistrar.Registrar, names []string) ModulesBuilder {
+	return func(database junodatabase.Database) []modules.Module {
+		mods := reg.BuildModules(modsregistrar.NewContext(config.Cfg, sdk.GetConfig(), ctx.EncodingConfig, database, ctx.Node))
+		return modsregistrar.GetModules(mods, names)
+	}
+}
+
 // initDB create tables needed by block syncer. It depends on which modules are configured
 func (b *BlockSyncerModular) initDB(recreateTables bool) error {
 
@@ -306,6 +375,14 @@ func (b *BlockSyncerModular) prepareMasterFlagTable() error {
 	return nil
 }
 
+// blockSyncerDBType returns the juno database type of the dialect, mysql is used if it is empty.
+func blockSyncerDBType(dialect string) databaseconfig.DatabaseType {
+	if dialect == "" {
+		return databaseconfig.MySQL
+	}
+	return databaseconfig.DatabaseType(strings.ToLower(dialect))
+}
+
 // makeBlockSyncerConfig make block syncer service config from StorageProviderConfig
 func makeBlockSyncerConfig(cfg *gfspconfig.GfSpConfig) *config.TomlConfig {
 	rpcAddress := cfg.Chain.ChainAddress[0]
@@ -326,7 +403,7 @@ func makeBlockSyncerConfig(cfg *gfspconfig.GfSpConfig) *config.TomlConfig {
 			Workers: int64(cfg.BlockSyncer.Workers),
 		},
 		Database: databaseconfig.Config{
-			Type:               "mysql",
+			Type:               blockSyncerDBType(cfg.BlockSyncer.Dialect),
 			DSN:                cfg.BlockSyncer.Dsn,
 			PartitionBatchSize: 10_000,
 			MaxIdleConnections: 10,
@@ -341,15 +418,17 @@ func makeBlockSyncerConfig(cfg *gfspconfig.GfSpConfig) *config.TomlConfig {
 	}
 }
 
-func newBackupBlockSyncerService(cfg *config.TomlConfig, mainDBIsMaster bool) (*BlockSyncerModular, error) {
+func newBackupBlockSyncerService(cfg *config.TomlConfig, mainDBIsMaster bool, recordDir, replayDir string) (*BlockSyncerModular, error) {
 	backUpConfig, err := generateConfigForBackup(cfg)
 	if err != nil {
 		return nil, err
 	}
 
 	BackupService = &BlockSyncerModular{
-		config: backUpConfig,
-		name:   BlockSyncerModularBackupName,
+		config:    backUpConfig,
+		name:      BlockSyncerModularBackupName,
+		recordDir: recordDir,
+		replayDir: replayDir,
 	}
 
 	if err = BackupService.initClient(); err != nil {
@@ -0,0 +1,463 @@
+package blocksyncer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	junodatabase "github.com/forbole/juno/v4/database"
+	databaseconfig "github.com/forbole/juno/v4/database/config"
+	"github.com/forbole/juno/v4/models"
+	"github.com/forbole/juno/v4/modules/bucket"
+	"github.com/forbole/juno/v4/modules/messages"
+	"github.com/forbole/juno/v4/modules/object"
+	"github.com/forbole/juno/v4/modules/payment"
+	sp "github.com/forbole/juno/v4/modules/storage_provider"
+	"github.com/forbole/juno/v4/parser"
+	"github.com/forbole/juno/v4/types"
+	"github.com/forbole/juno/v4/types/config"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	db "github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/database"
+	registrar "github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/modules"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/modules/prefixtree"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/store/bsdb"
+)
+
+var BlockSyncerModularReconcileName = strings.ToLower("BlockSyncerReconcile")
+
+// ReconcileShadowSchemaSuffix defines the suffix of the shadow schema name, the shadow schema
+// is in the same mysql server as the live schema, and is kept after reconcile for inspection.
+const ReconcileShadowSchemaSuffix = "_reconcile"
+
+// ReconcileMaxRetryCount defines the max retry count of a failed height, the reconcile fails
+// rather than retries the height forever as the block syncer does.
+const ReconcileMaxRetryCount = 10
+
+// reconcileTable defines the table that is reconciled, the rows of the shadow and the live
+// tables are matched by the keys, the auto increment id is not compared.
+type reconcileTable struct {
+	model schema.Tabler
+	keys  []string
+}
+
+// reconcileTables defines the tables of the modules that support reconcile, the modules whose
+// rows have no natural key to match, e.g. group and permission, are not supported.
+var reconcileTables = map[string][]*reconcileTable{
+	bucket.ModuleName: {{model: &models.Bucket{}, keys: []string{"bucket_id"}}},
+	object.ModuleName: {{model: &models.Object{}, keys: []string{"object_id"}}},
+	payment.ModuleName: {
+		{model: &models.StreamRecord{}, keys: []string{"account"}},
+		{model: &models.PaymentAccount{}, keys: []string{"addr"}},
+	},
+	sp.ModuleName:         {{model: &models.StorageProvider{}, keys: []string{"operator_address"}}},
+	prefixtree.ModuleName: {{model: &bsdb.SlashPrefixTreeNode{}, keys: []string{"bucket_name", "full_name", "is_object"}}},
+}
+
+// ReconcileOptions defines the options to reconcile the live tables.
+type ReconcileOptions struct {
+	// From is the first height to process again, the heights from it to the live epoch are
+	// processed.
+	From uint64
+	// Modules are the names of the modules to reconcile, e.g. object,prefix_tree.
+	Modules []string
+	// Apply corrects the live tables by the differences if it is true.
+	Apply bool
+}
+
+// ReconcileDiff defines the differences of a table between the shadow and the live schemas.
+type ReconcileDiff struct {
+	Table string
+	// Missing is the number of the rows that are only in the shadow table.
+	Missing int64
+	// Stale is the number of the rows that are only in the live table.
+	Stale int64
+	// Changed is the number of the rows that are in both tables but are different.
+	Changed int64
+}
+
+// CheckReconcileDialect returns error if the block syncer db is not mysql, the shadow schema
+// is created in the same mysql server as the live schema, and the statements quote the
+// identifiers in the mysql way.
+func CheckReconcileDialect(cfg *gfspconfig.GfSpConfig) error {
+	if dbType := blockSyncerDBType(cfg.BlockSyncer.Dialect); dbType != databaseconfig.MySQL {
+		return fmt.Errorf("reconcile only supports the mysql block syncer db, not %s", dbType)
+	}
+	return nil
+}
+
+// Reconcile processes the heights from From to the live epoch again into the shadow schema, and
+// returns the differences of the shadow and the live tables, which are applied to the live
+// tables if Apply is set.
+//
+// The BSDB keeps no history, so the shadow tables are seeded by the live tables, that is the
+// state as of the live epoch rather than the height before From, and the events of the heights
+// are applied again on it. Thus it always ends at the live epoch, otherwise the events after
+// it would be reverted by the corrections. It corrects the rows that the events write as a
+// whole, e.g. the missing or stale rows and the columns that the handlers set, but not the
+// corrupted columns that no event of the heights writes, these rows are not reported either.
+// An arbitrary height range can not be re-indexed without rebuilding the tables from the
+// genesis.
+func Reconcile(ctx context.Context, cfg *gfspconfig.GfSpConfig, opts *ReconcileOptions) ([]*ReconcileDiff, error) {
+	if err := CheckReconcileDialect(cfg); err != nil {
+		return nil, err
+	}
+	names, err := reconcileModuleNames(opts.Modules)
+	if err != nil {
+		return nil, err
+	}
+	live, err := newReconcileLiveService(ctx, cfg, names)
+	if err != nil {
+		return nil, err
+	}
+	liveDB := db.Cast(live.parserCtx.Database)
+	epoch, err := liveDB.GetEpoch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	to := uint64(epoch.BlockHeight)
+	if opts.From == 0 || opts.From > to {
+		return nil, fmt.Errorf("invalid reconcile start height %d, the live epoch is %d", opts.From, to)
+	}
+
+	dsn, err := mysqldriver.ParseDSN(config.Cfg.Database.DSN)
+	if err != nil {
+		return nil, err
+	}
+	liveSchema := dsn.DBName
+	shadowSchema := liveSchema + ReconcileShadowSchemaSuffix
+	shadowDB, err := openReconcileShadowDB(ctx, live, dsn, shadowSchema)
+	if err != nil {
+		return nil, err
+	}
+	defer shadowDB.Close()
+
+	var tables []*reconcileTable
+	for _, name := range names {
+		tables = append(tables, reconcileTables[name]...)
+	}
+	if err = seedReconcileShadowDB(ctx, shadowDB, tables, liveSchema, opts.From-1); err != nil {
+		return nil, err
+	}
+	log.Infow("process heights into shadow schema to reconcile", "schema", shadowSchema, "from", opts.From,
+		"to", to, "modules", names)
+	if err = live.reconcileRange(ctx, shadowDB, names, opts.From, to); err != nil {
+		return nil, err
+	}
+
+	var diffs []*ReconcileDiff
+	for _, table := range tables {
+		diff, err := table.diff(ctx, liveDB.Db, liveSchema, shadowSchema)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	if !opts.Apply {
+		return diffs, nil
+	}
+	current, err := liveDB.GetEpoch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current.BlockHeight != epoch.BlockHeight {
+		return nil, fmt.Errorf("live epoch moves from %d to %d during reconcile, stop the block syncer before applying",
+			epoch.BlockHeight, current.BlockHeight)
+	}
+	err = liveDB.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			if err := table.apply(tx, liveSchema, shadowSchema); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorw("failed to apply reconcile corrections", "error", err)
+		return nil, err
+	}
+	return diffs, nil
+}
+
+// reconcileModuleNames checks the modules support reconcile, the underscores of the names are
+// optional, e.g. both prefixtree and prefix_tree are accepted.
+func reconcileModuleNames(modules []string) ([]string, error) {
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no module to reconcile")
+	}
+	var names []string
+	for _, module := range modules {
+		found := false
+		for name := range reconcileTables {
+			if strings.ReplaceAll(name, "_", "") == strings.ReplaceAll(strings.TrimSpace(module), "_", "") {
+				names = append(names, name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("module %s does not support reconcile", module)
+		}
+	}
+	return names, nil
+}
+
+// newReconcileLiveService initializes the block syncer of the live schema, the live schema is
+// the current master of the main and the backup databases.
+func newReconcileLiveService(ctx context.Context, cfg *gfspconfig.GfSpConfig, names []string) (*BlockSyncerModular, error) {
+	junoCfg := makeBlockSyncerConfig(cfg)
+	junoCfg.Chain.Modules = names
+	live := &BlockSyncerModular{
+		config:    junoCfg,
+		name:      BlockSyncerModularName,
+		replayDir: cfg.BlockSyncer.ReplayDir,
+	}
+	if err := live.initClient(); err != nil {
+		return nil, err
+	}
+	FlagDB = db.Cast(live.parserCtx.Database)
+	master, err := FlagDB.GetMasterDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the main database is the master if the master flag is not recorded yet
+	if !master.OneRowId || master.IsMaster {
+		return live, nil
+	}
+	backupCfg, err := generateConfigForBackup(junoCfg)
+	if err != nil {
+		return nil, err
+	}
+	live = &BlockSyncerModular{
+		config:    backupCfg,
+		name:      BlockSyncerModularBackupName,
+		replayDir: cfg.BlockSyncer.ReplayDir,
+	}
+	if err = live.initClient(); err != nil {
+		return nil, err
+	}
+	return live, nil
+}
+
+// openReconcileShadowDB creates the shadow schema if it does not exist and connects to it.
+func openReconcileShadowDB(ctx context.Context, live *BlockSyncerModular, dsn *mysqldriver.Config, shadowSchema string) (*db.DB, error) {
+	liveDB := db.Cast(live.parserCtx.Database)
+	if err := liveDB.Db.WithContext(ctx).Exec("CREATE DATABASE IF NOT EXISTS " + quoteIdent(shadowSchema)).Error; err != nil {
+		log.Errorw("failed to create shadow schema", "schema", shadowSchema, "error", err)
+		return nil, err
+	}
+	shadowDSN := *dsn
+	shadowDSN.DBName = shadowSchema
+	shadowCfg := config.Cfg.Database
+	shadowCfg.DSN = shadowDSN.FormatDSN()
+	shadowDB, err := db.BlockSyncerDBBuilder(junodatabase.NewContext(shadowCfg, live.parserCtx.EncodingConfig))
+	if err != nil {
+		return nil, err
+	}
+	return db.Cast(shadowDB), nil
+}
+
+// seedReconcileShadowDB recreates the shadow tables by the live tables, and sets the epoch to
+// the height before the range.
+func seedReconcileShadowDB(ctx context.Context, shadowDB *db.DB, tables []*reconcileTable, liveSchema string, height uint64) error {
+	tablers := []schema.Tabler{&models.Epoch{}}
+	for _, table := range tables {
+		tablers = append(tablers, table.model)
+	}
+	if err := shadowDB.RecreateTables(ctx, tablers); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		name := table.model.TableName()
+		if err := shadowDB.Db.WithContext(ctx).Exec(fmt.Sprintf("INSERT INTO %s SELECT * FROM %s.%s",
+			quoteIdent(name), quoteIdent(liveSchema), quoteIdent(name))).Error; err != nil {
+			log.Errorw("failed to seed shadow table", "table", name, "error", err)
+			return err
+		}
+	}
+	return shadowDB.SaveEpoch(ctx, &models.Epoch{
+		OneRowId:    true,
+		BlockHeight: int64(height),
+	})
+}
+
+// reconcileRange processes the height range into the shadow schema by the parser worker, the
+// block data is prefetched by quickFetchBlockData as the block syncer does.
+func (b *BlockSyncerModular) reconcileRange(ctx context.Context, shadowDB *db.DB, names []string, from, to uint64) error {
+	builder := makeModulesBuilder(b.parserCtx, registrar.NewBlockSyncerRegistrar(messages.CosmosMessageAddressesParser), names)
+	shadowCtx := parser.NewContext(b.parserCtx.EncodingConfig, b.parserCtx.Node, shadowDB, builder(shadowDB), nil)
+	reconcileer := &BlockSyncerModular{
+		config:    b.config,
+		name:      BlockSyncerModularReconcileName,
+		parserCtx: shadowCtx,
+	}
+	shadowCtx.Indexer = NewIndexer(shadowCtx.EncodingConfig.Marshaler,
+		shadowCtx.Node,
+		shadowCtx.Database,
+		shadowCtx.Modules,
+		builder,
+		reconcileer.Name())
+
+	blockMap = new(sync.Map)
+	eventMap = new(sync.Map)
+	txMap = new(sync.Map)
+	Cast(shadowCtx.Indexer).GetLatestBlockHeight().Store(int64(to))
+	Cast(shadowCtx.Indexer).GetCatchUpFlag().Store(int64(-1))
+	go reconcileer.quickFetchBlockData(from)
+
+	worker := parser.NewWorker(shadowCtx, types.NewQueue(0), 0, false)
+	worker.SetIndexer(shadowCtx.Indexer)
+	for height := from; height <= to; height++ {
+		if err := reconcileHeight(ctx, worker, height); err != nil {
+			return err
+		}
+	}
+
+	epoch, err := shadowDB.GetEpoch(ctx)
+	if err != nil {
+		return err
+	}
+	if epoch.BlockHeight != int64(to) {
+		return fmt.Errorf("reconcile is interrupted at height %d", epoch.BlockHeight)
+	}
+	return nil
+}
+
+// reconcileHeight processes the height by the parser worker, and retries the failed height at
+// most ReconcileMaxRetryCount times.
+func reconcileHeight(ctx context.Context, worker *parser.Worker, height uint64) error {
+	var err error
+	for retryCount := 0; retryCount < ReconcileMaxRetryCount; retryCount++ {
+		if err = worker.ProcessIfNotExists(height); err == nil {
+			return nil
+		}
+		log.Errorw("failed to reconcile block", "height", height, "error", err,
+			"retry interval", config.GetAvgBlockTime(), "retry count", retryCount)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(config.GetAvgBlockTime()):
+		}
+	}
+	return fmt.Errorf("failed to reconcile block at height %d: %w", height, err)
+}
+
+// diff counts the differences of the shadow and the live tables.
+func (t *reconcileTable) diff(ctx context.Context, conn *gorm.DB, liveSchema, shadowSchema string) (*ReconcileDiff, error) {
+	cols, err := t.columns()
+	if err != nil {
+		return nil, err
+	}
+	live, shadow := t.qualifiedName(liveSchema), t.qualifiedName(shadowSchema)
+	diff := &ReconcileDiff{Table: t.model.TableName()}
+	queries := []struct {
+		count *int64
+		query string
+	}{
+		{&diff.Missing, fmt.Sprintf("SELECT COUNT(*) FROM %s AS s LEFT JOIN %s AS l ON %s WHERE l.%s IS NULL",
+			shadow, live, t.joinOn("l"), quoteIdent(t.keys[0]))},
+		{&diff.Stale, fmt.Sprintf("SELECT COUNT(*) FROM %s AS l LEFT JOIN %s AS s ON %s WHERE s.%s IS NULL",
+			live, shadow, t.joinOn("l"), quoteIdent(t.keys[0]))},
+		{&diff.Changed, fmt.Sprintf("SELECT COUNT(*) FROM %s AS s JOIN %s AS l ON %s WHERE NOT (%s)",
+			shadow, live, t.joinOn("l"), equalColumns(cols, "l"))},
+	}
+	for _, q := range queries {
+		if err = conn.WithContext(ctx).Raw(q.query).Scan(q.count).Error; err != nil {
+			log.Errorw("failed to diff reconcile table", "table", diff.Table, "error", err)
+			return nil, err
+		}
+	}
+	return diff, nil
+}
+
+// apply corrects the live table by the shadow table, the stale rows are deleted, the changed
+// rows are updated in place to keep the ids, and the missing rows are inserted. The live rows
+// are referred by the qualified table name rather than an alias in the correlated subqueries,
+// so the statements are not bound to the multi-table syntax of a dialect.
+func (t *reconcileTable) apply(tx *gorm.DB, liveSchema, shadowSchema string) error {
+	cols, err := t.columns()
+	if err != nil {
+		return err
+	}
+	live, shadow := t.qualifiedName(liveSchema), t.qualifiedName(shadowSchema)
+	sets := make([]string, 0, len(cols))
+	selects := make([]string, 0, len(cols))
+	for _, col := range cols {
+		sets = append(sets, fmt.Sprintf("%s = (SELECT s.%s FROM %s AS s WHERE %s)",
+			quoteIdent(col), quoteIdent(col), shadow, t.joinOn(live)))
+		selects = append(selects, "s."+quoteIdent(col))
+	}
+	statements := []string{
+		fmt.Sprintf("DELETE FROM %s WHERE NOT EXISTS (SELECT 1 FROM %s AS s WHERE %s)",
+			live, shadow, t.joinOn(live)),
+		fmt.Sprintf("UPDATE %s SET %s WHERE EXISTS (SELECT 1 FROM %s AS s WHERE %s AND NOT (%s))",
+			live, strings.Join(sets, ", "), shadow, t.joinOn(live), equalColumns(cols, live)),
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s AS s LEFT JOIN %s AS l ON %s WHERE l.%s IS NULL",
+			live, quoteIdents(cols), strings.Join(selects, ", "), shadow, live, t.joinOn("l"), quoteIdent(t.keys[0])),
+	}
+	for _, statement := range statements {
+		if err = tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columns returns the columns of the table except the auto increment id.
+func (t *reconcileTable) columns() ([]string, error) {
+	s, err := schema.Parse(t.model, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]string, 0, len(s.DBNames))
+	for _, name := range s.DBNames {
+		if name != "id" {
+			cols = append(cols, name)
+		}
+	}
+	return cols, nil
+}
+
+func (t *reconcileTable) qualifiedName(schemaName string) string {
+	return quoteIdent(schemaName) + "." + quoteIdent(t.model.TableName())
+}
+
+// joinOn matches the shadow rows aliased by s and the live rows referred by live by the keys.
+func (t *reconcileTable) joinOn(live string) string {
+	conditions := make([]string, 0, len(t.keys))
+	for _, key := range t.keys {
+		conditions = append(conditions, fmt.Sprintf("s.%s = %s.%s", quoteIdent(key), live, quoteIdent(key)))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// equalColumns compares the columns of the shadow and the live rows, the null values are
+// equal, and the comparison is never null, so its negation matches the changed rows.
+func equalColumns(cols []string, live string) string {
+	conditions := make([]string, 0, len(cols))
+	for _, col := range cols {
+		s, l := "s."+quoteIdent(col), live+"."+quoteIdent(col)
+		conditions = append(conditions, fmt.Sprintf("COALESCE(%s = %s, %s IS NULL AND %s IS NULL)", s, l, s, l))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// quoteIdent quotes the identifier by backticks, which is accepted by mysql and sqlite but not
+// postgres, see CheckReconcileDialect.
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func quoteIdents(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, quoteIdent(name))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,117 @@
+package blocksyncer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/blocksyncer/modules/prefixtree"
+	"github.com/bnb-chain/greenfield-storage-provider/store/bsdb"
+)
+
+func TestReconcileModuleNames(t *testing.T) {
+	names, err := reconcileModuleNames([]string{"object", "prefixtree"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"object", prefixtree.ModuleName}, names)
+
+	_, err = reconcileModuleNames([]string{"object", "group"})
+	assert.Error(t, err)
+	_, err = reconcileModuleNames(nil)
+	assert.Error(t, err)
+}
+
+func TestCheckReconcileDialect(t *testing.T) {
+	for dialect, supported := range map[string]bool{"": true, "mysql": true, "postgres": false, "sqlite": false} {
+		err := CheckReconcileDialect(&gfspconfig.GfSpConfig{BlockSyncer: gfspconfig.BlockSyncerConfig{Dialect: dialect}})
+		assert.Equal(t, supported, err == nil, dialect)
+	}
+}
+
+func TestReconcileTable_Columns(t *testing.T) {
+	table := reconcileTables[prefixtree.ModuleName][0]
+	cols, err := table.columns()
+	require.NoError(t, err)
+	assert.NotContains(t, cols, "id")
+	assert.Contains(t, cols, "full_name")
+	assert.Equal(t, "s.`bucket_name` = l.`bucket_name` AND s.`full_name` = l.`full_name` AND s.`is_object` = l.`is_object`",
+		table.joinOn("l"))
+	assert.Equal(t, "`live`.`slash_prefix_tree_nodes`", table.qualifiedName("live"))
+}
+
+// makeTestReconcileDB opens the live db as the main schema and attaches the shadow db as the
+// shadow schema, the prefix tree table is created in both schemas.
+func makeTestReconcileDB(t *testing.T) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "live.db")), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := gormDB.DB()
+	require.NoError(t, err)
+	// the attached schema is only visible to the connection that attaches it
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, gormDB.Exec("ATTACH DATABASE ? AS shadow", filepath.Join(t.TempDir(), "shadow.db")).Error)
+	for _, schemaName := range []string{"main", "shadow"} {
+		require.NoError(t, gormDB.Exec(`CREATE TABLE `+schemaName+`.`+bsdb.PrefixTreeTableName+` (id INTEGER PRIMARY KEY AUTOINCREMENT,
+			path_name TEXT, full_name TEXT, name TEXT, is_object BOOLEAN, is_folder BOOLEAN, bucket_name TEXT,
+			object_id BLOB, object_name TEXT, object_count BIGINT DEFAULT 0, total_size BIGINT DEFAULT 0)`).Error)
+	}
+	return gormDB
+}
+
+func TestReconcileTable_DiffApply(t *testing.T) {
+	gormDB := makeTestReconcileDB(t)
+	table := reconcileTables[prefixtree.ModuleName][0]
+	// the schema of the table name is ignored by gorm when the model has a table name
+	insertNode := func(schemaName, name string, size int64) {
+		require.NoError(t, gormDB.Exec("INSERT INTO "+schemaName+"."+bsdb.PrefixTreeTableName+
+			" (path_name, full_name, name, is_object, bucket_name, object_name, object_count, total_size)"+
+			" VALUES ('/', ?, ?, TRUE, ?, ?, 1, ?)", name, name, testBucketName, name, size).Error)
+	}
+	insertNode("main", "same.txt", 1)
+	insertNode("main", "stale.txt", 2)
+	insertNode("main", "changed.txt", 3)
+	insertNode("main", "null.txt", 4)
+	insertNode("shadow", "missing.txt", 5)
+	insertNode("shadow", "null.txt", 4)
+	insertNode("shadow", "changed.txt", 30)
+	insertNode("shadow", "same.txt", 1)
+	// the null column of the live row differs from the value of the shadow row
+	require.NoError(t, gormDB.Exec("UPDATE main."+bsdb.PrefixTreeTableName+
+		" SET object_name = NULL WHERE full_name = ?", "null.txt").Error)
+
+	diff, err := table.diff(context.Background(), gormDB, "main", "shadow")
+	require.NoError(t, err)
+	assert.Equal(t, &ReconcileDiff{Table: bsdb.PrefixTreeTableName, Missing: 1, Stale: 1, Changed: 2}, diff)
+
+	liveNodes := func() map[string]*bsdb.SlashPrefixTreeNode {
+		var nodes []*bsdb.SlashPrefixTreeNode
+		require.NoError(t, gormDB.Raw("SELECT * FROM main."+bsdb.PrefixTreeTableName).Scan(&nodes).Error)
+		byName := make(map[string]*bsdb.SlashPrefixTreeNode)
+		for _, n := range nodes {
+			byName[n.FullName] = n
+		}
+		return byName
+	}
+	before := liveNodes()
+	require.NoError(t, gormDB.Transaction(func(tx *gorm.DB) error {
+		return table.apply(tx, "main", "shadow")
+	}))
+	diff, err = table.diff(context.Background(), gormDB, "main", "shadow")
+	require.NoError(t, err)
+	assert.Equal(t, &ReconcileDiff{Table: bsdb.PrefixTreeTableName}, diff)
+
+	after := liveNodes()
+	require.Len(t, after, 4)
+	assert.NotContains(t, after, "stale.txt")
+	assert.Equal(t, int64(5), after["missing.txt"].TotalSize)
+	assert.Equal(t, int64(30), after["changed.txt"].TotalSize)
+	assert.Equal(t, "null.txt", after["null.txt"].ObjectName)
+	// the changed rows are updated in place
+	for _, name := range []string{"same.txt", "changed.txt", "null.txt"} {
+		assert.Equal(t, before[name].ID, after[name].ID, name)
+	}
+}
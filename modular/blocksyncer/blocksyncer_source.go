@@ -0,0 +1,473 @@
+package blocksyncer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/forbole/juno/v4/node"
+	"github.com/forbole/juno/v4/types"
+
+	"github.com/bnb-chain/greenfield-storage-provider/model/errors"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+// BlockSource defines the source of the block data that the block syncer indexes, the
+// node of the chain is the default block source.
+type BlockSource interface {
+	// ChainID returns the chain id of the block data.
+	ChainID() (string, error)
+	// LatestHeight returns the latest block height that the block source can serve.
+	LatestHeight() (int64, error)
+	// Block returns the block by height.
+	Block(height int64) (*coretypes.ResultBlock, error)
+	// Txs returns the transactions of the block.
+	Txs(block *coretypes.ResultBlock) ([]*types.Tx, error)
+	// BlockResults returns the results of the block by height.
+	BlockResults(height int64) (*coretypes.ResultBlockResults, error)
+}
+
+var _ BlockSource = (node.Node)(nil)
+
+// ArchiveShardSize defines the number of heights whose block data is in the same directory
+// of the archive, so no directory grows with the archive.
+const ArchiveShardSize = 10000
+
+const (
+	blockFileSuffix   = ".block.json.gz"
+	resultsFileSuffix = ".results.json.gz"
+	txsFileSuffix     = ".txs.json.gz"
+	// chainIDFile defines the file of the chain id that the archive is recorded from
+	chainIDFile = "chain_id"
+	// latestHeightFile defines the file of the highest height that the block data of it and
+	// of all the heights below it since the recording starts are recorded
+	latestHeightFile = "latest_height"
+)
+
+// archiveFile returns the file path of the block data in the archive, every height has a
+// block file, a results file and a txs file in the shard directory of the height.
+func archiveFile(dir string, height int64, suffix string) string {
+	return filepath.Join(dir, strconv.FormatInt(height/ArchiveShardSize, 10), strconv.FormatInt(height, 10)+suffix)
+}
+
+// recordedPart defines the recorded block data of a height, a height is recorded when all
+// the parts are recorded.
+type recordedPart uint8
+
+const (
+	recordedBlock recordedPart = 1 << iota
+	recordedResults
+	recordedTxs
+	recordedAll = recordedBlock | recordedResults | recordedTxs
+)
+
+// txRecord defines the recorded transaction, the transaction and the response are encoded
+// in protobuf, so the messages can be decoded without the interface registry.
+type txRecord struct {
+	Tx         []byte `json:"tx"`
+	TxResponse []byte `json:"tx_response"`
+}
+
+var _ node.Node = &blockSourceNode{}
+
+// blockSourceNode serves the block data from the block source, and the rest from the node.
+type blockSourceNode struct {
+	node.Node
+	source BlockSource
+}
+
+// NewBlockSourceNode returns the node that serves the block data from the block source.
+func NewBlockSourceNode(proxy node.Node, source BlockSource) node.Node {
+	return &blockSourceNode{
+		Node:   proxy,
+		source: source,
+	}
+}
+
+// ChainID returns the chain id of the block source.
+func (n *blockSourceNode) ChainID() (string, error) {
+	return n.source.ChainID()
+}
+
+// LatestHeight returns the latest block height of the block source.
+func (n *blockSourceNode) LatestHeight() (int64, error) {
+	return n.source.LatestHeight()
+}
+
+// Block returns the block from the block source.
+func (n *blockSourceNode) Block(height int64) (*coretypes.ResultBlock, error) {
+	return n.source.Block(height)
+}
+
+// Txs returns the transactions of the block from the block source.
+func (n *blockSourceNode) Txs(block *coretypes.ResultBlock) ([]*types.Tx, error) {
+	return n.source.Txs(block)
+}
+
+// BlockResults returns the results of the block from the block source.
+func (n *blockSourceNode) BlockResults(height int64) (*coretypes.ResultBlockResults, error) {
+	return n.source.BlockResults(height)
+}
+
+var _ BlockSource = &RecordBlockSource{}
+
+// RecordBlockSource records the block data fetched from the block source to the compressed
+// files of the archive directory, the fetch fails if the block data fails to be recorded, so
+// the retried fetch records it again. The block data is fetched concurrently and out of order,
+// so the recorder tracks the recorded heights and advances the latest height file only over
+// the heights without gaps, which is the height that the replayer serves up to. The recording
+// should resume from the latest height, the heights recorded above a gap are not served until
+// the gap is recorded.
+type RecordBlockSource struct {
+	source BlockSource
+	dir    string
+
+	mux sync.Mutex
+	// latest is the highest height that the heights since the recording starts are recorded,
+	// it is 0 before the first height is recorded
+	latest int64
+	// parts are the recorded parts of the heights that are not counted in latest
+	parts map[int64]recordedPart
+}
+
+// NewRecordBlockSource returns an instance of RecordBlockSource, the chain id of the block
+// source is recorded, and the archive recorded from another chain is refused.
+func NewRecordBlockSource(source BlockSource, dir string) (*RecordBlockSource, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	chainID, err := source.ChainID()
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := readChainIDFile(dir)
+	switch {
+	case err == nil && recorded != chainID:
+		return nil, fmt.Errorf("archive %s is recorded from chain %s, not %s", dir, recorded, chainID)
+	case err != nil && !os.IsNotExist(err):
+		return nil, err
+	case err != nil:
+		if err = writeFileAtomic(filepath.Join(dir, chainIDFile), []byte(chainID)); err != nil {
+			return nil, err
+		}
+	}
+	latest, err := readLatestHeightFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordBlockSource{
+		source: source,
+		dir:    dir,
+		latest: latest,
+		parts:  make(map[int64]recordedPart),
+	}, nil
+}
+
+// recorded marks the part of the height recorded, and advances the latest height over the
+// recorded heights. The first recorded height starts the recording of a new archive.
+func (r *RecordBlockSource) recorded(height int64, part recordedPart) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if height <= r.latest {
+		return nil
+	}
+	r.parts[height] |= part
+	if r.parts[height] != recordedAll {
+		return nil
+	}
+	base := r.latest
+	if base == 0 {
+		base = height - 1
+	}
+	latest := base
+	for r.parts[latest+1] == recordedAll {
+		latest++
+	}
+	if latest == base {
+		return nil
+	}
+	if err := writeFileAtomic(filepath.Join(r.dir, latestHeightFile), []byte(strconv.FormatInt(latest, 10))); err != nil {
+		return err
+	}
+	for h := base + 1; h <= latest; h++ {
+		delete(r.parts, h)
+	}
+	r.latest = latest
+	return nil
+}
+
+// ChainID returns the chain id of the block source.
+func (r *RecordBlockSource) ChainID() (string, error) {
+	return r.source.ChainID()
+}
+
+// LatestHeight returns the latest block height of the block source.
+func (r *RecordBlockSource) LatestHeight() (int64, error) {
+	return r.source.LatestHeight()
+}
+
+// Block fetches the block from the block source and records it.
+func (r *RecordBlockSource) Block(height int64) (*coretypes.ResultBlock, error) {
+	block, err := r.source.Block(height)
+	if err != nil {
+		return nil, err
+	}
+	data, err := cmtjson.Marshal(block)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeArchiveFile(archiveFile(r.dir, height, blockFileSuffix), data); err != nil {
+		log.Errorw("failed to record block", "height", height, "error", err)
+		return nil, err
+	}
+	if err = r.recorded(height, recordedBlock); err != nil {
+		log.Errorw("failed to record latest height", "height", height, "error", err)
+		return nil, err
+	}
+	return block, nil
+}
+
+// Txs fetches the transactions of the block from the block source and records them.
+func (r *RecordBlockSource) Txs(block *coretypes.ResultBlock) ([]*types.Tx, error) {
+	txs, err := r.source.Txs(block)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*txRecord, 0, len(txs))
+	for _, t := range txs {
+		record := &txRecord{}
+		if t.Tx != nil {
+			if record.Tx, err = t.Tx.Marshal(); err != nil {
+				return nil, err
+			}
+		}
+		if t.TxResponse != nil {
+			if record.TxResponse, err = t.TxResponse.Marshal(); err != nil {
+				return nil, err
+			}
+		}
+		records = append(records, record)
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeArchiveFile(archiveFile(r.dir, block.Block.Height, txsFileSuffix), data); err != nil {
+		log.Errorw("failed to record txs", "height", block.Block.Height, "error", err)
+		return nil, err
+	}
+	if err = r.recorded(block.Block.Height, recordedTxs); err != nil {
+		log.Errorw("failed to record latest height", "height", block.Block.Height, "error", err)
+		return nil, err
+	}
+	return txs, nil
+}
+
+// BlockResults fetches the results of the block from the block source and records them.
+func (r *RecordBlockSource) BlockResults(height int64) (*coretypes.ResultBlockResults, error) {
+	results, err := r.source.BlockResults(height)
+	if err != nil {
+		return nil, err
+	}
+	data, err := cmtjson.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeArchiveFile(archiveFile(r.dir, height, resultsFileSuffix), data); err != nil {
+		log.Errorw("failed to record block results", "height", height, "error", err)
+		return nil, err
+	}
+	if err = r.recorded(height, recordedResults); err != nil {
+		log.Errorw("failed to record latest height", "height", height, "error", err)
+		return nil, err
+	}
+	return results, nil
+}
+
+var _ BlockSource = &ReplayBlockSource{}
+
+// ReplayBlockSource replays the block data recorded by RecordBlockSource, it does not
+// depend on the chain, so the BSDB can be rebuilt from the archive.
+type ReplayBlockSource struct {
+	dir     string
+	chainID string
+}
+
+// NewReplayBlockSource returns an instance of ReplayBlockSource.
+func NewReplayBlockSource(dir string) (*ReplayBlockSource, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("replay archive %s is not a directory", dir)
+	}
+	chainID, err := readChainIDFile(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the chain id of replay archive %s: %w", dir, err)
+	}
+	return &ReplayBlockSource{dir: dir, chainID: chainID}, nil
+}
+
+// ChainID returns the chain id that the archive is recorded from, rather than the chain id
+// of the configured node.
+func (r *ReplayBlockSource) ChainID() (string, error) {
+	return r.chainID, nil
+}
+
+// LatestHeight returns the latest height recorded by the recorder, the heights up to it are
+// recorded without gaps. The latest height file is read every time, so the blocks recorded
+// meanwhile can be replayed.
+func (r *ReplayBlockSource) LatestHeight() (int64, error) {
+	return readLatestHeightFile(r.dir)
+}
+
+// Block returns the recorded block.
+func (r *ReplayBlockSource) Block(height int64) (*coretypes.ResultBlock, error) {
+	data, err := readArchiveFile(archiveFile(r.dir, height, blockFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	block := &coretypes.ResultBlock{}
+	if err = cmtjson.Unmarshal(data, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// Txs returns the recorded transactions of the block.
+func (r *ReplayBlockSource) Txs(block *coretypes.ResultBlock) ([]*types.Tx, error) {
+	data, err := readArchiveFile(archiveFile(r.dir, block.Block.Height, txsFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	var records []*txRecord
+	if err = json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	txs := make([]*types.Tx, 0, len(records))
+	for _, record := range records {
+		t := &tx.Tx{}
+		if err = t.Unmarshal(record.Tx); err != nil {
+			return nil, err
+		}
+		txResponse := &sdk.TxResponse{}
+		if err = txResponse.Unmarshal(record.TxResponse); err != nil {
+			return nil, err
+		}
+		var convTx *types.Tx
+		if convTx, err = types.NewTx(txResponse, t); err != nil {
+			return nil, err
+		}
+		txs = append(txs, convTx)
+	}
+	return txs, nil
+}
+
+// BlockResults returns the recorded results of the block.
+func (r *ReplayBlockSource) BlockResults(height int64) (*coretypes.ResultBlockResults, error) {
+	data, err := readArchiveFile(archiveFile(r.dir, height, resultsFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	results := &coretypes.ResultBlockResults{}
+	if err = cmtjson.Unmarshal(data, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// writeArchiveFile compresses the data and writes it to the archive file atomically.
+func writeArchiveFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// writeFileAtomic writes the data to a unique temporary file of the same directory and
+// renames it to the path, so the replayer never reads a partially written file, and the
+// concurrent writers of the same height never write the same temporary file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func readChainIDFile(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, chainIDFile))
+	if err != nil {
+		return "", err
+	}
+	chainID := strings.TrimSpace(string(data))
+	if chainID == "" {
+		return "", fmt.Errorf("empty chain id file in archive %s", dir)
+	}
+	return chainID, nil
+}
+
+// readLatestHeightFile returns the latest recorded height of the archive, it returns 0 if no
+// height is recorded.
+func readLatestHeightFile(dir string) (int64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, latestHeightFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func readArchiveFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", errors.ErrBlockNotRecorded, filepath.Base(path))
+		}
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
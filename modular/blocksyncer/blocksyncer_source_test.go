@@ -0,0 +1,195 @@
+package blocksyncer
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/forbole/juno/v4/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-storage-provider/model/errors"
+)
+
+// memBlockSource serves the block data from memory as a chain does.
+type memBlockSource struct {
+	chainID string
+	blocks  map[int64]*coretypes.ResultBlock
+	results map[int64]*coretypes.ResultBlockResults
+	txs     map[int64][]*types.Tx
+}
+
+func (m *memBlockSource) ChainID() (string, error) {
+	return m.chainID, nil
+}
+
+func (m *memBlockSource) LatestHeight() (int64, error) {
+	return int64(len(m.blocks)), nil
+}
+
+func (m *memBlockSource) Block(height int64) (*coretypes.ResultBlock, error) {
+	return m.blocks[height], nil
+}
+
+func (m *memBlockSource) Txs(block *coretypes.ResultBlock) ([]*types.Tx, error) {
+	return m.txs[block.Block.Height], nil
+}
+
+func (m *memBlockSource) BlockResults(height int64) (*coretypes.ResultBlockResults, error) {
+	return m.results[height], nil
+}
+
+func makeTestBlockSource(t *testing.T) *memBlockSource {
+	source := &memBlockSource{
+		chainID: "greenfield_9000-121",
+		blocks:  make(map[int64]*coretypes.ResultBlock),
+		results: make(map[int64]*coretypes.ResultBlockResults),
+		txs:     make(map[int64][]*types.Tx),
+	}
+	source.blocks[1], source.results[1] = makeTestBlock(t, 1, createObjectEvent(1, "dir/a.txt"))
+	source.blocks[2], source.results[2] = makeTestBlock(t, 2)
+	event, err := sdk.TypedEventToEvent(createObjectEvent(2, "dir/b.txt"))
+	require.NoError(t, err)
+	source.txs[2] = []*types.Tx{{
+		Tx:         &tx.Tx{Body: &tx.TxBody{Memo: "create object"}},
+		TxResponse: &sdk.TxResponse{Height: 2, TxHash: "ABCD", Events: []abci.Event{abci.Event(event)}},
+	}}
+	return source
+}
+
+func TestBlockSource_RecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	source := makeTestBlockSource(t)
+	recorder, err := NewRecordBlockSource(source, dir)
+	require.NoError(t, err)
+	for height := int64(1); height <= 2; height++ {
+		block, err := recorder.Block(height)
+		require.NoError(t, err)
+		_, err = recorder.Txs(block)
+		require.NoError(t, err)
+		_, err = recorder.BlockResults(height)
+		require.NoError(t, err)
+	}
+
+	replayer, err := NewReplayBlockSource(dir)
+	require.NoError(t, err)
+	chainID, err := replayer.ChainID()
+	require.NoError(t, err)
+	assert.Equal(t, source.chainID, chainID)
+	latest, err := replayer.LatestHeight()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), latest)
+
+	block, err := replayer.Block(2)
+	require.NoError(t, err)
+	assert.Equal(t, source.blocks[2].Block.Height, block.Block.Height)
+	assert.Equal(t, source.blocks[2].BlockID.Hash, block.BlockID.Hash)
+	results, err := replayer.BlockResults(1)
+	require.NoError(t, err)
+	assert.Equal(t, source.results[1].EndBlockEvents, results.EndBlockEvents)
+	txs, err := replayer.Txs(block)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, "ABCD", txs[0].TxHash)
+	assert.Equal(t, "create object", txs[0].Body.Memo)
+	assert.Equal(t, source.txs[2][0].Events, txs[0].Events)
+
+	_, err = replayer.Block(3)
+	assert.ErrorIs(t, err, errors.ErrBlockNotRecorded)
+
+	// the block data is in the shard directory of the height
+	assert.FileExists(t, filepath.Join(dir, "0", "2"+blockFileSuffix))
+	require.NoError(t, filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+		assert.False(t, strings.HasSuffix(path, ".tmp"), path)
+		return err
+	}))
+}
+
+func TestBlockSource_LatestHeight(t *testing.T) {
+	dir := t.TempDir()
+	source := makeTestBlockSource(t)
+	source.blocks[3], source.results[3] = makeTestBlock(t, 3)
+	source.blocks[4], source.results[4] = makeTestBlock(t, 4)
+	recorder, err := NewRecordBlockSource(source, dir)
+	require.NoError(t, err)
+	replayer, err := NewReplayBlockSource(dir)
+	require.NoError(t, err)
+	assertLatest := func(expected int64) {
+		latest, err := replayer.LatestHeight()
+		require.NoError(t, err)
+		assert.Equal(t, expected, latest)
+	}
+	record := func(recorder *RecordBlockSource, height int64) {
+		block, err := recorder.Block(height)
+		require.NoError(t, err)
+		_, err = recorder.Txs(block)
+		require.NoError(t, err)
+		_, err = recorder.BlockResults(height)
+		require.NoError(t, err)
+	}
+	assertLatest(0)
+
+	// the heights above the gap are not served until the gap is recorded
+	record(recorder, 1)
+	record(recorder, 3)
+	assertLatest(1)
+	block, err := recorder.Block(2)
+	require.NoError(t, err)
+	_, err = recorder.Txs(block)
+	require.NoError(t, err)
+	assertLatest(1)
+	_, err = recorder.BlockResults(2)
+	require.NoError(t, err)
+	assertLatest(3)
+
+	// the recorder of the archive resumes from the latest height
+	recorder, err = NewRecordBlockSource(source, dir)
+	require.NoError(t, err)
+	record(recorder, 3)
+	record(recorder, 4)
+	assertLatest(4)
+}
+
+func TestBlockSource_ChainID(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewReplayBlockSource(dir)
+	assert.Error(t, err)
+
+	source := makeTestBlockSource(t)
+	_, err = NewRecordBlockSource(source, dir)
+	require.NoError(t, err)
+	_, err = NewRecordBlockSource(source, dir)
+	assert.NoError(t, err)
+	_, err = NewRecordBlockSource(&memBlockSource{chainID: "greenfield_5600-1"}, dir)
+	assert.Error(t, err)
+}
+
+func TestBlockSource_ReplayIndex(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecordBlockSource(makeTestBlockSource(t), dir)
+	require.NoError(t, err)
+	replayer, err := NewReplayBlockSource(dir)
+	require.NoError(t, err)
+	indexer, gormDB := makeTestIndexer(t, &killModule{})
+
+	for _, source := range []BlockSource{recorder, replayer} {
+		for height := int64(1); height <= 2; height++ {
+			block, err := source.Block(height)
+			require.NoError(t, err)
+			txs, err := source.Txs(block)
+			require.NoError(t, err)
+			results, err := source.BlockResults(height)
+			require.NoError(t, err)
+			require.NoError(t, indexer.exportBlock(context.Background(), block, results, txs))
+		}
+		assert.Equal(t, []string{"dir/", "dir/a.txt", "dir/b.txt"}, prefixTreeNodes(t, gormDB))
+		assert.Equal(t, int64(2), epochHeight(t, gormDB))
+	}
+}
@@ -1,14 +1,19 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/forbole/juno/v4/database"
+	databaseconfig "github.com/forbole/juno/v4/database/config"
 	"github.com/forbole/juno/v4/database/mysql"
 	"github.com/forbole/juno/v4/database/sqlclient"
 	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-storage-provider/store/config"
+	"github.com/bnb-chain/greenfield-storage-provider/store/sqldb"
 )
 
 var _ database.Database = &DB{}
@@ -21,7 +26,7 @@ type DB struct {
 
 // BlockSyncerDBBuilder allows to create a new DB instance implementing the db.Builder type
 func BlockSyncerDBBuilder(ctx *database.Context) (database.Database, error) {
-	db, err := sqlclient.New(&ctx.Cfg)
+	db, err := openDB(&ctx.Cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +40,43 @@ func BlockSyncerDBBuilder(ctx *database.Context) (database.Database, error) {
 	}, nil
 }
 
+// openDB opens the db by the type of the config, the MySQL and PostgreSQL dbs are opened by juno,
+// the PostgreSQL db translates the MySQL only column types of the schemas when the tables are created.
+func openDB(cfg *databaseconfig.Config) (*gorm.DB, error) {
+	switch cfg.Type {
+	case "", databaseconfig.MySQL:
+		cfg.Type = databaseconfig.MySQL
+		return sqlclient.New(cfg)
+	case databaseconfig.PostgreSQL, config.SQLiteDialect:
+	default:
+		return nil, fmt.Errorf("unsupported block syncer db type: %s", cfg.Type)
+	}
+
+	dialector, err := sqldb.NewDialectorByDSN(string(cfg.Type), cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxIdleConnections > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConnections)
+	}
+	if cfg.MaxOpenConnections > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConnections)
+	}
+	if cfg.Type == config.SQLiteDialect {
+		// SQLite allows only one writer, serialize the connections to avoid the locked db file
+		sqlDB.SetMaxOpenConns(1)
+	}
+	return db, nil
+}
+
 // Cast allows to cast the given db to a DB instance
 func Cast(db database.Database) *DB {
 	bdDatabase, ok := db.(*DB)
@@ -44,6 +86,19 @@ func Cast(db database.Database) *DB {
 	return bdDatabase
 }
 
+// BeginTx starts a transaction and returns a DB that writes through it, the modules built
+// with the returned DB are committed or rolled back together by Commit or Rollback.
+func (db *DB) BeginTx(ctx context.Context) *DB {
+	return &DB{
+		Database: &mysql.Database{
+			Impl: database.Impl{
+				Db:             db.Db.WithContext(ctx).Begin(),
+				EncodingConfig: db.EncodingConfig,
+			},
+		},
+	}
+}
+
 // errIsNotFound check if the error is not found
 func errIsNotFound(err error) bool {
 	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, gorm.ErrRecordNotFound)
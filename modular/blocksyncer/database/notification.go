@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+
+	"github.com/forbole/juno/v4/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bnb-chain/greenfield-storage-provider/store/bsdb"
+)
+
+// CreateNotificationOutbox create the event in the outbox, it does nothing if the event has
+// been written by the replayed block
+func (db *DB) CreateNotificationOutbox(ctx context.Context, outbox *bsdb.NotificationOutbox) error {
+	return db.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}},
+		DoNothing: true,
+	}).Create(outbox).Error
+}
+
+// GetObjectOwner get the owner of the object by object id, the removed object is included
+// because the object has been marked removed when the delete event is handled
+func (db *DB) GetObjectOwner(ctx context.Context, objectID common.Hash) (string, error) {
+	var object *bsdb.Object
+	err := db.Db.WithContext(ctx).Table(bsdb.ObjectTableName).Select("owner").
+		Where("object_id = ?", objectID).Take(&object).Error
+	if err != nil {
+		if errIsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return object.Owner.String(), nil
+}
+
+// ListUndispatchedNotificationOutbox list the earliest events that have not been dispatched
+// to the subscriptions, the limit is the max number
+func (db *DB) ListUndispatchedNotificationOutbox(ctx context.Context, limit int) ([]*bsdb.NotificationOutbox, error) {
+	var outboxes []*bsdb.NotificationOutbox
+	err := db.Db.WithContext(ctx).Where("dispatched = ?", false).Order("id").Limit(limit).Find(&outboxes).Error
+	if err != nil {
+		return nil, err
+	}
+	return outboxes, nil
+}
+
+// GetNotificationOutbox get the event in the outbox by id
+func (db *DB) GetNotificationOutbox(ctx context.Context, id uint64) (*bsdb.NotificationOutbox, error) {
+	var outbox *bsdb.NotificationOutbox
+	err := db.Db.WithContext(ctx).Where("id = ?", id).Take(&outbox).Error
+	if err != nil {
+		if errIsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return outbox, nil
+}
+
+// DispatchNotificationOutbox create the deliveries of the event and mark the event dispatched
+// in a transaction
+func (db *DB) DispatchNotificationOutbox(ctx context.Context, outboxID uint64, deliveries []*bsdb.NotificationDelivery) error {
+	return db.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(deliveries) != 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&bsdb.NotificationOutbox{}).Where("id = ?", outboxID).Update("dispatched", true).Error
+	})
+}
+
+// ListDueNotificationDeliveries list the deliveries whose retry time is not after the unix
+// time, the limit is the max number
+func (db *DB) ListDueNotificationDeliveries(ctx context.Context, now int64, limit int) ([]*bsdb.NotificationDelivery, error) {
+	var deliveries []*bsdb.NotificationDelivery
+	err := db.Db.WithContext(ctx).Where("next_retry_at <= ?", now).Order("next_retry_at, id").
+		Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateNotificationDelivery update the attempts, the retry time and the error of the delivery
+func (db *DB) UpdateNotificationDelivery(ctx context.Context, delivery *bsdb.NotificationDelivery) error {
+	return db.Db.WithContext(ctx).Model(&bsdb.NotificationDelivery{}).Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"attempts":      delivery.Attempts,
+			"next_retry_at": delivery.NextRetryAt,
+			"last_error":    delivery.LastError,
+		}).Error
+}
+
+// DeleteNotificationDelivery delete the delivery by id
+func (db *DB) DeleteNotificationDelivery(ctx context.Context, id uint64) error {
+	return db.Db.WithContext(ctx).Where("id = ?", id).Delete(&bsdb.NotificationDelivery{}).Error
+}
+
+// DeadLetterNotificationDelivery move the delivery to the dead letter table in a transaction
+func (db *DB) DeadLetterNotificationDelivery(ctx context.Context, delivery *bsdb.NotificationDelivery, createTime int64) error {
+	return db.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&bsdb.NotificationDeadLetter{
+			OutboxID:       delivery.OutboxID,
+			SubscriptionID: delivery.SubscriptionID,
+			Attempts:       delivery.Attempts,
+			LastError:      delivery.LastError,
+			CreateTime:     createTime,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", delivery.ID).Delete(&bsdb.NotificationDelivery{}).Error
+	})
+}
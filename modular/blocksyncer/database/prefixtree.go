@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"strings"
 
 	"github.com/bnb-chain/greenfield-storage-provider/store/bsdb"
 	"github.com/forbole/juno/v4/common"
@@ -67,6 +68,19 @@ func (db *DB) GetPrefixTreeObject(ctx context.Context, objectID common.Hash) (*b
 	return prefixTreeNode, nil
 }
 
+// GetPrefixTreeObjectByName get prefix tree node object by full name and bucket name
+func (db *DB) GetPrefixTreeObjectByName(ctx context.Context, fullName, bucketName string) (*bsdb.SlashPrefixTreeNode, error) {
+	var prefixTreeNode *bsdb.SlashPrefixTreeNode
+	err := db.Db.WithContext(ctx).Where("full_name = ? AND bucket_name = ? AND is_object = ?", fullName, bucketName, true).Take(&prefixTreeNode).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return prefixTreeNode, nil
+}
+
 // GetPrefixTreeCount get prefix tree nodes count by path and bucket name
 func (db *DB) GetPrefixTreeCount(ctx context.Context, pathName, bucketName string) (int64, error) {
 	var count int64
@@ -76,3 +90,99 @@ func (db *DB) GetPrefixTreeCount(ctx context.Context, pathName, bucketName strin
 	}
 	return count, nil
 }
+
+// UpdatePrefixTreeStats add the object count and the total size to the directory nodes by
+// full names and bucket name, the deltas are negative if the objects are removed
+func (db *DB) UpdatePrefixTreeStats(ctx context.Context, bucketName string, fullNames []string, objectCount, totalSize int64) error {
+	if len(fullNames) == 0 {
+		return nil
+	}
+	return db.Db.WithContext(ctx).Table((&bsdb.SlashPrefixTreeNode{}).TableName()).
+		Where("bucket_name = ? AND full_name IN ? AND is_object = ?", bucketName, fullNames, false).
+		Updates(map[string]interface{}{
+			"object_count": gorm.Expr("object_count + ?", objectCount),
+			"total_size":   gorm.Expr("total_size + ?", totalSize),
+		}).Error
+}
+
+// prefixTreeBackfillBatchSize defines the number of the object nodes scanned in a batch
+const prefixTreeBackfillBatchSize = 1000
+
+// prefixTreeDirectory defines the directory node by the bucket name and the full name
+type prefixTreeDirectory struct {
+	bucketName string
+	fullName   string
+}
+
+// BackfillPrefixTreeStats computes the object count and the total size of all the prefix tree
+// nodes, it is used once when the stats columns are added to the existing prefix tree table.
+// The object nodes are updated by a correlated subquery, and the stats of the directories are
+// summed up in go by the parent directories of the object nodes, so it works on all dialects.
+func (db *DB) BackfillPrefixTreeStats(ctx context.Context) error {
+	node := &bsdb.SlashPrefixTreeNode{}
+	tree := node.TableName()
+	return db.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("UPDATE "+tree+" SET object_count = 1, total_size = COALESCE((SELECT o.payload_size FROM "+
+			bsdb.ObjectTableName+" o WHERE o.object_id = "+tree+".object_id), 0) WHERE is_object = ?", true).Error
+		if err != nil {
+			return err
+		}
+
+		stats := make(map[prefixTreeDirectory]*[2]int64)
+		var lastID uint64
+		for {
+			var objects []*bsdb.SlashPrefixTreeNode
+			err = tx.Select("id", "bucket_name", "full_name", "total_size").
+				Where("is_object = ? AND id > ?", true, lastID).
+				Order("id").Limit(prefixTreeBackfillBatchSize).Find(&objects).Error
+			if err != nil {
+				return err
+			}
+			for _, object := range objects {
+				lastID = object.ID
+				pathParts := strings.Split(object.FullName, "/")
+				for i := len(pathParts) - 1; i > 0; i-- {
+					directory := prefixTreeDirectory{bucketName: object.BucketName, fullName: strings.Join(pathParts[:i], "/") + "/"}
+					if stats[directory] == nil {
+						stats[directory] = &[2]int64{}
+					}
+					stats[directory][0]++
+					stats[directory][1] += object.TotalSize
+				}
+			}
+			if len(objects) < prefixTreeBackfillBatchSize {
+				break
+			}
+		}
+
+		// the directories without any object are reset as well
+		err = tx.Model(node).Where("is_object = ?", false).
+			Updates(map[string]interface{}{"object_count": 0, "total_size": 0}).Error
+		if err != nil {
+			return err
+		}
+		for directory, stat := range stats {
+			err = tx.Model(node).
+				Where("bucket_name = ? AND full_name = ? AND is_object = ?", directory.bucketName, directory.fullName, false).
+				Updates(map[string]interface{}{"object_count": stat[0], "total_size": stat[1]}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MigratePrefixTreeStats adds the stats columns to the prefix tree table created before and
+// backfills them, it does nothing if the table does not exist or has been migrated
+func (db *DB) MigratePrefixTreeStats(ctx context.Context) error {
+	node := &bsdb.SlashPrefixTreeNode{}
+	migrator := db.Db.WithContext(ctx).Migrator()
+	if !migrator.HasTable(node.TableName()) || migrator.HasColumn(node, "ObjectCount") {
+		return nil
+	}
+	if err := migrator.AutoMigrate(node); err != nil {
+		return err
+	}
+	return db.BackfillPrefixTreeStats(ctx)
+}
@@ -0,0 +1,132 @@
+package notification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sdkmath "cosmossdk.io/math"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	tmctypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/forbole/juno/v4/common"
+	"github.com/forbole/juno/v4/log"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/store/bsdb"
+)
+
+var (
+	EventCreateObject       = proto.MessageName(&storagetypes.EventCreateObject{})
+	EventSealObject         = proto.MessageName(&storagetypes.EventSealObject{})
+	EventDeleteObject       = proto.MessageName(&storagetypes.EventDeleteObject{})
+	EventCancelCreateObject = proto.MessageName(&storagetypes.EventCancelCreateObject{})
+	EventRejectSealObject   = proto.MessageName(&storagetypes.EventRejectSealObject{})
+)
+
+// notificationEvents maps the chain event types to the notification event types.
+var notificationEvents = map[string]string{
+	EventCreateObject:       corespdb.NotificationEventCreateObject,
+	EventSealObject:         corespdb.NotificationEventSealObject,
+	EventDeleteObject:       corespdb.NotificationEventDeleteObject,
+	EventCancelCreateObject: corespdb.NotificationEventCancelCreateObject,
+	EventRejectSealObject:   corespdb.NotificationEventRejectSealObject,
+}
+
+// Event is the json payload delivered to the webhooks.
+type Event struct {
+	EventID     string `json:"event_id"`
+	EventType   string `json:"event_type"`
+	BucketName  string `json:"bucket_name"`
+	ObjectName  string `json:"object_name"`
+	ObjectID    string `json:"object_id"`
+	Owner       string `json:"owner"`
+	PayloadSize uint64 `json:"payload_size,omitempty"`
+	Height      int64  `json:"height"`
+	TxHash      string `json:"tx_hash"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// HandleEvent handles the object lifecycle events and writes them to the outbox, the outbox
+// is committed or rolled back together with the other modules of the block.
+func (m *Module) HandleEvent(ctx context.Context, block *tmctypes.ResultBlock, txHash common.Hash, event sdk.Event) error {
+	eventType, ok := notificationEvents[event.Type]
+	if !ok {
+		return nil
+	}
+
+	typedEvent, err := sdk.ParseTypedEvent(abci.Event(event))
+	if err != nil {
+		log.Errorw("parse typed events error", "module", m.Name(), "event", event, "err", err)
+		return err
+	}
+
+	notification := &Event{EventType: eventType}
+	var objectID sdkmath.Uint
+	switch e := typedEvent.(type) {
+	case *storagetypes.EventCreateObject:
+		notification.BucketName, notification.ObjectName, objectID = e.BucketName, e.ObjectName, e.ObjectId
+		notification.Owner = common.HexToAddress(e.Owner).String()
+		notification.PayloadSize = e.PayloadSize
+	case *storagetypes.EventSealObject:
+		notification.BucketName, notification.ObjectName, objectID = e.BucketName, e.ObjectName, e.ObjectId
+	case *storagetypes.EventDeleteObject:
+		notification.BucketName, notification.ObjectName, objectID = e.BucketName, e.ObjectName, e.ObjectId
+	case *storagetypes.EventCancelCreateObject:
+		notification.BucketName, notification.ObjectName, objectID = e.BucketName, e.ObjectName, e.ObjectId
+	case *storagetypes.EventRejectSealObject:
+		notification.BucketName, notification.ObjectName, objectID = e.BucketName, e.ObjectName, e.ObjectId
+	default:
+		log.Errorw("type assert error", "type", event.Type, "event", typedEvent)
+		return errors.New("notification event assert error")
+	}
+	return m.writeOutbox(ctx, block, txHash, notification, common.BigToHash(objectID.BigInt()))
+}
+
+// writeOutbox completes the event and writes it to the outbox, the owner of the event that
+// does not carry one is queried from the objects table.
+func (m *Module) writeOutbox(ctx context.Context, block *tmctypes.ResultBlock, txHash common.Hash,
+	notification *Event, objectID common.Hash) error {
+	var err error
+	if notification.Owner == "" {
+		if notification.Owner, err = m.db.GetObjectOwner(ctx, objectID); err != nil {
+			log.Errorw("failed to get object owner", "object_id", objectID, "error", err)
+			return err
+		}
+	}
+	notification.ObjectID = objectID.Big().String()
+	notification.Height = block.Block.Height
+	notification.TxHash = txHash.Hex()
+	notification.Timestamp = block.Block.Time.Unix()
+	notification.EventID = makeEventID(notification)
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return m.db.CreateNotificationOutbox(ctx, &bsdb.NotificationOutbox{
+		EventID:    notification.EventID,
+		EventType:  notification.EventType,
+		BucketName: notification.BucketName,
+		ObjectName: notification.ObjectName,
+		ObjectID:   objectID,
+		Owner:      notification.Owner,
+		Height:     notification.Height,
+		TxHash:     txHash,
+		BlockTime:  notification.Timestamp,
+		Payload:    string(payload),
+	})
+}
+
+// makeEventID derives the event id from the block, the transaction and the object, so the
+// replayed block produces the same event id and the receivers can dedupe by it.
+func makeEventID(notification *Event) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d/%s/%s/%s", notification.Height, notification.TxHash,
+		notification.EventType, notification.ObjectID)))
+	return hex.EncodeToString(sum[:])
+}
@@ -38,6 +38,10 @@ func (m *Module) Name() string {
 
 // PrepareTables implements
 func (m *Module) PrepareTables() error {
+	// the prefix tree table created before the directory stats needs to be migrated
+	if err := m.db.MigratePrefixTreeStats(context.TODO()); err != nil {
+		return err
+	}
 	return m.db.PrepareTables(context.TODO(), []schema.Tabler{&bsdb.SlashPrefixTreeNode{}})
 }
 
@@ -81,7 +81,8 @@ func (m *Module) HandleEvent(ctx context.Context, block *tmctypes.ResultBlock, t
 }
 
 // handleCreateObject handles EventCreateObject.
-// It builds the directory tree structure for the object if necessary.
+// It builds the directory tree structure for the object if necessary, and adds the object
+// to the stats of all its parent directories.
 func (m *Module) handleCreateObject(ctx context.Context, sealObject *storagetypes.EventCreateObject) error {
 	var nodes []*bsdb.SlashPrefixTreeNode
 	objectPath := sealObject.ObjectName
@@ -125,21 +126,39 @@ func (m *Module) handleCreateObject(ctx context.Context, sealObject *storagetype
 	}
 	if object == nil {
 		objectNode := &bsdb.SlashPrefixTreeNode{
-			PathName:   strings.Join(pathParts[:len(pathParts)-1], "/") + "/",
-			FullName:   objectPath,
-			Name:       pathParts[len(pathParts)-1],
-			IsObject:   true,
-			IsFolder:   false,
-			BucketName: bucketName,
-			ObjectID:   common.BigToHash(objectID.BigInt()),
-			ObjectName: objectPath,
+			PathName:    strings.Join(pathParts[:len(pathParts)-1], "/") + "/",
+			FullName:    objectPath,
+			Name:        pathParts[len(pathParts)-1],
+			IsObject:    true,
+			IsFolder:    false,
+			BucketName:  bucketName,
+			ObjectID:    common.BigToHash(objectID.BigInt()),
+			ObjectName:  objectPath,
+			ObjectCount: 1,
+			TotalSize:   int64(sealObject.PayloadSize),
 		}
 		nodes = append(nodes, objectNode)
 	}
 	if len(nodes) == 0 {
 		return nil
 	}
-	return m.db.CreatePrefixTree(ctx, nodes)
+	if err = m.db.CreatePrefixTree(ctx, nodes); err != nil {
+		return err
+	}
+	// The object has been counted if the block is replayed
+	if object != nil {
+		return nil
+	}
+	return m.db.UpdatePrefixTreeStats(ctx, bucketName, parentDirectories(pathParts), 1, int64(sealObject.PayloadSize))
+}
+
+// parentDirectories returns the full names of all the parent directories of the object path.
+func parentDirectories(pathParts []string) []string {
+	var directories []string
+	for i := len(pathParts) - 1; i > 0; i-- {
+		directories = append(directories, strings.Join(pathParts[:i], "/")+"/")
+	}
+	return directories
 }
 
 // handleDeleteObject handles EventDeleteObject.
@@ -164,8 +183,25 @@ func (m *Module) handleRejectSealObject(ctx context.Context, cancelCreateObject
 func (m *Module) deleteObject(ctx context.Context, objectPath, bucketName string) error {
 	var nodes []*bsdb.SlashPrefixTreeNode
 
+	// The object has been deleted if the block is replayed, skip it, otherwise the parent
+	// directory that still has one child would be counted as empty and deleted
+	object, err := m.db.GetPrefixTreeObjectByName(ctx, objectPath, bucketName)
+	if err != nil {
+		log.Errorw("failed to get prefix tree object", "error", err)
+		return err
+	}
+	if object == nil {
+		return nil
+	}
+
 	// Split full path to get the directories
 	pathParts := strings.Split(objectPath, "/")
+	// Remove the object from the stats of all its parent directories, the directories
+	// that become empty are deleted below
+	if err = m.db.UpdatePrefixTreeStats(ctx, bucketName, parentDirectories(pathParts), -1, -object.TotalSize); err != nil {
+		log.Errorw("failed to update prefix tree stats", "error", err)
+		return err
+	}
 	nodes = append(nodes, &bsdb.SlashPrefixTreeNode{
 		FullName:   objectPath,
 		IsObject:   true,
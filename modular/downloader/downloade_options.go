@@ -1,9 +1,13 @@
 package downloader
 
 import (
+	"context"
+	"sync/atomic"
+
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 )
 
 const (
@@ -44,3 +48,25 @@ func DefaultDownloaderOptions(downloader *DownloadModular, cfg *gfspconfig.GfSpC
 	downloader.bucketFreeQuota = cfg.Bucket.FreeQuotaPerBucket
 	return nil
 }
+
+// ReloadConfig applies the reloaded capacities of queues and the free read quota per bucket.
+func (d *DownloadModular) ReloadConfig(ctx context.Context, cfg interface{}) error {
+	newCfg, ok := cfg.(*gfspconfig.GfSpConfig)
+	if !ok {
+		return gfspconfig.ErrInvalidReloadConfig
+	}
+	if newCfg.Parallel.DownloadObjectParallelPerNode == 0 {
+		newCfg.Parallel.DownloadObjectParallelPerNode = DefaultDownloadObjectParallelPerNode
+	}
+	if newCfg.Parallel.ChallengePieceParallelPerNode == 0 {
+		newCfg.Parallel.ChallengePieceParallelPerNode = DefaultChallengePieceParallelPerNode
+	}
+	if newCfg.Bucket.FreeQuotaPerBucket == 0 {
+		newCfg.Bucket.FreeQuotaPerBucket = DefaultBucketFreeQuota
+	}
+	d.downloadQueue.SetCap(newCfg.Parallel.DownloadObjectParallelPerNode)
+	d.challengeQueue.SetCap(newCfg.Parallel.ChallengePieceParallelPerNode)
+	atomic.StoreUint64(&d.bucketFreeQuota, newCfg.Bucket.FreeQuotaPerBucket)
+	log.CtxInfow(ctx, "succeed to reload downloader config")
+	return nil
+}
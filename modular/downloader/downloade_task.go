@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
 	"github.com/bnb-chain/greenfield-storage-provider/core/module"
@@ -55,7 +56,7 @@ func (d *DownloadModular) PreDownloadObject(
 			ReadTimestampUs: sqldb.GetCurrentTimestampUs(),
 		},
 		&spdb.BucketQuota{
-			ReadQuotaSize: task.GetBucketInfo().GetChargedReadQuota() + d.bucketFreeQuota,
+			ReadQuotaSize: task.GetBucketInfo().GetChargedReadQuota() + atomic.LoadUint64(&d.bucketFreeQuota),
 		},
 	); err != nil {
 		log.CtxErrorw(ctx, "failed to check bucket quota", "error", err)
@@ -109,25 +110,50 @@ type segmentPieceInfo struct {
 	length          uint64
 }
 
+// SplitToSegmentPieceInfos returns the segment slices to read for the ranges of the
+// task in order, only the segments that overlap a range are read.
 func (d *DownloadModular) SplitToSegmentPieceInfos(
 	ctx context.Context,
 	task task.DownloadObjectTask) (
 	[]*segmentPieceInfo, error) {
+	params, err := d.baseApp.QueryStorageParamsByTimestamp(ctx, task.GetObjectInfo().GetCreateAt())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query storage params by object create time", "error", err)
+		return nil, err
+	}
+	var pieceInfos []*segmentPieceInfo
+	for _, r := range task.GetRanges() {
+		rangePieceInfos, err := d.splitRangeToSegmentPieceInfos(ctx, task,
+			params.VersionedParams.GetMaxSegmentSize(), r.Low, r.High)
+		if err != nil {
+			return nil, err
+		}
+		pieceInfos = append(pieceInfos, rangePieceInfos...)
+	}
+	return pieceInfos, nil
+}
+
+func (d *DownloadModular) splitRangeToSegmentPieceInfos(
+	ctx context.Context,
+	task task.DownloadObjectTask,
+	segmentSize uint64,
+	rangeLow int64,
+	rangeHigh int64) (
+	[]*segmentPieceInfo, error) {
 	if task.GetObjectInfo().GetPayloadSize() == 0 ||
-		task.GetLow() >= int64(task.GetObjectInfo().GetPayloadSize()) ||
-		task.GetHigh() >= int64(task.GetObjectInfo().GetPayloadSize()) ||
-		task.GetHigh() <= task.GetLow() {
+		rangeLow < 0 ||
+		rangeLow >= int64(task.GetObjectInfo().GetPayloadSize()) ||
+		rangeHigh >= int64(task.GetObjectInfo().GetPayloadSize()) ||
+		rangeHigh < rangeLow {
 		log.CtxErrorw(ctx, "failed to parser params", "object_size",
-			task.GetObjectInfo().GetPayloadSize(), "low", task.GetLow(), "high", task.GetHigh())
+			task.GetObjectInfo().GetPayloadSize(), "low", rangeLow, "high", rangeHigh)
 		return nil, ErrInvalidParam
 	}
-	segmentSize := task.GetStorageParams().VersionedParams.GetMaxSegmentSize()
-	segmentCount := d.baseApp.PieceOp().MaxSegmentSize(task.GetObjectInfo().GetPayloadSize(),
-		task.GetStorageParams().VersionedParams.GetMaxSegmentSize())
+	segmentCount := d.baseApp.PieceOp().SegmentCount(task.GetObjectInfo().GetPayloadSize(), segmentSize)
 	var (
 		pieceInfos []*segmentPieceInfo
-		low        = uint64(task.GetLow())
-		high       = uint64(task.GetHigh())
+		low        = uint64(rangeLow)
+		high       = uint64(rangeHigh)
 	)
 	for segmentPieceIndex := uint64(0); segmentPieceIndex < uint64(segmentCount); segmentPieceIndex++ {
 		currentStart := segmentPieceIndex * segmentSize
@@ -0,0 +1,258 @@
+package executor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corercmgr "github.com/bnb-chain/greenfield-storage-provider/core/rcmgr"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/metrics"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+const (
+	// GCFailedPieceMaxRetry defines the max retry number of deleting the piece that gc
+	// object task failed to delete, the piece is kept in SPDB for checking after that.
+	GCFailedPieceMaxRetry = 5
+	// GCFailedPieceRetryLimit defines the max number of failed pieces that are retried by
+	// a gc object task.
+	GCFailedPieceRetryLimit = 1000
+	// HandoverPieceCheckInterval defines the seconds between two checks of the pieces
+	// received from the exiting SP, the handover not done within it is abandoned.
+	HandoverPieceCheckInterval int64 = 3600
+	// HandoverPieceCheckLimit defines the max number of handover pieces that are checked
+	// by a gc object task.
+	HandoverPieceCheckLimit = 100
+)
+
+// gcObjects deletes the pieces and the integrity meta of the objects concurrently, each
+// deletion reserves a low priority task from the resource manager, so the concurrency is
+// bounded by both the gc object parallel and the resource limit. The pieces failed to
+// delete are recorded to be retried by the later tasks, returns error only if the failure
+// can not be recorded or the integrity meta can not be deleted.
+func (e *ExecuteModular) gcObjects(ctx context.Context, objectInfos []*storagetypes.ObjectInfo) error {
+	var (
+		err          error
+		gcErr        error
+		mux          sync.Mutex
+		wg           sync.WaitGroup
+		failedPieces []*corespdb.GCFailedPiece
+	)
+	for _, objectInfo := range objectInfos {
+		var (
+			pieceKeys []string
+			span      corercmgr.ResourceScopeSpan
+		)
+		if pieceKeys, err = e.gcPieceKeys(ctx, objectInfo); err != nil {
+			break
+		}
+		if span, err = e.ReserveResource(ctx, &corercmgr.ScopeStat{NumTasksLow: 1}); err != nil {
+			// wait for the running deletions to release the resource
+			wg.Wait()
+			if span, err = e.ReserveResource(ctx, &corercmgr.ScopeStat{NumTasksLow: 1}); err != nil {
+				log.CtxErrorw(ctx, "failed to reserve resource for gc object", "error", err)
+				break
+			}
+		}
+		wg.Add(1)
+		go func(objectInfo *storagetypes.ObjectInfo, pieceKeys []string) {
+			defer func() {
+				e.ReleaseResource(ctx, span)
+				wg.Done()
+			}()
+			failed := e.baseApp.PieceStore().DeletePieces(ctx, pieceKeys)
+			integrityErr := e.baseApp.GfSpDB().DeleteObjectIntegrity(objectInfo.Id.Uint64())
+			mux.Lock()
+			defer mux.Unlock()
+			for pieceKey, deleteErr := range failed {
+				log.CtxErrorw(ctx, "failed to delete piece", "piece_key", pieceKey, "error", deleteErr)
+				failedPieces = append(failedPieces, &corespdb.GCFailedPiece{
+					PieceKey:         pieceKey,
+					ObjectID:         objectInfo.Id.Uint64(),
+					ErrorDescription: deleteErr.Error(),
+					UpdateTime:       time.Now().Unix(),
+				})
+			}
+			if integrityErr != nil {
+				log.CtxErrorw(ctx, "failed to delete integrity meta", "object_id", objectInfo.Id.Uint64(),
+					"error", integrityErr)
+				gcErr = integrityErr
+				return
+			}
+			metrics.GCObjectCounter.WithLabelValues(e.Name()).Inc()
+		}(objectInfo, pieceKeys)
+	}
+	wg.Wait()
+	if len(failedPieces) != 0 {
+		metrics.GCFailedPieceCounter.WithLabelValues(e.Name()).Add(float64(len(failedPieces)))
+		if recordErr := e.baseApp.GfSpDB().SetGCFailedPieces(failedPieces); recordErr != nil {
+			log.CtxErrorw(ctx, "failed to record gc failed pieces", "error", recordErr)
+			return recordErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return gcErr
+}
+
+// gcPieceKeys returns the keys of the pieces that this SP stores for the object, they are
+// the segments if this SP is primary, or the pieces of the replicate idx if this SP is
+// secondary. The segment count is computed by the params of the object create time.
+func (e *ExecuteModular) gcPieceKeys(ctx context.Context, objectInfo *storagetypes.ObjectInfo) ([]string, error) {
+	params, err := e.baseApp.QueryStorageParamsByTimestamp(ctx, objectInfo.GetCreateAt())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query storage params by object create time",
+			"object_id", objectInfo.Id.Uint64(), "error", err)
+		return nil, err
+	}
+	segmentCount := e.baseApp.PieceOp().SegmentCount(objectInfo.GetPayloadSize(),
+		params.VersionedParams.GetMaxSegmentSize())
+	pieceKeys := make([]string, 0, segmentCount)
+	for segIdx := uint32(0); segIdx < segmentCount; segIdx++ {
+		pieceKeys = append(pieceKeys, e.baseApp.PieceOp().SegmentPieceKey(objectInfo.Id.Uint64(), segIdx))
+	}
+	for rIdx, address := range objectInfo.GetSecondarySpAddresses() {
+		if !strings.EqualFold(e.baseApp.OperateAddress(), address) {
+			continue
+		}
+		// the secondary sp of the replica object stores the segments
+		if objectInfo.GetRedundancyType() == storagetypes.REDUNDANCY_EC_TYPE {
+			for segIdx := uint32(0); segIdx < segmentCount; segIdx++ {
+				pieceKeys = append(pieceKeys, e.baseApp.PieceOp().ECPieceKey(objectInfo.Id.Uint64(),
+					segIdx, uint32(rIdx)))
+			}
+		}
+		break
+	}
+	return pieceKeys, nil
+}
+
+// retryGCFailedPieces retries deleting the pieces that the former gc object tasks failed to
+// delete, the pieces deleted are removed from SPDB, and the retry count of the others is
+// increased.
+func (e *ExecuteModular) retryGCFailedPieces(ctx context.Context) {
+	pieces, err := e.baseApp.GfSpDB().ListGCFailedPieces(GCFailedPieceMaxRetry, GCFailedPieceRetryLimit)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list gc failed pieces", "error", err)
+		return
+	}
+	if len(pieces) == 0 {
+		return
+	}
+	pieceKeys := make([]string, 0, len(pieces))
+	for _, piece := range pieces {
+		pieceKeys = append(pieceKeys, piece.PieceKey)
+	}
+	failed := e.baseApp.PieceStore().DeletePieces(ctx, pieceKeys)
+	var (
+		deleted      []string
+		failedPieces []*corespdb.GCFailedPiece
+	)
+	for _, piece := range pieces {
+		deleteErr, ok := failed[piece.PieceKey]
+		if !ok {
+			deleted = append(deleted, piece.PieceKey)
+			continue
+		}
+		piece.RetryCount++
+		piece.ErrorDescription = deleteErr.Error()
+		piece.UpdateTime = time.Now().Unix()
+		failedPieces = append(failedPieces, piece)
+	}
+	if err = e.baseApp.GfSpDB().DeleteGCFailedPieces(deleted); err != nil {
+		log.CtxErrorw(ctx, "failed to delete gc failed pieces", "error", err)
+	}
+	if err = e.baseApp.GfSpDB().SetGCFailedPieces(failedPieces); err != nil {
+		log.CtxErrorw(ctx, "failed to update gc failed pieces", "error", err)
+	}
+	log.CtxDebugw(ctx, "finish to retry gc failed pieces", "deleted", len(deleted), "failed", len(failedPieces))
+}
+
+// gcHandoverPieces checks the pieces received from the exiting SPs, this SP is not listed
+// as secondary of the objects on greenfield, so the pieces are backed by the exiting SP
+// that is still listed at the replicate idx only. The pieces are deleted if the object is
+// deleted, the exiting SP is not listed at the replicate idx any more, or the handover is
+// not done within the check interval. The record is dropped only if this SP is listed at
+// the replicate idx, the pieces are gc as secondary SP then.
+func (e *ExecuteModular) gcHandoverPieces(ctx context.Context) {
+	now := time.Now().Unix()
+	pieces, err := e.baseApp.GfSpDB().ListHandoverPieces(now-HandoverPieceCheckInterval, HandoverPieceCheckLimit)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list handover pieces", "error", err)
+		return
+	}
+	for _, piece := range pieces {
+		objectInfo, queryErr := e.baseApp.Consensus().QueryObjectInfoByID(ctx,
+			strconv.FormatUint(piece.ObjectID, 10))
+		// refer to https://github.com/bnb-chain/greenfield/blob/master/x/storage/types/errors.go
+		if queryErr != nil && !strings.Contains(queryErr.Error(), "No such object") {
+			log.CtxErrorw(ctx, "failed to get object info from consensus", "object_id", piece.ObjectID,
+				"error", queryErr)
+			continue
+		}
+		var secondary string
+		if queryErr == nil && int(piece.ReplicateIdx) < len(objectInfo.GetSecondarySpAddresses()) {
+			secondary = objectInfo.GetSecondarySpAddresses()[piece.ReplicateIdx]
+		}
+		switch {
+		case secondary != "" && strings.EqualFold(secondary, e.baseApp.OperateAddress()):
+			err = e.baseApp.GfSpDB().DeleteHandoverPiece(piece.ObjectID, piece.ReplicateIdx)
+		case secondary != "" && strings.EqualFold(secondary, piece.SrcSpAddress) && piece.Done:
+			piece.UpdateTime = now
+			err = e.baseApp.GfSpDB().SetHandoverPiece(piece)
+		default:
+			err = e.deleteHandoverPieces(ctx, piece)
+		}
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to check handover pieces", "object_id", piece.ObjectID,
+				"replicate_idx", piece.ReplicateIdx, "error", err)
+		}
+	}
+}
+
+// deleteHandoverPieces deletes the pieces and the meta received from the exiting SP, the
+// pieces failed to delete are recorded to be retried by the later gc object tasks.
+func (e *ExecuteModular) deleteHandoverPieces(ctx context.Context, piece *corespdb.HandoverPiece) error {
+	pieceKeys := make([]string, 0, piece.SegmentCount)
+	for segIdx := uint32(0); segIdx < piece.SegmentCount; segIdx++ {
+		if piece.RedundancyType == int32(storagetypes.REDUNDANCY_EC_TYPE) {
+			pieceKeys = append(pieceKeys, e.baseApp.PieceOp().ECPieceKey(piece.ObjectID, segIdx, piece.ReplicateIdx))
+		} else {
+			pieceKeys = append(pieceKeys, e.baseApp.PieceOp().SegmentPieceKey(piece.ObjectID, segIdx))
+		}
+	}
+	var failedPieces []*corespdb.GCFailedPiece
+	for pieceKey, deleteErr := range e.baseApp.PieceStore().DeletePieces(ctx, pieceKeys) {
+		log.CtxErrorw(ctx, "failed to delete piece", "piece_key", pieceKey, "error", deleteErr)
+		failedPieces = append(failedPieces, &corespdb.GCFailedPiece{
+			PieceKey:         pieceKey,
+			ObjectID:         piece.ObjectID,
+			ErrorDescription: deleteErr.Error(),
+			UpdateTime:       time.Now().Unix(),
+		})
+	}
+	if err := e.baseApp.GfSpDB().SetGCFailedPieces(failedPieces); err != nil {
+		return err
+	}
+	var err error
+	if piece.Done {
+		err = e.baseApp.GfSpDB().DeleteObjectIntegrity(piece.ObjectID)
+	} else {
+		err = e.baseApp.GfSpDB().DeleteAllReplicatePieceChecksum(piece.ObjectID, piece.ReplicateIdx,
+			piece.SegmentCount)
+	}
+	if err != nil {
+		return err
+	}
+	if err = e.baseApp.GfSpDB().DeleteHandoverPiece(piece.ObjectID, piece.ReplicateIdx); err != nil {
+		return err
+	}
+	log.CtxDebugw(ctx, "succeed to gc handover pieces", "object_id", piece.ObjectID,
+		"replicate_idx", piece.ReplicateIdx, "done", piece.Done)
+	return nil
+}
@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/stretchr/testify/assert"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+const (
+	testSrcSpAddress   = "0x2222222222222222222222222222222222222222"
+	testOtherSpAddress = "0x3333333333333333333333333333333333333333"
+)
+
+// mockHandoverDB records the handover pieces and the deleted meta of the test.
+type mockHandoverDB struct {
+	corespdb.SPDB
+	pieces            map[uint64]*corespdb.HandoverPiece
+	deletedIntegrity  []uint64
+	deletedChecksums  []uint64
+	updatedHandovers  []uint64
+	failedPieceNumber int
+}
+
+func (m *mockHandoverDB) ListHandoverPieces(updateTime int64, _ int) ([]*corespdb.HandoverPiece, error) {
+	var pieces []*corespdb.HandoverPiece
+	for _, piece := range m.pieces {
+		if piece.UpdateTime < updateTime {
+			copied := *piece
+			pieces = append(pieces, &copied)
+		}
+	}
+	return pieces, nil
+}
+
+func (m *mockHandoverDB) SetHandoverPiece(piece *corespdb.HandoverPiece) error {
+	m.pieces[piece.ObjectID] = piece
+	m.updatedHandovers = append(m.updatedHandovers, piece.ObjectID)
+	return nil
+}
+
+func (m *mockHandoverDB) DeleteHandoverPiece(objectID uint64, _ uint32) error {
+	delete(m.pieces, objectID)
+	return nil
+}
+
+func (m *mockHandoverDB) SetGCFailedPieces(pieces []*corespdb.GCFailedPiece) error {
+	m.failedPieceNumber += len(pieces)
+	return nil
+}
+
+func (m *mockHandoverDB) DeleteObjectIntegrity(objectID uint64) error {
+	m.deletedIntegrity = append(m.deletedIntegrity, objectID)
+	return nil
+}
+
+func (m *mockHandoverDB) DeleteAllReplicatePieceChecksum(objectID uint64, _ uint32, _ uint32) error {
+	m.deletedChecksums = append(m.deletedChecksums, objectID)
+	return nil
+}
+
+func TestExecuteModularGCHandoverPieces(t *testing.T) {
+	newObject := func(id uint64, secondary string) *storagetypes.ObjectInfo {
+		return &storagetypes.ObjectInfo{Id: sdkmath.NewUint(id), SecondarySpAddresses: []string{secondary}}
+	}
+	chain := &mockConsensus{objects: map[string]*storagetypes.ObjectInfo{
+		"backed":    newObject(2, testSrcSpAddress),
+		"abandoned": newObject(3, testSrcSpAddress),
+		"assigned":  newObject(4, testOperateAddress),
+		"replaced":  newObject(5, testOtherSpAddress),
+	}}
+	store := &mockPieceStore{pieces: make(map[string][]byte)}
+	db := &mockHandoverDB{pieces: make(map[uint64]*corespdb.HandoverPiece)}
+	for objectID := uint64(1); objectID <= 5; objectID++ {
+		db.pieces[objectID] = &corespdb.HandoverPiece{ObjectID: objectID, SrcSpAddress: testSrcSpAddress,
+			SegmentCount: 2, RedundancyType: int32(storagetypes.REDUNDANCY_EC_TYPE), Done: objectID != 3, UpdateTime: 1}
+		for _, key := range []string{"_s0_p0", "_s1_p0"} {
+			store.pieces[sdkmath.NewUint(objectID).String()+key] = []byte("piece")
+		}
+	}
+	// the recently checked pieces are not checked again
+	db.pieces[6] = &corespdb.HandoverPiece{ObjectID: 6, SrcSpAddress: testSrcSpAddress, SegmentCount: 1,
+		UpdateTime: time.Now().Unix()}
+	store.pieces["6_s0_p0"] = []byte("piece")
+	e := newTestExecuteModular(t, chain, store, db)
+	e.gcHandoverPieces(context.Background())
+
+	// the object 1 is deleted, the handover of the object 3 is abandoned, and the
+	// exiting sp of the object 5 is replaced on greenfield
+	for _, key := range []string{"1_s0_p0", "1_s1_p0", "3_s0_p0", "3_s1_p0", "5_s0_p0", "5_s1_p0"} {
+		assert.NotContains(t, store.pieces, key)
+	}
+	assert.ElementsMatch(t, []uint64{1, 5}, db.deletedIntegrity)
+	assert.Equal(t, []uint64{3}, db.deletedChecksums)
+	// the pieces of the object 2 are backed by the exiting sp, and this sp is listed as
+	// secondary of the object 4 on greenfield
+	for _, key := range []string{"2_s0_p0", "2_s1_p0", "4_s0_p0", "4_s1_p0", "6_s0_p0"} {
+		assert.Contains(t, store.pieces, key)
+	}
+	assert.Equal(t, []uint64{2}, db.updatedHandovers)
+	assert.Greater(t, db.pieces[2].UpdateTime, int64(1))
+	assert.Len(t, db.pieces, 2)
+	assert.Contains(t, db.pieces, uint64(6))
+	assert.Equal(t, 0, db.failedPieceNumber)
+}
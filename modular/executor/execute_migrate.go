@@ -0,0 +1,248 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bnb-chain/greenfield-common/go/hash"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	"github.com/bnb-chain/greenfield-storage-provider/core/module"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/manager"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// MigrateFrameOverhead defines the max bytes of the migrate frame except the piece data.
+const MigrateFrameOverhead = 64 * 1024
+
+var (
+	ErrMigrateStream         = gfsperrors.Register(module.ExecuteModularName, http.StatusBadRequest, 40008, "bucket migration stream broken")
+	ErrMigrateObjectMismatch = gfsperrors.Register(module.ExecuteModularName, http.StatusNotAcceptable, 40009, "migrating object verification failed")
+)
+
+// migratingObject is the object that is importing from the bucket migration stream.
+type migratingObject struct {
+	objectInfo     *storagetypes.ObjectInfo
+	meta           *gfsptask.GfSpMigrateObjectMeta
+	maxSegmentSize uint64
+	segmentCount   uint32
+	checksums      [][]byte
+}
+
+// HandleMigrateBucketTask imports the objects of the migrating bucket from the source
+// SP. Every piece is verified by the checksum before stored, and the integrity hash of
+// the object is verified against greenfield before the object is registered to SPDB.
+// The progress is reported after each object, so the migration resumes from the last
+// migrated object if the stream is broken.
+func (e *ExecuteModular) HandleMigrateBucketTask(
+	ctx context.Context,
+	task coretask.MigrateBucketTask) {
+	var (
+		err       error
+		signature []byte
+		stream    io.ReadCloser
+		frame     *gfsptask.GfSpMigrateFrame
+		params    *storagetypes.Params
+		migrating *migratingObject
+	)
+	defer func() {
+		task.SetError(err)
+		log.CtxDebugw(ctx, "finish to migrate bucket", "info", task.Info(), "error", err)
+	}()
+	if task.GetBucketInfo() == nil {
+		err = ErrDanglingPointer
+		return
+	}
+	params, err = e.baseApp.Consensus().QueryStorageParams(ctx)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get storage params", "error", err)
+		return
+	}
+	task.SetUpdateTime(time.Now().Unix())
+	signature, err = e.baseApp.GfSpClient().SignMigrateBucketTask(ctx, task)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to sign migrate bucket task", "error", err)
+		return
+	}
+	task.SetSignature(signature)
+	stream, err = e.baseApp.GfSpClient().MigrateBucketFromSource(ctx, task.GetSrcSpEndpoint(), task)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to request source sp to export bucket", "error", err)
+		return
+	}
+	defer stream.Close()
+
+	// the task is canceled by the admin of this node, or in the manager
+	cancel := func() bool {
+		return ctx.Err() != nil || errors.Is(e.ReportTask(ctx, task), manager.ErrCanceledTask)
+	}
+	reader := bufio.NewReader(stream)
+	maxFrameSize := params.VersionedParams.GetMaxSegmentSize() + MigrateFrameOverhead
+	for {
+		// the pieces of the migrating object are split by the params of its create time
+		frameSize := maxFrameSize
+		if migrating != nil && migrating.maxSegmentSize+MigrateFrameOverhead > frameSize {
+			frameSize = migrating.maxSegmentSize + MigrateFrameOverhead
+		}
+		frame, err = gfsptask.ReadMigrateFrame(reader, frameSize)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to read migrate frame", "error", err)
+			err = ErrMigrateStream
+			return
+		}
+		switch f := frame.GetFrame().(type) {
+		case *gfsptask.GfSpMigrateFrame_ObjectMeta:
+			if migrating != nil {
+				log.CtxErrorw(ctx, "migrating object is incomplete", "object_name",
+					migrating.objectInfo.GetObjectName())
+				err = ErrMigrateStream
+				return
+			}
+			if migrating, err = e.beginMigrateObject(ctx, task, f.ObjectMeta); err != nil {
+				return
+			}
+		case *gfsptask.GfSpMigrateFrame_Piece:
+			if migrating == nil {
+				log.CtxErrorw(ctx, "migrate piece without object meta")
+				err = ErrMigrateStream
+				return
+			}
+			if err = e.importMigratePiece(ctx, migrating, f.Piece); err != nil {
+				return
+			}
+		case *gfsptask.GfSpMigrateFrame_End:
+			if migrating != nil {
+				log.CtxErrorw(ctx, "migrating object is incomplete", "object_name",
+					migrating.objectInfo.GetObjectName())
+				err = ErrMigrateStream
+				return
+			}
+			task.SetFinished(true)
+			log.CtxInfow(ctx, "succeed to migrate bucket", "exported", f.End.GetObjectNumber(),
+				"migrated", task.GetMigratedObjectNumber())
+			return
+		default:
+			log.CtxErrorw(ctx, "unknown migrate frame")
+			err = ErrMigrateStream
+			return
+		}
+		if migrating == nil || uint32(len(migrating.checksums)) < migrating.segmentCount {
+			continue
+		}
+		if err = e.doneMigrateObject(ctx, migrating); err != nil {
+			return
+		}
+		task.SetLastMigratedObjectName(migrating.objectInfo.GetObjectName())
+		task.SetMigratedObjectNumber(task.GetMigratedObjectNumber() + 1)
+		migrating = nil
+		if cancel() {
+			log.CtxErrorw(ctx, "migrate bucket task has been canceled", "info", task.Info())
+			return
+		}
+	}
+}
+
+// beginMigrateObject verifies the meta of the migrating object against greenfield.
+func (e *ExecuteModular) beginMigrateObject(
+	ctx context.Context,
+	task coretask.MigrateBucketTask,
+	meta *gfsptask.GfSpMigrateObjectMeta) (
+	*migratingObject, error) {
+	if meta.GetObjectInfo() == nil || meta.GetObjectInfo().GetBucketName() != task.GetBucketInfo().GetBucketName() ||
+		meta.GetObjectInfo().GetObjectName() <= task.GetLastMigratedObjectName() {
+		log.CtxErrorw(ctx, "unexpected migrating object", "object_info", meta.GetObjectInfo())
+		return nil, ErrMigrateObjectMismatch
+	}
+	objectInfo, err := e.baseApp.Consensus().QueryObjectInfo(ctx, meta.GetObjectInfo().GetBucketName(),
+		meta.GetObjectInfo().GetObjectName())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get object info from consensus", "error", err)
+		return nil, err
+	}
+	if objectInfo.Id.Uint64() != meta.GetObjectInfo().Id.Uint64() || len(objectInfo.GetChecksums()) == 0 ||
+		!bytes.Equal(objectInfo.GetChecksums()[0], meta.GetIntegrityHash()) {
+		log.CtxErrorw(ctx, "migrating object mismatch greenfield", "object_name", objectInfo.GetObjectName())
+		return nil, ErrMigrateObjectMismatch
+	}
+	params, err := e.baseApp.QueryStorageParamsByTimestamp(ctx, objectInfo.GetCreateAt())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query storage params by object create time", "error", err)
+		return nil, err
+	}
+	segmentCount := e.baseApp.PieceOp().SegmentCount(objectInfo.GetPayloadSize(),
+		params.VersionedParams.GetMaxSegmentSize())
+	if uint32(len(meta.GetPieceChecksumList())) != segmentCount {
+		log.CtxErrorw(ctx, "migrating object checksum number mismatch", "object_name",
+			objectInfo.GetObjectName(), "checksums", len(meta.GetPieceChecksumList()), "segments", segmentCount)
+		return nil, ErrMigrateObjectMismatch
+	}
+	return &migratingObject{objectInfo: objectInfo, meta: meta,
+		maxSegmentSize: params.VersionedParams.GetMaxSegmentSize(), segmentCount: segmentCount}, nil
+}
+
+// importMigratePiece verifies the checksum of the piece and stores it to piece store,
+// the pieces of the object must arrive in the order of segment index.
+func (e *ExecuteModular) importMigratePiece(
+	ctx context.Context,
+	migrating *migratingObject,
+	piece *gfsptask.GfSpMigratePiece) error {
+	segmentIdx := uint32(len(migrating.checksums))
+	if piece.GetObjectId() != migrating.objectInfo.Id.Uint64() || piece.GetSegmentIdx() != segmentIdx ||
+		segmentIdx >= migrating.segmentCount {
+		log.CtxErrorw(ctx, "unexpected migrating piece", "object_id", piece.GetObjectId(),
+			"segment_idx", piece.GetSegmentIdx(), "expect_segment_idx", segmentIdx)
+		return ErrMigrateStream
+	}
+	checksum := hash.GenerateChecksum(piece.GetPieceData())
+	if !bytes.Equal(checksum, migrating.meta.GetPieceChecksumList()[segmentIdx]) {
+		log.CtxErrorw(ctx, "migrating piece checksum mismatch", "object_id", piece.GetObjectId(),
+			"segment_idx", segmentIdx)
+		return ErrMigrateObjectMismatch
+	}
+	pieceKey := e.baseApp.PieceOp().SegmentPieceKey(piece.GetObjectId(), segmentIdx)
+	if err := e.baseApp.PieceStore().PutPiece(ctx, pieceKey, piece.GetPieceData()); err != nil {
+		log.CtxErrorw(ctx, "failed to put migrating piece to piece store", "piece_key", pieceKey, "error", err)
+		return err
+	}
+	migrating.checksums = append(migrating.checksums, checksum)
+	return nil
+}
+
+// doneMigrateObject verifies the integrity hash of the imported pieces against
+// greenfield and registers the integrity meta signed by this SP.
+func (e *ExecuteModular) doneMigrateObject(
+	ctx context.Context,
+	migrating *migratingObject) error {
+	signature, integrity, err := e.baseApp.GfSpClient().SignIntegrityHash(ctx,
+		migrating.objectInfo.Id.Uint64(), migrating.checksums)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to sign the integrity hash", "error", err)
+		return err
+	}
+	if !bytes.Equal(integrity, migrating.objectInfo.GetChecksums()[0]) {
+		log.CtxErrorw(ctx, "migrating object integrity hash mismatch", "object_name",
+			migrating.objectInfo.GetObjectName())
+		return ErrMigrateObjectMismatch
+	}
+	err = e.baseApp.GfSpDB().SetObjectIntegrity(&corespdb.IntegrityMeta{
+		ObjectID:          migrating.objectInfo.Id.Uint64(),
+		PieceChecksumList: migrating.checksums,
+		IntegrityChecksum: integrity,
+		Signature:         signature,
+	})
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to write integrity hash to db", "error", err)
+		return ErrGfSpDB
+	}
+	log.CtxDebugw(ctx, "succeed to migrate object", "object_name", migrating.objectInfo.GetObjectName())
+	return nil
+}
@@ -0,0 +1,150 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/bnb-chain/greenfield-common/go/hash"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	"github.com/bnb-chain/greenfield-storage-provider/core/consensus"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+const (
+	testMaxSegmentSize = 16
+	testOperateAddress = "0x1111111111111111111111111111111111111111"
+)
+
+// mockConsensus returns the objects and the params of the test.
+type mockConsensus struct {
+	consensus.NullConsensus
+	objects map[string]*storagetypes.ObjectInfo
+}
+
+func (m *mockConsensus) QueryObjectInfo(_ context.Context, _, object string) (*storagetypes.ObjectInfo, error) {
+	return m.objects[object], nil
+}
+
+func (m *mockConsensus) QueryObjectInfoByID(_ context.Context, objectID string) (*storagetypes.ObjectInfo, error) {
+	for _, objectInfo := range m.objects {
+		if objectInfo.Id.String() == objectID {
+			return objectInfo, nil
+		}
+	}
+	return nil, errors.New("No such object")
+}
+
+func (m *mockConsensus) QueryStorageParamsByTimestamp(context.Context, int64) (*storagetypes.Params, error) {
+	return &storagetypes.Params{VersionedParams: storagetypes.VersionedParams{MaxSegmentSize: testMaxSegmentSize}}, nil
+}
+
+// mockPieceStore stores the pieces in memory.
+type mockPieceStore struct {
+	pieces map[string][]byte
+}
+
+func (m *mockPieceStore) GetPiece(_ context.Context, key string, _, _ int64) ([]byte, error) {
+	return m.pieces[key], nil
+}
+
+func (m *mockPieceStore) PutPiece(_ context.Context, key string, value []byte) error {
+	m.pieces[key] = value
+	return nil
+}
+
+func (m *mockPieceStore) DeletePiece(_ context.Context, key string) error {
+	delete(m.pieces, key)
+	return nil
+}
+
+func (m *mockPieceStore) DeletePieces(_ context.Context, keys []string) map[string]error {
+	for _, key := range keys {
+		delete(m.pieces, key)
+	}
+	return nil
+}
+
+func newTestExecuteModular(t *testing.T, chain consensus.Consensus, store *mockPieceStore,
+	db corespdb.SPDB) *ExecuteModular {
+	app := &gfspapp.GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{
+		SpAccount: gfspconfig.SpAccountConfig{SpOperateAddress: testOperateAddress},
+		Customize: &gfspconfig.Customize{Consensus: chain, PieceStore: store, GfSpDB: db},
+	}
+	require.NoError(t, gfspapp.DefaultStaticOption(app, cfg))
+	require.NoError(t, gfspapp.DefaultGfSpConsensusOption(app, cfg))
+	if db != nil {
+		require.NoError(t, gfspapp.DefaultGfSpDBOption(app, cfg))
+	}
+	require.NoError(t, gfspapp.DefaultGfSpPieceStoreOption(app, cfg))
+	require.NoError(t, gfspapp.DefaultGfSpPieceOpOption(app, cfg))
+	return &ExecuteModular{baseApp: app}
+}
+
+func TestExecuteModularImportMigrateObject(t *testing.T) {
+	segments := [][]byte{[]byte("0123456789abcdef"), []byte("0123")}
+	checksums := [][]byte{hash.GenerateChecksum(segments[0]), hash.GenerateChecksum(segments[1])}
+	integrityHash := hash.GenerateIntegrityHash(checksums)
+	objectInfo := &storagetypes.ObjectInfo{BucketName: "bucket", ObjectName: "object", Id: sdkmath.NewUint(1),
+		PayloadSize: 20, Checksums: [][]byte{integrityHash}}
+	chain := &mockConsensus{objects: map[string]*storagetypes.ObjectInfo{"object": objectInfo}}
+	store := &mockPieceStore{pieces: make(map[string][]byte)}
+	e := newTestExecuteModular(t, chain, store, nil)
+	task := &gfsptask.GfSpMigrateBucketTask{}
+	task.InitMigrateBucketTask(&storagetypes.BucketInfo{BucketName: "bucket", Id: sdkmath.NewUint(1)},
+		"endpoint", "dest", 0, 0, 0)
+	ctx := context.Background()
+
+	// the object is not after the resume point
+	task.SetLastMigratedObjectName("object")
+	_, err := e.beginMigrateObject(ctx, task, &gfsptask.GfSpMigrateObjectMeta{ObjectInfo: objectInfo,
+		IntegrityHash: integrityHash, PieceChecksumList: checksums})
+	assert.Equal(t, ErrMigrateObjectMismatch, err)
+	task.SetLastMigratedObjectName("")
+
+	// the integrity hash mismatches greenfield
+	_, err = e.beginMigrateObject(ctx, task, &gfsptask.GfSpMigrateObjectMeta{ObjectInfo: objectInfo,
+		IntegrityHash: []byte("tampered"), PieceChecksumList: checksums})
+	assert.Equal(t, ErrMigrateObjectMismatch, err)
+
+	// the checksum number mismatches the segment count
+	_, err = e.beginMigrateObject(ctx, task, &gfsptask.GfSpMigrateObjectMeta{ObjectInfo: objectInfo,
+		IntegrityHash: integrityHash, PieceChecksumList: checksums[:1]})
+	assert.Equal(t, ErrMigrateObjectMismatch, err)
+
+	migrating, err := e.beginMigrateObject(ctx, task, &gfsptask.GfSpMigrateObjectMeta{ObjectInfo: objectInfo,
+		IntegrityHash: integrityHash, PieceChecksumList: checksums})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), migrating.segmentCount)
+	assert.Equal(t, uint64(testMaxSegmentSize), migrating.maxSegmentSize)
+
+	// the piece out of order
+	err = e.importMigratePiece(ctx, migrating, &gfsptask.GfSpMigratePiece{ObjectId: 1, SegmentIdx: 1,
+		PieceData: segments[1]})
+	assert.Equal(t, ErrMigrateStream, err)
+	// the tampered piece is not stored
+	err = e.importMigratePiece(ctx, migrating, &gfsptask.GfSpMigratePiece{ObjectId: 1, SegmentIdx: 0,
+		PieceData: []byte("tampered")})
+	assert.Equal(t, ErrMigrateObjectMismatch, err)
+	assert.Empty(t, store.pieces)
+
+	for segmentIdx, segment := range segments {
+		require.NoError(t, e.importMigratePiece(ctx, migrating, &gfsptask.GfSpMigratePiece{ObjectId: 1,
+			SegmentIdx: uint32(segmentIdx), PieceData: segment}))
+	}
+	assert.Equal(t, checksums, migrating.checksums)
+	assert.Equal(t, segments[0], store.pieces["1_s0"])
+	assert.Equal(t, segments[1], store.pieces["1_s1"])
+	// the piece exceeds the segment count
+	err = e.importMigratePiece(ctx, migrating, &gfsptask.GfSpMigratePiece{ObjectId: 1, SegmentIdx: 2,
+		PieceData: segments[1]})
+	assert.Equal(t, ErrMigrateStream, err)
+}
@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bnb-chain/greenfield-common/go/redundancy"
+
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// HandleRepairPieceTask rebuilds the pieces of the unhealthy secondary SP from the
+// segments stored in this SP as primary, and replicates them to a new SP. The task is
+// skipped if the object or the secondary SP changed on greenfield after the task is
+// generated.
+func (e *ExecuteModular) HandleRepairPieceTask(
+	ctx context.Context,
+	task coretask.RepairPieceTask) {
+	var (
+		err        error
+		dest       string
+		params     *storagetypes.Params
+		objectInfo *storagetypes.ObjectInfo
+		bucketInfo *storagetypes.BucketInfo
+	)
+	defer func() {
+		task.SetError(err)
+		log.CtxDebugw(ctx, "finish to repair piece", "info", task.Info(), "error", err)
+	}()
+	if task.GetObjectInfo() == nil || task.GetStorageParams() == nil {
+		err = ErrDanglingPointer
+		return
+	}
+	objectInfo, err = e.baseApp.Consensus().QueryObjectInfoByID(ctx, task.GetObjectInfo().Id.String())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get object info from consensus", "error", err)
+		return
+	}
+	bucketInfo, err = e.baseApp.Consensus().QueryBucketInfo(ctx, objectInfo.GetBucketName())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get bucket info from consensus", "error", err)
+		return
+	}
+	rIdx := task.GetReplicateIdx()
+	if objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_SEALED ||
+		!strings.EqualFold(bucketInfo.GetPrimarySpAddress(), e.baseApp.OperateAddress()) ||
+		int(rIdx) >= len(objectInfo.GetSecondarySpAddresses()) ||
+		!strings.EqualFold(objectInfo.GetSecondarySpAddresses()[rIdx], task.GetUnhealthySpAddress()) {
+		log.CtxInfow(ctx, "skip to repair piece, the object is changed", "object_status",
+			objectInfo.GetObjectStatus(), "primary_sp", bucketInfo.GetPrimarySpAddress())
+		return
+	}
+	params, err = e.objectStorageParams(ctx, objectInfo, task.GetStorageParams())
+	if err != nil {
+		return
+	}
+	dest, err = e.handoverSecondaryPieces(ctx, objectInfo, params, rIdx,
+		e.loadRepairPiece(ctx, objectInfo, params, rIdx), nil)
+	task.SetDestSpOperatorAddress(dest)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to repair piece", "dest", dest, "error", err)
+		return
+	}
+	log.CtxInfow(ctx, "succeed to repair piece", "object_id", objectInfo.Id.Uint64(),
+		"replicate_idx", rIdx, "unhealthy_sp", task.GetUnhealthySpAddress(), "dest", dest)
+}
+
+// loadRepairPiece returns the loader that rebuilds the pieces of the replicate idx from
+// the segments stored in this SP as primary.
+func (e *ExecuteModular) loadRepairPiece(
+	ctx context.Context,
+	objectInfo *storagetypes.ObjectInfo,
+	params *storagetypes.Params,
+	rIdx uint32) func(pIdx uint32) ([]byte, error) {
+	return func(pIdx uint32) ([]byte, error) {
+		pieceKey := e.baseApp.PieceOp().SegmentPieceKey(objectInfo.Id.Uint64(), pIdx)
+		segData, err := e.baseApp.PieceStore().GetPiece(ctx, pieceKey, 0, -1)
+		if err != nil {
+			return nil, err
+		}
+		if objectInfo.GetRedundancyType() != storagetypes.REDUNDANCY_EC_TYPE {
+			return segData, nil
+		}
+		ecData, err := redundancy.EncodeRawSegment(segData,
+			int(params.VersionedParams.GetRedundantDataChunkNum()),
+			int(params.VersionedParams.GetRedundantParityChunkNum()))
+		if err != nil {
+			return nil, err
+		}
+		if int(rIdx) >= len(ecData) {
+			return nil, ErrReplicateIdsOutOfBounds
+		}
+		return ecData[rIdx], nil
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/hex"
 	"math"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,6 +37,12 @@ func (e *ExecuteModular) HandleReplicatePieceTask(
 		err = ErrDanglingPointer
 		return
 	}
+	// the resolved params are passed to the approval and receive tasks by the task
+	params, err := e.objectStorageParams(ctx, task.GetObjectInfo(), task.GetStorageParams())
+	if err != nil {
+		return
+	}
+	task.SetStorageParams(params)
 	low := task.GetStorageParams().VersionedParams.GetRedundantDataChunkNum() +
 		task.GetStorageParams().VersionedParams.GetRedundantParityChunkNum()
 	high := math.Ceil(float64(low) * e.askReplicateApprovalExFactor)
@@ -78,6 +85,7 @@ func (e *ExecuteModular) AskReplicatePieceApproval(
 		err       error
 		approvals []*gfsptask.GfSpReplicatePieceApprovalTask
 		spInfo    *sptypes.StorageProvider
+		fundings  = make(map[string]string)
 	)
 	approvals, err = e.baseApp.GfSpClient().AskSecondaryReplicatePieceApproval(ctx, task, low, high, timeout)
 	if err != nil {
@@ -97,12 +105,13 @@ func (e *ExecuteModular) AskReplicatePieceApproval(
 		}
 		approval.SetApprovedSpEndpoint(spInfo.GetEndpoint())
 		approval.SetApprovedSpApprovalAddress(spInfo.GetApprovalAddress())
+		fundings[strings.ToLower(approval.GetApprovedSpOperatorAddress())] = spInfo.GetFundingAddress()
 	}
 	if len(approvals) < low {
 		log.CtxErrorw(ctx, "failed to get sufficient sp info from db")
 		return nil, ErrGfSpDB
 	}
-	return approvals, nil
+	return e.rankApprovals(ctx, approvals, fundings), nil
 }
 
 func (e *ExecuteModular) handleReplicatePiece(
@@ -122,6 +131,7 @@ func (e *ExecuteModular) handleReplicatePiece(
 		secondaryOpAddress = make([]string, replCount)
 		secondarySignature = make([][]byte, replCount)
 		approvals          = make([]coretask.ApprovalReplicatePieceTask, replCount)
+		stats              = make([]replicateStat, replCount)
 		finish             bool
 	)
 	resetApprovals := func() (bool, error) {
@@ -134,6 +144,7 @@ func (e *ExecuteModular) handleReplicatePiece(
 				}
 				approvals[rIdx] = backUpApprovals[0]
 				backUpApprovals = backUpApprovals[1:]
+				stats[rIdx] = replicateStat{}
 			}
 		}
 		return doneAll, nil
@@ -143,7 +154,7 @@ func (e *ExecuteModular) handleReplicatePiece(
 			if !done {
 				wg.Add(1)
 				go e.doReplicatePiece(ctx, &wg, rTask, approvals[rIdx],
-					uint32(rIdx), pieceIdx, data[rIdx])
+					uint32(rIdx), pieceIdx, data[rIdx], &stats[rIdx])
 			}
 		}
 		wg.Wait()
@@ -153,7 +164,7 @@ func (e *ExecuteModular) handleReplicatePiece(
 			if !done {
 				wg.Add(1)
 				go e.doReplicatePiece(ctx, &wg, rTask, approvals[rIdx],
-					uint32(rIdx), pieceIdx, data)
+					uint32(rIdx), pieceIdx, data, &stats[rIdx])
 			}
 		}
 		wg.Wait()
@@ -162,6 +173,8 @@ func (e *ExecuteModular) handleReplicatePiece(
 		for rIdx, done := range record {
 			if !done {
 				_, signature, innerErr := e.doneReplicatePiece(ctx, rTask, approvals[rIdx], uint32(rIdx))
+				e.recordReplicateScore(ctx, approvals[rIdx].GetApprovedSpOperatorAddress(), innerErr == nil,
+					stats[rIdx])
 				if innerErr == nil {
 					secondaryOpAddress[rIdx] = approvals[rIdx].GetApprovedSpOperatorAddress()
 					secondarySignature[rIdx] = signature
@@ -219,6 +232,7 @@ func (e *ExecuteModular) doReplicatePiece(
 	replicateIdx uint32,
 	pieceIdx uint32,
 	data []byte,
+	stat *replicateStat,
 ) (err error) {
 	var signature []byte
 	metrics.ReplicatePieceSizeCounter.WithLabelValues(e.Name()).Add(float64(len(data)))
@@ -239,13 +253,14 @@ func (e *ExecuteModular) doReplicatePiece(
 		return
 	}
 	receive.SetSignature(signature)
-	err = e.baseApp.GfSpClient().ReplicatePieceToSecondary(ctx,
-		approval.GetApprovedSpEndpoint(), approval, receive, data)
+	err = e.replicatePieceToSecondary(ctx, approval, receive, data)
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to replicate piece", "replicate_idx", replicateIdx,
 			"piece_idx", pieceIdx, "error", err)
 		return
 	}
+	stat.bytes += uint64(len(data))
+	stat.cost += time.Since(startTime)
 	log.CtxDebugw(ctx, "success to replicate piece", "replicate_idx", replicateIdx,
 		"piece_idx", pieceIdx)
 	return
@@ -273,8 +288,7 @@ func (e *ExecuteModular) doneReplicatePiece(
 		return nil, nil, err
 	}
 	receive.SetSignature(taskSignature)
-	integrity, signature, err = e.baseApp.GfSpClient().DoneReplicatePieceToSecondary(ctx,
-		approval.GetApprovedSpEndpoint(), approval, receive)
+	integrity, signature, err = e.doneReplicatePieceToSecondary(ctx, approval, receive)
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to done replicate piece",
 			"endpoint", approval.GetApprovedSpEndpoint(),
@@ -0,0 +1,409 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/bnb-chain/greenfield-common/go/hash"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	"github.com/bnb-chain/greenfield-storage-provider/core/module"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/manager"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// SPExitListLimit defines the number of objects or buckets listed for handing over at once.
+const SPExitListLimit int64 = 100
+
+var ErrNoHandoverSp = gfsperrors.Register(module.ExecuteModularName, http.StatusNotFound, 40010, "no sp to hand over")
+
+// HandleSPExitTask hands over the data of the exiting SP. The pieces of the objects that
+// this SP stores as secondary are replicated to a new SP picked by the replicate piece
+// approval, and the SPs are notified to migrate the buckets that this SP stores as
+// primary. The objects and buckets are handed over in the ascending order of id, the
+// progress is reported after each one, so the exit resumes from the last id.
+func (e *ExecuteModular) HandleSPExitTask(
+	ctx context.Context,
+	task coretask.SPExitTask) {
+	var (
+		err    error
+		params *storagetypes.Params
+	)
+	defer func() {
+		task.SetError(err)
+		log.CtxDebugw(ctx, "finish to exit sp", "info", task.Info(), "error", err)
+	}()
+	if task.GetSpOperatorAddress() == "" {
+		err = ErrDanglingPointer
+		return
+	}
+	params, err = e.baseApp.Consensus().QueryStorageParams(ctx)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get storage params", "error", err)
+		return
+	}
+	// the burst is raised to the max segment size of the handing over object
+	limiter := rate.NewLimiter(rate.Limit(e.spExitHandoverSpeed), 0)
+	// the task is canceled by the admin of this node, or in the manager
+	cancel := func() bool {
+		return ctx.Err() != nil || errors.Is(e.ReportTask(ctx, task), manager.ErrCanceledTask)
+	}
+	var canceled bool
+	if canceled, err = e.handoverSecondaryObjects(ctx, task, params, limiter, cancel); err != nil || canceled {
+		return
+	}
+	if canceled, err = e.handoverPrimaryBuckets(ctx, task, cancel); err != nil || canceled {
+		return
+	}
+	task.SetFinished(true)
+	log.CtxInfow(ctx, "succeed to exit sp", "info", task.Info())
+}
+
+// handoverSecondaryObjects hands over the pieces of the objects that this SP stores as
+// secondary after the last handed over object.
+func (e *ExecuteModular) handoverSecondaryObjects(
+	ctx context.Context,
+	task coretask.SPExitTask,
+	params *storagetypes.Params,
+	limiter *rate.Limiter,
+	cancel func() bool) (bool, error) {
+	for {
+		objects, err := e.baseApp.GfSpClient().ListObjectsBySecondarySp(ctx, task.GetSpOperatorAddress(),
+			task.GetLastSecondaryObjectId(), SPExitListLimit)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to list objects by secondary sp", "error", err)
+			return false, err
+		}
+		if len(objects) == 0 {
+			return false, nil
+		}
+		for _, object := range objects {
+			objectID := object.GetObjectInfo().Id.Uint64()
+			task.SetLastSecondaryObjectId(objectID)
+			objectInfo, queryErr := e.baseApp.Consensus().QueryObjectInfoByID(ctx, object.GetObjectInfo().Id.String())
+			if queryErr != nil {
+				log.CtxErrorw(ctx, "failed to get object info from consensus", "object_id", objectID, "error", queryErr)
+				e.recordSPExit(task, corespdb.SPExitObjectResource, objectID,
+					object.GetObjectInfo().GetObjectName(), "", queryErr)
+			} else {
+				// the object is deleted or the sp is not secondary any more
+				rIdx := secondaryIndex(objectInfo, task.GetSpOperatorAddress())
+				if objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_SEALED || rIdx < 0 {
+					continue
+				}
+				objectParams, handoverErr := e.objectStorageParams(ctx, objectInfo, params)
+				var dest string
+				if handoverErr == nil {
+					dest, handoverErr = e.handoverSecondaryPieces(ctx, objectInfo, objectParams, uint32(rIdx),
+						e.loadSecondaryPiece(ctx, objectInfo, uint32(rIdx)), limiter)
+				}
+				e.recordSPExit(task, corespdb.SPExitObjectResource, objectID,
+					objectInfo.GetObjectName(), dest, handoverErr)
+			}
+			if cancel() {
+				log.CtxErrorw(ctx, "sp exit task has been canceled", "info", task.Info())
+				return true, nil
+			}
+		}
+	}
+}
+
+// loadSecondaryPiece returns the loader of the pieces that this SP stores as secondary.
+func (e *ExecuteModular) loadSecondaryPiece(
+	ctx context.Context,
+	objectInfo *storagetypes.ObjectInfo,
+	rIdx uint32) func(pIdx uint32) ([]byte, error) {
+	return func(pIdx uint32) ([]byte, error) {
+		var pieceKey string
+		if objectInfo.GetRedundancyType() == storagetypes.REDUNDANCY_EC_TYPE {
+			pieceKey = e.baseApp.PieceOp().ECPieceKey(objectInfo.Id.Uint64(), pIdx, rIdx)
+		} else {
+			pieceKey = e.baseApp.PieceOp().SegmentPieceKey(objectInfo.Id.Uint64(), pIdx)
+		}
+		return e.baseApp.PieceStore().GetPiece(ctx, pieceKey, 0, -1)
+	}
+}
+
+// handoverSecondaryPieces replicates the pieces of the replicate idx loaded by loadPiece
+// to a new SP, the new SP keeps the pieces though it is not listed as secondary on
+// greenfield, until this SP is not listed at the replicate idx. The params must be
+// resolved by the create time of the object. The limiter is optional. Returns the
+// operator address of the new SP.
+func (e *ExecuteModular) handoverSecondaryPieces(
+	ctx context.Context,
+	objectInfo *storagetypes.ObjectInfo,
+	params *storagetypes.Params,
+	rIdx uint32,
+	loadPiece func(pIdx uint32) ([]byte, error),
+	limiter *rate.Limiter) (string, error) {
+	bucketInfo, err := e.baseApp.Consensus().QueryBucketInfo(ctx, objectInfo.GetBucketName())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get bucket info from consensus", "error", err)
+		return "", err
+	}
+	// the primary and the secondary sps of the object are excluded, ask more approvals
+	// than the excluded sps to get at least one new sp.
+	excluded := append([]string{bucketInfo.GetPrimarySpAddress()}, objectInfo.GetSecondarySpAddresses()...)
+	rAppTask := &gfsptask.GfSpReplicatePieceApprovalTask{}
+	rAppTask.InitApprovalReplicatePieceTask(objectInfo, params, e.baseApp.TaskPriority(rAppTask),
+		e.baseApp.OperateAddress())
+	approvals, err := e.AskReplicatePieceApproval(ctx, rAppTask, 1, len(excluded)+1, e.askReplicateApprovalTimeout)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get approvals", "error", err)
+		return "", err
+	}
+	var approval *gfsptask.GfSpReplicatePieceApprovalTask
+	for _, candidate := range approvals {
+		if candidate.GetApprovedSpEndpoint() == "" || containsAddress(excluded, candidate.GetApprovedSpOperatorAddress()) {
+			continue
+		}
+		approval = candidate
+		break
+	}
+	if approval == nil {
+		log.CtxErrorw(ctx, "failed to pick up the sp to hand over", "approvals", len(approvals))
+		return "", ErrNoHandoverSp
+	}
+	dest := approval.GetApprovedSpOperatorAddress()
+	segmentCount := e.baseApp.PieceOp().SegmentCount(objectInfo.GetPayloadSize(),
+		params.VersionedParams.GetMaxSegmentSize())
+	if burst := int(params.VersionedParams.GetMaxSegmentSize()); limiter != nil && burst > limiter.Burst() {
+		limiter.SetBurst(burst)
+	}
+	for pIdx := uint32(0); pIdx < segmentCount; pIdx++ {
+		data, err := loadPiece(pIdx)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to load piece", "segment_idx", pIdx, "error", err)
+			return dest, err
+		}
+		if limiter != nil {
+			if err = limiter.WaitN(ctx, len(data)); err != nil {
+				return dest, err
+			}
+		}
+		receive := &gfsptask.GfSpReceivePieceTask{}
+		receive.InitReceivePieceTask(objectInfo, params, e.baseApp.TaskPriority(rAppTask), rIdx,
+			int32(pIdx), int64(len(data)))
+		receive.SetPieceChecksum(hash.GenerateChecksum(data))
+		receive.SetHandover(true)
+		if err = e.sendHandoverPiece(ctx, approval, receive, data); err != nil {
+			log.CtxErrorw(ctx, "failed to hand over piece", "segment_idx", pIdx, "error", err)
+			return dest, err
+		}
+	}
+	receive := &gfsptask.GfSpReceivePieceTask{}
+	receive.InitReceivePieceTask(objectInfo, params, e.baseApp.TaskPriority(rAppTask), rIdx, -1, 0)
+	receive.SetHandover(true)
+	signature, err := e.baseApp.GfSpClient().SignReceiveTask(ctx, receive)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to sign done receive task", "error", err)
+		return dest, err
+	}
+	receive.SetSignature(signature)
+	integrity, signature, err := e.doneReplicatePieceToSecondary(ctx, approval, receive)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to done hand over piece", "endpoint", approval.GetApprovedSpEndpoint(),
+			"error", err)
+		return dest, err
+	}
+	if int(rIdx+1) >= len(objectInfo.GetChecksums()) {
+		return dest, ErrReplicateIdsOutOfBounds
+	}
+	err = veritySignature(ctx, objectInfo.Id.Uint64(), integrity, objectInfo.GetChecksums()[rIdx+1],
+		approval.GetApprovedSpOperatorAddress(), approval.GetApprovedSpApprovalAddress(), signature)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to verify handover sp signature", "error", err)
+		return dest, err
+	}
+	log.CtxDebugw(ctx, "succeed to hand over secondary pieces", "object_id", objectInfo.Id.Uint64(),
+		"replicate_idx", rIdx, "dest", dest)
+	return dest, nil
+}
+
+// sendHandoverPiece signs the receive task and sends the piece to the new SP.
+func (e *ExecuteModular) sendHandoverPiece(
+	ctx context.Context,
+	approval coretask.ApprovalReplicatePieceTask,
+	receive *gfsptask.GfSpReceivePieceTask,
+	data []byte) error {
+	signature, err := e.baseApp.GfSpClient().SignReceiveTask(ctx, receive)
+	if err != nil {
+		return err
+	}
+	receive.SetSignature(signature)
+	return e.replicatePieceToSecondary(ctx, approval, receive, data)
+}
+
+// handoverPrimaryBuckets notifies the SPs to migrate the buckets that this SP stores as
+// primary after the last handed over bucket.
+func (e *ExecuteModular) handoverPrimaryBuckets(
+	ctx context.Context,
+	task coretask.SPExitTask,
+	cancel func() bool) (bool, error) {
+	ownSp, err := e.baseApp.GfSpDB().GetOwnSpInfo()
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get own sp info", "error", err)
+		return false, ErrGfSpDB
+	}
+	sps, err := e.baseApp.GfSpDB().FetchAllSpWithoutOwnSp(sptypes.STATUS_IN_SERVICE)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get sp list", "error", err)
+		return false, ErrGfSpDB
+	}
+	for {
+		buckets, err := e.baseApp.GfSpClient().ListBucketsByPrimarySp(ctx, task.GetSpOperatorAddress(),
+			task.GetLastPrimaryBucketId(), SPExitListLimit)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to list buckets by primary sp", "error", err)
+			return false, err
+		}
+		if len(buckets) == 0 {
+			return false, nil
+		}
+		if len(sps) == 0 {
+			log.CtxErrorw(ctx, "failed to hand over buckets, no sp in service")
+			return false, ErrNoHandoverSp
+		}
+		for _, bucket := range buckets {
+			bucketID := bucket.GetBucketInfo().Id.Uint64()
+			task.SetLastPrimaryBucketId(bucketID)
+			if bucket.GetRemoved() {
+				continue
+			}
+			bucketInfo, queryErr := e.baseApp.Consensus().QueryBucketInfo(ctx, bucket.GetBucketInfo().GetBucketName())
+			if queryErr != nil {
+				log.CtxErrorw(ctx, "failed to get bucket info from consensus", "bucket_id", bucketID, "error", queryErr)
+				e.recordSPExit(task, corespdb.SPExitBucketResource, bucketID,
+					bucket.GetBucketInfo().GetBucketName(), "", queryErr)
+			} else {
+				// the bucket is deleted or the sp is not primary any more
+				if bucketInfo.Id.Uint64() != bucketID ||
+					!strings.EqualFold(bucketInfo.GetPrimarySpAddress(), task.GetSpOperatorAddress()) {
+					continue
+				}
+				dest, notifyErr := e.notifyMigrateBucket(ctx, bucketInfo, ownSp.GetEndpoint(), sps)
+				e.recordSPExit(task, corespdb.SPExitBucketResource, bucketID, bucketInfo.GetBucketName(),
+					dest, notifyErr)
+			}
+			if cancel() {
+				log.CtxErrorw(ctx, "sp exit task has been canceled", "info", task.Info())
+				return true, nil
+			}
+		}
+	}
+}
+
+// notifyMigrateBucket notifies the SPs in turn from the one picked by the bucket id until
+// one of them accepts to migrate the bucket. Returns the operator address of the SP.
+func (e *ExecuteModular) notifyMigrateBucket(
+	ctx context.Context,
+	bucketInfo *storagetypes.BucketInfo,
+	srcSpEndpoint string,
+	sps []*sptypes.StorageProvider) (string, error) {
+	var (
+		err  error
+		dest string
+	)
+	start := int(bucketInfo.Id.Uint64() % uint64(len(sps)))
+	for i := 0; i < len(sps); i++ {
+		sp := sps[(start+i)%len(sps)]
+		dest = sp.GetOperatorAddress()
+		migrate := &gfsptask.GfSpMigrateBucketTask{}
+		migrate.InitMigrateBucketTask(bucketInfo, srcSpEndpoint, dest, e.baseApp.TaskPriority(migrate),
+			e.baseApp.TaskTimeout(migrate, 0), e.baseApp.TaskMaxRetry(migrate))
+		migrate.SetUpdateTime(time.Now().Unix())
+		var signature []byte
+		signature, err = e.baseApp.GfSpClient().SignMigrateBucketTask(ctx, migrate)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to sign migrate bucket task", "error", err)
+			return "", err
+		}
+		migrate.SetSignature(signature)
+		// the destination sp may request to export the bucket once it accepts, the gateway
+		// only exports the bucket to the destination sp recorded as handed over
+		if err = e.baseApp.GfSpDB().SetSPExitRecord(&corespdb.SPExitRecord{
+			ResourceType:  corespdb.SPExitBucketResource,
+			ResourceID:    bucketInfo.Id.Uint64(),
+			ResourceName:  bucketInfo.GetBucketName(),
+			DestSpAddress: dest,
+			Succeed:       true,
+			UpdateTime:    time.Now().Unix(),
+		}); err != nil {
+			log.CtxErrorw(ctx, "failed to set sp exit record", "error", err)
+			return "", ErrGfSpDB
+		}
+		if err = e.baseApp.GfSpClient().NotifyMigrateBucket(ctx, sp.GetEndpoint(), migrate); err == nil {
+			log.CtxDebugw(ctx, "succeed to hand over bucket", "bucket_name", bucketInfo.GetBucketName(),
+				"dest", dest)
+			return dest, nil
+		}
+		log.CtxErrorw(ctx, "failed to notify sp to migrate bucket", "bucket_name", bucketInfo.GetBucketName(),
+			"dest", dest, "error", err)
+	}
+	return dest, err
+}
+
+// recordSPExit records the result of handing over the object or bucket to SPDB, and
+// counts it in the task.
+func (e *ExecuteModular) recordSPExit(
+	task coretask.SPExitTask,
+	resourceType string,
+	resourceID uint64,
+	resourceName string,
+	dest string,
+	handoverErr error) {
+	record := &corespdb.SPExitRecord{
+		ResourceType:  resourceType,
+		ResourceID:    resourceID,
+		ResourceName:  resourceName,
+		DestSpAddress: dest,
+		Succeed:       handoverErr == nil,
+		UpdateTime:    time.Now().Unix(),
+	}
+	if handoverErr != nil {
+		record.ErrorMessage = handoverErr.Error()
+	}
+	if err := e.baseApp.GfSpDB().SetSPExitRecord(record); err != nil {
+		log.Errorw("failed to set sp exit record", "resource_type", resourceType, "resource_id", resourceID,
+			"error", err)
+	}
+	switch {
+	case resourceType == corespdb.SPExitObjectResource && handoverErr == nil:
+		task.SetHandoverObjectNumber(task.GetHandoverObjectNumber() + 1)
+	case resourceType == corespdb.SPExitObjectResource:
+		task.SetFailedObjectNumber(task.GetFailedObjectNumber() + 1)
+	case handoverErr == nil:
+		task.SetHandoverBucketNumber(task.GetHandoverBucketNumber() + 1)
+	default:
+		task.SetFailedBucketNumber(task.GetFailedBucketNumber() + 1)
+	}
+}
+
+// secondaryIndex returns the replicate idx of the sp in the secondary sps of the object,
+// returns -1 if the sp is not secondary.
+func secondaryIndex(objectInfo *storagetypes.ObjectInfo, spAddress string) int {
+	for idx, address := range objectInfo.GetSecondarySpAddresses() {
+		if strings.EqualFold(address, spAddress) {
+			return idx
+		}
+	}
+	return -1
+}
+
+func containsAddress(addresses []string, address string) bool {
+	for _, addr := range addresses {
+		if strings.EqualFold(addr, address) {
+			return true
+		}
+	}
+	return false
+}
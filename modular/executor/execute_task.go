@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
@@ -145,9 +144,17 @@ func (e *ExecuteModular) HandleReceivePieceTask(
 		if err != nil {
 			log.CtxErrorw(ctx, "failed to delete integrity")
 		}
-		var pieceKey string
+		var (
+			pieceKey string
+			params   *storagetypes.Params
+		)
+		params, err = e.baseApp.QueryStorageParamsByTimestamp(ctx, onChainObject.GetCreateAt())
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to query storage params by object create time", "error", err)
+			return
+		}
 		segmentCount := e.baseApp.PieceOp().SegmentCount(onChainObject.GetPayloadSize(),
-			task.GetStorageParams().GetMaxPayloadSize())
+			params.VersionedParams.GetMaxSegmentSize())
 		for i := uint32(0); i < segmentCount; i++ {
 			if task.GetObjectInfo().GetRedundancyType() == storagetypes.REDUNDANCY_EC_TYPE {
 				pieceKey = e.baseApp.PieceOp().ECPieceKey(onChainObject.Id.Uint64(),
@@ -173,6 +180,8 @@ func (e *ExecuteModular) HandleGCObjectTask(
 		task.SetError(err)
 	}()
 
+	e.retryGCFailedPieces(ctx)
+	e.gcHandoverPieces(ctx)
 	objects, endBlockNumber, err := e.baseApp.GfSpClient().ListDeletedObjectsByBlockNumberRange(
 		ctx, e.baseApp.OperateAddress(), task.GetStartBlockNumber(),
 		task.GetEndBlockNumber(), true)
@@ -184,86 +193,38 @@ func (e *ExecuteModular) HandleGCObjectTask(
 		return
 	}
 
+	// the task is canceled by the admin of this node, or in the manager
 	cancel := func() bool {
-		return errors.Is(e.ReportTask(ctx, task), manager.ErrCanceledTask)
+		return ctx.Err() != nil || errors.Is(e.ReportTask(ctx, task), manager.ErrCanceledTask)
 	}
 
-	var (
-		objectInfo       *storagetypes.ObjectInfo
-		deletingBlock    uint64
-		deletingObjectID uint64
-		deletingIdx      int
-		pieceKey         string
-		segmentCount     uint32
-		params           *storagetypes.Params
-	)
-	params, err = e.baseApp.GfSpDB().GetStorageParams()
-	if err != nil {
-		log.CtxErrorw(ctx, "failed to get params", "error", err)
-		return
-	}
-	for {
+	// the objects are deleted in batches, the progress is reported after each batch, the
+	// pieces failed to delete are retried by the later tasks, so they do not block the
+	// block range.
+	for start := 0; start < len(objects); start += e.gcObjectParallel {
 		log.CtxDebugw(ctx, "report gc object process", "info", task.Info())
 		if cancel() {
 			return
 		}
-		if deletingIdx >= len(objects) {
-			deletingBlock = task.GetEndBlockNumber() + 1
+		end := start + e.gcObjectParallel
+		if end > len(objects) {
+			end = len(objects)
 		}
-		if deletingBlock > endBlockNumber {
-			deletingBlock = task.GetEndBlockNumber() + 1
+		objectInfos := make([]*storagetypes.ObjectInfo, 0, end-start)
+		for _, object := range objects[start:end] {
+			objectInfos = append(objectInfos, object.GetObjectInfo())
 		}
-		task.SetCurrentBlockNumber(deletingBlock)
-		if task.GetCurrentBlockNumber() > task.GetEndBlockNumber() {
+		if err = e.gcObjects(ctx, objectInfos); err != nil {
 			return
 		}
-		object := objects[deletingIdx]
-		objectInfo = object.GetObjectInfo()
-		deletingIdx++
-		deletingObjectID = objectInfo.Id.Uint64()
-		deletingBlock = uint64(object.GetDeleteAt())
-
-		segmentCount = e.baseApp.PieceOp().SegmentCount(
-			objectInfo.GetPayloadSize(), params.VersionedParams.GetMaxSegmentSize())
-		for segIdx := uint32(0); segIdx < segmentCount; segIdx++ {
-			pieceKey = e.baseApp.PieceOp().SegmentPieceKey(deletingObjectID, segIdx)
-			err = e.baseApp.PieceStore().DeletePiece(ctx, pieceKey)
-			if err != nil {
-				log.CtxErrorw(ctx, "failed to delete segment piece",
-					"piece_key", pieceKey, "error", err)
-				return
-			}
-			log.CtxDebugw(ctx, "succeed to delete primary payload", "piece_key", pieceKey)
-		}
-		for rIdx, address := range objectInfo.GetSecondarySpAddresses() {
-			if strings.Compare(e.baseApp.OperateAddress(), address) != 0 {
-				continue
-			}
-			for segIdx := uint32(0); segIdx < segmentCount; segIdx++ {
-				if objectInfo.GetRedundancyType() == storagetypes.REDUNDANCY_REPLICA_TYPE {
-					pieceKey = e.baseApp.PieceOp().SegmentPieceKey(deletingObjectID, segIdx)
-				} else {
-					pieceKey = e.baseApp.PieceOp().ECPieceKey(deletingObjectID, segIdx, uint32(rIdx))
-				}
-				err = e.baseApp.PieceStore().DeletePiece(ctx, pieceKey)
-				if err != nil {
-					log.CtxErrorw(ctx, "failed to delete replicate piece",
-						"piece_key", pieceKey, "error", err)
-					return
-				}
-				log.CtxDebugw(ctx, "succeed to delete secondary piece", "piece_key", pieceKey)
-			}
+		last := objects[end-1]
+		task.SetCurrentBlockNumber(uint64(last.GetDeleteAt()))
+		task.SetLastDeletedObjectId(last.GetObjectInfo().Id.Uint64())
+		if uint64(last.GetDeleteAt()) > endBlockNumber {
 			break
 		}
-		err = e.baseApp.GfSpDB().DeleteObjectIntegrity(deletingObjectID)
-		if err != nil {
-			log.CtxErrorw(ctx, "failed to delete integrity meta", "error", err)
-			return
-		}
-		task.SetCurrentBlockNumber(deletingBlock)
-		task.SetLastDeletedObjectId(deletingObjectID)
-		metrics.GCObjectCounter.WithLabelValues(e.Name()).Inc()
 	}
+	task.SetCurrentBlockNumber(task.GetEndBlockNumber() + 1)
 }
 
 func (e *ExecuteModular) HandleGCZombiePieceTask(
@@ -277,3 +238,21 @@ func (e *ExecuteModular) HandleGCMetaTask(
 	task coretask.GCMetaTask) {
 	log.CtxWarn(ctx, "gc meta future support")
 }
+
+// objectStorageParams returns the copy of the params whose versioned params are resolved
+// by the create time of the object, the other params are kept.
+func (e *ExecuteModular) objectStorageParams(
+	ctx context.Context,
+	objectInfo *storagetypes.ObjectInfo,
+	params *storagetypes.Params) (
+	*storagetypes.Params, error) {
+	versioned, err := e.baseApp.QueryStorageParamsByTimestamp(ctx, objectInfo.GetCreateAt())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query storage params by object create time",
+			"object_id", objectInfo.Id.Uint64(), "error", err)
+		return nil, err
+	}
+	resolved := *params
+	resolved.VersionedParams = versioned.VersionedParams
+	return &resolved, nil
+}
@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -41,6 +42,22 @@ type ExecuteModular struct {
 	doingGCObjectTaskCnt       int64
 	doingGCZombiePieceTaskCnt  int64
 	doingGCGCMetaTaskCnt       int64
+	doingMigrateBucketTaskCnt  int64
+	doingSPExitTaskCnt         int64
+	doingRepairPieceTaskCnt    int64
+
+	spExitHandoverSpeed int64
+	gcObjectParallel    int
+
+	// the diversity constraints of ranking the secondary sp candidates
+	secondaryDistinctFunding bool
+	secondaryRegions         map[string]string
+
+	replicateOverP2P             bool
+	p2pReplicateFallbackInterval int64
+	// p2pReplicateFallback records the unix time until which the replicates to the
+	// secondary sp fall back to http
+	p2pReplicateFallback sync.Map
 }
 
 func (e *ExecuteModular) Name() string {
@@ -105,6 +122,10 @@ func (e *ExecuteModular) omitError(err error) bool {
 }
 
 func (e *ExecuteModular) AskTask(ctx context.Context, limit corercmgr.Limit) {
+	// no new task is taken when the node is draining
+	if e.baseApp.Draining() {
+		return
+	}
 	askTask, err := e.baseApp.GfSpClient().AskTask(ctx, limit)
 	if err != nil {
 		if e.omitError(err) {
@@ -127,6 +148,22 @@ func (e *ExecuteModular) AskTask(ctx context.Context, limit corercmgr.Limit) {
 	defer e.ReleaseResource(ctx, span)
 	defer e.ReportTask(ctx, askTask)
 	ctx = log.WithValue(ctx, log.CtxKeyTask, askTask.Key().String())
+	// the task is canceled by admin through the context, the report above is deferred with
+	// the context before canceling, so the canceled task is still reported with the error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done, err := e.baseApp.BeginInflightTask(askTask.Key().String(), cancel)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to begin task", "error", err)
+		askTask.SetError(err)
+		return
+	}
+	defer done()
+	defer func() {
+		if ctx.Err() != nil && askTask.Error() == nil {
+			askTask.SetError(gfspapp.ErrTaskCanceled)
+		}
+	}()
 	switch t := askTask.(type) {
 	case *gfsptask.GfSpReplicatePieceTask:
 		metrics.ExecutorReplicatePieceTaskCounter.WithLabelValues(e.Name()).Inc()
@@ -158,6 +195,19 @@ func (e *ExecuteModular) AskTask(ctx context.Context, limit corercmgr.Limit) {
 		atomic.AddInt64(&e.doingGCGCMetaTaskCnt, 1)
 		defer atomic.AddInt64(&e.doingGCGCMetaTaskCnt, -1)
 		e.HandleGCMetaTask(ctx, t)
+	case *gfsptask.GfSpMigrateBucketTask:
+		atomic.AddInt64(&e.doingMigrateBucketTaskCnt, 1)
+		defer atomic.AddInt64(&e.doingMigrateBucketTaskCnt, -1)
+		e.HandleMigrateBucketTask(ctx, t)
+	case *gfsptask.GfSpSPExitTask:
+		atomic.AddInt64(&e.doingSPExitTaskCnt, 1)
+		defer atomic.AddInt64(&e.doingSPExitTaskCnt, -1)
+		e.HandleSPExitTask(ctx, t)
+	case *gfsptask.GfSpRepairPieceTask:
+		metrics.ExecutorRepairPieceTaskCounter.WithLabelValues(e.Name()).Inc()
+		atomic.AddInt64(&e.doingRepairPieceTaskCnt, 1)
+		defer atomic.AddInt64(&e.doingRepairPieceTaskCnt, -1)
+		e.HandleRepairPieceTask(ctx, t)
 	default:
 		log.CtxErrorw(ctx, "unsupported task type")
 	}
@@ -200,12 +250,15 @@ func (e *ExecuteModular) ReleaseResource(
 
 func (e *ExecuteModular) Statistics() string {
 	return fmt.Sprintf(
-		"maxAsk[%d], asking[%d], replicate[%d], seal[%d], receive[%d], gcObject[%d], gcZombie[%d], gcMeta[%d]",
+		"maxAsk[%d], asking[%d], replicate[%d], seal[%d], receive[%d], gcObject[%d], gcZombie[%d], gcMeta[%d], migrateBucket[%d], spExit[%d], repairPiece[%d]",
 		atomic.LoadInt64(&e.maxExecuteNum), atomic.LoadInt64(&e.executingNum),
 		atomic.LoadInt64(&e.doingReplicatePieceTaskCnt),
 		atomic.LoadInt64(&e.doingSpSealObjectTaskCnt),
 		atomic.LoadInt64(&e.doingReceivePieceTaskCnt),
 		atomic.LoadInt64(&e.doingGCObjectTaskCnt),
 		atomic.LoadInt64(&e.doingGCZombiePieceTaskCnt),
-		atomic.LoadInt64(&e.doingGCGCMetaTaskCnt))
+		atomic.LoadInt64(&e.doingGCGCMetaTaskCnt),
+		atomic.LoadInt64(&e.doingMigrateBucketTaskCnt),
+		atomic.LoadInt64(&e.doingSPExitTaskCnt),
+		atomic.LoadInt64(&e.doingRepairPieceTaskCnt))
 }
@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"strings"
+
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
@@ -34,6 +36,15 @@ const (
 	// DefaultStatisticsOutputInterval defines the default interval for output statistics info,
 	// it is used to log and debug.
 	DefaultStatisticsOutputInterval int = 60
+	// DefaultExecutorSPExitHandoverSpeed defines the default bytes per second of handing
+	// over the secondary pieces when the sp exits.
+	DefaultExecutorSPExitHandoverSpeed int64 = 20 * 1024 * 1024
+	// DefaultExecutorGCObjectParallel defines the default number of objects that are
+	// deleted concurrently by the gc object task.
+	DefaultExecutorGCObjectParallel int = 16
+	// DefaultExecutorP2PReplicateFallbackInterval defines the default seconds of
+	// replicating to the secondary sp over http after the p2p replicate fails.
+	DefaultExecutorP2PReplicateFallbackInterval int64 = 600
 )
 
 func NewExecuteModular(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig) (coremodule.Modular, error) {
@@ -73,6 +84,24 @@ func DefaultExecutorOptions(executor *ExecuteModular, cfg *gfspconfig.GfSpConfig
 		cfg.Executor.MaxListenSealRetry = DefaultExecutorMaxListenSealRetry
 	}
 	executor.maxListenSealRetry = cfg.Executor.MaxListenSealRetry
+	if cfg.Executor.SPExitHandoverSpeed == 0 {
+		cfg.Executor.SPExitHandoverSpeed = DefaultExecutorSPExitHandoverSpeed
+	}
+	executor.spExitHandoverSpeed = cfg.Executor.SPExitHandoverSpeed
+	if cfg.Executor.GCObjectParallel == 0 {
+		cfg.Executor.GCObjectParallel = DefaultExecutorGCObjectParallel
+	}
+	executor.gcObjectParallel = cfg.Executor.GCObjectParallel
+	executor.secondaryDistinctFunding = cfg.Executor.SecondaryDistinctFunding
+	executor.secondaryRegions = make(map[string]string)
+	for address, region := range cfg.Executor.SecondaryRegions {
+		executor.secondaryRegions[strings.ToLower(address)] = region
+	}
+	executor.replicateOverP2P = cfg.Executor.ReplicateOverP2P
+	if cfg.Executor.P2PReplicateFallbackInterval == 0 {
+		cfg.Executor.P2PReplicateFallbackInterval = DefaultExecutorP2PReplicateFallbackInterval
+	}
+	executor.p2pReplicateFallbackInterval = cfg.Executor.P2PReplicateFallbackInterval
 	executor.statisticsOutputInterval = DefaultStatisticsOutputInterval
 	return nil
 }
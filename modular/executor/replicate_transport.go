@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspclient"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/p2p"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+// replicatePieceToSecondary replicates the piece to the secondary SP over p2p if it is
+// preferred, and falls back to http if the piece fails to be transported over p2p, the
+// piece refused by the secondary SP is returned as it is, it is refused by http as well.
+func (e *ExecuteModular) replicatePieceToSecondary(
+	ctx context.Context,
+	approval coretask.ApprovalReplicatePieceTask,
+	receive coretask.ReceivePieceTask,
+	data []byte) error {
+	if e.preferP2PReplicate(approval) {
+		err := e.baseApp.GfSpClient().ReplicatePieceToSecondaryByP2P(ctx, approval, receive, data)
+		if !isP2PTransportError(err) {
+			return err
+		}
+		e.fallbackP2PReplicate(ctx, approval, err)
+	}
+	return e.baseApp.GfSpClient().ReplicatePieceToSecondary(ctx, approval.GetApprovedSpEndpoint(),
+		approval, receive, data)
+}
+
+// doneReplicatePieceToSecondary notifies the secondary SP that all the pieces are
+// replicated, it is transport independent of the replicated pieces, because both
+// transports hand the pieces over to the receiver of the secondary SP.
+func (e *ExecuteModular) doneReplicatePieceToSecondary(
+	ctx context.Context,
+	approval coretask.ApprovalReplicatePieceTask,
+	receive coretask.ReceivePieceTask) (
+	[]byte, []byte, error) {
+	if e.preferP2PReplicate(approval) {
+		integrity, signature, err := e.baseApp.GfSpClient().DoneReplicatePieceToSecondaryByP2P(ctx, approval, receive)
+		if !isP2PTransportError(err) {
+			return integrity, signature, err
+		}
+		e.fallbackP2PReplicate(ctx, approval, err)
+	}
+	return e.baseApp.GfSpClient().DoneReplicatePieceToSecondary(ctx, approval.GetApprovedSpEndpoint(),
+		approval, receive)
+}
+
+func (e *ExecuteModular) preferP2PReplicate(approval coretask.ApprovalReplicatePieceTask) bool {
+	if !e.replicateOverP2P {
+		return false
+	}
+	until, ok := e.p2pReplicateFallback.Load(strings.ToLower(approval.GetApprovedSpOperatorAddress()))
+	return !ok || time.Now().Unix() >= until.(int64)
+}
+
+func (e *ExecuteModular) fallbackP2PReplicate(
+	ctx context.Context,
+	approval coretask.ApprovalReplicatePieceTask,
+	err error) {
+	log.CtxWarnw(ctx, "failed to replicate piece over p2p, fall back to http",
+		"sp", approval.GetApprovedSpOperatorAddress(), "error", err)
+	e.p2pReplicateFallback.Store(strings.ToLower(approval.GetApprovedSpOperatorAddress()),
+		time.Now().Unix()+e.p2pReplicateFallbackInterval)
+}
+
+// isP2PTransportError returns whether the error is raised by the p2p transport rather than
+// responded by the secondary SP, the errors are compared by the inner code because they
+// are decoded from the grpc response.
+func isP2PTransportError(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch gfsperrors.MakeGfSpError(err).GetInnerCode() {
+	case p2p.ErrReplicateUnsupported.GetInnerCode(),
+		p2p.ErrReplicateTransport.GetInnerCode(),
+		gfspclient.ErrRpcUnknown.GetInnerCode():
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,42 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspclient"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/p2p"
+)
+
+func TestIsP2PTransportError(t *testing.T) {
+	// decodeGfSpError mocks the error decoded from the grpc response, it keeps the inner
+	// code rather than the registered error pointer.
+	decodeGfSpError := func(err *gfsperrors.GfSpError) error {
+		return &gfsperrors.GfSpError{
+			CodeSpace:      err.GetCodeSpace(),
+			HttpStatusCode: err.GetHttpStatusCode(),
+			InnerCode:      err.GetInnerCode(),
+			Description:    err.GetDescription(),
+		}
+	}
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "success", err: nil, want: false},
+		{name: "unsupported protocol", err: decodeGfSpError(p2p.ErrReplicateUnsupported), want: true},
+		{name: "stream failed", err: decodeGfSpError(p2p.ErrReplicateTransport), want: true},
+		{name: "p2p module unavailable", err: gfspclient.ErrRpcUnknown, want: true},
+		{name: "secondary sp refused", err: decodeGfSpError(p2p.ErrRepeatedTask), want: false},
+		{name: "secondary sp inner error", err: gfsperrors.MakeGfSpError(errors.New("mock error")), want: false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isP2PTransportError(tt.err))
+		})
+	}
+}
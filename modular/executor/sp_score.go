@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/metrics"
+)
+
+// replicateStat accumulates the replicated pieces to a secondary SP in a replicate task,
+// it is recorded to the SP score when the replicate is done.
+type replicateStat struct {
+	bytes uint64
+	cost  time.Duration
+}
+
+// rankApprovals ranks the secondary SP candidates by the score in descending order, the
+// candidates that break the diversity constraints with a higher ranked candidate are
+// ranked behind the others, so they are only used as backups. The fundings map the
+// operator address to the funding address of the candidates.
+func (e *ExecuteModular) rankApprovals(
+	ctx context.Context,
+	approvals []*gfsptask.GfSpReplicatePieceApprovalTask,
+	fundings map[string]string) []*gfsptask.GfSpReplicatePieceApprovalTask {
+	scores, err := e.baseApp.GfSpDB().ListSPScores()
+	if err != nil {
+		log.CtxWarnw(ctx, "failed to list sp scores, use the arrival order", "error", err)
+		return approvals
+	}
+	defaultScore := (&corespdb.SPScore{}).Score()
+	scoreMap := make(map[string]float64, len(scores))
+	for _, score := range scores {
+		scoreMap[strings.ToLower(score.SpAddress)] = score.Score()
+	}
+	scoreOf := func(approval *gfsptask.GfSpReplicatePieceApprovalTask) float64 {
+		if score, ok := scoreMap[strings.ToLower(approval.GetApprovedSpOperatorAddress())]; ok {
+			return score
+		}
+		return defaultScore
+	}
+	sort.SliceStable(approvals, func(i, j int) bool {
+		return scoreOf(approvals[i]) > scoreOf(approvals[j])
+	})
+
+	var (
+		ranked      = make([]*gfsptask.GfSpReplicatePieceApprovalTask, 0, len(approvals))
+		deferred    []*gfsptask.GfSpReplicatePieceApprovalTask
+		usedFunding = make(map[string]bool)
+		usedRegion  = make(map[string]bool)
+	)
+	for _, approval := range approvals {
+		address := strings.ToLower(approval.GetApprovedSpOperatorAddress())
+		funding := fundings[address]
+		region, hasRegion := e.secondaryRegions[address]
+		if (e.secondaryDistinctFunding && funding != "" && usedFunding[funding]) ||
+			(hasRegion && usedRegion[region]) {
+			deferred = append(deferred, approval)
+			continue
+		}
+		if funding != "" {
+			usedFunding[funding] = true
+		}
+		if hasRegion {
+			usedRegion[region] = true
+		}
+		ranked = append(ranked, approval)
+	}
+	return append(ranked, deferred...)
+}
+
+// recordReplicateScore records the replicate result of the secondary SP to its score.
+func (e *ExecuteModular) recordReplicateScore(ctx context.Context, spAddress string, succeed bool,
+	stat replicateStat) {
+	score, err := e.baseApp.GfSpDB().UpdateSPReplicateScore(spAddress, succeed, stat.bytes,
+		stat.cost.Milliseconds())
+	if err != nil {
+		log.CtxWarnw(ctx, "failed to update sp replicate score", "sp", spAddress, "error", err)
+		return
+	}
+	metrics.SPScoreGauge.WithLabelValues(spAddress).Set(score.Score())
+}
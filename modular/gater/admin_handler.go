@@ -231,9 +231,9 @@ func (g *GateModular) challengeHandler(w http.ResponseWriter, r *http.Request) {
 		err = ErrInvalidHeader
 		return
 	}
-	parms, err := g.baseApp.Consensus().QueryStorageParams(reqCtx.Context())
+	parms, err := g.baseApp.QueryStorageParamsByTimestamp(reqCtx.Context(), objectInfo.GetCreateAt())
 	if err != nil {
-		log.CtxErrorw(reqCtx.Context(), "failed to get storage params", "error", err)
+		log.CtxErrorw(reqCtx.Context(), "failed to query storage params by object create time", "error", err)
 		return
 	}
 	var pieceSize uint64
@@ -371,3 +371,34 @@ func (g *GateModular) replicateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	log.CtxDebugw(reqCtx.Context(), "succeed to replicate piece")
 }
+
+// p2pNodeHandler handles the query p2p node info request, other SPs discover the p2p
+// node of this SP by the endpoint on greenfield.
+func (g *GateModular) p2pNodeHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		reqCtx *RequestContext
+		node   string
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHttpCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+	// ignore the error, the p2p node info is public, the peers are verified by the
+	// signature of the p2p messages
+	reqCtx, _ = NewRequestContext(r)
+
+	node, err = g.baseApp.GfSpClient().QueryP2PSelfNode(reqCtx.Context())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to query p2p self node", "error", err)
+		return
+	}
+	w.Header().Set(model.GnfdP2PNodeHeader, node)
+}
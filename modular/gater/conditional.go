@@ -0,0 +1,114 @@
+package gater
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	metadatatypes "github.com/bnb-chain/greenfield-storage-provider/modular/metadata/types"
+	"github.com/bnb-chain/greenfield-storage-provider/util"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// PublicObjectCacheMaxAge defines the seconds that the public object can be cached by
+// CDNs and browsers, the private object must be revalidated every time.
+const PublicObjectCacheMaxAge = 3600
+
+// objectETag returns the strong ETag of the object, it is derived from the integrity
+// checksum on chain, so it changes only if the object content changes.
+func objectETag(objectInfo *storagetypes.ObjectInfo) string {
+	if len(objectInfo.GetChecksums()) == 0 {
+		return ""
+	}
+	return "\"" + hex.EncodeToString(objectInfo.GetChecksums()[0]) + "\""
+}
+
+// objectLastModified returns the seal time of the object, the metadata service records
+// it as the update time of the sealed object, falls back to the create time on chain.
+func objectLastModified(object *metadatatypes.Object, objectInfo *storagetypes.ObjectInfo) time.Time {
+	if object != nil && object.GetUpdateTime() != 0 {
+		return time.Unix(object.GetUpdateTime(), 0).UTC()
+	}
+	return time.Unix(objectInfo.GetCreateAt(), 0).UTC()
+}
+
+// isPublicObject returns whether the object is public read, the object inherits the
+// visibility of the bucket if it is not specified.
+func isPublicObject(objectInfo *storagetypes.ObjectInfo, bucketInfo *storagetypes.BucketInfo) bool {
+	visibility := objectInfo.GetVisibility()
+	if visibility == storagetypes.VISIBILITY_TYPE_INHERIT || visibility == storagetypes.VISIBILITY_TYPE_UNSPECIFIED {
+		visibility = bucketInfo.GetVisibility()
+	}
+	return visibility == storagetypes.VISIBILITY_TYPE_PUBLIC_READ
+}
+
+// objectCacheControl returns the Cache-Control of the object by the visibility.
+func objectCacheControl(objectInfo *storagetypes.ObjectInfo, bucketInfo *storagetypes.BucketInfo) string {
+	if isPublicObject(objectInfo, bucketInfo) {
+		return "public, max-age=" + util.Uint64ToString(PublicObjectCacheMaxAge)
+	}
+	return "private, no-cache"
+}
+
+// setObjectHeaders sets the metadata headers of the object for GET and HEAD requests.
+func setObjectHeaders(w http.ResponseWriter, objectInfo *storagetypes.ObjectInfo, bucketInfo *storagetypes.BucketInfo,
+	lastModified time.Time) {
+	w.Header().Set(model.ContentTypeHeader, objectInfo.GetContentType())
+	w.Header().Set(model.AcceptRangesHeader, model.AcceptRangesBytesValue)
+	w.Header().Set(model.CacheControlHeader, objectCacheControl(objectInfo, bucketInfo))
+	w.Header().Set(model.LastModifiedHeader, lastModified.Format(http.TimeFormat))
+	if etag := objectETag(objectInfo); etag != "" {
+		w.Header().Set(model.ETagHeader, etag)
+	}
+}
+
+// checkPreconditions evaluates the conditional headers of the request in the order of
+// RFC 7232, returns http.StatusNotModified or http.StatusPreconditionFailed if the
+// object should not be served, otherwise returns 0 and whether the Range header is
+// ignored because of If-Range.
+func checkPreconditions(header http.Header, etag string, lastModified time.Time) (int, bool) {
+	if ifMatch := header.Get(model.IfMatchHeader); ifMatch != "" {
+		if !matchETag(ifMatch, etag, false) {
+			return http.StatusPreconditionFailed, false
+		}
+	} else if since, err := http.ParseTime(header.Get(model.IfUnmodifiedSinceHeader)); err == nil {
+		if lastModified.After(since) {
+			return http.StatusPreconditionFailed, false
+		}
+	}
+	if ifNoneMatch := header.Get(model.IfNoneMatchHeader); ifNoneMatch != "" {
+		if matchETag(ifNoneMatch, etag, true) {
+			return http.StatusNotModified, false
+		}
+	} else if since, err := http.ParseTime(header.Get(model.IfModifiedSinceHeader)); err == nil {
+		if !lastModified.After(since) {
+			return http.StatusNotModified, false
+		}
+	}
+	ifRange := header.Get(model.IfRangeHeader)
+	if ifRange == "" || header.Get(model.RangeHeader) == "" {
+		return 0, false
+	}
+	if strings.HasPrefix(ifRange, "\"") {
+		return 0, etag == "" || ifRange != etag
+	}
+	since, err := http.ParseTime(ifRange)
+	return 0, err != nil || !lastModified.Equal(since)
+}
+
+// matchETag returns whether the If-Match or If-None-Match header value matches the
+// ETag of the object, the weak ETags only match in weak comparison.
+func matchETag(headerValue string, etag string, weak bool) bool {
+	for _, value := range strings.Split(headerValue, ",") {
+		value = strings.TrimSpace(value)
+		if weak {
+			value = strings.TrimPrefix(value, "W/")
+		}
+		if value == "*" || (etag != "" && value == etag) {
+			return true
+		}
+	}
+	return false
+}
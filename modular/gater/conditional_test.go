@@ -0,0 +1,78 @@
+package gater
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+func TestCheckPreconditions(t *testing.T) {
+	etag := "\"mock-checksum\""
+	lastModified := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	before := lastModified.Add(-time.Hour).Format(http.TimeFormat)
+	after := lastModified.Add(time.Hour).Format(http.TimeFormat)
+	testCases := []struct {
+		name        string
+		header      map[string]string
+		statusCode  int
+		ignoreRange bool
+	}{
+		{"no conditional header", nil, 0, false},
+		{"if-match matched", map[string]string{model.IfMatchHeader: "\"other\", " + etag}, 0, false},
+		{"if-match any", map[string]string{model.IfMatchHeader: "*"}, 0, false},
+		{"if-match not matched", map[string]string{model.IfMatchHeader: "\"other\""}, http.StatusPreconditionFailed, false},
+		{"if-match weak etag", map[string]string{model.IfMatchHeader: "W/" + etag}, http.StatusPreconditionFailed, false},
+		{"if-unmodified-since modified", map[string]string{model.IfUnmodifiedSinceHeader: before}, http.StatusPreconditionFailed, false},
+		{"if-unmodified-since not modified", map[string]string{model.IfUnmodifiedSinceHeader: after}, 0, false},
+		{"if-none-match matched", map[string]string{model.IfNoneMatchHeader: etag}, http.StatusNotModified, false},
+		{"if-none-match weak etag", map[string]string{model.IfNoneMatchHeader: "W/" + etag}, http.StatusNotModified, false},
+		{"if-none-match not matched", map[string]string{model.IfNoneMatchHeader: "\"other\""}, 0, false},
+		{"if-none-match overrides if-modified-since", map[string]string{
+			model.IfNoneMatchHeader: "\"other\"", model.IfModifiedSinceHeader: after}, 0, false},
+		{"if-modified-since not modified", map[string]string{model.IfModifiedSinceHeader: after}, http.StatusNotModified, false},
+		{"if-modified-since modified", map[string]string{model.IfModifiedSinceHeader: before}, 0, false},
+		{"if-range etag matched", map[string]string{model.RangeHeader: "bytes=0-1", model.IfRangeHeader: etag}, 0, false},
+		{"if-range etag not matched", map[string]string{model.RangeHeader: "bytes=0-1", model.IfRangeHeader: "\"other\""}, 0, true},
+		{"if-range date matched", map[string]string{model.RangeHeader: "bytes=0-1",
+			model.IfRangeHeader: lastModified.Format(http.TimeFormat)}, 0, false},
+		{"if-range date not matched", map[string]string{model.RangeHeader: "bytes=0-1", model.IfRangeHeader: before}, 0, true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range testCase.header {
+				header.Set(k, v)
+			}
+			statusCode, ignoreRange := checkPreconditions(header, etag, lastModified)
+			assert.Equal(t, testCase.statusCode, statusCode)
+			assert.Equal(t, testCase.ignoreRange, ignoreRange)
+		})
+	}
+}
+
+func TestIsPublicObject(t *testing.T) {
+	testCases := []struct {
+		name             string
+		objectVisibility storagetypes.VisibilityType
+		bucketVisibility storagetypes.VisibilityType
+		public           bool
+	}{
+		{"public object", storagetypes.VISIBILITY_TYPE_PUBLIC_READ, storagetypes.VISIBILITY_TYPE_PRIVATE, true},
+		{"private object", storagetypes.VISIBILITY_TYPE_PRIVATE, storagetypes.VISIBILITY_TYPE_PUBLIC_READ, false},
+		{"inherit public bucket", storagetypes.VISIBILITY_TYPE_INHERIT, storagetypes.VISIBILITY_TYPE_PUBLIC_READ, true},
+		{"inherit private bucket", storagetypes.VISIBILITY_TYPE_INHERIT, storagetypes.VISIBILITY_TYPE_PRIVATE, false},
+		{"unspecified", storagetypes.VISIBILITY_TYPE_UNSPECIFIED, storagetypes.VISIBILITY_TYPE_UNSPECIFIED, false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.public, isPublicObject(
+				&storagetypes.ObjectInfo{Visibility: testCase.objectVisibility},
+				&storagetypes.BucketInfo{Visibility: testCase.bucketVisibility}))
+		})
+	}
+}
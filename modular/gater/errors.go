@@ -3,6 +3,7 @@ package gater
 import (
 	"encoding/xml"
 	"net/http"
+	"strings"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
 	"github.com/bnb-chain/greenfield-storage-provider/core/module"
@@ -11,24 +12,31 @@ import (
 )
 
 var (
-	ErrUnsupportedSignType    = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50001, "unsupported sign type")
-	ErrAuthorizationFormat    = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50002, "authorization format error")
-	ErrRequestConsistent      = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50003, "request is tampered")
-	ErrNoPermission           = gfsperrors.Register(module.GateModularName, http.StatusUnauthorized, 50004, "no permission")
-	ErrDecodeMsg              = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50005, "gnfd msg encoding error")
-	ErrValidateMsg            = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50006, "gnfd msg validate error")
-	ErrRefuseApproval         = gfsperrors.Register(module.GateModularName, http.StatusOK, 50007, "approval request is refuse")
-	ErrUnsupportedRequestType = gfsperrors.Register(module.GateModularName, http.StatusNotFound, 50008, "unsupported request type")
-	ErrInvalidHeader          = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50009, "invalid request header")
-	ErrInvalidQuery           = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50010, "invalid request header params for query")
-	ErrEncodeResponse         = gfsperrors.Register(module.GateModularName, http.StatusInternalServerError, 50011, "server slipped away, try again later")
-	ErrInvalidRange           = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50012, "invalid range params")
-	ErrExceptionStream        = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50013, "stream exception")
-	ErrMismatchSp             = gfsperrors.Register(module.GateModularName, http.StatusNotAcceptable, 50014, "mismatch sp")
-	ErrSignature              = gfsperrors.Register(module.GateModularName, http.StatusNotAcceptable, 50015, "signature verification failed")
-	ErrInvalidPayloadSize     = gfsperrors.Register(module.GateModularName, http.StatusForbidden, 50016, "invalid payload")
-	ErrApprovalExpired        = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 550015, "approval expired")
-	ErrConsensus              = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 55001, "server slipped away, try again later")
+	ErrUnsupportedSignType            = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50001, "unsupported sign type")
+	ErrAuthorizationFormat            = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50002, "authorization format error")
+	ErrRequestConsistent              = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50003, "request is tampered")
+	ErrNoPermission                   = gfsperrors.Register(module.GateModularName, http.StatusUnauthorized, 50004, "no permission")
+	ErrDecodeMsg                      = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50005, "gnfd msg encoding error")
+	ErrValidateMsg                    = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50006, "gnfd msg validate error")
+	ErrRefuseApproval                 = gfsperrors.Register(module.GateModularName, http.StatusOK, 50007, "approval request is refuse")
+	ErrUnsupportedRequestType         = gfsperrors.Register(module.GateModularName, http.StatusNotFound, 50008, "unsupported request type")
+	ErrInvalidHeader                  = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50009, "invalid request header")
+	ErrInvalidQuery                   = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50010, "invalid request header params for query")
+	ErrEncodeResponse                 = gfsperrors.Register(module.GateModularName, http.StatusInternalServerError, 50011, "server slipped away, try again later")
+	ErrInvalidRange                   = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50012, "invalid range params")
+	ErrExceptionStream                = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50013, "stream exception")
+	ErrMismatchSp                     = gfsperrors.Register(module.GateModularName, http.StatusNotAcceptable, 50014, "mismatch sp")
+	ErrSignature                      = gfsperrors.Register(module.GateModularName, http.StatusNotAcceptable, 50015, "signature verification failed")
+	ErrInvalidPayloadSize             = gfsperrors.Register(module.GateModularName, http.StatusForbidden, 50016, "invalid payload")
+	ErrShareLinkExpired               = gfsperrors.Register(module.GateModularName, http.StatusForbidden, 50017, "share link is expired")
+	ErrInvalidShareLink               = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 50018, "invalid share link")
+	ErrPreconditionFailed             = gfsperrors.Register(module.GateModularName, http.StatusPreconditionFailed, 50019, "precondition failed")
+	ErrSPExiting                      = gfsperrors.Register(module.GateModularName, http.StatusServiceUnavailable, 50020, "the sp is exiting")
+	ErrNotificationUnavailable        = gfsperrors.Register(module.GateModularName, http.StatusServiceUnavailable, 50021, "notification is unavailable")
+	ErrNoSuchNotificationSubscription = gfsperrors.Register(module.GateModularName, http.StatusNotFound, 50022, "no such notification subscription")
+	ErrMigrateBucketNotApproved       = gfsperrors.Register(module.GateModularName, http.StatusForbidden, 50023, "bucket migration is not approved")
+	ErrApprovalExpired                = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 550015, "approval expired")
+	ErrConsensus                      = gfsperrors.Register(module.GateModularName, http.StatusBadRequest, 55001, "server slipped away, try again later")
 )
 
 func MakeErrorResponse(w http.ResponseWriter, err error) {
@@ -52,3 +60,111 @@ func MakeErrorResponse(w http.ResponseWriter, err error) {
 		log.Errorw("failed to write error response", "error", gfspErr.String())
 	}
 }
+
+// S3Error defines the error of the S3 compatible api, the Code is the S3 error code
+// that the S3 SDKs and tools depend on to handle the error.
+type S3Error struct {
+	Code           string
+	Message        string
+	HttpStatusCode int
+}
+
+func (e *S3Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// withMessage returns a copy of the S3 error with the detailed message.
+func (e *S3Error) withMessage(message string) *S3Error {
+	return &S3Error{Code: e.Code, Message: message, HttpStatusCode: e.HttpStatusCode}
+}
+
+var (
+	S3ErrAccessDenied                      = &S3Error{"AccessDenied", "Access Denied.", http.StatusForbidden}
+	S3ErrExpiredPresignRequest             = &S3Error{"AccessDenied", "Request has expired.", http.StatusForbidden}
+	S3ErrRequestNotReadyYet                = &S3Error{"AccessDenied", "Request is not valid yet.", http.StatusForbidden}
+	S3ErrInvalidAccessKeyID                = &S3Error{"InvalidAccessKeyId", "The access key Id you provided does not exist in our records.", http.StatusForbidden}
+	S3ErrSignatureDoesNotMatch             = &S3Error{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.", http.StatusForbidden}
+	S3ErrRequestTimeTooSkewed              = &S3Error{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.", http.StatusForbidden}
+	S3ErrAuthorizationHeaderMalformed      = &S3Error{"AuthorizationHeaderMalformed", "The authorization header is malformed.", http.StatusBadRequest}
+	S3ErrAuthorizationQueryParametersError = &S3Error{"AuthorizationQueryParametersError", "The authorization query parameters are malformed.", http.StatusBadRequest}
+	S3ErrSignatureVersionNotSupported      = &S3Error{"InvalidRequest", "The authorization mechanism you have provided is not supported. Please use AWS4-HMAC-SHA256.", http.StatusBadRequest}
+	S3ErrInvalidArgument                   = &S3Error{"InvalidArgument", "Invalid Argument.", http.StatusBadRequest}
+	S3ErrInvalidBucketName                 = &S3Error{"InvalidBucketName", "The specified bucket is not valid.", http.StatusBadRequest}
+	S3ErrNoSuchBucket                      = &S3Error{"NoSuchBucket", "The specified bucket does not exist.", http.StatusNotFound}
+	S3ErrNoSuchKey                         = &S3Error{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	S3ErrInvalidRange                      = &S3Error{"InvalidRange", "The requested range is not satisfiable.", http.StatusRequestedRangeNotSatisfiable}
+	S3ErrPreconditionFailed                = &S3Error{"PreconditionFailed", "At least one of the preconditions you specified did not hold.", http.StatusPreconditionFailed}
+	S3ErrMethodNotAllowed                  = &S3Error{"MethodNotAllowed", "The specified method is not allowed against this resource.", http.StatusMethodNotAllowed}
+	S3ErrNotImplemented                    = &S3Error{"NotImplemented", "A header or query you provided implies functionality that is not implemented.", http.StatusNotImplemented}
+	S3ErrSlowDown                          = &S3Error{"SlowDown", "Please reduce your request rate.", http.StatusServiceUnavailable}
+	S3ErrInternalError                     = &S3Error{"InternalError", "We encountered an internal error. Please try again.", http.StatusInternalServerError}
+)
+
+// makeS3Error converts the error to the S3 error, the GfSp errors are converted by the
+// http status code, isObject indicates whether the request targets an object.
+func makeS3Error(err error, isObject bool) *S3Error {
+	if s3Err, ok := err.(*S3Error); ok {
+		return s3Err
+	}
+	gfspErr := gfsperrors.MakeGfSpError(err)
+	switch gfspErr.GetInnerCode() {
+	case ErrInvalidRange.GetInnerCode():
+		return S3ErrInvalidRange
+	case ErrPreconditionFailed.GetInnerCode():
+		return S3ErrPreconditionFailed
+	}
+	switch code := int(gfspErr.GetHttpStatusCode()); {
+	case code == http.StatusNotFound:
+		if isObject && !strings.Contains(gfspErr.GetDescription(), "bucket") {
+			return S3ErrNoSuchKey
+		}
+		return S3ErrNoSuchBucket
+	case code == http.StatusServiceUnavailable || code == http.StatusTooManyRequests:
+		return S3ErrSlowDown
+	case code >= http.StatusInternalServerError:
+		return S3ErrInternalError
+	default:
+		return S3ErrInvalidArgument.withMessage(gfspErr.GetDescription())
+	}
+}
+
+// makeS3AuthorizeError converts the error of VerifyAuthorize to the S3 error, the
+// refused request is returned as AccessDenied with the reason from the authorizer.
+func makeS3AuthorizeError(err error, isObject bool) *S3Error {
+	if err == nil {
+		return S3ErrAccessDenied
+	}
+	s3Err := makeS3Error(err, isObject)
+	if s3Err.HttpStatusCode == http.StatusNotFound || s3Err.HttpStatusCode >= http.StatusInternalServerError {
+		return s3Err
+	}
+	return S3ErrAccessDenied.withMessage(gfsperrors.MakeGfSpError(err).GetDescription())
+}
+
+// MakeS3ErrorResponse writes the S3 error response, the response body is omitted for
+// the HEAD request.
+func MakeS3ErrorResponse(w http.ResponseWriter, r *http.Request, s3Err *S3Error) {
+	var xmlInfo = struct {
+		XMLName  xml.Name `xml:"Error"`
+		Code     string   `xml:"Code"`
+		Message  string   `xml:"Message"`
+		Resource string   `xml:"Resource"`
+	}{
+		Code:     s3Err.Code,
+		Message:  s3Err.Message,
+		Resource: r.URL.Path,
+	}
+	xmlBody, err := xml.Marshal(&xmlInfo)
+	if err != nil {
+		log.Errorw("failed to marshal s3 error response", "error", s3Err.Error())
+	}
+	w.Header().Set(model.ContentTypeHeader, model.ContentTypeXMLHeaderValue)
+	w.WriteHeader(s3Err.HttpStatusCode)
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write([]byte(xml.Header))
+	if _, err = w.Write(xmlBody); err != nil {
+		log.Errorw("failed to write s3 error response", "error", s3Err.Error())
+	}
+}
@@ -3,10 +3,12 @@ package gater
 import (
 	"context"
 	"net/http"
+	"sync"
 
 	"github.com/gorilla/mux"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	"github.com/bnb-chain/greenfield-storage-provider/core/module"
 	"github.com/bnb-chain/greenfield-storage-provider/core/rcmgr"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
@@ -24,6 +26,18 @@ type GateModular struct {
 
 	maxListReadQuota int64
 	maxPayloadSize   uint64
+
+	// the bytes per second of exporting the migrating bucket to the destination SP
+	migrateBucketExportSpeed int64
+
+	// the optional S3 compatible read api, it is served by a separate http server
+	s3Enable      bool
+	s3HttpAddress string
+	s3Domain      string
+	s3Region      string
+	s3HttpServer  *http.Server
+	s3Mux         sync.RWMutex
+	s3AccessKeys  map[string]gfspconfig.S3AccessKeyConfig
 }
 
 func (g *GateModular) Name() string {
@@ -37,6 +51,9 @@ func (g *GateModular) Start(ctx context.Context) error {
 	}
 	g.scope = scope
 	go g.server(ctx)
+	if g.s3Enable {
+		go g.s3Server(ctx)
+	}
 	return nil
 }
 
@@ -57,9 +74,29 @@ func (g *GateModular) server(ctx context.Context) {
 	}
 }
 
+func (g *GateModular) s3Server(ctx context.Context) {
+	router := mux.NewRouter().SkipClean(true)
+	if g.baseApp.EnableMetrics() {
+		router.Use(metrics.DefaultHTTPServerMetrics.InstrumentationHandler)
+	}
+	g.RegisterS3Handler(router)
+	server := &http.Server{
+		Addr:    g.s3HttpAddress,
+		Handler: router,
+	}
+	g.s3HttpServer = server
+	if err := server.ListenAndServe(); err != nil {
+		log.Errorw("failed to listen s3 api", "error", err)
+		return
+	}
+}
+
 func (g *GateModular) Stop(ctx context.Context) error {
 	g.scope.Release()
 	g.httpServer.Shutdown(ctx)
+	if g.s3HttpServer != nil {
+		g.s3HttpServer.Shutdown(ctx)
+	}
 	return nil
 }
 
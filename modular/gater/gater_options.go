@@ -1,6 +1,11 @@
 package gater
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
@@ -12,6 +17,10 @@ const (
 	DefaultGatewayDomain    = "localhost:9133"
 	DefaultMaxListReadQuota = 100
 	DefaultMaxPayloadSize   = 2 * 1024 * 1024 * 1024
+	DefaultS3HttpAddress    = "localhost:9134"
+	// DefaultMigrateBucketExportSpeed defines the default bytes per second of exporting
+	// the migrating bucket, it prevents the migration from starving the user requests.
+	DefaultMigrateBucketExportSpeed = 20 * 1024 * 1024
 )
 
 func NewGateModular(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig) (coremodule.Modular, error) {
@@ -35,10 +44,29 @@ func DefaultGaterOptions(gater *GateModular, cfg *gfspconfig.GfSpConfig) error {
 	if cfg.Bucket.MaxPayloadSize == 0 {
 		cfg.Bucket.MaxPayloadSize = DefaultMaxPayloadSize
 	}
+	if cfg.Gateway.MigrateBucketExportSpeed == 0 {
+		cfg.Gateway.MigrateBucketExportSpeed = DefaultMigrateBucketExportSpeed
+	}
 	gater.maxPayloadSize = cfg.Bucket.MaxPayloadSize
+	gater.migrateBucketExportSpeed = cfg.Gateway.MigrateBucketExportSpeed
 	gater.domain = cfg.Gateway.Domain
 	gater.httpAddress = cfg.Gateway.HttpAddress
 	gater.maxListReadQuota = cfg.Bucket.MaxListReadQuotaNumber
+	if cfg.Gateway.S3.Enable {
+		if cfg.Gateway.S3.HttpAddress == "" {
+			cfg.Gateway.S3.HttpAddress = DefaultS3HttpAddress
+		}
+		accessKeys, err := makeS3AccessKeys(cfg.Gateway.S3.AccessKeys)
+		if err != nil {
+			log.Errorw("failed to load s3 access keys", "error", err)
+			return err
+		}
+		gater.s3Enable = true
+		gater.s3HttpAddress = cfg.Gateway.S3.HttpAddress
+		gater.s3Domain = cfg.Gateway.S3.Domain
+		gater.s3Region = cfg.Gateway.S3.Region
+		gater.s3AccessKeys = accessKeys
+	}
 	rateCfg := makeAPIRateLimitCfg(cfg.APIRateLimiter)
 	if err := localhttp.NewAPILimiter(rateCfg); err != nil {
 		log.Errorw("failed to new api limiter", "err", err)
@@ -76,3 +104,46 @@ func makeAPIRateLimitCfg(cfg localhttp.RateLimiterConfig) *localhttp.APILimiterC
 		IPLimitCfg:  cfg.IPLimitCfg,
 	}
 }
+
+// makeS3AccessKeys returns the S3 access keys indexed by the access key id, the mapped
+// account must be a valid greenfield account address.
+func makeS3AccessKeys(keys []gfspconfig.S3AccessKeyConfig) (map[string]gfspconfig.S3AccessKeyConfig, error) {
+	accessKeys := make(map[string]gfspconfig.S3AccessKeyConfig, len(keys))
+	for _, key := range keys {
+		if key.AccessKeyID == "" || key.SecretAccessKey == "" {
+			return nil, fmt.Errorf("s3 access key id and secret access key must not be empty")
+		}
+		if !common.IsHexAddress(key.Account) {
+			return nil, fmt.Errorf("invalid account %s of s3 access key %s", key.Account, key.AccessKeyID)
+		}
+		if _, ok := accessKeys[key.AccessKeyID]; ok {
+			return nil, fmt.Errorf("repeated s3 access key %s", key.AccessKeyID)
+		}
+		accessKeys[key.AccessKeyID] = key
+	}
+	return accessKeys, nil
+}
+
+// ReloadConfig applies the reloaded api rate limiter config and S3 access keys.
+func (g *GateModular) ReloadConfig(ctx context.Context, cfg interface{}) error {
+	newCfg, ok := cfg.(*gfspconfig.GfSpConfig)
+	if !ok {
+		return gfspconfig.ErrInvalidReloadConfig
+	}
+	if err := localhttp.NewAPILimiter(makeAPIRateLimitCfg(newCfg.APIRateLimiter)); err != nil {
+		log.CtxErrorw(ctx, "failed to reload api limiter", "error", err)
+		return err
+	}
+	if g.s3Enable {
+		accessKeys, err := makeS3AccessKeys(newCfg.Gateway.S3.AccessKeys)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to reload s3 access keys", "error", err)
+			return err
+		}
+		g.s3Mux.Lock()
+		g.s3AccessKeys = accessKeys
+		g.s3Mux.Unlock()
+	}
+	log.CtxInfow(ctx, "succeed to reload gater config")
+	return nil
+}
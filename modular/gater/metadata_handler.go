@@ -312,3 +312,55 @@ func (g *GateModular) getBucketMetaHandler(w http.ResponseWriter, r *http.Reques
 	w.Header().Set(model.ContentTypeHeader, model.ContentTypeJSONHeaderValue)
 	w.Write(b.Bytes())
 }
+
+// getPrefixStatsHandler handle get prefix stats request, it returns the object count and the total size
+// of the directory by the prefix and of its immediate child directories
+func (g *GateModular) getPrefixStatsHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		b      bytes.Buffer
+		reqCtx *RequestContext
+		resp   *types.GfSpGetPrefixStatsResponse
+	)
+
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			log.CtxErrorw(reqCtx.Context(), "failed to get prefix stats", reqCtx.String())
+			MakeErrorResponse(w, err)
+		}
+	}()
+
+	reqCtx, err = NewRequestContext(r)
+	if err != nil {
+		return
+	}
+
+	if err = s3util.CheckValidBucketName(reqCtx.bucketName); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to check bucket name", "bucket_name", reqCtx.bucketName, "error", err)
+		return
+	}
+
+	prefix := r.URL.Query().Get(model.ListObjectsPrefixQuery)
+	if !checkValidObjectPrefix(prefix) {
+		log.CtxErrorw(reqCtx.Context(), "failed to check prefix", "prefix", prefix)
+		err = ErrInvalidQuery
+		return
+	}
+
+	resp, err = g.baseApp.GfSpClient().GetPrefixStats(reqCtx.Context(), reqCtx.bucketName, prefix)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get prefix stats", "error", err)
+		return
+	}
+
+	m := jsonpb.Marshaler{EmitDefaults: true, OrigName: true, EnumsAsInts: true}
+	if err = m.Marshal(&b, resp); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to marshal prefix stats", "error", err)
+		return
+	}
+
+	w.Header().Set(model.ContentTypeHeader, model.ContentTypeJSONHeaderValue)
+	w.Write(b.Bytes())
+}
@@ -0,0 +1,328 @@
+package gater
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/p2p/p2pnode"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+const (
+	// MigrateBucketSignExpiry defines the max seconds between the signing time of the
+	// migrate bucket task and the export request.
+	MigrateBucketSignExpiry int64 = 10 * 60
+	// MigrateBucketListLimit defines the number of objects listed for exporting at once.
+	MigrateBucketListLimit uint64 = 1000
+)
+
+// migrateBucketHandler handles the export bucket request from the destination SP of the
+// bucket migration. The objects are exported in the ascending order of the object name
+// after the resume point, each object is exported as the integrity meta frame followed
+// by the segment piece frames, and the stream ends with the end frame.
+func (g *GateModular) migrateBucketHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		reqCtx     *RequestContext
+		migrateMsg []byte
+		bucketInfo *storagetypes.BucketInfo
+		params     *storagetypes.Params
+		exporting  bool
+		migrate    = gfsptask.GfSpMigrateBucketTask{}
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHttpCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			// the error in the middle of exporting is known by the destination SP from
+			// the absence of the end frame
+			if !exporting {
+				MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+			}
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+	// ignore the error, because the migrate request only between SPs, the request
+	// verification is by signature of the MigrateBucketTask
+	reqCtx, _ = NewRequestContext(r)
+
+	migrateMsg, err = hex.DecodeString(r.Header.Get(model.GnfdMigrateBucketMsgHeader))
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to parse migrate bucket header",
+			"migrate", r.Header.Get(model.GnfdMigrateBucketMsgHeader))
+		err = ErrDecodeMsg
+		return
+	}
+	err = json.Unmarshal(migrateMsg, &migrate)
+	if err != nil || migrate.GetBucketInfo() == nil || migrate.GetTask() == nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to unmarshal migrate bucket header",
+			"migrate", r.Header.Get(model.GnfdMigrateBucketMsgHeader))
+		err = ErrDecodeMsg
+		return
+	}
+	if _, err = g.baseApp.GfSpDB().GetSpByAddress(migrate.GetDestSpOperatorAddress(),
+		corespdb.OperatorAddressType); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get destination sp", "error", err)
+		err = ErrMismatchSp
+		return
+	}
+	err = p2pnode.VerifySignature(migrate.GetDestSpOperatorAddress(), migrate.GetSignBytes(), migrate.GetSignature())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify migrate bucket signature", "error", err)
+		err = ErrSignature
+		return
+	}
+	if now := time.Now().Unix(); migrate.GetUpdateTime()+MigrateBucketSignExpiry < now ||
+		migrate.GetUpdateTime()-MigrateBucketSignExpiry > now {
+		log.CtxErrorw(reqCtx.Context(), "migrate bucket request expired", "sign_time", migrate.GetUpdateTime())
+		err = ErrApprovalExpired
+		return
+	}
+	bucketInfo, err = g.baseApp.Consensus().QueryBucketInfo(reqCtx.Context(), migrate.GetBucketInfo().GetBucketName())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get bucket info from consensus", "error", err)
+		err = ErrConsensus
+		return
+	}
+	if bucketInfo.Id.Uint64() != migrate.GetBucketInfo().Id.Uint64() ||
+		!strings.EqualFold(bucketInfo.GetPrimarySpAddress(), g.baseApp.OperateAddress()) {
+		log.CtxErrorw(reqCtx.Context(), "failed to export bucket, the primary sp is not self",
+			"primary", bucketInfo.GetPrimarySpAddress())
+		err = ErrMismatchSp
+		return
+	}
+	if err = g.checkMigrateBucketApproval(reqCtx, bucketInfo, &migrate); err != nil {
+		return
+	}
+
+	w.Header().Set(model.ContentTypeHeader, model.OctetStream)
+	w.WriteHeader(http.StatusOK)
+	exporting = true
+	writer := bufio.NewWriter(w)
+	// the burst is raised to the max segment size of the exporting object
+	limiter := rate.NewLimiter(rate.Limit(g.migrateBucketExportSpeed), 0)
+	var exported uint64
+	startAfter := migrate.GetLastMigratedObjectName()
+	for {
+		objects, _, _, isTruncated, _, _, _, _, _, _, listErr := g.baseApp.GfSpClient().ListObjectsByBucketName(
+			reqCtx.Context(), bucketInfo.GetBucketName(), "", MigrateBucketListLimit, startAfter, "", "", "")
+		if listErr != nil {
+			log.CtxErrorw(reqCtx.Context(), "failed to list objects for exporting", "error", listErr)
+			err = listErr
+			return
+		}
+		for _, object := range objects {
+			objectInfo := object.GetObjectInfo()
+			startAfter = objectInfo.GetObjectName()
+			if object.GetRemoved() || objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_SEALED {
+				continue
+			}
+			// the segments of the object are split by the params of its create time
+			if params, err = g.baseApp.QueryStorageParamsByTimestamp(reqCtx.Context(),
+				objectInfo.GetCreateAt()); err != nil {
+				log.CtxErrorw(reqCtx.Context(), "failed to query storage params by object create time",
+					"object_name", objectInfo.GetObjectName(), "error", err)
+				return
+			}
+			if burst := int(params.VersionedParams.GetMaxSegmentSize()); burst > limiter.Burst() {
+				limiter.SetBurst(burst)
+			}
+			if err = g.exportObject(reqCtx, writer, limiter, objectInfo, params); err != nil {
+				log.CtxErrorw(reqCtx.Context(), "failed to export object", "object_name",
+					objectInfo.GetObjectName(), "error", err)
+				return
+			}
+			exported++
+		}
+		if !isTruncated || len(objects) == 0 {
+			break
+		}
+	}
+	err = gfsptask.WriteMigrateFrame(writer, &gfsptask.GfSpMigrateFrame{
+		Frame: &gfsptask.GfSpMigrateFrame_End{End: &gfsptask.GfSpMigrateEnd{ObjectNumber: exported}}})
+	if err == nil {
+		err = writer.Flush()
+	}
+	log.CtxInfow(reqCtx.Context(), "finish to export bucket", "bucket_name", bucketInfo.GetBucketName(),
+		"exported", exported, "error", err)
+}
+
+// checkMigrateBucketApproval checks that migrating the bucket to the destination SP is
+// approved, either by the signature of the bucket owner, or by this SP that hands over
+// the bucket to the destination SP when it exits.
+func (g *GateModular) checkMigrateBucketApproval(reqCtx *RequestContext, bucketInfo *storagetypes.BucketInfo,
+	migrate *gfsptask.GfSpMigrateBucketTask) error {
+	if len(migrate.GetOwnerSignature()) != 0 {
+		if migrate.GetApprovalExpiry() < time.Now().Unix() {
+			log.CtxErrorw(reqCtx.Context(), "bucket owner approval expired", "expiry", migrate.GetApprovalExpiry())
+			return ErrApprovalExpired
+		}
+		if err := p2pnode.VerifySignature(bucketInfo.GetOwner(), migrate.GetApprovalSignBytes(),
+			migrate.GetOwnerSignature()); err != nil {
+			log.CtxErrorw(reqCtx.Context(), "failed to verify bucket owner approval", "owner",
+				bucketInfo.GetOwner(), "error", err)
+			return ErrMigrateBucketNotApproved
+		}
+		return nil
+	}
+	record, err := g.baseApp.GfSpDB().GetSPExitRecord(corespdb.SPExitBucketResource, bucketInfo.Id.Uint64())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get sp exit record", "error", err)
+		return err
+	}
+	if record == nil || !record.Succeed || !strings.EqualFold(record.DestSpAddress, migrate.GetDestSpOperatorAddress()) {
+		log.CtxErrorw(reqCtx.Context(), "bucket is not handed over to the destination sp",
+			"dest", migrate.GetDestSpOperatorAddress())
+		return ErrMigrateBucketNotApproved
+	}
+	return nil
+}
+
+// exportObject writes the integrity meta and the segment pieces of the object, the
+// writing of pieces is paced by the limiter. The params must be resolved by the create
+// time of the object.
+func (g *GateModular) exportObject(reqCtx *RequestContext, writer *bufio.Writer, limiter *rate.Limiter,
+	objectInfo *storagetypes.ObjectInfo, params *storagetypes.Params) error {
+	integrity, err := g.baseApp.GfSpDB().GetObjectIntegrity(objectInfo.Id.Uint64())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get object integrity", "error", err)
+		return err
+	}
+	err = gfsptask.WriteMigrateFrame(writer, &gfsptask.GfSpMigrateFrame{
+		Frame: &gfsptask.GfSpMigrateFrame_ObjectMeta{ObjectMeta: &gfsptask.GfSpMigrateObjectMeta{
+			ObjectInfo:        objectInfo,
+			IntegrityHash:     integrity.IntegrityChecksum,
+			PieceChecksumList: integrity.PieceChecksumList,
+		}}})
+	if err != nil {
+		return err
+	}
+	segmentCount := g.baseApp.PieceOp().SegmentCount(objectInfo.GetPayloadSize(),
+		params.VersionedParams.GetMaxSegmentSize())
+	for segmentIdx := uint32(0); segmentIdx < segmentCount; segmentIdx++ {
+		key := g.baseApp.PieceOp().SegmentPieceKey(objectInfo.Id.Uint64(), segmentIdx)
+		data, err := g.baseApp.PieceStore().GetPiece(reqCtx.Context(), key, 0, -1)
+		if err != nil {
+			log.CtxErrorw(reqCtx.Context(), "failed to get piece", "piece_key", key, "error", err)
+			return err
+		}
+		if err = limiter.WaitN(reqCtx.Context(), len(data)); err != nil {
+			return err
+		}
+		err = gfsptask.WriteMigrateFrame(writer, &gfsptask.GfSpMigrateFrame{
+			Frame: &gfsptask.GfSpMigrateFrame_Piece{Piece: &gfsptask.GfSpMigratePiece{
+				ObjectId:   objectInfo.Id.Uint64(),
+				SegmentIdx: segmentIdx,
+				PieceData:  data,
+			}}})
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// notifyMigrateBucketHandler handles the notification from the exiting primary SP of the
+// bucket, this SP creates the migrate bucket task to pull the bucket from the primary SP.
+func (g *GateModular) notifyMigrateBucketHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		reqCtx     *RequestContext
+		migrateMsg []byte
+		bucketInfo *storagetypes.BucketInfo
+		primarySp  *sptypes.StorageProvider
+		notify     = gfsptask.GfSpMigrateBucketTask{}
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHttpCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+	// ignore the error, because the notification only between SPs, the request
+	// verification is by signature of the primary SP of the bucket
+	reqCtx, _ = NewRequestContext(r)
+
+	if g.baseApp.SPExiting() {
+		log.CtxErrorw(reqCtx.Context(), "failed to accept migrate bucket, the sp is exiting")
+		err = ErrSPExiting
+		return
+	}
+	migrateMsg, err = hex.DecodeString(r.Header.Get(model.GnfdMigrateBucketMsgHeader))
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to parse notify migrate bucket header",
+			"migrate", r.Header.Get(model.GnfdMigrateBucketMsgHeader))
+		err = ErrDecodeMsg
+		return
+	}
+	err = json.Unmarshal(migrateMsg, &notify)
+	if err != nil || notify.GetBucketInfo() == nil || notify.GetTask() == nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to unmarshal notify migrate bucket header",
+			"migrate", r.Header.Get(model.GnfdMigrateBucketMsgHeader))
+		err = ErrDecodeMsg
+		return
+	}
+	if !strings.EqualFold(notify.GetDestSpOperatorAddress(), g.baseApp.OperateAddress()) {
+		log.CtxErrorw(reqCtx.Context(), "failed to accept migrate bucket, the destination sp is not self",
+			"dest", notify.GetDestSpOperatorAddress())
+		err = ErrMismatchSp
+		return
+	}
+	if now := time.Now().Unix(); notify.GetUpdateTime()+MigrateBucketSignExpiry < now ||
+		notify.GetUpdateTime()-MigrateBucketSignExpiry > now {
+		log.CtxErrorw(reqCtx.Context(), "notify migrate bucket request expired", "sign_time", notify.GetUpdateTime())
+		err = ErrApprovalExpired
+		return
+	}
+	bucketInfo, err = g.baseApp.Consensus().QueryBucketInfo(reqCtx.Context(), notify.GetBucketInfo().GetBucketName())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get bucket info from consensus", "error", err)
+		err = ErrConsensus
+		return
+	}
+	if bucketInfo.Id.Uint64() != notify.GetBucketInfo().Id.Uint64() {
+		log.CtxErrorw(reqCtx.Context(), "failed to accept migrate bucket, the bucket is changed")
+		err = ErrMismatchSp
+		return
+	}
+	err = p2pnode.VerifySignature(bucketInfo.GetPrimarySpAddress(), notify.GetSignBytes(), notify.GetSignature())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify notify migrate bucket signature", "error", err)
+		err = ErrSignature
+		return
+	}
+	primarySp, err = g.baseApp.GfSpDB().GetSpByAddress(bucketInfo.GetPrimarySpAddress(), corespdb.OperatorAddressType)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get primary sp", "error", err)
+		err = ErrMismatchSp
+		return
+	}
+	migrate := &gfsptask.GfSpMigrateBucketTask{}
+	migrate.InitMigrateBucketTask(bucketInfo, primarySp.GetEndpoint(), g.baseApp.OperateAddress(),
+		g.baseApp.TaskPriority(migrate), g.baseApp.TaskTimeout(migrate, 0), g.baseApp.TaskMaxRetry(migrate))
+	if err = g.baseApp.GfSpClient().CreateMigrateBucket(reqCtx.Context(), migrate); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to create migrate bucket task", "error", err)
+		return
+	}
+	log.CtxInfow(reqCtx.Context(), "succeed to accept migrate bucket", "info", migrate.Info())
+}
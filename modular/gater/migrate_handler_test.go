@@ -0,0 +1,159 @@
+package gater
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+const testDestSpAddress = "0x1111111111111111111111111111111111111111"
+
+// mockMigrateDB returns the integrity metas and the sp exit records of the test.
+type mockMigrateDB struct {
+	corespdb.SPDB
+	integrity map[uint64]*corespdb.IntegrityMeta
+	exits     map[uint64]*corespdb.SPExitRecord
+}
+
+func (m *mockMigrateDB) GetObjectIntegrity(objectID uint64) (*corespdb.IntegrityMeta, error) {
+	return m.integrity[objectID], nil
+}
+
+func (m *mockMigrateDB) GetSPExitRecord(_ string, resourceID uint64) (*corespdb.SPExitRecord, error) {
+	return m.exits[resourceID], nil
+}
+
+// mockPieceStore stores the pieces in memory.
+type mockPieceStore map[string][]byte
+
+func (m mockPieceStore) GetPiece(_ context.Context, key string, _, _ int64) ([]byte, error) {
+	return m[key], nil
+}
+
+func (m mockPieceStore) PutPiece(_ context.Context, key string, value []byte) error {
+	m[key] = value
+	return nil
+}
+
+func (m mockPieceStore) DeletePiece(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func (m mockPieceStore) DeletePieces(_ context.Context, keys []string) map[string]error {
+	for _, key := range keys {
+		delete(m, key)
+	}
+	return nil
+}
+
+func newTestMigrateGateModular(t *testing.T, db corespdb.SPDB, store mockPieceStore) *GateModular {
+	app := &gfspapp.GfSpBaseApp{}
+	cfg := &gfspconfig.GfSpConfig{Customize: &gfspconfig.Customize{GfSpDB: db, PieceStore: store}}
+	require.NoError(t, gfspapp.DefaultGfSpDBOption(app, cfg))
+	require.NoError(t, gfspapp.DefaultGfSpPieceStoreOption(app, cfg))
+	require.NoError(t, gfspapp.DefaultGfSpPieceOpOption(app, cfg))
+	return &GateModular{baseApp: app}
+}
+
+func TestGateModularExportObject(t *testing.T) {
+	segments := [][]byte{[]byte("0123456789abcdef"), []byte("0123")}
+	integrity := &corespdb.IntegrityMeta{ObjectID: 1, IntegrityChecksum: []byte("integrity"),
+		PieceChecksumList: [][]byte{[]byte("checksum0"), []byte("checksum1")}}
+	db := &mockMigrateDB{integrity: map[uint64]*corespdb.IntegrityMeta{1: integrity}}
+	store := mockPieceStore{"1_s0": segments[0], "1_s1": segments[1]}
+	g := newTestMigrateGateModular(t, db, store)
+	objectInfo := &storagetypes.ObjectInfo{BucketName: "bucket", ObjectName: "object", Id: sdkmath.NewUint(1),
+		PayloadSize: 20}
+	params := &storagetypes.Params{VersionedParams: storagetypes.VersionedParams{MaxSegmentSize: 16}}
+	reqCtx := newRequestContext(httptest.NewRequest("GET", "/", nil))
+	defer reqCtx.Cancel()
+
+	var buf bytes.Buffer
+	require.NoError(t, g.exportObject(reqCtx, bufio.NewWriter(&buf), rate.NewLimiter(rate.Inf, 16),
+		objectInfo, params))
+
+	reader := bufio.NewReader(&buf)
+	frame, err := gfsptask.ReadMigrateFrame(reader, 1024)
+	require.NoError(t, err)
+	meta := frame.GetObjectMeta()
+	require.NotNil(t, meta)
+	assert.Equal(t, "object", meta.GetObjectInfo().GetObjectName())
+	assert.Equal(t, integrity.IntegrityChecksum, meta.GetIntegrityHash())
+	assert.Equal(t, integrity.PieceChecksumList, meta.GetPieceChecksumList())
+	for segmentIdx, segment := range segments {
+		frame, err = gfsptask.ReadMigrateFrame(reader, 1024)
+		require.NoError(t, err)
+		piece := frame.GetPiece()
+		require.NotNil(t, piece)
+		assert.Equal(t, uint64(1), piece.GetObjectId())
+		assert.Equal(t, uint32(segmentIdx), piece.GetSegmentIdx())
+		assert.Equal(t, segment, piece.GetPieceData())
+	}
+	assert.Equal(t, 0, reader.Buffered())
+}
+
+func TestGateModularCheckMigrateBucketApproval(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	owner := ethcrypto.PubkeyToAddress(key.PublicKey).Hex()
+	bucketInfo := &storagetypes.BucketInfo{BucketName: "bucket", Owner: owner, Id: sdkmath.NewUint(1)}
+	db := &mockMigrateDB{exits: make(map[uint64]*corespdb.SPExitRecord)}
+	g := newTestMigrateGateModular(t, db, mockPieceStore{})
+	reqCtx := newRequestContext(httptest.NewRequest("GET", "/", nil))
+	defer reqCtx.Cancel()
+
+	newMigrate := func(signer string, dest string, expiry int64) *gfsptask.GfSpMigrateBucketTask {
+		migrate := &gfsptask.GfSpMigrateBucketTask{}
+		migrate.InitMigrateBucketTask(bucketInfo, "endpoint", testDestSpAddress, 0, 0, 0)
+		if signer == "" {
+			return migrate
+		}
+		signKey := key
+		if signer != owner {
+			signKey, err = ethcrypto.GenerateKey()
+			require.NoError(t, err)
+		}
+		sig, err := ethcrypto.Sign(sdk.Keccak256(gfsptask.MigrateBucketApprovalSignBytes(
+			bucketInfo.Id.Uint64(), dest, expiry)), signKey)
+		require.NoError(t, err)
+		migrate.SetOwnerSignature(sig)
+		migrate.SetApprovalExpiry(expiry)
+		return migrate
+	}
+	expiry := time.Now().Add(time.Hour).Unix()
+
+	assert.NoError(t, g.checkMigrateBucketApproval(reqCtx, bucketInfo, newMigrate(owner, testDestSpAddress, expiry)))
+	assert.Equal(t, ErrApprovalExpired, g.checkMigrateBucketApproval(reqCtx, bucketInfo,
+		newMigrate(owner, testDestSpAddress, time.Now().Add(-time.Hour).Unix())))
+	assert.Equal(t, ErrMigrateBucketNotApproved, g.checkMigrateBucketApproval(reqCtx, bucketInfo,
+		newMigrate(owner, "0x2222222222222222222222222222222222222222", expiry)))
+	assert.Equal(t, ErrMigrateBucketNotApproved, g.checkMigrateBucketApproval(reqCtx, bucketInfo,
+		newMigrate("other", testDestSpAddress, expiry)))
+
+	// without the owner approval, the bucket must be handed over by this SP
+	assert.Equal(t, ErrMigrateBucketNotApproved, g.checkMigrateBucketApproval(reqCtx, bucketInfo,
+		newMigrate("", "", 0)))
+	db.exits[1] = &corespdb.SPExitRecord{ResourceType: corespdb.SPExitBucketResource, ResourceID: 1,
+		DestSpAddress: "0x2222222222222222222222222222222222222222", Succeed: true}
+	assert.Equal(t, ErrMigrateBucketNotApproved, g.checkMigrateBucketApproval(reqCtx, bucketInfo,
+		newMigrate("", "", 0)))
+	db.exits[1].DestSpAddress = testDestSpAddress
+	assert.NoError(t, g.checkMigrateBucketApproval(reqCtx, bucketInfo, newMigrate("", "", 0)))
+}
@@ -0,0 +1,301 @@
+package gater
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+// notificationSecretLength defines the byte length of the generated subscription secret
+const notificationSecretLength = 32
+
+// notificationSubscriptionXML defines the subscription in the response, the secret is only
+// returned when the subscription is created.
+type notificationSubscriptionXML struct {
+	ID         uint64   `xml:"ID"`
+	BucketName string   `xml:"BucketName,omitempty"`
+	Owner      string   `xml:"Owner,omitempty"`
+	URL        string   `xml:"URL"`
+	EventTypes []string `xml:"EventType"`
+	Secret     string   `xml:"Secret,omitempty"`
+	CreateTime int64    `xml:"CreateTime"`
+}
+
+func makeNotificationSubscriptionXML(subscription *corespdb.NotificationSubscription) *notificationSubscriptionXML {
+	return &notificationSubscriptionXML{
+		ID:         subscription.ID,
+		BucketName: subscription.BucketName,
+		Owner:      subscription.Owner,
+		URL:        subscription.URL,
+		EventTypes: subscription.EventTypes,
+		CreateTime: subscription.CreateTime,
+	}
+}
+
+// parseNotificationEventTypes parses the comma separated event types, all the event types
+// are subscribed if it is empty.
+func parseNotificationEventTypes(events string) ([]string, error) {
+	if events == "" {
+		return nil, nil
+	}
+	var eventTypes []string
+	for _, eventType := range strings.Split(events, ",") {
+		supported := false
+		for _, t := range corespdb.NotificationEventTypes {
+			if eventType == t {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return nil, ErrInvalidQuery
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+	return eventTypes, nil
+}
+
+// checkNotificationURL checks the webhook url is an absolute http or https url, the address
+// that the host resolves to is checked by the deliverer on every dial rather than here, because
+// the host may resolve to another address when the notification is delivered.
+func checkNotificationURL(webhook string) error {
+	u, err := url.Parse(webhook)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ErrInvalidQuery
+	}
+	return nil
+}
+
+// writeNotificationXML writes the xml response of the subscription requests.
+func writeNotificationXML(w http.ResponseWriter, xmlInfo interface{}) error {
+	xmlBody, err := xml.Marshal(xmlInfo)
+	if err != nil {
+		log.Errorw("failed to marshal xml", "error", err)
+		return ErrEncodeResponse
+	}
+	w.Header().Set(model.ContentTypeHeader, model.ContentTypeXMLHeaderValue)
+	if _, err = w.Write(xmlBody); err != nil {
+		log.Errorw("failed to write body", "error", err)
+		return ErrEncodeResponse
+	}
+	return nil
+}
+
+// createNotificationSubscriptionHandler handles the create notification subscription request,
+// the bucket subscription is only created by the bucket owner, otherwise the subscription of the
+// objects owned by the request account is created. The secret of signing the notifications is
+// generated and returned only in the response.
+func (g *GateModular) createNotificationSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err          error
+		reqCtx       *RequestContext
+		authorized   bool
+		subscription *corespdb.NotificationSubscription
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHttpCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r)
+	if err != nil {
+		return
+	}
+	// the subscriptions are managed by the signed account, the anonymous request is refused
+	if reqCtx.Account() == "" {
+		log.CtxErrorw(reqCtx.Context(), "anonymous account has no permission to manage notification subscriptions")
+		err = ErrNoPermission
+		return
+	}
+	if g.baseApp.GfSpDB() == nil {
+		err = ErrNotificationUnavailable
+		return
+	}
+	queryParams := r.URL.Query()
+	subscription = &corespdb.NotificationSubscription{
+		Account:    reqCtx.Account(),
+		URL:        queryParams.Get(model.NotificationURLQuery),
+		CreateTime: time.Now().Unix(),
+	}
+	if err = checkNotificationURL(subscription.URL); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "invalid notification url", "url", subscription.URL)
+		return
+	}
+	if subscription.EventTypes, err = parseNotificationEventTypes(queryParams.Get(model.NotificationEventsQuery)); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "invalid notification event types",
+			"events", queryParams.Get(model.NotificationEventsQuery))
+		return
+	}
+	if bucketName := queryParams.Get(model.NotificationBucketQuery); bucketName != "" {
+		authorized, err = g.baseApp.GfSpClient().VerifyAuthorize(reqCtx.Context(),
+			coremodule.AuthOpTypeManageNotification, reqCtx.Account(), bucketName, "")
+		if err != nil {
+			log.CtxErrorw(reqCtx.Context(), "failed to verify authorize", "error", err)
+			return
+		}
+		if !authorized {
+			log.CtxErrorw(reqCtx.Context(), "no permission to operate")
+			err = ErrNoPermission
+			return
+		}
+		subscription.BucketName = bucketName
+	} else {
+		subscription.Owner = reqCtx.Account()
+	}
+
+	secret := make([]byte, notificationSecretLength)
+	if _, err = rand.Read(secret); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to generate notification secret", "error", err)
+		return
+	}
+	subscription.Secret = hex.EncodeToString(secret)
+	if subscription.ID, err = g.baseApp.GfSpDB().CreateNotificationSubscription(subscription); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to create notification subscription", "error", err)
+		return
+	}
+
+	xmlSubscription := makeNotificationSubscriptionXML(subscription)
+	xmlSubscription.Secret = subscription.Secret
+	var xmlInfo = struct {
+		XMLName      xml.Name                     `xml:"CreateNotificationSubscriptionResult"`
+		Version      string                       `xml:"version,attr"`
+		Subscription *notificationSubscriptionXML `xml:"Subscription"`
+	}{
+		Version:      model.GnfdResponseXMLVersion,
+		Subscription: xmlSubscription,
+	}
+	if err = writeNotificationXML(w, &xmlInfo); err != nil {
+		return
+	}
+	log.CtxDebugw(reqCtx.Context(), "succeed to create notification subscription", "id", subscription.ID)
+}
+
+// listNotificationSubscriptionsHandler handles the list notification subscriptions request,
+// the subscriptions managed by the request account are listed without the secrets.
+func (g *GateModular) listNotificationSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err           error
+		reqCtx        *RequestContext
+		subscriptions []*corespdb.NotificationSubscription
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHttpCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r)
+	if err != nil {
+		return
+	}
+	// the subscriptions are managed by the signed account, the anonymous request is refused
+	if reqCtx.Account() == "" {
+		log.CtxErrorw(reqCtx.Context(), "anonymous account has no permission to manage notification subscriptions")
+		err = ErrNoPermission
+		return
+	}
+	if g.baseApp.GfSpDB() == nil {
+		err = ErrNotificationUnavailable
+		return
+	}
+	if subscriptions, err = g.baseApp.GfSpDB().ListNotificationSubscriptions(reqCtx.Account()); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to list notification subscriptions", "error", err)
+		return
+	}
+
+	var xmlInfo = struct {
+		XMLName       xml.Name                       `xml:"ListNotificationSubscriptionsResult"`
+		Version       string                         `xml:"version,attr"`
+		Subscriptions []*notificationSubscriptionXML `xml:"Subscription"`
+	}{
+		Version: model.GnfdResponseXMLVersion,
+	}
+	for _, subscription := range subscriptions {
+		xmlInfo.Subscriptions = append(xmlInfo.Subscriptions, makeNotificationSubscriptionXML(subscription))
+	}
+	if err = writeNotificationXML(w, &xmlInfo); err != nil {
+		return
+	}
+	log.CtxDebugw(reqCtx.Context(), "succeed to list notification subscriptions", "count", len(subscriptions))
+}
+
+// deleteNotificationSubscriptionHandler handles the delete notification subscription request,
+// the subscription is only deleted by the account that manages it, the pending deliveries of
+// the subscription are dropped by the block syncer.
+func (g *GateModular) deleteNotificationSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err          error
+		reqCtx       *RequestContext
+		id           uint64
+		subscription *corespdb.NotificationSubscription
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			reqCtx.SetError(gfsperrors.MakeGfSpError(err))
+			reqCtx.SetHttpCode(int(gfsperrors.MakeGfSpError(err).GetHttpStatusCode()))
+			MakeErrorResponse(w, gfsperrors.MakeGfSpError(err))
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = NewRequestContext(r)
+	if err != nil {
+		return
+	}
+	// the subscriptions are managed by the signed account, the anonymous request is refused
+	if reqCtx.Account() == "" {
+		log.CtxErrorw(reqCtx.Context(), "anonymous account has no permission to manage notification subscriptions")
+		err = ErrNoPermission
+		return
+	}
+	if g.baseApp.GfSpDB() == nil {
+		err = ErrNotificationUnavailable
+		return
+	}
+	if id, err = strconv.ParseUint(r.URL.Query().Get(model.NotificationSubscriptionIDQuery), 10, 64); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to parse notification subscription id", "error", err)
+		err = ErrInvalidQuery
+		return
+	}
+	if subscription, err = g.baseApp.GfSpDB().GetNotificationSubscription(id); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get notification subscription", "error", err)
+		return
+	}
+	if subscription == nil || subscription.Account != reqCtx.Account() {
+		log.CtxErrorw(reqCtx.Context(), "no such notification subscription", "id", id)
+		err = ErrNoSuchNotificationSubscription
+		return
+	}
+	if err = g.baseApp.GfSpDB().DeleteNotificationSubscription(id, reqCtx.Account()); err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to delete notification subscription", "error", err)
+		return
+	}
+	log.CtxDebugw(reqCtx.Context(), "succeed to delete notification subscription", "id", id)
+}
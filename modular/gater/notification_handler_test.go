@@ -0,0 +1,49 @@
+package gater
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+)
+
+func TestNotificationHandlers_RefuseAnonymous(t *testing.T) {
+	g := newTestMigrateGateModular(t, &mockMigrateDB{}, mockPieceStore{})
+	cases := []struct {
+		name    string
+		method  string
+		query   string
+		handler http.HandlerFunc
+	}{
+		{
+			name:    "create subscription",
+			method:  http.MethodPost,
+			query:   model.NotificationURLQuery + "=https://hook.test",
+			handler: g.createNotificationSubscriptionHandler,
+		},
+		{
+			name:    "list subscriptions",
+			method:  http.MethodGet,
+			handler: g.listNotificationSubscriptionsHandler,
+		},
+		{
+			name:    "delete subscription",
+			method:  http.MethodDelete,
+			query:   model.NotificationSubscriptionIDQuery + "=1",
+			handler: g.deleteNotificationSubscriptionHandler,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, scheme+testDomain+model.NotificationSubscriptionPath+"?"+tt.query, nil)
+			// the v2 signature is not verified and carries no account
+			r.Header.Set(model.GnfdAuthorizationHeader, signaturePrefix(model.SignTypeV2, model.SignAlgorithm))
+			w := httptest.NewRecorder()
+			tt.handler(w, r)
+			assert.Equal(t, int(ErrNoPermission.GetHttpStatusCode()), w.Code)
+		})
+	}
+}
@@ -2,8 +2,12 @@ package gater
 
 import (
 	"encoding/xml"
+	"fmt"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/bnb-chain/greenfield/types/s3util"
@@ -11,6 +15,7 @@ import (
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
 	"github.com/bnb-chain/greenfield-storage-provider/model"
 	"github.com/bnb-chain/greenfield-storage-provider/model/job"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
@@ -86,37 +91,145 @@ func (g *GateModular) putObjectHandler(w http.ResponseWriter, r *http.Request) {
 	log.CtxDebugw(ctx, "succeed to upload payload data")
 }
 
-func parseRange(rangeStr string) (bool, int64, int64) {
+// MaxRangeCount defines the max number of ranges in the Range header of one request.
+const MaxRangeCount = 64
+
+// parseRanges parses the Range header to the byte ranges of the object payload, the
+// end of the range is clamped to the payload size, and the suffix range bytes=-n means
+// the last n bytes. The overlapping and adjacent ranges are coalesced, so no byte of the
+// payload is downloaded twice. Returns false if the header is absent or malformed, the
+// whole object is served in this case.
+func parseRanges(rangeStr string, payloadSize int64) (bool, []coretask.TRange, error) {
 	if rangeStr == "" {
-		return false, -1, -1
+		return false, nil, nil
 	}
 	rangeStr = strings.ToLower(rangeStr)
 	rangeStr = strings.ReplaceAll(rangeStr, " ", "")
 	if !strings.HasPrefix(rangeStr, "bytes=") {
-		return false, -1, -1
+		return false, nil, nil
 	}
-	rangeStr = rangeStr[len("bytes="):]
-	if strings.HasSuffix(rangeStr, "-") {
-		rangeStr = rangeStr[:len(rangeStr)-1]
-		rangeStart, err := util.StringToUint64(rangeStr)
-		if err != nil {
-			return false, -1, -1
+	specs := strings.Split(rangeStr[len("bytes="):], ",")
+	if len(specs) > MaxRangeCount {
+		return true, nil, ErrInvalidRange
+	}
+	ranges := make([]coretask.TRange, 0, len(specs))
+	for _, spec := range specs {
+		pair := strings.Split(spec, "-")
+		if len(pair) != 2 {
+			return false, nil, nil
+		}
+		var low, high int64
+		if pair[0] == "" {
+			suffix, err := util.StringToUint64(pair[1])
+			if err != nil {
+				return false, nil, nil
+			}
+			low, high = payloadSize-int64(suffix), payloadSize-1
+			if low < 0 {
+				low = 0
+			}
+		} else {
+			rangeStart, err := util.StringToUint64(pair[0])
+			if err != nil {
+				return false, nil, nil
+			}
+			low, high = int64(rangeStart), payloadSize-1
+			if pair[1] != "" {
+				rangeEnd, err := util.StringToUint64(pair[1])
+				if err != nil {
+					return false, nil, nil
+				}
+				if int64(rangeEnd) < high {
+					high = int64(rangeEnd)
+				}
+			}
 		}
-		return true, int64(rangeStart), -1
+		if low < 0 || high < 0 || low > high {
+			return true, nil, ErrInvalidRange
+		}
+		ranges = append(ranges, coretask.TRange{Low: low, High: high})
 	}
-	pair := strings.Split(rangeStr, "-")
-	if len(pair) == 2 {
-		rangeStart, err := util.StringToUint64(pair[0])
-		if err != nil {
-			return false, -1, -1
+	return true, coalesceRanges(ranges), nil
+}
+
+// coalesceRanges sorts the ranges and merges the overlapping and adjacent ones, RFC 7233
+// allows coalescing the ranges regardless of the order in the Range header.
+func coalesceRanges(ranges []coretask.TRange) []coretask.TRange {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Low < ranges[j].Low
+	})
+	coalesced := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &coalesced[len(coalesced)-1]
+		if r.Low > last.High+1 {
+			coalesced = append(coalesced, r)
+			continue
+		}
+		if r.High > last.High {
+			last.High = r.High
 		}
-		rangeEnd, err := util.StringToUint64(pair[1])
+	}
+	return coalesced
+}
+
+// newDownloadObjectTask returns the download object task of the ranges, the read quota
+// is charged and the timeout is estimated by the sum of the ranges size.
+func (g *GateModular) newDownloadObjectTask(objectInfo *storagetypes.ObjectInfo, bucketInfo *storagetypes.BucketInfo,
+	params *storagetypes.Params, account string, ranges []coretask.TRange) *gfsptask.GfSpDownloadObjectTask {
+	task := &gfsptask.GfSpDownloadObjectTask{}
+	task.InitDownloadObjectTask(objectInfo, bucketInfo, params, g.baseApp.TaskPriority(task), account,
+		ranges[0].Low, ranges[0].High, 0, g.baseApp.TaskMaxRetry(task))
+	if len(ranges) > 1 {
+		task.SetRanges(ranges)
+	}
+	task.SetTimeout(g.baseApp.TaskTimeout(task, uint64(task.GetSize())))
+	return task
+}
+
+// writeObjectRanges writes the downloaded payload data of the ranges, the data of the
+// ranges is in order, and the multiple ranges are written as multipart/byteranges.
+func writeObjectRanges(w http.ResponseWriter, objectInfo *storagetypes.ObjectInfo, isRange bool,
+	ranges []coretask.TRange, data []byte) error {
+	if !isRange {
+		w.Header().Set(model.ContentLengthHeader, util.Uint64ToString(objectInfo.GetPayloadSize()))
+		w.Write(data)
+		return nil
+	}
+	if len(ranges) == 1 {
+		w.Header().Set(model.ContentRangeHeader, "bytes "+util.Uint64ToString(uint64(ranges[0].Low))+
+			"-"+util.Uint64ToString(uint64(ranges[0].High)))
+		w.Write(data)
+		return nil
+	}
+	var size int64
+	for _, r := range ranges {
+		size += r.High - r.Low + 1
+	}
+	if size > int64(len(data)) {
+		log.Errorw("downloaded data is shorter than the ranges", "data_size", len(data))
+		return ErrExceptionStream
+	}
+	// the parts are streamed to the response, the content length is not known in advance
+	partWriter := multipart.NewWriter(w)
+	w.Header().Set(model.ContentTypeHeader, model.ContentTypeMultipartByteRangesValue+"; boundary="+partWriter.Boundary())
+	w.WriteHeader(http.StatusPartialContent)
+	var offset int64
+	for _, r := range ranges {
+		size = r.High - r.Low + 1
+		part, err := partWriter.CreatePart(textproto.MIMEHeader{
+			model.ContentTypeHeader: {objectInfo.GetContentType()},
+			model.ContentRangeHeader: {fmt.Sprintf("bytes %d-%d/%d", r.Low, r.High,
+				objectInfo.GetPayloadSize())},
+		})
 		if err != nil {
-			return false, -1, -1
+			return err
+		}
+		if _, err = part.Write(data[offset : offset+size]); err != nil {
+			return err
 		}
-		return true, int64(rangeStart), int64(rangeEnd)
+		offset += size
 	}
-	return false, -1, -1
+	return partWriter.Close()
 }
 
 // getObjectHandler handles the download object request.
@@ -192,40 +305,44 @@ func (g *GateModular) getObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isRange, rangeStart, rangeEnd := parseRange(reqCtx.request.Header.Get(model.RangeHeader))
-	if isRange && (rangeEnd < 0 || rangeEnd >= int64(objectInfo.GetPayloadSize())) {
-		rangeEnd = int64(objectInfo.GetPayloadSize()) - 1
+	// the create time on chain is used as last modified time if failed to query metadata
+	objectMeta, _ := g.baseApp.GfSpClient().GetObjectMeta(reqCtx.Context(), objectInfo.GetObjectName(),
+		objectInfo.GetBucketName(), false)
+	lastModified := objectLastModified(objectMeta, objectInfo)
+	statusCode, ignoreRange := checkPreconditions(reqCtx.request.Header, objectETag(objectInfo), lastModified)
+	if statusCode == http.StatusPreconditionFailed {
+		err = ErrPreconditionFailed
+		return
 	}
-	if isRange && (rangeStart < 0 || rangeEnd < 0 || rangeStart > rangeEnd) {
-		err = ErrInvalidRange
+	setObjectHeaders(w, objectInfo, bucketInfo, lastModified)
+	if statusCode == http.StatusNotModified {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
-	var low int64
-	var high int64
-	if isRange {
-		low = rangeStart
-		high = rangeEnd
-	} else {
-		low = 0
-		high = int64(objectInfo.GetPayloadSize()) - 1
+	if r.Method == http.MethodHead {
+		w.Header().Set(model.ContentLengthHeader, util.Uint64ToString(objectInfo.GetPayloadSize()))
+		log.CtxDebugw(reqCtx.Context(), "succeed to head object")
+		return
 	}
 
-	task := &gfsptask.GfSpDownloadObjectTask{}
-	task.InitDownloadObjectTask(objectInfo, bucketInfo, params, g.baseApp.TaskPriority(task), reqCtx.Account(),
-		low, high, g.baseApp.TaskTimeout(task, uint64(high-low+1)), g.baseApp.TaskMaxRetry(task))
+	isRange, ranges, err := parseRanges(reqCtx.request.Header.Get(model.RangeHeader), int64(objectInfo.GetPayloadSize()))
+	if err != nil {
+		return
+	}
+	if !isRange || ignoreRange {
+		isRange = false
+		ranges = []coretask.TRange{{Low: 0, High: int64(objectInfo.GetPayloadSize()) - 1}}
+	}
+
+	task := g.newDownloadObjectTask(objectInfo, bucketInfo, params, reqCtx.Account(), ranges)
 	data, err := g.baseApp.GfSpClient().GetObject(reqCtx.Context(), task)
 	if err != nil {
 		log.CtxErrorw(reqCtx.Context(), "failed to download object", "error", err)
 		return
 	}
-	w.Header().Set(model.ContentTypeHeader, objectInfo.GetContentType())
-	if isRange {
-		w.Header().Set(model.ContentRangeHeader, "bytes "+util.Uint64ToString(uint64(low))+
-			"-"+util.Uint64ToString(uint64(high)))
-	} else {
-		w.Header().Set(model.ContentLengthHeader, util.Uint64ToString(objectInfo.GetPayloadSize()))
+	if err = writeObjectRanges(w, objectInfo, isRange, ranges, data); err != nil {
+		return
 	}
-	w.Write(data)
 	log.CtxDebugw(reqCtx.Context(), "succeed to download object")
 }
 
@@ -310,14 +427,16 @@ func (g *GateModular) getObjectByUniversalEndpointHandler(w http.ResponseWriter,
 	var (
 		err         error
 		reqCtx      *RequestContext
+		authorized  bool
+		account     string
 		isRange     bool
-		rangeStart  int64
-		rangeEnd    int64
+		ranges      []coretask.TRange
 		redirectUrl string
 		params      *storagetypes.Params
 	)
 
-	// ignore the error, because the universal endpoint does not need signature
+	// ignore the error, because the public object does not need signature, the
+	// private object is checked by the signed share link or the signed request.
 	reqCtx, _ = NewRequestContext(r)
 
 	defer func() {
@@ -373,6 +492,10 @@ func (g *GateModular) getObjectByUniversalEndpointHandler(w http.ResponseWriter,
 		} else {
 			redirectUrl = endpoint + "/view/" + reqCtx.bucketName + "/" + reqCtx.objectName
 		}
+		// keep the share link signature and expiry
+		if r.URL.RawQuery != "" {
+			redirectUrl = redirectUrl + "?" + r.URL.RawQuery
+		}
 
 		log.Debugw("getting redirect url:", "redirectUrl", redirectUrl)
 
@@ -380,13 +503,40 @@ func (g *GateModular) getObjectByUniversalEndpointHandler(w http.ResponseWriter,
 		return
 	}
 
-	// In first phase, do not provide universal endpoint for private object
 	getObjectInfoRes, err := g.baseApp.GfSpClient().GetObjectMeta(reqCtx.Context(), escapedObjectName, reqCtx.bucketName, false)
 	if err != nil || getObjectInfoRes == nil || getObjectInfoRes.GetObjectInfo() == nil {
 		log.Errorw("failed to check object meta", "object_name", escapedObjectName, "error", err)
 		return
 	}
 
+	// the private object is only served to the signer of the share link or the account
+	// who signs the request, both of them need the permission to get the object
+	if reqCtx.IsShareLink() {
+		if account, err = reqCtx.VerifyShareLink(escapedObjectName, g.baseApp.GfSpDB()); err != nil {
+			return
+		}
+	} else if !isPublicObject(getObjectInfoRes.GetObjectInfo(), getBucketInfoRes.GetBucketInfo()) {
+		account = reqCtx.Account()
+		if account == "" {
+			log.CtxErrorw(reqCtx.Context(), "no permission to get private object without signature")
+			err = ErrNoPermission
+			return
+		}
+	}
+	if account != "" {
+		authorized, err = g.baseApp.GfSpClient().VerifyAuthorize(reqCtx.Context(),
+			coremodule.AuthOpTypeGetObject, account, reqCtx.bucketName, escapedObjectName)
+		if err != nil {
+			log.CtxErrorw(reqCtx.Context(), "failed to verify authorize", "error", err)
+			return
+		}
+		if !authorized {
+			log.CtxErrorw(reqCtx.Context(), "no permission to operate", "account", account)
+			err = ErrNoPermission
+			return
+		}
+	}
+
 	if getObjectInfoRes.GetObjectInfo().GetObjectStatus() != storagetypes.OBJECT_STATUS_SEALED {
 		log.Errorw("object is not sealed",
 			"status", getObjectInfoRes.GetObjectInfo().GetObjectStatus())
@@ -400,38 +550,52 @@ func (g *GateModular) getObjectByUniversalEndpointHandler(w http.ResponseWriter,
 		return
 	}
 
-	var low int64
-	var high int64
-	if isRange {
-		low = rangeStart
-		high = rangeEnd
-	} else {
-		low = 0
-		high = int64(getObjectInfoRes.GetObjectInfo().GetPayloadSize()) - 1
+	lastModified := objectLastModified(getObjectInfoRes, getObjectInfoRes.GetObjectInfo())
+	statusCode, ignoreRange := checkPreconditions(reqCtx.request.Header, objectETag(getObjectInfoRes.GetObjectInfo()), lastModified)
+	if statusCode == http.StatusPreconditionFailed {
+		err = ErrPreconditionFailed
+		return
 	}
-
-	task := &gfsptask.GfSpDownloadObjectTask{}
-	task.InitDownloadObjectTask(getObjectInfoRes.GetObjectInfo(), getBucketInfoRes.GetBucketInfo(), params, g.baseApp.TaskPriority(task), reqCtx.Account(),
-		low, high, g.baseApp.TaskTimeout(task, uint64(high-low+1)), g.baseApp.TaskMaxRetry(task))
-	data, err := g.baseApp.GfSpClient().GetObject(reqCtx.Context(), task)
-	if err != nil {
-		log.CtxErrorw(reqCtx.Context(), "failed to download object", "error", err)
+	setObjectHeaders(w, getObjectInfoRes.GetObjectInfo(), getBucketInfoRes.GetBucketInfo(), lastModified)
+	if statusCode == http.StatusNotModified {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
-
 	if isDownload {
 		w.Header().Set(model.ContentDispositionHeader, model.ContentDispositionAttachmentValue+"; filename=\""+escapedObjectName+"\"")
 	} else {
 		w.Header().Set(model.ContentDispositionHeader, model.ContentDispositionInlineValue)
 	}
-	w.Header().Set(model.ContentTypeHeader, getObjectInfoRes.GetObjectInfo().GetContentType())
-	if isRange {
-		w.Header().Set(model.ContentRangeHeader, "bytes "+util.Uint64ToString(uint64(low))+
-			"-"+util.Uint64ToString(uint64(high)))
-	} else {
+	if r.Method == http.MethodHead {
 		w.Header().Set(model.ContentLengthHeader, util.Uint64ToString(getObjectInfoRes.GetObjectInfo().GetPayloadSize()))
+		log.CtxDebugw(reqCtx.Context(), "succeed to head object for universal endpoint")
+		return
+	}
+
+	isRange, ranges, err = parseRanges(reqCtx.request.Header.Get(model.RangeHeader),
+		int64(getObjectInfoRes.GetObjectInfo().GetPayloadSize()))
+	if err != nil {
+		return
+	}
+	if !isRange || ignoreRange {
+		isRange = false
+		ranges = []coretask.TRange{{Low: 0, High: int64(getObjectInfoRes.GetObjectInfo().GetPayloadSize()) - 1}}
+	}
+
+	// the read traffic of the share link is recorded to the signer
+	if account == "" {
+		account = reqCtx.Account()
+	}
+	task := g.newDownloadObjectTask(getObjectInfoRes.GetObjectInfo(), getBucketInfoRes.GetBucketInfo(),
+		params, account, ranges)
+	data, err := g.baseApp.GfSpClient().GetObject(reqCtx.Context(), task)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to download object", "error", err)
+		return
+	}
+	if err = writeObjectRanges(w, getObjectInfoRes.GetObjectInfo(), isRange, ranges, data); err != nil {
+		return
 	}
-	w.Write(data)
 	log.CtxDebugw(reqCtx.Context(), "succeed to download object for universal endpoint")
 }
 
@@ -0,0 +1,84 @@
+package gater
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+func TestParseRanges(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		isRange  bool
+		ranges   []coretask.TRange
+		hasError bool
+	}{
+		{"no range", "", false, nil, false},
+		{"invalid unit", "items=0-1", false, nil, false},
+		{"malformed range", "bytes=a-b", false, nil, false},
+		{"single range", "bytes=0-9", true, []coretask.TRange{{Low: 0, High: 9}}, false},
+		{"single byte", "bytes=5-5", true, []coretask.TRange{{Low: 5, High: 5}}, false},
+		{"open range", "bytes=90-", true, []coretask.TRange{{Low: 90, High: 99}}, false},
+		{"clamped range", "bytes=90-200", true, []coretask.TRange{{Low: 90, High: 99}}, false},
+		{"suffix range", "bytes=-10", true, []coretask.TRange{{Low: 90, High: 99}}, false},
+		{"multiple ranges", "bytes=0-9, 20-29,-5", true,
+			[]coretask.TRange{{Low: 0, High: 9}, {Low: 20, High: 29}, {Low: 95, High: 99}}, false},
+		{"unordered ranges", "bytes=-5,20-29,0-9", true,
+			[]coretask.TRange{{Low: 0, High: 9}, {Low: 20, High: 29}, {Low: 95, High: 99}}, false},
+		{"overlapping ranges", "bytes=0-9,5-14,10-12", true, []coretask.TRange{{Low: 0, High: 14}}, false},
+		{"adjacent ranges", "bytes=20-29,0-9,10-19", true, []coretask.TRange{{Low: 0, High: 29}}, false},
+		{"duplicate ranges", "bytes=0-,0-,0-", true, []coretask.TRange{{Low: 0, High: 99}}, false},
+		{"start exceeds end", "bytes=9-0", true, nil, true},
+		{"start exceeds size", "bytes=100-", true, nil, true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			isRange, ranges, err := parseRanges(testCase.header, 100)
+			assert.Equal(t, testCase.isRange, isRange)
+			assert.Equal(t, testCase.ranges, ranges)
+			assert.Equal(t, testCase.hasError, err != nil)
+		})
+	}
+}
+
+func TestWriteObjectRanges(t *testing.T) {
+	objectInfo := &storagetypes.ObjectInfo{PayloadSize: 100, ContentType: "text/plain"}
+	ranges := []coretask.TRange{{Low: 0, High: 1}, {Low: 10, High: 12}}
+	w := httptest.NewRecorder()
+	require.NoError(t, writeObjectRanges(w, objectInfo, true, ranges, []byte("abcde")))
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	// the parts are streamed without the content length
+	assert.Empty(t, w.Header().Get(model.ContentLengthHeader))
+
+	mediaType, params, err := mime.ParseMediaType(w.Header().Get(model.ContentTypeHeader))
+	require.NoError(t, err)
+	assert.Equal(t, model.ContentTypeMultipartByteRangesValue, mediaType)
+	reader := multipart.NewReader(w.Body, params["boundary"])
+	for _, expect := range []struct {
+		contentRange string
+		data         string
+	}{{"bytes 0-1/100", "ab"}, {"bytes 10-12/100", "cde"}} {
+		part, err := reader.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, expect.contentRange, part.Header.Get(model.ContentRangeHeader))
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.Equal(t, expect.data, string(data))
+	}
+	_, err = reader.NextPart()
+	assert.Equal(t, io.EOF, err)
+
+	// the downloaded data is shorter than the ranges
+	assert.Equal(t, ErrExceptionStream, writeObjectRanges(httptest.NewRecorder(), objectInfo, true, ranges, []byte("abc")))
+}
@@ -3,11 +3,14 @@ package gater
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/eth/ethsecp256k1"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
@@ -15,8 +18,17 @@ import (
 	"github.com/gorilla/mux"
 
 	commonhttp "github.com/bnb-chain/greenfield-common/go/http"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
 	"github.com/bnb-chain/greenfield-storage-provider/model"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/util"
+)
+
+const (
+	// MaxShareLinkExpiry defines the max valid duration of the signed share link.
+	MaxShareLinkExpiry = 7 * 24 * time.Hour
+	// EddsaPublicKeySize defines the size of the compressed EDDSA public key of the off-chain auth.
+	EddsaPublicKeySize = 32
 )
 
 // RequestContext generates from http request, it records the common info
@@ -43,6 +55,18 @@ type RequestContext struct {
 // request signature, returns the instance regardless of the success or
 // failure of the verification.
 func NewRequestContext(r *http.Request) (*RequestContext, error) {
+	reqCtx := newRequestContext(r)
+	account, err := reqCtx.VerifySignature()
+	if err != nil {
+		return reqCtx, err
+	}
+	reqCtx.account = account
+	return reqCtx, nil
+}
+
+// newRequestContext returns an instance of RequestContext without verifying the
+// request signature.
+func newRequestContext(r *http.Request) *RequestContext {
 	vars := mux.Vars(r)
 	routerName := ""
 	if mux.CurrentRoute(r) != nil {
@@ -60,12 +84,7 @@ func NewRequestContext(r *http.Request) (*RequestContext, error) {
 		vars:       vars,
 		startTime:  time.Now(),
 	}
-	account, err := reqCtx.VerifySignature()
-	if err != nil {
-		return reqCtx, err
-	}
-	reqCtx.account = account
-	return reqCtx, nil
+	return reqCtx
 }
 
 // Context returns the RequestContext runtime context.
@@ -226,3 +245,128 @@ func RecoverAddr(msg []byte, sig []byte) (sdk.AccAddress, ethsecp256k1.PubKey, e
 	recoverAcc := sdk.AccAddress(pk.Address().Bytes())
 	return recoverAcc, pk, nil
 }
+
+// shareLinkContent returns the content of the share link to sign, the signature is only
+// valid for the object of the bucket before the expiry timestamp.
+func shareLinkContent(bucketName, objectName string, expiry int64) string {
+	return fmt.Sprintf("GreenfieldShareLink\n%s\n%s\n%d", bucketName, objectName, expiry)
+}
+
+// ShareLinkMsgToSign returns the msg to sign of the ECDSA share link, it is the keccak256
+// hash of the share link content.
+func ShareLinkMsgToSign(bucketName, objectName string, expiry int64) []byte {
+	return ethcrypto.Keccak256([]byte(shareLinkContent(bucketName, objectName, expiry)))
+}
+
+// IsShareLink returns whether the request is sent by the signed share link.
+func (r *RequestContext) IsShareLink() bool {
+	return r.request.URL.Query().Get(model.ShareLinkSignatureQuery) != ""
+}
+
+// VerifyShareLink verifies the signature and expiry of the share link of the object,
+// returns the account who signs the link, the permission of the account should be
+// checked by authorizer. The EDDSA share link is verified by the public key that the
+// signer registers for the app domain by the off-chain auth.
+func (r *RequestContext) VerifyShareLink(objectName string, authKeyDB corespdb.OffChainAuthKeyDB) (string, error) {
+	query := r.request.URL.Query()
+	algorithm := query.Get(model.ShareLinkSignAlgorithmQuery)
+	if algorithm == "" {
+		algorithm = model.SignAlgorithm
+	}
+	signer, err := sdk.AccAddressFromHexUnsafe(query.Get(model.ShareLinkSignerQuery))
+	if err != nil {
+		log.CtxErrorw(r.ctx, "failed to parse share link signer", "error", err)
+		return "", ErrInvalidShareLink
+	}
+	expiry, err := util.StringToInt64(query.Get(model.ShareLinkExpiryQuery))
+	if err != nil {
+		log.CtxErrorw(r.ctx, "failed to parse share link expiry", "error", err)
+		return "", ErrInvalidShareLink
+	}
+	now := time.Now()
+	if time.Unix(expiry, 0).Before(now) {
+		log.CtxErrorw(r.ctx, "share link is expired", "expiry", expiry)
+		return "", ErrShareLinkExpired
+	}
+	if time.Unix(expiry, 0).After(now.Add(MaxShareLinkExpiry)) {
+		log.CtxErrorw(r.ctx, "share link expiry exceeds the max", "expiry", expiry)
+		return "", ErrInvalidShareLink
+	}
+	signature, err := hex.DecodeString(query.Get(model.ShareLinkSignatureQuery))
+	if err != nil {
+		log.CtxErrorw(r.ctx, "failed to decode share link signature", "error", err)
+		return "", ErrInvalidShareLink
+	}
+
+	switch algorithm {
+	case model.SignAlgorithm:
+		if len(signature) != ethcrypto.SignatureLength {
+			log.CtxErrorw(r.ctx, "invalid share link signature length", "length", len(signature))
+			return "", ErrInvalidShareLink
+		}
+		msg := ShareLinkMsgToSign(r.bucketName, objectName, expiry)
+		addr, pk, err := RecoverAddr(msg, signature)
+		if err != nil {
+			log.CtxErrorw(r.ctx, "failed to recover share link signer", "error", err)
+			return "", ErrSignature
+		}
+		if !secp256k1.VerifySignature(pk.Bytes(), msg, signature[:len(signature)-1]) {
+			log.CtxErrorw(r.ctx, "failed to verify share link signature")
+			return "", ErrSignature
+		}
+		if !addr.Equals(signer) {
+			log.CtxErrorw(r.ctx, "share link is not signed by the signer", "signer", signer.String(),
+				"recovered", addr.String())
+			return "", ErrSignature
+		}
+	case model.SignAlgorithmEddsa:
+		domain := query.Get(model.ShareLinkAppDomainQuery)
+		if domain == "" || authKeyDB == nil {
+			log.CtxErrorw(r.ctx, "failed to verify eddsa share link, no app domain or auth key db")
+			return "", ErrInvalidShareLink
+		}
+		publicKey, err := authKeyDB.GetOffChainAuthPublicKey(signer.String(), domain)
+		if err != nil {
+			log.CtxErrorw(r.ctx, "failed to get off-chain auth public key", "error", err)
+			return "", ErrInvalidShareLink
+		}
+		if publicKey == nil || time.Unix(publicKey.ExpiryDate, 0).Before(now) {
+			log.CtxErrorw(r.ctx, "no valid off-chain auth public key", "signer", signer.String(), "domain", domain)
+			return "", ErrSignature
+		}
+		if err = VerifyEddsaSignature(publicKey.PublicKey, signature,
+			[]byte(shareLinkContent(r.bucketName, objectName, expiry))); err != nil {
+			log.CtxErrorw(r.ctx, "failed to verify eddsa share link signature", "error", err)
+			return "", ErrSignature
+		}
+	default:
+		log.CtxErrorw(r.ctx, "unsupported share link sign algorithm", "algorithm", algorithm)
+		return "", ErrUnsupportedSignType
+	}
+	return signer.String(), nil
+}
+
+// VerifyEddsaSignature verifies the EDDSA signature of the msg by the hex encoded public key,
+// the msg is hashed by MiMC.
+func VerifyEddsaSignature(publicKey string, signature, msg []byte) error {
+	pkBytes, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return err
+	}
+	pk := new(eddsa.PublicKey)
+	size, err := pk.SetBytes(pkBytes)
+	if err != nil {
+		return err
+	}
+	if size != EddsaPublicKeySize {
+		return fmt.Errorf("invalid eddsa public key size: %d", size)
+	}
+	valid, err := pk.Verify(signature, msg, mimc.NewMiMC())
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("invalid eddsa signature")
+	}
+	return nil
+}
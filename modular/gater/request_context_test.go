@@ -0,0 +1,126 @@
+package gater
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+)
+
+const testAppDomain = "https://app.com"
+
+type mockOffChainAuthKeyDB map[string]*corespdb.OffChainAuthPublicKey
+
+func (m mockOffChainAuthKeyDB) GetOffChainAuthPublicKey(userAddress string, domain string) (
+	*corespdb.OffChainAuthPublicKey, error) {
+	return m[userAddress+domain], nil
+}
+
+func makeShareLinkRequest(algorithm, signer string, signature []byte, expiry int64) *RequestContext {
+	url := scheme + testDomain + "/download/" + bucketName + "/" + objectName + "?" +
+		model.ShareLinkSignAlgorithmQuery + "=" + algorithm + "&" +
+		model.ShareLinkSignerQuery + "=" + signer + "&" +
+		model.ShareLinkAppDomainQuery + "=" + testAppDomain + "&" +
+		model.ShareLinkExpiryQuery + "=" + strconv.FormatInt(expiry, 10) + "&" +
+		model.ShareLinkSignatureQuery + "=" + hex.EncodeToString(signature)
+	reqCtx, _ := NewRequestContext(httptest.NewRequest("GET", url, nil))
+	reqCtx.bucketName = bucketName
+	return reqCtx
+}
+
+func makeECDSAShareLinkRequest(t *testing.T, privKey *ecdsa.PrivateKey, expiry int64, signedObject string) *RequestContext {
+	signature, err := ethcrypto.Sign(ShareLinkMsgToSign(bucketName, signedObject, expiry), privKey)
+	require.NoError(t, err)
+	signer := sdk.AccAddress(ethcrypto.PubkeyToAddress(privKey.PublicKey).Bytes()).String()
+	return makeShareLinkRequest(model.SignAlgorithm, signer, signature, expiry)
+}
+
+func TestVerifyShareLink(t *testing.T) {
+	privKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	account := sdk.AccAddress(ethcrypto.PubkeyToAddress(privKey.PublicKey).Bytes()).String()
+	expiry := time.Now().Add(time.Hour).Unix()
+
+	reqCtx := makeECDSAShareLinkRequest(t, privKey, expiry, objectName)
+	assert.True(t, reqCtx.IsShareLink())
+	signer, err := reqCtx.VerifyShareLink(objectName, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, account, signer)
+
+	// the signature of other object does not recover the signer
+	reqCtx = makeECDSAShareLinkRequest(t, privKey, expiry, "other-object")
+	_, err = reqCtx.VerifyShareLink(objectName, nil)
+	assert.Equal(t, ErrSignature, err)
+
+	reqCtx = makeECDSAShareLinkRequest(t, privKey, time.Now().Add(-time.Hour).Unix(), objectName)
+	_, err = reqCtx.VerifyShareLink(objectName, nil)
+	assert.Equal(t, ErrShareLinkExpired, err)
+
+	reqCtx = makeECDSAShareLinkRequest(t, privKey, time.Now().Add(2*MaxShareLinkExpiry).Unix(), objectName)
+	_, err = reqCtx.VerifyShareLink(objectName, nil)
+	assert.Equal(t, ErrInvalidShareLink, err)
+
+	reqCtx = makeShareLinkRequest("unknown", account, []byte("signature"), expiry)
+	_, err = reqCtx.VerifyShareLink(objectName, nil)
+	assert.Equal(t, ErrUnsupportedSignType, err)
+}
+
+func TestVerifyEddsaShareLink(t *testing.T) {
+	privKey, err := eddsa.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecdsaKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	account := sdk.AccAddress(ethcrypto.PubkeyToAddress(ecdsaKey.PublicKey).Bytes()).String()
+	expiry := time.Now().Add(time.Hour).Unix()
+	authKeyDB := mockOffChainAuthKeyDB{account + testAppDomain: {
+		UserAddress: account,
+		Domain:      testAppDomain,
+		PublicKey:   hex.EncodeToString(privKey.PublicKey.Bytes()),
+		ExpiryDate:  time.Now().Add(time.Hour).Unix(),
+	}}
+	sign := func(signedObject string) []byte {
+		signature, err := privKey.Sign([]byte(shareLinkContent(bucketName, signedObject, expiry)), mimc.NewMiMC())
+		require.NoError(t, err)
+		return signature
+	}
+
+	reqCtx := makeShareLinkRequest(model.SignAlgorithmEddsa, account, sign(objectName), expiry)
+	signer, err := reqCtx.VerifyShareLink(objectName, authKeyDB)
+	assert.Nil(t, err)
+	assert.Equal(t, account, signer)
+
+	reqCtx = makeShareLinkRequest(model.SignAlgorithmEddsa, account, sign("other-object"), expiry)
+	_, err = reqCtx.VerifyShareLink(objectName, authKeyDB)
+	assert.Equal(t, ErrSignature, err)
+
+	// the signer has no public key of the app domain
+	otherKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	otherAccount := sdk.AccAddress(ethcrypto.PubkeyToAddress(otherKey.PublicKey).Bytes()).String()
+	reqCtx = makeShareLinkRequest(model.SignAlgorithmEddsa, otherAccount, sign(objectName), expiry)
+	_, err = reqCtx.VerifyShareLink(objectName, authKeyDB)
+	assert.Equal(t, ErrSignature, err)
+
+	// the public key is expired
+	authKeyDB[account+testAppDomain].ExpiryDate = time.Now().Add(-time.Hour).Unix()
+	reqCtx = makeShareLinkRequest(model.SignAlgorithmEddsa, account, sign(objectName), expiry)
+	_, err = reqCtx.VerifyShareLink(objectName, authKeyDB)
+	assert.Equal(t, ErrSignature, err)
+
+	reqCtx = makeShareLinkRequest(model.SignAlgorithmEddsa, account, sign(objectName), expiry)
+	_, err = reqCtx.VerifyShareLink(objectName, nil)
+	assert.Equal(t, ErrInvalidShareLink, err)
+}
@@ -15,6 +15,7 @@ const (
 	approvalRouterName                    = "GetApproval"
 	putObjectRouterName                   = "PutObject"
 	getObjectRouterName                   = "GetObject"
+	headObjectRouterName                  = "HeadObject"
 	challengeRouterName                   = "Challenge"
 	replicateObjectPieceRouterName        = "ReplicateObjectPiece"
 	getUserBucketsRouterName              = "GetUserBuckets"
@@ -28,6 +29,18 @@ const (
 	viewObjectByUniversalEndpointName     = "ViewObjectByUniversalEndpoint"
 	getObjectMetaRouterName               = "GetObjectMeta"
 	getBucketMetaRouterName               = "GetBucketMeta"
+	getPrefixStatsRouterName              = "GetPrefixStats"
+	migrateBucketRouterName               = "MigrateBucket"
+	notifyMigrateBucketRouterName         = "NotifyMigrateBucket"
+	p2pNodeRouterName                     = "P2PNode"
+	createNotificationRouterName          = "CreateNotificationSubscription"
+	listNotificationsRouterName           = "ListNotificationSubscriptions"
+	deleteNotificationRouterName          = "DeleteNotificationSubscription"
+	s3ListBucketsRouterName               = "S3ListBuckets"
+	s3ListObjectsV2RouterName             = "S3ListObjectsV2"
+	s3HeadBucketRouterName                = "S3HeadBucket"
+	s3GetObjectRouterName                 = "S3GetObject"
+	s3HeadObjectRouterName                = "S3HeadObject"
 )
 
 const (
@@ -63,6 +76,11 @@ func (g *GateModular) RegisterHandler(router *mux.Router) {
 		Methods(http.MethodGet).
 		Queries(model.GetBucketMetaQuery, "").
 		HandlerFunc(g.getBucketMetaHandler)
+	hostBucketRouter.NewRoute().
+		Name(getPrefixStatsRouterName).
+		Methods(http.MethodGet).
+		Queries(model.GetPrefixStatsQuery, "").
+		HandlerFunc(g.getPrefixStatsHandler)
 	hostBucketRouter.NewRoute().
 		Name(getObjectMetaRouterName).
 		Methods(http.MethodGet).
@@ -74,6 +92,11 @@ func (g *GateModular) RegisterHandler(router *mux.Router) {
 		Methods(http.MethodGet).
 		Path("/{object:.+}").
 		HandlerFunc(g.getObjectHandler)
+	hostBucketRouter.NewRoute().
+		Name(headObjectRouterName).
+		Methods(http.MethodHead).
+		Path("/{object:.+}").
+		HandlerFunc(g.getObjectHandler)
 	hostBucketRouter.NewRoute().
 		Name(getBucketReadQuotaRouterName).
 		Methods(http.MethodGet).
@@ -116,15 +139,43 @@ func (g *GateModular) RegisterHandler(router *mux.Router) {
 		Name(replicateObjectPieceRouterName).
 		Methods(http.MethodPut).
 		HandlerFunc(g.replicateHandler)
+	// export bucket to the migrating destination sp
+	router.Path(model.MigrateBucketPath).
+		Name(migrateBucketRouterName).
+		Methods(http.MethodGet).
+		HandlerFunc(g.migrateBucketHandler)
+	// the exiting primary sp notifies to migrate the bucket
+	router.Path(model.NotifyMigrateBucketPath).
+		Name(notifyMigrateBucketRouterName).
+		Methods(http.MethodPost).
+		HandlerFunc(g.notifyMigrateBucketHandler)
+	// other sps discover the p2p node
+	router.Path(model.P2PNodePath).
+		Name(p2pNodeRouterName).
+		Methods(http.MethodGet).
+		HandlerFunc(g.p2pNodeHandler)
+	// manage the subscriptions of the object lifecycle notification
+	router.Path(model.NotificationSubscriptionPath).
+		Name(createNotificationRouterName).
+		Methods(http.MethodPost).
+		HandlerFunc(g.createNotificationSubscriptionHandler)
+	router.Path(model.NotificationSubscriptionPath).
+		Name(listNotificationsRouterName).
+		Methods(http.MethodGet).
+		HandlerFunc(g.listNotificationSubscriptionsHandler)
+	router.Path(model.NotificationSubscriptionPath).
+		Name(deleteNotificationRouterName).
+		Methods(http.MethodDelete).
+		HandlerFunc(g.deleteNotificationSubscriptionHandler)
 	// universal endpoint download
 	router.Path("/download/{bucket:[^/]*}/{object:.+}").
 		Name(downloadObjectByUniversalEndpointName).
-		Methods(http.MethodGet).
+		Methods(http.MethodGet, http.MethodHead).
 		HandlerFunc(g.downloadObjectByUniversalEndpointHandler)
 	// universal endpoint view
 	router.Path("/view/{bucket:[^/]*}/{object:.+}").
 		Name(viewObjectByUniversalEndpointName).
-		Methods(http.MethodGet).
+		Methods(http.MethodGet, http.MethodHead).
 		HandlerFunc(g.viewObjectByUniversalEndpointHandler)
 	//redirect for universal endpoint
 	http.Handle("/", router)
@@ -157,6 +208,11 @@ func (g *GateModular) RegisterHandler(router *mux.Router) {
 		Methods(http.MethodGet).
 		Queries(model.GetBucketMetaQuery, "").
 		HandlerFunc(g.getBucketMetaHandler)
+	pathBucketRouter.NewRoute().
+		Name(getPrefixStatsRouterName).
+		Methods(http.MethodGet).
+		Queries(model.GetPrefixStatsQuery, "").
+		HandlerFunc(g.getPrefixStatsHandler)
 	pathBucketRouter.NewRoute().
 		Name(getObjectMetaRouterName).
 		Methods(http.MethodGet).
@@ -168,6 +224,11 @@ func (g *GateModular) RegisterHandler(router *mux.Router) {
 		Methods(http.MethodGet).
 		Path("/{object:.+}").
 		HandlerFunc(g.getObjectHandler)
+	pathBucketRouter.NewRoute().
+		Name(headObjectRouterName).
+		Methods(http.MethodHead).
+		Path("/{object:.+}").
+		HandlerFunc(g.getObjectHandler)
 	pathBucketRouter.NewRoute().
 		Name(getBucketReadQuotaRouterName).
 		Methods(http.MethodGet).
@@ -192,3 +253,42 @@ func (g *GateModular) RegisterHandler(router *mux.Router) {
 	router.NotFoundHandler = http.HandlerFunc(g.notFoundHandler)
 	router.Use(localhttp.Limit)
 }
+
+// RegisterS3Handler registers the S3 compatible read handlers to the router of the S3 api.
+func (g *GateModular) RegisterS3Handler(router *mux.Router) {
+	// bucket router, virtual-hosted style
+	if g.s3Domain != "" {
+		hostBucketRouter := router.Host("{bucket:.+}." + g.s3Domain).Subrouter()
+		g.registerS3BucketHandler(hostBucketRouter.Path("/").Subrouter(), hostBucketRouter.Path("/{object:.+}").Subrouter())
+	}
+	router.Path("/").
+		Name(s3ListBucketsRouterName).
+		Methods(http.MethodGet).
+		HandlerFunc(g.s3ListBucketsHandler)
+	// path style, the trailing slash of the bucket is optional
+	g.registerS3BucketHandler(router.Path("/{bucket:[^/]+}{slash:/?}").Subrouter(),
+		router.Path("/{bucket:[^/]+}/{object:.+}").Subrouter())
+	router.MethodNotAllowedHandler = http.HandlerFunc(g.s3NotAllowedHandler)
+	router.NotFoundHandler = http.HandlerFunc(g.s3NotAllowedHandler)
+	router.Use(localhttp.Limit)
+}
+
+// registerS3BucketHandler registers the bucket and object handlers of the S3 api.
+func (g *GateModular) registerS3BucketHandler(bucketRouter *mux.Router, objectRouter *mux.Router) {
+	bucketRouter.NewRoute().
+		Name(s3ListObjectsV2RouterName).
+		Methods(http.MethodGet).
+		HandlerFunc(g.s3ListObjectsV2Handler)
+	bucketRouter.NewRoute().
+		Name(s3HeadBucketRouterName).
+		Methods(http.MethodHead).
+		HandlerFunc(g.s3HeadBucketHandler)
+	objectRouter.NewRoute().
+		Name(s3GetObjectRouterName).
+		Methods(http.MethodGet).
+		HandlerFunc(g.s3GetObjectHandler)
+	objectRouter.NewRoute().
+		Name(s3HeadObjectRouterName).
+		Methods(http.MethodHead).
+		HandlerFunc(g.s3GetObjectHandler)
+}
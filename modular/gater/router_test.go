@@ -102,6 +102,30 @@ func TestRouters(t *testing.T) {
 			shouldMatch:      true,
 			wantedRouterName: getObjectRouterName,
 		},
+		{
+			name:             "Head object router, virtual host style",
+			router:           gwRouter,
+			method:           http.MethodHead,
+			url:              scheme + bucketName + "." + testDomain + "/" + objectName,
+			shouldMatch:      true,
+			wantedRouterName: headObjectRouterName,
+		},
+		{
+			name:             "Head object router, path style",
+			router:           gwRouter,
+			method:           http.MethodHead,
+			url:              scheme + testDomain + "/" + bucketName + "/" + objectName,
+			shouldMatch:      true,
+			wantedRouterName: headObjectRouterName,
+		},
+		{
+			name:             "Head object router, universal endpoint",
+			router:           gwRouter,
+			method:           http.MethodHead,
+			url:              scheme + testDomain + "/download/" + bucketName + "/" + objectName,
+			shouldMatch:      true,
+			wantedRouterName: downloadObjectByUniversalEndpointName,
+		},
 		{
 			name:             "Get bucket read quota router, virtual host style",
 			router:           gwRouter,
@@ -194,6 +218,22 @@ func TestRouters(t *testing.T) {
 			shouldMatch:      true,
 			wantedRouterName: getBucketMetaRouterName,
 		},
+		{
+			name:             "Get prefix stats router, virtual host style",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              scheme + bucketName + "." + testDomain + "?" + model.GetPrefixStatsQuery + "&" + model.ListObjectsPrefixQuery + "=dir/",
+			shouldMatch:      true,
+			wantedRouterName: getPrefixStatsRouterName,
+		},
+		{
+			name:             "Get prefix stats router, path style",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              scheme + testDomain + "/" + bucketName + "?" + model.GetPrefixStatsQuery,
+			shouldMatch:      true,
+			wantedRouterName: getPrefixStatsRouterName,
+		},
 		{
 			name:             "Challenge router",
 			router:           gwRouter,
@@ -210,6 +250,47 @@ func TestRouters(t *testing.T) {
 			shouldMatch:      true,
 			wantedRouterName: replicateObjectPieceRouterName,
 		},
+		{
+			name:             "Migrate bucket router",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              scheme + testDomain + model.MigrateBucketPath,
+			shouldMatch:      true,
+			wantedRouterName: migrateBucketRouterName,
+		},
+		{
+			name:             "Notify migrate bucket router",
+			router:           gwRouter,
+			method:           http.MethodPost,
+			url:              scheme + testDomain + model.NotifyMigrateBucketPath,
+			shouldMatch:      true,
+			wantedRouterName: notifyMigrateBucketRouterName,
+		},
+		{
+			name:   "Create notification subscription router",
+			router: gwRouter,
+			method: http.MethodPost,
+			url: scheme + testDomain + model.NotificationSubscriptionPath + "?" + model.NotificationBucketQuery + "=" +
+				bucketName + "&" + model.NotificationURLQuery + "=https://hook.test",
+			shouldMatch:      true,
+			wantedRouterName: createNotificationRouterName,
+		},
+		{
+			name:             "List notification subscriptions router",
+			router:           gwRouter,
+			method:           http.MethodGet,
+			url:              scheme + testDomain + model.NotificationSubscriptionPath,
+			shouldMatch:      true,
+			wantedRouterName: listNotificationsRouterName,
+		},
+		{
+			name:             "Delete notification subscription router",
+			router:           gwRouter,
+			method:           http.MethodDelete,
+			url:              scheme + testDomain + model.NotificationSubscriptionPath + "?" + model.NotificationSubscriptionIDQuery + "=1",
+			shouldMatch:      true,
+			wantedRouterName: deleteNotificationRouterName,
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -0,0 +1,437 @@
+package gater
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/util"
+	"github.com/bnb-chain/greenfield/types/s3util"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+const (
+	// S3XMLNamespace is the xml namespace of the S3 api responses.
+	S3XMLNamespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+	// S3TimeFormat is the time format of the S3 api responses.
+	S3TimeFormat = "2006-01-02T15:04:05.000Z"
+	// S3StorageClass is the storage class of all the objects.
+	S3StorageClass = "STANDARD"
+	// S3EncodingTypeURL indicates the object keys in the list response are url encoded.
+	S3EncodingTypeURL = "url"
+)
+
+// s3Owner is the owner of the buckets in the ListBuckets response.
+type s3Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+// s3Bucket is the bucket in the ListBuckets response.
+type s3Bucket struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+// s3ListAllMyBucketsResult is the response of ListBuckets.
+type s3ListAllMyBucketsResult struct {
+	XMLName xml.Name   `xml:"ListAllMyBucketsResult"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Owner   s3Owner    `xml:"Owner"`
+	Buckets []s3Bucket `xml:"Buckets>Bucket"`
+}
+
+// s3Object is the object in the ListObjectsV2 response.
+type s3Object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         uint64 `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+// s3CommonPrefix is the common prefix in the ListObjectsV2 response.
+type s3CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// s3ListBucketResult is the response of ListObjectsV2.
+type s3ListBucketResult struct {
+	XMLName               xml.Name         `xml:"ListBucketResult"`
+	Xmlns                 string           `xml:"xmlns,attr"`
+	Name                  string           `xml:"Name"`
+	Prefix                string           `xml:"Prefix"`
+	StartAfter            string           `xml:"StartAfter,omitempty"`
+	ContinuationToken     string           `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
+	KeyCount              int              `xml:"KeyCount"`
+	MaxKeys               uint64           `xml:"MaxKeys"`
+	Delimiter             string           `xml:"Delimiter,omitempty"`
+	EncodingType          string           `xml:"EncodingType,omitempty"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	Contents              []s3Object       `xml:"Contents"`
+	CommonPrefixes        []s3CommonPrefix `xml:"CommonPrefixes"`
+}
+
+// newS3RequestContext returns an instance of RequestContext, and verifies the AWS
+// Signature Version 4 of the request instead of the greenfield signature.
+func (g *GateModular) newS3RequestContext(r *http.Request) (*RequestContext, error) {
+	reqCtx := newRequestContext(r)
+	g.s3Mux.RLock()
+	accessKeys := g.s3AccessKeys
+	g.s3Mux.RUnlock()
+	account, err := verifyS3SignV4(r, accessKeys, g.s3Region, time.Now())
+	if err != nil {
+		return reqCtx, err
+	}
+	reqCtx.account = account
+	return reqCtx, nil
+}
+
+// writeS3XMLResponse writes the xml response of the S3 api.
+func writeS3XMLResponse(w http.ResponseWriter, response interface{}) error {
+	body, err := xml.Marshal(response)
+	if err != nil {
+		return ErrEncodeResponse
+	}
+	w.Header().Set(model.ContentTypeHeader, model.ContentTypeXMLHeaderValue)
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+	return nil
+}
+
+// s3NotAllowedHandler handles the S3 requests that are not supported by the read api.
+func (g *GateModular) s3NotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	log.Errorw("failed to find the s3 handler", "method", r.Method, "host", r.Host, "url", r.URL)
+	MakeS3ErrorResponse(w, r, S3ErrMethodNotAllowed)
+}
+
+// s3ListBucketsHandler handles the S3 ListBuckets request, it lists the buckets of
+// the account which are served by this SP.
+func (g *GateModular) s3ListBucketsHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err    error
+		reqCtx *RequestContext
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			s3Err := makeS3Error(err, false)
+			reqCtx.SetError(s3Err)
+			reqCtx.SetHttpCode(s3Err.HttpStatusCode)
+			MakeS3ErrorResponse(w, r, s3Err)
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = g.newS3RequestContext(r)
+	if err != nil {
+		return
+	}
+	if reqCtx.Account() == "" {
+		err = S3ErrAccessDenied.withMessage("Anonymous users can not list buckets.")
+		return
+	}
+	buckets, err := g.baseApp.GfSpClient().GetUserBuckets(reqCtx.Context(), reqCtx.Account())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get user buckets", "error", err)
+		return
+	}
+	result := &s3ListAllMyBucketsResult{
+		Xmlns:   S3XMLNamespace,
+		Owner:   s3Owner{ID: reqCtx.Account(), DisplayName: reqCtx.Account()},
+		Buckets: make([]s3Bucket, 0, len(buckets)),
+	}
+	for _, bucket := range buckets {
+		if bucket.GetRemoved() || bucket.GetBucketInfo().GetPrimarySpAddress() != g.baseApp.OperateAddress() {
+			continue
+		}
+		result.Buckets = append(result.Buckets, s3Bucket{
+			Name:         bucket.GetBucketInfo().GetBucketName(),
+			CreationDate: time.Unix(bucket.GetBucketInfo().GetCreateAt(), 0).UTC().Format(S3TimeFormat),
+		})
+	}
+	err = writeS3XMLResponse(w, result)
+}
+
+// s3HeadBucketHandler handles the S3 HeadBucket request, the bucket exists and can be
+// listed by the account.
+func (g *GateModular) s3HeadBucketHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		reqCtx     *RequestContext
+		authorized bool
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			s3Err := makeS3Error(err, false)
+			reqCtx.SetError(s3Err)
+			reqCtx.SetHttpCode(s3Err.HttpStatusCode)
+			MakeS3ErrorResponse(w, r, s3Err)
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = g.newS3RequestContext(r)
+	if err != nil {
+		return
+	}
+	if err = s3util.CheckValidBucketName(reqCtx.bucketName); err != nil {
+		err = S3ErrInvalidBucketName
+		return
+	}
+	authorized, err = g.baseApp.GfSpClient().VerifyAuthorize(reqCtx.Context(),
+		coremodule.AuthOpTypeListObjects, reqCtx.Account(), reqCtx.bucketName, "")
+	if err != nil || !authorized {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify authorize", "authorized", authorized, "error", err)
+		err = makeS3AuthorizeError(err, false)
+		return
+	}
+}
+
+// s3ListObjectsV2Handler handles the S3 ListObjectsV2 request.
+func (g *GateModular) s3ListObjectsV2Handler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err               error
+		reqCtx            *RequestContext
+		authorized        bool
+		maxKeys           uint64
+		continuationToken string
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			s3Err := makeS3Error(err, false)
+			reqCtx.SetError(s3Err)
+			reqCtx.SetHttpCode(s3Err.HttpStatusCode)
+			MakeS3ErrorResponse(w, r, s3Err)
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = g.newS3RequestContext(r)
+	if err != nil {
+		return
+	}
+	queryParams := r.URL.Query()
+	if queryParams.Get(model.S3ListTypeQuery) != "2" {
+		err = S3ErrNotImplemented.withMessage("Only ListObjectsV2 is supported, list-type=2 is required.")
+		return
+	}
+	if err = s3util.CheckValidBucketName(reqCtx.bucketName); err != nil {
+		err = S3ErrInvalidBucketName
+		return
+	}
+	requestStartAfter := queryParams.Get(model.ListObjectsStartAfterQuery)
+	requestContinuationToken := queryParams.Get(model.ListObjectsContinuationTokenQuery)
+	requestDelimiter := queryParams.Get(model.ListObjectsDelimiterQuery)
+	requestPrefix := queryParams.Get(model.ListObjectsPrefixQuery)
+	encodingType := queryParams.Get(model.S3EncodingTypeQuery)
+	if requestDelimiter != "" && requestDelimiter != "/" {
+		err = S3ErrInvalidArgument.withMessage("Only the delimiter / is supported.")
+		return
+	}
+	if encodingType != "" && encodingType != S3EncodingTypeURL {
+		err = S3ErrInvalidArgument.withMessage("Invalid Encoding Method specified in Request.")
+		return
+	}
+	if requestMaxKeys := queryParams.Get(model.ListObjectsMaxKeysQuery); requestMaxKeys != "" {
+		if maxKeys, err = util.StringToUint64(requestMaxKeys); err != nil {
+			err = S3ErrInvalidArgument.withMessage("Provided max-keys not an integer or within integer range.")
+			return
+		}
+	}
+	if requestStartAfter != "" && s3util.CheckValidObjectName(requestStartAfter) != nil {
+		err = S3ErrInvalidArgument.withMessage("Invalid start-after.")
+		return
+	}
+	if !checkValidObjectPrefix(requestPrefix) {
+		err = S3ErrInvalidArgument.withMessage("Invalid prefix.")
+		return
+	}
+	if requestContinuationToken != "" {
+		decodedContinuationToken, decodeErr := base64.StdEncoding.DecodeString(requestContinuationToken)
+		if decodeErr != nil || s3util.CheckValidObjectName(string(decodedContinuationToken)) != nil ||
+			!strings.HasPrefix(string(decodedContinuationToken), requestPrefix) {
+			err = S3ErrInvalidArgument.withMessage("The continuation token provided is incorrect.")
+			return
+		}
+		continuationToken = string(decodedContinuationToken)
+	} else {
+		continuationToken = requestStartAfter
+	}
+
+	authorized, err = g.baseApp.GfSpClient().VerifyAuthorize(reqCtx.Context(),
+		coremodule.AuthOpTypeListObjects, reqCtx.Account(), reqCtx.bucketName, "")
+	if err != nil || !authorized {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify authorize", "authorized", authorized, "error", err)
+		err = makeS3AuthorizeError(err, false)
+		return
+	}
+
+	objects, _, maxKeys, isTruncated, nextContinuationToken, _, _, _, commonPrefixes, _, err :=
+		g.baseApp.GfSpClient().ListObjectsByBucketName(reqCtx.Context(), reqCtx.bucketName, "", maxKeys,
+			requestStartAfter, continuationToken, requestDelimiter, requestPrefix)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to list objects by bucket name", "error", err)
+		return
+	}
+
+	encode := func(s string) string {
+		if encodingType == S3EncodingTypeURL {
+			return s3URIEncode(s, false)
+		}
+		return s
+	}
+	result := &s3ListBucketResult{
+		Xmlns:             S3XMLNamespace,
+		Name:              reqCtx.bucketName,
+		Prefix:            encode(requestPrefix),
+		StartAfter:        encode(requestStartAfter),
+		ContinuationToken: requestContinuationToken,
+		MaxKeys:           maxKeys,
+		Delimiter:         encode(requestDelimiter),
+		EncodingType:      encodingType,
+		IsTruncated:       isTruncated,
+	}
+	if isTruncated {
+		result.NextContinuationToken = nextContinuationToken
+	}
+	for _, object := range objects {
+		objectInfo := object.GetObjectInfo()
+		if object.GetRemoved() || objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_SEALED {
+			continue
+		}
+		result.Contents = append(result.Contents, s3Object{
+			Key:          encode(objectInfo.GetObjectName()),
+			LastModified: objectLastModified(object, objectInfo).Format(S3TimeFormat),
+			ETag:         objectETag(objectInfo),
+			Size:         objectInfo.GetPayloadSize(),
+			StorageClass: S3StorageClass,
+		})
+	}
+	for _, prefix := range commonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, s3CommonPrefix{Prefix: encode(prefix)})
+	}
+	result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+	err = writeS3XMLResponse(w, result)
+}
+
+// s3GetObjectHandler handles the S3 GetObject and HeadObject requests, S3 does not
+// support multiple ranges, the whole object is served if multiple ranges are requested.
+func (g *GateModular) s3GetObjectHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		reqCtx     *RequestContext
+		authorized bool
+		objectInfo *storagetypes.ObjectInfo
+		bucketInfo *storagetypes.BucketInfo
+		params     *storagetypes.Params
+	)
+	defer func() {
+		reqCtx.Cancel()
+		if err != nil {
+			s3Err := makeS3Error(err, true)
+			reqCtx.SetError(s3Err)
+			reqCtx.SetHttpCode(s3Err.HttpStatusCode)
+			MakeS3ErrorResponse(w, r, s3Err)
+		} else {
+			reqCtx.SetHttpCode(http.StatusOK)
+		}
+		log.CtxDebugw(reqCtx.Context(), reqCtx.String())
+	}()
+
+	reqCtx, err = g.newS3RequestContext(r)
+	if err != nil {
+		return
+	}
+	if err = s3util.CheckValidBucketName(reqCtx.bucketName); err != nil {
+		err = S3ErrInvalidBucketName
+		return
+	}
+	if err = s3util.CheckValidObjectName(reqCtx.objectName); err != nil {
+		err = S3ErrNoSuchKey
+		return
+	}
+	authorized, err = g.baseApp.GfSpClient().VerifyAuthorize(reqCtx.Context(),
+		coremodule.AuthOpTypeGetObject, reqCtx.Account(), reqCtx.bucketName, reqCtx.objectName)
+	if err != nil || !authorized {
+		log.CtxErrorw(reqCtx.Context(), "failed to verify authorize", "authorized", authorized, "error", err)
+		err = makeS3AuthorizeError(err, true)
+		return
+	}
+
+	bucketInfo, objectInfo, err = g.baseApp.Consensus().QueryBucketInfoAndObjectInfo(reqCtx.Context(),
+		reqCtx.bucketName, reqCtx.objectName)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get bucket and object info from consensus", "error", err)
+		err = ErrConsensus
+		return
+	}
+	params, err = g.baseApp.Consensus().QueryStorageParams(reqCtx.Context())
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to get storage params from consensus", "error", err)
+		err = ErrConsensus
+		return
+	}
+
+	// the create time on chain is used as last modified time if failed to query metadata
+	objectMeta, _ := g.baseApp.GfSpClient().GetObjectMeta(reqCtx.Context(), objectInfo.GetObjectName(),
+		objectInfo.GetBucketName(), false)
+	lastModified := objectLastModified(objectMeta, objectInfo)
+	statusCode, ignoreRange := checkPreconditions(r.Header, objectETag(objectInfo), lastModified)
+	if statusCode == http.StatusPreconditionFailed {
+		err = S3ErrPreconditionFailed
+		return
+	}
+	setObjectHeaders(w, objectInfo, bucketInfo, lastModified)
+	if statusCode == http.StatusNotModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	payloadSize := int64(objectInfo.GetPayloadSize())
+	if r.Method == http.MethodHead || payloadSize == 0 {
+		w.Header().Set(model.ContentLengthHeader, util.Uint64ToString(uint64(payloadSize)))
+		return
+	}
+
+	isRange, ranges, err := parseRanges(r.Header.Get(model.RangeHeader), payloadSize)
+	if err != nil {
+		w.Header().Set(model.ContentRangeHeader, fmt.Sprintf("bytes */%d", payloadSize))
+		err = S3ErrInvalidRange
+		return
+	}
+	if !isRange || ignoreRange || len(ranges) > 1 {
+		isRange = false
+		ranges = []coretask.TRange{{Low: 0, High: payloadSize - 1}}
+	}
+
+	task := g.newDownloadObjectTask(objectInfo, bucketInfo, params, reqCtx.Account(), ranges)
+	data, err := g.baseApp.GfSpClient().GetObject(reqCtx.Context(), task)
+	if err != nil {
+		log.CtxErrorw(reqCtx.Context(), "failed to download object", "error", err)
+		return
+	}
+	w.Header().Set(model.ContentLengthHeader, util.Uint64ToString(uint64(len(data))))
+	if isRange {
+		w.Header().Set(model.ContentRangeHeader, fmt.Sprintf("bytes %d-%d/%d",
+			ranges[0].Low, ranges[0].High, payloadSize))
+		w.WriteHeader(http.StatusPartialContent)
+	}
+	w.Write(data)
+	log.CtxDebugw(reqCtx.Context(), "succeed to download object by s3 api")
+}
@@ -0,0 +1,296 @@
+package gater
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+)
+
+const (
+	// S3SignV4Algorithm is the only supported sign algorithm of the S3 compatible api.
+	S3SignV4Algorithm = "AWS4-HMAC-SHA256"
+	// S3SignV4Terminator is the last part of the credential scope.
+	S3SignV4Terminator = "aws4_request"
+	// S3ServiceName is the service part of the credential scope.
+	S3ServiceName = "s3"
+	// S3UnsignedPayload indicates the payload is not signed, it is used by the presigned url.
+	S3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// S3EmptyPayloadHash is the hex encoded sha256 of the empty payload.
+	S3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// S3SignTimeFormat is the format of X-Amz-Date.
+	S3SignTimeFormat = "20060102T150405Z"
+	// S3SignDateFormat is the format of the date in the credential scope.
+	S3SignDateFormat = "20060102"
+	// S3MaxClockSkew defines the max difference between the signing time of the request
+	// and the server time.
+	S3MaxClockSkew = 15 * time.Minute
+	// S3MaxPresignExpiry defines the max valid duration of the presigned url.
+	S3MaxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// s3SignV4 is the parsed AWS Signature Version 4 of the request, it is carried in the
+// Authorization header or in the query of the presigned url.
+type s3SignV4 struct {
+	accessKeyID   string
+	scopeDate     string
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+	signTime      time.Time
+	expires       time.Duration
+	presigned     bool
+}
+
+// scope returns the credential scope, e.g. 20130524/us-east-1/s3/aws4_request.
+func (s *s3SignV4) scope() string {
+	return strings.Join([]string{s.scopeDate, s.region, s.service, S3SignV4Terminator}, "/")
+}
+
+// isS3SignedRequest returns whether the request carries the AWS signature, the request
+// without signature is served as the anonymous request.
+func isS3SignedRequest(r *http.Request) bool {
+	return r.Header.Get(model.GnfdAuthorizationHeader) != "" || r.URL.Query().Get(model.AmzSignatureQuery) != ""
+}
+
+// verifyS3SignV4 verifies the AWS Signature Version 4 of the request, and returns the
+// greenfield account that the access key is mapped to. The anonymous request returns
+// the empty account, it is only allowed to access the public resources.
+func verifyS3SignV4(r *http.Request, accessKeys map[string]gfspconfig.S3AccessKeyConfig, region string,
+	now time.Time) (string, error) {
+	if !isS3SignedRequest(r) {
+		return "", nil
+	}
+	var (
+		sign *s3SignV4
+		err  error
+	)
+	if r.URL.Query().Get(model.AmzSignatureQuery) != "" {
+		sign, err = parseS3PresignedQuery(r)
+	} else {
+		sign, err = parseS3AuthorizationHeader(r)
+	}
+	if err != nil {
+		return "", err
+	}
+	if sign.service != S3ServiceName || (region != "" && sign.region != region) ||
+		sign.scopeDate != sign.signTime.Format(S3SignDateFormat) {
+		return "", S3ErrAuthorizationHeaderMalformed
+	}
+	if sign.presigned {
+		if now.Before(sign.signTime.Add(-S3MaxClockSkew)) {
+			return "", S3ErrRequestNotReadyYet
+		}
+		if now.After(sign.signTime.Add(sign.expires)) {
+			return "", S3ErrExpiredPresignRequest
+		}
+	} else if now.Sub(sign.signTime) > S3MaxClockSkew || sign.signTime.Sub(now) > S3MaxClockSkew {
+		return "", S3ErrRequestTimeTooSkewed
+	}
+	accessKey, ok := accessKeys[sign.accessKeyID]
+	if !ok {
+		return "", S3ErrInvalidAccessKeyID
+	}
+
+	payloadHash := r.Header.Get(model.AmzContentSha256Header)
+	if sign.presigned {
+		payloadHash = S3UnsignedPayload
+	} else if payloadHash == "" {
+		payloadHash = S3EmptyPayloadHash
+	}
+	canonicalRequest := s3CanonicalRequest(r, sign.signedHeaders, payloadHash)
+	stringToSign := strings.Join([]string{S3SignV4Algorithm, sign.signTime.Format(S3SignTimeFormat),
+		sign.scope(), s3Sha256Hex([]byte(canonicalRequest))}, "\n")
+	signingKey := s3SigningKey(accessKey.SecretAccessKey, sign.scopeDate, sign.region, sign.service)
+	signature := hex.EncodeToString(s3HmacSha256(signingKey, []byte(stringToSign)))
+	if !hmac.Equal([]byte(signature), []byte(sign.signature)) {
+		return "", S3ErrSignatureDoesNotMatch
+	}
+	return accessKey.Account, nil
+}
+
+// parseS3AuthorizationHeader parses the Authorization header, e.g.
+// AWS4-HMAC-SHA256 Credential=AKID/20130524/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=...
+func parseS3AuthorizationHeader(r *http.Request) (*s3SignV4, error) {
+	auth := r.Header.Get(model.GnfdAuthorizationHeader)
+	if !strings.HasPrefix(auth, S3SignV4Algorithm+" ") {
+		return nil, S3ErrSignatureVersionNotSupported
+	}
+	sign := &s3SignV4{}
+	fields := make(map[string]string)
+	for _, field := range strings.Split(strings.TrimPrefix(auth, S3SignV4Algorithm), ",") {
+		pair := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(pair) != 2 {
+			return nil, S3ErrAuthorizationHeaderMalformed
+		}
+		fields[pair[0]] = pair[1]
+	}
+	if err := sign.parseCredential(fields["Credential"]); err != nil {
+		return nil, S3ErrAuthorizationHeaderMalformed
+	}
+	if fields["SignedHeaders"] == "" || fields["Signature"] == "" {
+		return nil, S3ErrAuthorizationHeaderMalformed
+	}
+	sign.signedHeaders = parseS3SignedHeaders(fields["SignedHeaders"])
+	if !s3SignedHost(sign.signedHeaders) {
+		return nil, S3ErrAuthorizationHeaderMalformed
+	}
+	sign.signature = fields["Signature"]
+	signTime, err := time.Parse(S3SignTimeFormat, r.Header.Get(model.AmzDateHeader))
+	if err != nil {
+		if signTime, err = http.ParseTime(r.Header.Get("Date")); err != nil {
+			return nil, S3ErrAccessDenied
+		}
+	}
+	sign.signTime = signTime.UTC()
+	return sign, nil
+}
+
+// parseS3PresignedQuery parses the signature of the presigned url from the query.
+func parseS3PresignedQuery(r *http.Request) (*s3SignV4, error) {
+	query := r.URL.Query()
+	if query.Get(model.AmzAlgorithmQuery) != S3SignV4Algorithm {
+		return nil, S3ErrSignatureVersionNotSupported
+	}
+	sign := &s3SignV4{presigned: true}
+	if err := sign.parseCredential(query.Get(model.AmzCredentialQuery)); err != nil {
+		return nil, S3ErrAuthorizationQueryParametersError
+	}
+	signTime, err := time.Parse(S3SignTimeFormat, query.Get(model.AmzDateQuery))
+	if err != nil {
+		return nil, S3ErrAuthorizationQueryParametersError
+	}
+	sign.signTime = signTime.UTC()
+	expires, err := strconv.ParseInt(query.Get(model.AmzExpiresQuery), 10, 64)
+	if err != nil || expires < 0 || time.Duration(expires)*time.Second > S3MaxPresignExpiry {
+		return nil, S3ErrAuthorizationQueryParametersError
+	}
+	sign.expires = time.Duration(expires) * time.Second
+	if query.Get(model.AmzSignedHeadersQuery) == "" {
+		return nil, S3ErrAuthorizationQueryParametersError
+	}
+	sign.signedHeaders = parseS3SignedHeaders(query.Get(model.AmzSignedHeadersQuery))
+	if !s3SignedHost(sign.signedHeaders) {
+		return nil, S3ErrAuthorizationQueryParametersError
+	}
+	sign.signature = query.Get(model.AmzSignatureQuery)
+	return sign, nil
+}
+
+// parseS3SignedHeaders returns the lower-cased and sorted names of the signed headers, which
+// are the names used by the canonical request.
+func parseS3SignedHeaders(signedHeaders string) []string {
+	names := strings.Split(strings.ToLower(signedHeaders), ";")
+	sort.Strings(names)
+	return names
+}
+
+// s3SignedHost returns whether the host header is signed, the signature without the host
+// can be replayed to the other buckets of the virtual hosted style.
+func s3SignedHost(signedHeaders []string) bool {
+	for _, name := range signedHeaders {
+		if name == "host" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCredential parses the credential, e.g. AKID/20130524/us-east-1/s3/aws4_request.
+func (s *s3SignV4) parseCredential(credential string) error {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[0] == "" || parts[4] != S3SignV4Terminator {
+		return S3ErrAuthorizationHeaderMalformed
+	}
+	s.accessKeyID, s.scopeDate, s.region, s.service = parts[0], parts[1], parts[2], parts[3]
+	return nil
+}
+
+// s3CanonicalRequest returns the canonical request of AWS Signature Version 4, the
+// signature of the presigned url is excluded from the canonical query. The names of the
+// signed headers are lower-cased and sorted by the parsers.
+func s3CanonicalRequest(r *http.Request, signedHeaders []string, payloadHash string) string {
+	query := r.URL.Query()
+	query.Del(model.AmzSignatureQuery)
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var queryPairs []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			queryPairs = append(queryPairs, s3URIEncode(key, true)+"="+s3URIEncode(value, true))
+		}
+	}
+
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		var value string
+		if name == "host" {
+			value = r.Host
+		} else {
+			values := r.Header.Values(name)
+			for i := range values {
+				values[i] = strings.Join(strings.Fields(values[i]), " ")
+			}
+			value = strings.Join(values, ",")
+		}
+		headers.WriteString(name + ":" + value + "\n")
+	}
+
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	return strings.Join([]string{r.Method, s3URIEncode(path, false), strings.Join(queryPairs, "&"),
+		headers.String(), strings.Join(signedHeaders, ";"), payloadHash}, "\n")
+}
+
+// s3URIEncode encodes the string as the URI encoding of AWS Signature Version 4, the
+// unreserved characters are kept, and the slash is kept unless encodeSlash is true.
+func s3URIEncode(s string, encodeSlash bool) string {
+	const hexChars = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(hexChars[c>>4])
+		sb.WriteByte(hexChars[c&0x0f])
+	}
+	return sb.String()
+}
+
+// s3SigningKey derives the signing key from the secret access key and the credential scope.
+func s3SigningKey(secret, date, region, service string) []byte {
+	key := s3HmacSha256([]byte("AWS4"+secret), []byte(date))
+	key = s3HmacSha256(key, []byte(region))
+	key = s3HmacSha256(key, []byte(service))
+	return s3HmacSha256(key, []byte(S3SignV4Terminator))
+}
+
+func s3HmacSha256(key []byte, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func s3Sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
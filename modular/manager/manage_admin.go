@@ -0,0 +1,132 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/core/module"
+	"github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+const (
+	// CanceledTaskExpiredTime defines the seconds of keeping the canceled task key,
+	// the report of canceled task after expired is handled as normal.
+	CanceledTaskExpiredTime int64 = 60 * 60
+)
+
+var (
+	ErrUnsupportedPauseTask = gfsperrors.Register(module.ManageModularName, http.StatusBadRequest, 60006, "unsupported task type to pause")
+	ErrNoSuchTask           = gfsperrors.Register(module.ManageModularName, http.StatusNotFound, 60007, "no such task")
+)
+
+// dispatchTaskTypes defines the task types that manager dispatches to executor,
+// the dispatching of them can be paused.
+var dispatchTaskTypes = map[task.TType]bool{
+	task.TypeTaskReplicatePiece: true,
+	task.TypeTaskSealObject:     true,
+	task.TypeTaskReceivePiece:   true,
+	task.TypeTaskGCObject:       true,
+	task.TypeTaskGCZombiePiece:  true,
+	task.TypeTaskGCMeta:         true,
+	task.TypeTaskMigrateBucket:  true,
+	task.TypeTaskSPExit:         true,
+	task.TypeTaskRepairPiece:    true,
+}
+
+func (m *ManageModular) PauseDispatchTask(
+	ctx context.Context,
+	taskType task.TType,
+	pause bool) error {
+	if !dispatchTaskTypes[taskType] {
+		log.CtxErrorw(ctx, "failed to pause task", "task_type", task.TaskTypeName(taskType))
+		return ErrUnsupportedPauseTask
+	}
+	m.adminMux.Lock()
+	defer m.adminMux.Unlock()
+	if pause {
+		m.pausedTaskTypes[taskType] = true
+	} else {
+		delete(m.pausedTaskTypes, taskType)
+	}
+	log.CtxInfow(ctx, "succeed to set task dispatching", "task_type", task.TaskTypeName(taskType), "pause", pause)
+	return nil
+}
+
+func (m *ManageModular) QueryPausedTaskTypes(ctx context.Context) ([]task.TType, error) {
+	m.adminMux.RLock()
+	defer m.adminMux.RUnlock()
+	var taskTypes []task.TType
+	for taskType := range m.pausedTaskTypes {
+		taskTypes = append(taskTypes, taskType)
+	}
+	return taskTypes, nil
+}
+
+// filterPausedTasks removes the tasks that the dispatching of task type is paused.
+func (m *ManageModular) filterPausedTasks(tasks []task.Task) []task.Task {
+	var dispatchTasks []task.Task
+	for _, t := range tasks {
+		if !m.TaskPaused(t.Type()) {
+			dispatchTasks = append(dispatchTasks, t)
+		}
+	}
+	return dispatchTasks
+}
+
+// TaskPaused returns whether the dispatching of the task type is paused.
+func (m *ManageModular) TaskPaused(taskType task.TType) bool {
+	m.adminMux.RLock()
+	defer m.adminMux.RUnlock()
+	return m.pausedTaskTypes[taskType]
+}
+
+func (m *ManageModular) CancelTask(
+	ctx context.Context,
+	key task.TKey) error {
+	var canceled task.Task
+	for _, pop := range []func(task.TKey) task.Task{
+		m.uploadQueue.PopByKey,
+		m.replicateQueue.PopByKey,
+		m.sealQueue.PopByKey,
+		m.receiveQueue.PopByKey,
+		m.gcObjectQueue.PopByKey,
+		m.gcZombieQueue.PopByKey,
+		m.gcMetaQueue.PopByKey,
+		m.migrateBucketQueue.PopByKey,
+		m.spExitQueue.PopByKey,
+		m.repairPieceQueue.PopByKey,
+		m.downloadQueue.PopByKey,
+		m.challengeQueue.PopByKey,
+	} {
+		if t := pop(key); t != nil {
+			canceled = t
+		}
+	}
+	if canceled == nil {
+		log.CtxErrorw(ctx, "failed to cancel task, no such task", "task_key", key)
+		return ErrNoSuchTask
+	}
+
+	m.adminMux.Lock()
+	defer m.adminMux.Unlock()
+	now := time.Now().Unix()
+	for canceledKey, canceledTime := range m.canceledTasks {
+		if canceledTime+CanceledTaskExpiredTime < now {
+			delete(m.canceledTasks, canceledKey)
+		}
+	}
+	m.canceledTasks[key] = now
+	log.CtxInfow(ctx, "succeed to cancel task", "info", canceled.Info())
+	return nil
+}
+
+// TaskCanceled returns whether the task has been canceled by admin.
+func (m *ManageModular) TaskCanceled(key task.TKey) bool {
+	m.adminMux.RLock()
+	defer m.adminMux.RUnlock()
+	canceledTime, ok := m.canceledTasks[key]
+	return ok && canceledTime+CanceledTaskExpiredTime >= time.Now().Unix()
+}
@@ -0,0 +1,78 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfsptqueue"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	"github.com/bnb-chain/greenfield-storage-provider/core/task"
+)
+
+func newTestManageModular() *ManageModular {
+	return &ManageModular{
+		uploadQueue:        gfsptqueue.NewGfSpTQueue("upload", 10),
+		replicateQueue:     gfsptqueue.NewGfSpTQueueWithLimit("replicate", 10),
+		sealQueue:          gfsptqueue.NewGfSpTQueueWithLimit("seal", 10),
+		receiveQueue:       gfsptqueue.NewGfSpTQueueWithLimit("receive", 10),
+		gcObjectQueue:      gfsptqueue.NewGfSpTQueueWithLimit("gc_object", 10),
+		gcZombieQueue:      gfsptqueue.NewGfSpTQueueWithLimit("gc_zombie", 10),
+		gcMetaQueue:        gfsptqueue.NewGfSpTQueueWithLimit("gc_meta", 10),
+		migrateBucketQueue: gfsptqueue.NewGfSpTQueueWithLimit("migrate_bucket", 10),
+		spExitQueue:        gfsptqueue.NewGfSpTQueueWithLimit("sp_exit", 10),
+		repairPieceQueue:   gfsptqueue.NewGfSpTQueueWithLimit("repair_piece", 10),
+		downloadQueue:      gfsptqueue.NewGfSpTQueue("download", 10),
+		challengeQueue:     gfsptqueue.NewGfSpTQueue("challenge", 10),
+		pausedTaskTypes:    make(map[task.TType]bool),
+		canceledTasks:      make(map[task.TKey]int64),
+	}
+}
+
+func testObjectInfo(id uint64) *storagetypes.ObjectInfo {
+	return &storagetypes.ObjectInfo{BucketName: "bucket", ObjectName: "object", Id: sdkmath.NewUint(id)}
+}
+
+func TestManageModularPauseDispatchTask(t *testing.T) {
+	m := newTestManageModular()
+	ctx := context.Background()
+	assert.Equal(t, ErrUnsupportedPauseTask, m.PauseDispatchTask(ctx, task.TypeTaskUpload, true))
+
+	replicateTask := &gfsptask.GfSpReplicatePieceTask{}
+	replicateTask.InitReplicatePieceTask(testObjectInfo(1), &storagetypes.Params{}, 0, 0, 0)
+	sealTask := &gfsptask.GfSpSealObjectTask{}
+	sealTask.InitSealObjectTask(testObjectInfo(2), &storagetypes.Params{}, 0, nil, 0, 0)
+
+	require.NoError(t, m.PauseDispatchTask(ctx, task.TypeTaskReplicatePiece, true))
+	pausedTypes, err := m.QueryPausedTaskTypes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []task.TType{task.TypeTaskReplicatePiece}, pausedTypes)
+	assert.Equal(t, []task.Task{sealTask}, m.filterPausedTasks([]task.Task{replicateTask, sealTask}))
+
+	require.NoError(t, m.PauseDispatchTask(ctx, task.TypeTaskReplicatePiece, false))
+	assert.False(t, m.TaskPaused(task.TypeTaskReplicatePiece))
+	assert.Len(t, m.filterPausedTasks([]task.Task{replicateTask, sealTask}), 2)
+}
+
+func TestManageModularCancelTask(t *testing.T) {
+	m := newTestManageModular()
+	ctx := context.Background()
+	replicateTask := &gfsptask.GfSpReplicatePieceTask{}
+	replicateTask.InitReplicatePieceTask(testObjectInfo(1), &storagetypes.Params{}, 0, 0, 0)
+	require.NoError(t, m.replicateQueue.Push(replicateTask))
+
+	assert.Equal(t, ErrNoSuchTask, m.CancelTask(ctx, "no-such-task"))
+	require.NoError(t, m.CancelTask(ctx, replicateTask.Key()))
+	assert.False(t, m.replicateQueue.Has(replicateTask.Key()))
+	assert.True(t, m.TaskCanceled(replicateTask.Key()))
+	// the later report of the canceled task is rejected
+	assert.Equal(t, ErrCanceledTask, m.HandleReplicatePieceTask(ctx, replicateTask))
+
+	// the canceled task key expires
+	m.canceledTasks[replicateTask.Key()] -= CanceledTaskExpiredTime + 1
+	assert.False(t, m.TaskCanceled(replicateTask.Key()))
+}
@@ -0,0 +1,330 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-common/go/hash"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	"github.com/bnb-chain/greenfield-storage-provider/core/module"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/downloader"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/metrics"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+var ErrAuditRepairMismatch = gfsperrors.Register(module.ManageModularName, http.StatusNotAcceptable, 60012, "the repaired integrity hash mismatches greenfield")
+
+// AuditRepairHook repairs the finding of the self challenge auditor, the finding is
+// recorded as repaired if the hook returns nil.
+type AuditRepairHook func(ctx context.Context, objectInfo *storagetypes.ObjectInfo, finding *corespdb.AuditFinding) error
+
+// RegisterAuditRepairHook registers the repair hook of the finding type, it overwrites
+// the registered hook and must be called before the manager starts.
+func (m *ManageModular) RegisterAuditRepairHook(findingType string, hook AuditRepairHook) {
+	m.auditRepairHooks[findingType] = hook
+}
+
+// auditLoop samples the sealed objects that this SP stores as primary or secondary in
+// rounds, and challenges the sampled objects against this SP through the downloader as
+// the validators do.
+func (m *ManageModular) auditLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(m.auditInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !m.auditPrimaryObjects(ctx) {
+				continue
+			}
+			m.auditSecondaryObjects(ctx)
+		}
+	}
+}
+
+// auditPrimaryObjects audits the sampled objects of the bucket that this SP is primary,
+// the buckets are scanned in the ascending order of id and the objects in the order of
+// name, a page of objects is scanned in each round. Returns false if the resource is
+// exhausted, the page is scanned again in the next round.
+func (m *ManageModular) auditPrimaryObjects(ctx context.Context) bool {
+	buckets, err := m.baseApp.GfSpClient().ListBucketsByPrimarySp(ctx, m.baseApp.OperateAddress(),
+		m.auditPrimaryBucketCursor, 1)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list buckets by primary sp for audit", "error", err)
+		return true
+	}
+	if len(buckets) == 0 {
+		// start the next pass from the first bucket
+		m.auditPrimaryBucketCursor, m.auditPrimaryObjectToken = 0, ""
+		return true
+	}
+	bucketInfo := buckets[0].GetBucketInfo()
+	objects, _, _, truncated, nextToken, _, _, _, _, _, err := m.baseApp.GfSpClient().ListObjectsByBucketName(ctx,
+		bucketInfo.GetBucketName(), "", uint64(m.auditScanNumber), "", m.auditPrimaryObjectToken, "", "")
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list objects by bucket name for audit", "error", err)
+		return true
+	}
+	for _, object := range objects {
+		if !m.sampleAuditObject(ctx, object.GetObjectInfo(), object.GetRemoved()) {
+			return false
+		}
+	}
+	if !truncated {
+		m.auditPrimaryBucketCursor, m.auditPrimaryObjectToken = bucketInfo.Id.Uint64(), ""
+		return true
+	}
+	// the metadata service encodes the continuation token, which is the name of the
+	// first object in the next page
+	token, err := base64.StdEncoding.DecodeString(nextToken)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to decode continuation token for audit", "error", err)
+		return true
+	}
+	m.auditPrimaryObjectToken = string(token)
+	return true
+}
+
+// auditSecondaryObjects audits the sampled objects that this SP is secondary, the objects
+// are scanned in the ascending order of id.
+func (m *ManageModular) auditSecondaryObjects(ctx context.Context) {
+	objects, err := m.baseApp.GfSpClient().ListObjectsBySecondarySp(ctx, m.baseApp.OperateAddress(),
+		m.auditSecondaryCursor, m.auditScanNumber)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list objects by secondary sp for audit", "error", err)
+		return
+	}
+	if len(objects) == 0 {
+		// start the next pass from the first object
+		m.auditSecondaryCursor = 0
+		return
+	}
+	for _, object := range objects {
+		if !m.sampleAuditObject(ctx, object.GetObjectInfo(), object.GetRemoved()) {
+			return
+		}
+		m.auditSecondaryCursor = object.GetObjectInfo().Id.Uint64()
+	}
+}
+
+// sampleAuditObject audits the object by the sample rate, returns false if the resource
+// is exhausted, the object is scanned again in the next round.
+func (m *ManageModular) sampleAuditObject(
+	ctx context.Context,
+	object *storagetypes.ObjectInfo,
+	removed bool) bool {
+	if removed || object.GetObjectStatus() != storagetypes.OBJECT_STATUS_SEALED ||
+		m.auditRand.Float64() >= m.auditSampleRate {
+		return true
+	}
+	objectInfo, err := m.baseApp.Consensus().QueryObjectInfoByID(ctx, object.Id.String())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get object info from consensus for audit", "error", err)
+		return true
+	}
+	bucketInfo, err := m.baseApp.Consensus().QueryBucketInfo(ctx, objectInfo.GetBucketName())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get bucket info from consensus for audit", "error", err)
+		return true
+	}
+	rIdx := int32(-1)
+	if !strings.EqualFold(bucketInfo.GetPrimarySpAddress(), m.baseApp.OperateAddress()) {
+		if rIdx = secondaryIdx(objectInfo, m.baseApp.OperateAddress()); rIdx < 0 {
+			return true
+		}
+	}
+	if objectInfo.GetObjectStatus() != storagetypes.OBJECT_STATUS_SEALED ||
+		int(rIdx+1) >= len(objectInfo.GetChecksums()) {
+		return true
+	}
+	return m.auditObject(ctx, objectInfo, bucketInfo, rIdx)
+}
+
+// auditObject challenges a random segment of the object against this SP, and records
+// the finding. Returns false if the resource is exhausted.
+func (m *ManageModular) auditObject(
+	ctx context.Context,
+	objectInfo *storagetypes.ObjectInfo,
+	bucketInfo *storagetypes.BucketInfo,
+	rIdx int32) bool {
+	params, err := m.baseApp.QueryStorageParamsByTimestamp(ctx, objectInfo.GetCreateAt())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get storage params for audit", "error", err)
+		return true
+	}
+	maxSegmentSize := params.VersionedParams.GetMaxSegmentSize()
+	segmentCount := m.baseApp.PieceOp().SegmentCount(objectInfo.GetPayloadSize(), maxSegmentSize)
+	if segmentCount == 0 {
+		return true
+	}
+	segmentIdx := uint32(m.auditRand.Intn(int(segmentCount)))
+	// the secondary sp of the replica object stores the segments
+	challengeIdx := rIdx
+	if objectInfo.GetRedundancyType() != storagetypes.REDUNDANCY_EC_TYPE {
+		challengeIdx = -1
+	}
+	pieceSize := m.baseApp.PieceOp().SegmentSize(objectInfo.GetPayloadSize(), segmentIdx, maxSegmentSize)
+	task := &gfsptask.GfSpChallengePieceTask{}
+	task.InitChallengePieceTask(objectInfo, bucketInfo, m.baseApp.TaskPriority(task), m.baseApp.OperateAddress(),
+		challengeIdx, segmentIdx, m.baseApp.TaskTimeout(task, uint64(pieceSize)), m.baseApp.TaskMaxRetry(task))
+	span, err := m.ReserveResource(ctx, task.EstimateLimit().ScopeStat())
+	if err != nil {
+		log.CtxDebugw(ctx, "stop to audit, resource exhausted", "error", err)
+		return false
+	}
+	defer m.ReleaseResource(ctx, span)
+
+	metrics.AuditObjectCounter.WithLabelValues(m.Name()).Inc()
+	var findingType, detail string
+	integrity, checksums, data, err := m.baseApp.GfSpClient().GetChallengeInfo(ctx, task)
+	if err != nil {
+		if findingType = auditChallengeError(err); findingType == "" {
+			log.CtxErrorw(ctx, "failed to challenge piece for audit", "error", err)
+			return true
+		}
+		detail = err.Error()
+	} else {
+		findingType, detail = auditChallengeInfo(objectInfo.GetChecksums()[rIdx+1], segmentIdx,
+			integrity, checksums, data)
+	}
+	if findingType == "" {
+		log.CtxDebugw(ctx, "succeed to audit object", "object_id", objectInfo.Id.Uint64(),
+			"redundancy_idx", rIdx, "segment_idx", segmentIdx)
+		return true
+	}
+	m.recordAuditFinding(ctx, objectInfo, &corespdb.AuditFinding{
+		ObjectID:      objectInfo.Id.Uint64(),
+		BucketName:    objectInfo.GetBucketName(),
+		ObjectName:    objectInfo.GetObjectName(),
+		RedundancyIdx: rIdx,
+		SegmentIdx:    segmentIdx,
+		FindingType:   findingType,
+		Detail:        detail,
+	})
+	return true
+}
+
+// recordAuditFinding repairs the finding by the registered hook if auto repair is
+// enabled, and records the finding to SPDB and metrics.
+func (m *ManageModular) recordAuditFinding(
+	ctx context.Context,
+	objectInfo *storagetypes.ObjectInfo,
+	finding *corespdb.AuditFinding) {
+	log.CtxErrorw(ctx, "audit finds problem", "object_id", finding.ObjectID, "redundancy_idx",
+		finding.RedundancyIdx, "segment_idx", finding.SegmentIdx, "finding_type", finding.FindingType,
+		"detail", finding.Detail)
+	metrics.AuditFindingCounter.WithLabelValues(finding.FindingType).Inc()
+	if hook, ok := m.auditRepairHooks[finding.FindingType]; ok && m.auditAutoRepair {
+		if err := hook(ctx, objectInfo, finding); err != nil {
+			log.CtxErrorw(ctx, "failed to repair audit finding", "object_id", finding.ObjectID,
+				"finding_type", finding.FindingType, "error", err)
+		} else {
+			finding.Repaired = true
+			metrics.AuditRepairedCounter.WithLabelValues(finding.FindingType).Inc()
+		}
+	}
+	finding.UpdateTime = time.Now().Unix()
+	if err := m.baseApp.GfSpDB().SetAuditFinding(finding); err != nil {
+		log.CtxErrorw(ctx, "failed to set audit finding", "error", err)
+	}
+}
+
+// repairAuditIntegrity rebuilds the integrity meta from the pieces in piece store, the
+// integrity meta is overwritten only if the rebuilt integrity hash matches greenfield.
+func (m *ManageModular) repairAuditIntegrity(
+	ctx context.Context,
+	objectInfo *storagetypes.ObjectInfo,
+	finding *corespdb.AuditFinding) error {
+	params, err := m.baseApp.QueryStorageParamsByTimestamp(ctx, objectInfo.GetCreateAt())
+	if err != nil {
+		return err
+	}
+	segmentCount := m.baseApp.PieceOp().SegmentCount(objectInfo.GetPayloadSize(),
+		params.VersionedParams.GetMaxSegmentSize())
+	checksums := make([][]byte, 0, segmentCount)
+	for segmentIdx := uint32(0); segmentIdx < segmentCount; segmentIdx++ {
+		var pieceKey string
+		if finding.RedundancyIdx >= 0 && objectInfo.GetRedundancyType() == storagetypes.REDUNDANCY_EC_TYPE {
+			pieceKey = m.baseApp.PieceOp().ECPieceKey(objectInfo.Id.Uint64(), segmentIdx,
+				uint32(finding.RedundancyIdx))
+		} else {
+			pieceKey = m.baseApp.PieceOp().SegmentPieceKey(objectInfo.Id.Uint64(), segmentIdx)
+		}
+		data, err := m.baseApp.PieceStore().GetPiece(ctx, pieceKey, 0, -1)
+		if err != nil {
+			return err
+		}
+		checksums = append(checksums, hash.GenerateChecksum(data))
+	}
+	signature, integrity, err := m.baseApp.GfSpClient().SignIntegrityHash(ctx, objectInfo.Id.Uint64(), checksums)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(integrity, objectInfo.GetChecksums()[finding.RedundancyIdx+1]) {
+		return ErrAuditRepairMismatch
+	}
+	return m.baseApp.GfSpDB().SetObjectIntegrity(&corespdb.IntegrityMeta{
+		ObjectID:          objectInfo.Id.Uint64(),
+		PieceChecksumList: checksums,
+		IntegrityChecksum: integrity,
+		Signature:         signature,
+	})
+}
+
+// auditChallengeError returns the finding type of the challenge error, returns empty if
+// the error is not caused by the stored data.
+func auditChallengeError(err error) string {
+	switch gfsperrors.MakeGfSpError(err).GetInnerCode() {
+	case downloader.ErrPieceStore.GetInnerCode():
+		return corespdb.AuditMissingPiece
+	case downloader.ErrGfSpDB.GetInnerCode():
+		return corespdb.AuditMissingIntegrity
+	default:
+		return ""
+	}
+}
+
+// auditChallengeInfo verifies the challenge info as the validators do, returns the
+// finding type and the detail, the finding type is empty if the challenge passes.
+func auditChallengeInfo(
+	expectIntegrity []byte,
+	segmentIdx uint32,
+	integrity []byte,
+	checksums [][]byte,
+	data []byte) (string, string) {
+	if int(segmentIdx) >= len(checksums) {
+		return corespdb.AuditStaleIntegrity, fmt.Sprintf("%d piece checksums, challenge segment %d",
+			len(checksums), segmentIdx)
+	}
+	if !bytes.Equal(hash.GenerateIntegrityHash(checksums), integrity) {
+		return corespdb.AuditStaleIntegrity, "integrity hash mismatches piece checksums"
+	}
+	if !bytes.Equal(integrity, expectIntegrity) {
+		return corespdb.AuditStaleIntegrity, "integrity hash mismatches greenfield"
+	}
+	if !bytes.Equal(hash.GenerateChecksum(data), checksums[segmentIdx]) {
+		return corespdb.AuditPieceHashMismatch, "piece hash mismatches piece checksum"
+	}
+	return "", ""
+}
+
+// secondaryIdx returns the redundancy idx of the sp in the secondary sps of the object,
+// returns -1 if the sp is not secondary.
+func secondaryIdx(objectInfo *storagetypes.ObjectInfo, spAddress string) int32 {
+	for idx, address := range objectInfo.GetSecondarySpAddresses() {
+		if strings.EqualFold(address, spAddress) {
+			return int32(idx)
+		}
+	}
+	return -1
+}
@@ -0,0 +1,150 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	"github.com/bnb-chain/greenfield-storage-provider/core/module"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+var (
+	ErrMigrateToPrimary      = gfsperrors.Register(module.ManageModularName, http.StatusBadRequest, 60008, "the bucket is already stored in this sp as primary")
+	ErrMigrateBucketDone     = gfsperrors.Register(module.ManageModularName, http.StatusBadRequest, 60009, "the bucket has been migrated")
+	ErrMigrateBucketMismatch = gfsperrors.Register(module.ManageModularName, http.StatusBadRequest, 60010, "the bucket is migrating from another sp")
+)
+
+func (m *ManageModular) HandleCreateMigrateBucketTask(
+	ctx context.Context,
+	migrateTask task.MigrateBucketTask) error {
+	if migrateTask == nil || migrateTask.GetBucketInfo() == nil {
+		log.CtxErrorw(ctx, "failed to handle create migrate bucket, task pointer dangling")
+		return ErrDanglingTask
+	}
+	if strings.EqualFold(migrateTask.GetBucketInfo().GetPrimarySpAddress(), m.baseApp.OperateAddress()) {
+		log.CtxErrorw(ctx, "failed to migrate bucket, the primary sp is self")
+		return ErrMigrateToPrimary
+	}
+	if m.migrateBucketQueue.Has(migrateTask.Key()) {
+		log.CtxErrorw(ctx, "migrate bucket task repeated")
+		return ErrRepeatedTask
+	}
+	progress, err := m.baseApp.GfSpDB().GetMigrateBucketProgress(migrateTask.GetBucketInfo().GetBucketName())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get migrate bucket progress", "error", err)
+		return err
+	}
+	// resume from the persisted progress if the bucket was migrated partly
+	if progress != nil && progress.BucketID == migrateTask.GetBucketInfo().Id.Uint64() {
+		if progress.Finished {
+			log.CtxErrorw(ctx, "the bucket has been migrated", "migrated", progress.MigratedObjectNumber)
+			return ErrMigrateBucketDone
+		}
+		if progress.SrcSpEndpoint != migrateTask.GetSrcSpEndpoint() {
+			log.CtxErrorw(ctx, "the bucket is migrating from another sp", "src", progress.SrcSpEndpoint)
+			return ErrMigrateBucketMismatch
+		}
+		migrateTask.SetLastMigratedObjectName(progress.LastMigratedObjectName)
+		migrateTask.SetMigratedObjectNumber(progress.MigratedObjectNumber)
+		migrateTask.SetOwnerSignature(progress.OwnerSignature)
+		migrateTask.SetApprovalExpiry(progress.ApprovalExpiry)
+	}
+	if err = m.setMigrateBucketProgress(migrateTask); err != nil {
+		log.CtxErrorw(ctx, "failed to set migrate bucket progress", "error", err)
+		return err
+	}
+	if err = m.migrateBucketQueue.Push(migrateTask); err != nil {
+		log.CtxErrorw(ctx, "failed to push migrate bucket task to queue", "error", err)
+		return ErrExceedTask
+	}
+	return nil
+}
+
+func (m *ManageModular) HandleMigrateBucketTask(
+	ctx context.Context,
+	migrateTask task.MigrateBucketTask) error {
+	if migrateTask == nil || migrateTask.GetBucketInfo() == nil {
+		log.CtxErrorw(ctx, "failed to handle migrate bucket, task pointer dangling")
+		return ErrDanglingTask
+	}
+	if !m.migrateBucketQueue.Has(migrateTask.Key()) || m.TaskCanceled(migrateTask.Key()) {
+		return ErrCanceledTask
+	}
+	oldTask := m.migrateBucketQueue.PopByKey(migrateTask.Key())
+	if oldTask == nil {
+		log.CtxErrorw(ctx, "report migrate bucket task is clear", "report_info", migrateTask.Info())
+		return ErrCanceledTask
+	}
+	// the object number only grows, the report behind the queued progress comes
+	// from the stale executor that timed out.
+	if oldTask.(task.MigrateBucketTask).GetMigratedObjectNumber() > migrateTask.GetMigratedObjectNumber() {
+		log.CtxErrorw(ctx, "report migrate bucket task is expired", "report_info", migrateTask.Info(),
+			"current_info", oldTask.Info())
+		m.migrateBucketQueue.Push(oldTask)
+		return ErrCanceledTask
+	}
+	if err := m.setMigrateBucketProgress(migrateTask); err != nil {
+		log.CtxErrorw(ctx, "failed to update migrate bucket progress", "error", err)
+	}
+	if migrateTask.GetFinished() {
+		log.CtxInfow(ctx, "succeed to migrate bucket", "info", migrateTask.Info())
+		return nil
+	}
+	if migrateTask.Error() != nil {
+		log.CtxErrorw(ctx, "handler error migrate bucket task", "error", migrateTask.Error())
+	}
+	migrateTask.SetUpdateTime(time.Now().Unix())
+	m.migrateBucketQueue.Push(migrateTask)
+	return nil
+}
+
+// setMigrateBucketProgress persists the progress of migrating bucket, it is used to
+// resume the migration after the restart of manager.
+func (m *ManageModular) setMigrateBucketProgress(migrateTask task.MigrateBucketTask) error {
+	return m.baseApp.GfSpDB().SetMigrateBucketProgress(&corespdb.MigrateBucketProgress{
+		BucketName:             migrateTask.GetBucketInfo().GetBucketName(),
+		BucketID:               migrateTask.GetBucketInfo().Id.Uint64(),
+		SrcSpEndpoint:          migrateTask.GetSrcSpEndpoint(),
+		LastMigratedObjectName: migrateTask.GetLastMigratedObjectName(),
+		MigratedObjectNumber:   migrateTask.GetMigratedObjectNumber(),
+		Finished:               migrateTask.GetFinished(),
+		UpdateTime:             time.Now().Unix(),
+		OwnerSignature:         migrateTask.GetOwnerSignature(),
+		ApprovalExpiry:         migrateTask.GetApprovalExpiry(),
+	})
+}
+
+// loadMigrateBucketTask resumes the unfinished migrate bucket tasks from SPDB.
+func (m *ManageModular) loadMigrateBucketTask(ctx context.Context) error {
+	progresses, err := m.baseApp.GfSpDB().ListUnfinishedMigrateBucketProgress()
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list unfinished migrate bucket progress", "error", err)
+		return err
+	}
+	for _, progress := range progresses {
+		bucketInfo, err := m.baseApp.Consensus().QueryBucketInfo(ctx, progress.BucketName)
+		if err != nil || bucketInfo.Id.Uint64() != progress.BucketID {
+			log.CtxErrorw(ctx, "failed to resume migrate bucket task, bucket is changed",
+				"bucket_name", progress.BucketName, "error", err)
+			continue
+		}
+		migrateTask := &gfsptask.GfSpMigrateBucketTask{}
+		migrateTask.InitMigrateBucketTask(bucketInfo, progress.SrcSpEndpoint, m.baseApp.OperateAddress(),
+			m.baseApp.TaskPriority(migrateTask), m.baseApp.TaskTimeout(migrateTask, 0),
+			m.baseApp.TaskMaxRetry(migrateTask))
+		migrateTask.SetLastMigratedObjectName(progress.LastMigratedObjectName)
+		migrateTask.SetMigratedObjectNumber(progress.MigratedObjectNumber)
+		if err = m.migrateBucketQueue.Push(migrateTask); err != nil {
+			log.CtxErrorw(ctx, "failed to push migrate bucket task to queue", "error", err)
+			continue
+		}
+		log.CtxInfow(ctx, "succeed to resume migrate bucket task", "info", migrateTask.Info())
+	}
+	return nil
+}
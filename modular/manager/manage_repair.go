@@ -0,0 +1,171 @@
+package manager
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	"github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/metrics"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+// RepairScanListLimit defines the number of objects listed for generating repair piece
+// tasks at once.
+const RepairScanListLimit int64 = 100
+
+func (m *ManageModular) HandleRepairPieceTask(
+	ctx context.Context,
+	repairTask task.RepairPieceTask) error {
+	if repairTask == nil || repairTask.GetObjectInfo() == nil {
+		log.CtxErrorw(ctx, "failed to handle repair piece, task pointer dangling")
+		return ErrDanglingTask
+	}
+	if !m.repairPieceQueue.Has(repairTask.Key()) || m.TaskCanceled(repairTask.Key()) {
+		return ErrCanceledTask
+	}
+	if repairTask.Error() != nil {
+		log.CtxErrorw(ctx, "handler error repair piece task", "info", repairTask.Info(),
+			"error", repairTask.Error())
+		return m.handleFailedRepairPieceTask(ctx, repairTask)
+	}
+	m.repairPieceQueue.PopByKey(repairTask.Key())
+	metrics.RepairPieceTaskSucceedCounter.WithLabelValues(m.Name()).Inc()
+	log.CtxInfow(ctx, "succeed to repair piece", "info", repairTask.Info())
+	return nil
+}
+
+func (m *ManageModular) handleFailedRepairPieceTask(
+	ctx context.Context,
+	handleTask task.RepairPieceTask) error {
+	oldTask := m.repairPieceQueue.PopByKey(handleTask.Key())
+	if oldTask == nil {
+		log.CtxErrorw(ctx, "task has been canceled")
+		return ErrCanceledTask
+	}
+	handleTask = oldTask.(task.RepairPieceTask)
+	if !handleTask.ExceedRetry() {
+		handleTask.SetUpdateTime(time.Now().Unix())
+		m.repairPieceQueue.Push(handleTask)
+		log.CtxDebugw(ctx, "push task again to retry", "info", handleTask.Info())
+	} else {
+		metrics.RepairPieceTaskFailedCounter.WithLabelValues(m.Name()).Inc()
+		log.CtxWarnw(ctx, "delete expired repair piece task", "info", handleTask.Info())
+	}
+	return nil
+}
+
+// scanRepairPieceTasks generates the repair piece tasks for the objects whose primary SP
+// is this SP and whose secondary SP is reported unhealthy by p2p. The objects of every
+// unhealthy SP are scanned in the ascending order of id from the cursor, the cursor is
+// kept while the SP is unhealthy, so the repaired objects are not scanned again.
+func (m *ManageModular) scanRepairPieceTasks(ctx context.Context) {
+	unhealthySps, err := m.baseApp.GfSpClient().QueryUnhealthySp(ctx)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query unhealthy sps", "error", err)
+		return
+	}
+	unhealthy := make(map[string]bool)
+	for _, sp := range unhealthySps {
+		unhealthy[sp] = true
+	}
+	// the sp becomes healthy, its objects are scanned from the beginning if it turns
+	// unhealthy again.
+	for sp := range m.repairCursors {
+		if !unhealthy[sp] {
+			delete(m.repairCursors, sp)
+		}
+	}
+	if len(unhealthySps) == 0 {
+		return
+	}
+	params, err := m.baseApp.Consensus().QueryStorageParams(ctx)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get storage params", "error", err)
+		return
+	}
+	buckets := make(map[string]*storagetypes.BucketInfo)
+	for _, sp := range unhealthySps {
+		if !m.scanUnhealthySp(ctx, sp, params, buckets) {
+			return
+		}
+	}
+}
+
+// scanUnhealthySp generates the repair piece tasks for the objects of the unhealthy sp,
+// returns false if the repair piece queue is full.
+func (m *ManageModular) scanUnhealthySp(
+	ctx context.Context,
+	spAddress string,
+	params *storagetypes.Params,
+	buckets map[string]*storagetypes.BucketInfo) bool {
+	for {
+		objects, err := m.baseApp.GfSpClient().ListObjectsBySecondarySp(ctx, spAddress,
+			m.repairCursors[spAddress], RepairScanListLimit)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to list objects by secondary sp", "sp", spAddress, "error", err)
+			return true
+		}
+		if len(objects) == 0 {
+			return true
+		}
+		for _, object := range objects {
+			objectInfo := object.GetObjectInfo()
+			if !object.GetRemoved() && objectInfo.GetObjectStatus() == storagetypes.OBJECT_STATUS_SEALED &&
+				m.primaryOfBucket(ctx, objectInfo.GetBucketName(), buckets) {
+				if !m.pushRepairPieceTask(ctx, objectInfo, params, spAddress) {
+					return false
+				}
+			}
+			m.repairCursors[spAddress] = objectInfo.Id.Uint64()
+		}
+	}
+}
+
+// primaryOfBucket returns whether this SP is the primary SP of the bucket, the bucket info
+// is cached in the scanning round.
+func (m *ManageModular) primaryOfBucket(
+	ctx context.Context,
+	bucketName string,
+	buckets map[string]*storagetypes.BucketInfo) bool {
+	bucketInfo, ok := buckets[bucketName]
+	if !ok {
+		var err error
+		bucketInfo, err = m.baseApp.Consensus().QueryBucketInfo(ctx, bucketName)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to get bucket info from consensus", "bucket_name", bucketName,
+				"error", err)
+			return false
+		}
+		buckets[bucketName] = bucketInfo
+	}
+	return strings.EqualFold(bucketInfo.GetPrimarySpAddress(), m.baseApp.OperateAddress())
+}
+
+// pushRepairPieceTask pushes the repair piece task of the object to queue, returns false
+// if the queue is full.
+func (m *ManageModular) pushRepairPieceTask(
+	ctx context.Context,
+	objectInfo *storagetypes.ObjectInfo,
+	params *storagetypes.Params,
+	spAddress string) bool {
+	rIdx := secondaryIdx(objectInfo, spAddress)
+	if rIdx < 0 {
+		return true
+	}
+	repairTask := &gfsptask.GfSpRepairPieceTask{}
+	repairTask.InitRepairPieceTask(objectInfo, params, uint32(rIdx), spAddress,
+		m.baseApp.TaskPriority(repairTask), m.baseApp.TaskTimeout(repairTask, objectInfo.GetPayloadSize()),
+		m.baseApp.TaskMaxRetry(repairTask))
+	if m.repairPieceQueue.Has(repairTask.Key()) {
+		return true
+	}
+	if err := m.repairPieceQueue.Push(repairTask); err != nil {
+		log.CtxDebugw(ctx, "repair piece queue is full", "error", err)
+		return false
+	}
+	log.CtxDebugw(ctx, "succeed to generate repair piece task", "info", repairTask.Info())
+	return true
+}
@@ -0,0 +1,157 @@
+package manager
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	"github.com/bnb-chain/greenfield-storage-provider/core/module"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+var ErrSPExitDone = gfsperrors.Register(module.ManageModularName, http.StatusBadRequest, 60011, "the sp has exited")
+
+func (m *ManageModular) HandleCreateSPExitTask(
+	ctx context.Context,
+	exitTask task.SPExitTask) error {
+	if exitTask == nil || exitTask.GetSpOperatorAddress() == "" {
+		log.CtxErrorw(ctx, "failed to handle create sp exit, task pointer dangling")
+		return ErrDanglingTask
+	}
+	if m.spExitQueue.Has(exitTask.Key()) {
+		log.CtxErrorw(ctx, "sp exit task repeated")
+		return ErrRepeatedTask
+	}
+	progress, err := m.baseApp.GfSpDB().GetSPExitProgress(exitTask.GetSpOperatorAddress())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get sp exit progress", "error", err)
+		return err
+	}
+	startTime := time.Now().Unix()
+	// resume from the persisted progress if the sp has exited partly
+	if progress != nil {
+		if progress.Finished {
+			log.CtxErrorw(ctx, "the sp has exited", "handover_object", progress.HandoverObjectNumber,
+				"handover_bucket", progress.HandoverBucketNumber)
+			return ErrSPExitDone
+		}
+		setSPExitTaskProgress(exitTask, progress)
+		startTime = progress.StartTime
+	}
+	if err = m.setSPExitProgress(exitTask, startTime); err != nil {
+		log.CtxErrorw(ctx, "failed to set sp exit progress", "error", err)
+		return err
+	}
+	if err = m.spExitQueue.Push(exitTask); err != nil {
+		log.CtxErrorw(ctx, "failed to push sp exit task to queue", "error", err)
+		return ErrExceedTask
+	}
+	return nil
+}
+
+func (m *ManageModular) HandleSPExitTask(
+	ctx context.Context,
+	exitTask task.SPExitTask) error {
+	if exitTask == nil || exitTask.GetSpOperatorAddress() == "" {
+		log.CtxErrorw(ctx, "failed to handle sp exit, task pointer dangling")
+		return ErrDanglingTask
+	}
+	if !m.spExitQueue.Has(exitTask.Key()) || m.TaskCanceled(exitTask.Key()) {
+		return ErrCanceledTask
+	}
+	oldTask := m.spExitQueue.PopByKey(exitTask.Key())
+	if oldTask == nil {
+		log.CtxErrorw(ctx, "report sp exit task is clear", "report_info", exitTask.Info())
+		return ErrCanceledTask
+	}
+	// the handled number only grows, the report behind the queued progress comes
+	// from the stale executor that timed out.
+	if handledNumber(oldTask.(task.SPExitTask)) > handledNumber(exitTask) {
+		log.CtxErrorw(ctx, "report sp exit task is expired", "report_info", exitTask.Info(),
+			"current_info", oldTask.Info())
+		m.spExitQueue.Push(oldTask)
+		return ErrCanceledTask
+	}
+	if err := m.setSPExitProgress(exitTask, 0); err != nil {
+		log.CtxErrorw(ctx, "failed to update sp exit progress", "error", err)
+	}
+	if exitTask.GetFinished() {
+		log.CtxInfow(ctx, "succeed to exit sp", "info", exitTask.Info())
+		return nil
+	}
+	if exitTask.Error() != nil {
+		log.CtxErrorw(ctx, "handler error sp exit task", "error", exitTask.Error())
+	}
+	exitTask.SetUpdateTime(time.Now().Unix())
+	m.spExitQueue.Push(exitTask)
+	return nil
+}
+
+// handledNumber returns the number of objects and buckets that have been handed over
+// or failed to hand over.
+func handledNumber(exitTask task.SPExitTask) uint64 {
+	return exitTask.GetHandoverObjectNumber() + exitTask.GetFailedObjectNumber() +
+		exitTask.GetHandoverBucketNumber() + exitTask.GetFailedBucketNumber()
+}
+
+// setSPExitTaskProgress fills the cursors and counters of the task from the progress.
+func setSPExitTaskProgress(exitTask task.SPExitTask, progress *corespdb.SPExitProgress) {
+	exitTask.SetLastSecondaryObjectId(progress.LastSecondaryObjectID)
+	exitTask.SetLastPrimaryBucketId(progress.LastPrimaryBucketID)
+	exitTask.SetHandoverObjectNumber(progress.HandoverObjectNumber)
+	exitTask.SetFailedObjectNumber(progress.FailedObjectNumber)
+	exitTask.SetHandoverBucketNumber(progress.HandoverBucketNumber)
+	exitTask.SetFailedBucketNumber(progress.FailedBucketNumber)
+}
+
+// setSPExitProgress persists the progress of sp exit, it is used to resume the exit
+// after the restart of manager. The start time is kept if it is zero.
+func (m *ManageModular) setSPExitProgress(exitTask task.SPExitTask, startTime int64) error {
+	if startTime == 0 {
+		progress, err := m.baseApp.GfSpDB().GetSPExitProgress(exitTask.GetSpOperatorAddress())
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			startTime = progress.StartTime
+		}
+	}
+	return m.baseApp.GfSpDB().SetSPExitProgress(&corespdb.SPExitProgress{
+		SpAddress:             exitTask.GetSpOperatorAddress(),
+		LastSecondaryObjectID: exitTask.GetLastSecondaryObjectId(),
+		LastPrimaryBucketID:   exitTask.GetLastPrimaryBucketId(),
+		HandoverObjectNumber:  exitTask.GetHandoverObjectNumber(),
+		FailedObjectNumber:    exitTask.GetFailedObjectNumber(),
+		HandoverBucketNumber:  exitTask.GetHandoverBucketNumber(),
+		FailedBucketNumber:    exitTask.GetFailedBucketNumber(),
+		Finished:              exitTask.GetFinished(),
+		StartTime:             startTime,
+		UpdateTime:            time.Now().Unix(),
+	})
+}
+
+// loadSPExitTask resumes the unfinished sp exit task from SPDB.
+func (m *ManageModular) loadSPExitTask(ctx context.Context) error {
+	progress, err := m.baseApp.GfSpDB().GetSPExitProgress(m.baseApp.OperateAddress())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get sp exit progress", "error", err)
+		return err
+	}
+	if progress == nil || progress.Finished {
+		return nil
+	}
+	exitTask := &gfsptask.GfSpSPExitTask{}
+	exitTask.InitSPExitTask(progress.SpAddress, m.baseApp.TaskPriority(exitTask),
+		m.baseApp.TaskTimeout(exitTask, 0), m.baseApp.TaskMaxRetry(exitTask))
+	setSPExitTaskProgress(exitTask, progress)
+	if err = m.spExitQueue.Push(exitTask); err != nil {
+		log.CtxErrorw(ctx, "failed to push sp exit task to queue", "error", err)
+		return nil
+	}
+	log.CtxInfow(ctx, "succeed to resume sp exit task", "info", exitTask.Info())
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
@@ -65,12 +66,31 @@ func (m *ManageModular) DispatchTask(
 			"task_limit", "task_limit", task.EstimateLimit().String())
 		backUpTasks = append(backUpTasks, task)
 	}
+	task = m.migrateBucketQueue.TopByLimit(limit)
+	if task != nil {
+		log.CtxDebugw(ctx, "add migrate bucket task to backup set", "task_key", task.Key().String(),
+			"task_limit", task.EstimateLimit().String())
+		backUpTasks = append(backUpTasks, task)
+	}
+	task = m.spExitQueue.TopByLimit(limit)
+	if task != nil {
+		log.CtxDebugw(ctx, "add sp exit task to backup set", "task_key", task.Key().String(),
+			"task_limit", task.EstimateLimit().String())
+		backUpTasks = append(backUpTasks, task)
+	}
+	task = m.repairPieceQueue.TopByLimit(limit)
+	if task != nil {
+		log.CtxDebugw(ctx, "add repair piece task to backup set", "task_key", task.Key().String(),
+			"task_limit", task.EstimateLimit().String())
+		backUpTasks = append(backUpTasks, task)
+	}
 	task = m.receiveQueue.TopByLimit(limit)
 	if task != nil {
 		log.CtxDebugw(ctx, "add confirm receive piece to backup set", "task_key", task.Key().String(),
 			"task_limit", task.EstimateLimit().String())
 		backUpTasks = append(backUpTasks, task)
 	}
+	backUpTasks = m.filterPausedTasks(backUpTasks)
 	task = m.PickUpTask(ctx, backUpTasks)
 	if task == nil {
 		return nil, nil
@@ -85,7 +105,7 @@ func (m *ManageModular) HandleCreateUploadObjectTask(
 		log.CtxErrorw(ctx, "failed to handle begin upload object, task pointer dangling")
 		return ErrDanglingTask
 	}
-	if m.UploadingObjectNumber() >= m.maxUploadObjectNumber {
+	if int64(m.UploadingObjectNumber()) >= atomic.LoadInt64(&m.maxUploadObjectNumber) {
 		log.CtxErrorw(ctx, "uploading object exceed", "uploading", m.uploadQueue.Len(),
 			"replicating", m.replicateQueue.Len(), "sealing", m.sealQueue.Len())
 		return ErrExceedTask
@@ -112,6 +132,10 @@ func (m *ManageModular) HandleDoneUploadObjectTask(
 		log.CtxErrorw(ctx, "failed to handle done upload object, pointer dangling")
 		return ErrDanglingTask
 	}
+	if m.TaskCanceled(task.Key()) {
+		log.CtxErrorw(ctx, "upload object task has been canceled")
+		return ErrCanceledTask
+	}
 	m.uploadQueue.PopByKey(task.Key())
 	if m.TaskUploading(ctx, task) {
 		log.CtxErrorw(ctx, "uploading object repeated")
@@ -154,6 +178,10 @@ func (m *ManageModular) HandleReplicatePieceTask(
 		log.CtxErrorw(ctx, "failed to handle replicate piece, pointer dangling")
 		return ErrDanglingTask
 	}
+	if m.TaskCanceled(task.Key()) {
+		log.CtxErrorw(ctx, "replicate piece task has been canceled")
+		return ErrCanceledTask
+	}
 	if task.Error() != nil {
 		log.CtxErrorw(ctx, "handler error replicate piece task", "error", task.Error())
 		return m.handleFailedReplicatePieceTask(ctx, task)
@@ -229,6 +257,10 @@ func (m *ManageModular) HandleSealObjectTask(
 		log.CtxErrorw(ctx, "failed to handle seal object, task pointer dangling")
 		return ErrDanglingTask
 	}
+	if m.TaskCanceled(task.Key()) {
+		log.CtxErrorw(ctx, "seal object task has been canceled")
+		return ErrCanceledTask
+	}
 	if task.Error() != nil {
 		log.CtxErrorw(ctx, "handler error seal object task", "error", task.Error())
 		return m.handleFailedSealObjectTask(ctx, task)
@@ -277,6 +309,10 @@ func (m *ManageModular) handleFailedSealObjectTask(
 func (m *ManageModular) HandleReceivePieceTask(
 	ctx context.Context,
 	task task.ReceivePieceTask) error {
+	if m.TaskCanceled(task.Key()) {
+		log.CtxErrorw(ctx, "receive piece task has been canceled")
+		return ErrCanceledTask
+	}
 	if task.GetSealed() {
 		m.receiveQueue.PopByKey(task.Key())
 		log.CtxDebugw(ctx, "succeed to confirm receive piece seal on chain")
@@ -321,7 +357,7 @@ func (m *ManageModular) HandleGCObjectTask(
 		log.CtxErrorw(ctx, "failed to handle gc object due to task pointer dangling")
 		return ErrDanglingTask
 	}
-	if !m.gcObjectQueue.Has(gcTask.Key()) {
+	if !m.gcObjectQueue.Has(gcTask.Key()) || m.TaskCanceled(gcTask.Key()) {
 		return ErrCanceledTask
 	}
 	if gcTask.GetEndBlockNumber() < gcTask.GetCurrentBlockNumber() {
@@ -391,6 +427,9 @@ func (m *ManageModular) QueryTasks(
 	gcObjectTasks, _ := taskqueue.ScanTQueueWithLimitBySubKey(m.gcObjectQueue, subKey)
 	gcZombieTasks, _ := taskqueue.ScanTQueueWithLimitBySubKey(m.gcZombieQueue, subKey)
 	gcMetaTasks, _ := taskqueue.ScanTQueueWithLimitBySubKey(m.gcMetaQueue, subKey)
+	migrateBucketTasks, _ := taskqueue.ScanTQueueWithLimitBySubKey(m.migrateBucketQueue, subKey)
+	spExitTasks, _ := taskqueue.ScanTQueueWithLimitBySubKey(m.spExitQueue, subKey)
+	repairPieceTasks, _ := taskqueue.ScanTQueueWithLimitBySubKey(m.repairPieceQueue, subKey)
 	downloadTasks, _ := taskqueue.ScanTQueueBySubKey(m.downloadQueue, subKey)
 	challengeTasks, _ := taskqueue.ScanTQueueBySubKey(m.challengeQueue, subKey)
 
@@ -402,6 +441,9 @@ func (m *ManageModular) QueryTasks(
 	tasks = append(tasks, gcObjectTasks...)
 	tasks = append(tasks, gcZombieTasks...)
 	tasks = append(tasks, gcMetaTasks...)
+	tasks = append(tasks, migrateBucketTasks...)
+	tasks = append(tasks, spExitTasks...)
+	tasks = append(tasks, repairPieceTasks...)
 	tasks = append(tasks, downloadTasks...)
 	tasks = append(tasks, challengeTasks...)
 	return tasks, nil
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
@@ -34,17 +35,20 @@ type ManageModular struct {
 	baseApp *gfspapp.GfSpBaseApp
 	scope   rcmgr.ResourceScope
 
-	uploadQueue    taskqueue.TQueueOnStrategy
-	replicateQueue taskqueue.TQueueOnStrategyWithLimit
-	sealQueue      taskqueue.TQueueOnStrategyWithLimit
-	receiveQueue   taskqueue.TQueueOnStrategyWithLimit
-	gcObjectQueue  taskqueue.TQueueOnStrategyWithLimit
-	gcZombieQueue  taskqueue.TQueueOnStrategyWithLimit
-	gcMetaQueue    taskqueue.TQueueOnStrategyWithLimit
-	downloadQueue  taskqueue.TQueueOnStrategy
-	challengeQueue taskqueue.TQueueOnStrategy
-
-	maxUploadObjectNumber int
+	uploadQueue        taskqueue.TQueueOnStrategy
+	replicateQueue     taskqueue.TQueueOnStrategyWithLimit
+	sealQueue          taskqueue.TQueueOnStrategyWithLimit
+	receiveQueue       taskqueue.TQueueOnStrategyWithLimit
+	gcObjectQueue      taskqueue.TQueueOnStrategyWithLimit
+	gcZombieQueue      taskqueue.TQueueOnStrategyWithLimit
+	gcMetaQueue        taskqueue.TQueueOnStrategyWithLimit
+	migrateBucketQueue taskqueue.TQueueOnStrategyWithLimit
+	spExitQueue        taskqueue.TQueueOnStrategyWithLimit
+	repairPieceQueue   taskqueue.TQueueOnStrategyWithLimit
+	downloadQueue      taskqueue.TQueueOnStrategy
+	challengeQueue     taskqueue.TQueueOnStrategy
+
+	maxUploadObjectNumber int64
 
 	gcObjectTimeInterval  int
 	gcBlockHeight         uint64
@@ -54,9 +58,30 @@ type ManageModular struct {
 	syncConsensusInfoInterval uint64
 	statisticsOutputInterval  int
 
+	// repairCursors records the last scanned object id of the unhealthy sps, it is only
+	// accessed in the event loop.
+	repairScanInterval int
+	repairCursors      map[string]uint64
+
+	// the audit cursors are only accessed in the audit loop.
+	auditEnabled             bool
+	auditSampleRate          float64
+	auditInterval            int
+	auditScanNumber          int64
+	auditAutoRepair          bool
+	auditRepairHooks         map[string]AuditRepairHook
+	auditRand                *rand.Rand
+	auditPrimaryBucketCursor uint64
+	auditPrimaryObjectToken  string
+	auditSecondaryCursor     uint64
+
 	discontinueBucketEnabled       bool
 	discontinueBucketTimeInterval  int
 	discontinueBucketKeepAliveDays int
+
+	adminMux        sync.RWMutex
+	pausedTaskTypes map[task.TType]bool
+	canceledTasks   map[task.TKey]int64
 }
 
 func (m *ManageModular) Name() string {
@@ -73,6 +98,12 @@ func (m *ManageModular) Start(ctx context.Context) error {
 	m.receiveQueue.SetFilterTaskStrategy(m.FilterUploadingTask)
 	m.gcObjectQueue.SetRetireTaskStrategy(m.ResetGCObjectTask)
 	m.gcObjectQueue.SetFilterTaskStrategy(m.FilterGCTask)
+	m.migrateBucketQueue.SetRetireTaskStrategy(m.GCMigrateBucketQueue)
+	m.migrateBucketQueue.SetFilterTaskStrategy(m.FilterUploadingTask)
+	m.spExitQueue.SetRetireTaskStrategy(m.GCSPExitQueue)
+	m.spExitQueue.SetFilterTaskStrategy(m.FilterUploadingTask)
+	m.repairPieceQueue.SetRetireTaskStrategy(m.GCRepairPieceQueue)
+	m.repairPieceQueue.SetFilterTaskStrategy(m.FilterUploadingTask)
 	m.downloadQueue.SetRetireTaskStrategy(m.GCCacheQueue)
 	m.challengeQueue.SetRetireTaskStrategy(m.GCCacheQueue)
 
@@ -87,6 +118,9 @@ func (m *ManageModular) Start(ctx context.Context) error {
 	}
 
 	go m.eventLoop(ctx)
+	if m.auditEnabled {
+		go m.auditLoop(ctx)
+	}
 	return nil
 }
 
@@ -95,6 +129,7 @@ func (m *ManageModular) eventLoop(ctx context.Context) {
 	syncConsensusInfoTicker := time.NewTicker(time.Duration(m.syncConsensusInfoInterval) * time.Second)
 	statisticsTicker := time.NewTicker(time.Duration(m.statisticsOutputInterval) * time.Second)
 	discontinueBucketTicker := time.NewTicker(time.Duration(m.discontinueBucketTimeInterval) * time.Second)
+	repairScanTicker := time.NewTicker(time.Duration(m.repairScanInterval) * time.Second)
 	for {
 		select {
 		case <-ctx.Done():
@@ -137,6 +172,8 @@ func (m *ManageModular) eventLoop(ctx context.Context) {
 			}
 			m.discontinueBuckets(ctx)
 			log.Infof("finish to discontinue buckets", "time", time.Now())
+		case <-repairScanTicker.C:
+			m.scanRepairPieceTasks(ctx)
 		}
 	}
 }
@@ -193,7 +230,10 @@ func (m *ManageModular) ReleaseResource(ctx context.Context, span rcmgr.Resource
 }
 
 func (m *ManageModular) LoadTaskFromDB() error {
-	return nil
+	if err := m.loadMigrateBucketTask(context.Background()); err != nil {
+		return err
+	}
+	return m.loadSPExitTask(context.Background())
 }
 
 func (m *ManageModular) TaskUploading(ctx context.Context, task task.Task) bool {
@@ -266,6 +306,30 @@ func (m *ManageModular) ResetGCObjectTask(qTask task.Task) bool {
 	return false
 }
 
+func (m *ManageModular) GCMigrateBucketQueue(qTask task.Task) bool {
+	if qTask.Expired() {
+		log.Errorw("delete expired migrate bucket task, it will be resumed after restart", "info", qTask.Info())
+		return true
+	}
+	return false
+}
+
+func (m *ManageModular) GCSPExitQueue(qTask task.Task) bool {
+	if qTask.Expired() {
+		log.Errorw("delete expired sp exit task, it will be resumed after restart", "info", qTask.Info())
+		return true
+	}
+	return false
+}
+
+func (m *ManageModular) GCRepairPieceQueue(qTask task.Task) bool {
+	if qTask.Expired() {
+		log.Errorw("delete expired repair piece task", "info", qTask.Info())
+		return true
+	}
+	return false
+}
+
 func (m *ManageModular) GCCacheQueue(qTask task.Task) bool {
 	return true
 }
@@ -307,6 +371,11 @@ func (m *ManageModular) PickUpTask(ctx context.Context, tasks []task.Task) task.
 }
 
 func (m *ManageModular) syncConsensusInfo(ctx context.Context) {
+	// the versioned params that are effective before the safe delay are verified and
+	// cached, the objects created in the period resolve their params from the cache.
+	if err := m.baseApp.SyncVersionedParams(ctx); err != nil {
+		log.CtxErrorw(ctx, "failed to sync versioned params", "error", err)
+	}
 	spInfoList, err := m.baseApp.Consensus().QuerySPInfo(ctx)
 	if err != nil {
 		log.CtxErrorw(ctx, "failed to query sp info", "error", err)
@@ -328,9 +397,11 @@ func (m *ManageModular) syncConsensusInfo(ctx context.Context) {
 
 func (m *ManageModular) Statistics() string {
 	return fmt.Sprintf(
-		"upload[%d], replicate[%d], seal[%d], receive[%d], gcObject[%d], gcZombie[%d], gcMeta[%d], download[%d], challenge[%d], gcBlock[%d], gcSafeDistance[%d]",
+		"upload[%d], replicate[%d], seal[%d], receive[%d], gcObject[%d], gcZombie[%d], gcMeta[%d], migrateBucket[%d], spExit[%d], repairPiece[%d], download[%d], challenge[%d], gcBlock[%d], gcSafeDistance[%d]",
 		m.uploadQueue.Len(), m.replicateQueue.Len(), m.sealQueue.Len(),
 		m.receiveQueue.Len(), m.gcObjectQueue.Len(), m.gcZombieQueue.Len(),
-		m.gcMetaQueue.Len(), m.downloadQueue.Len(), m.challengeQueue.Len(),
+		m.gcMetaQueue.Len(), m.migrateBucketQueue.Len(), m.spExitQueue.Len(), m.repairPieceQueue.Len(),
+		m.downloadQueue.Len(),
+		m.challengeQueue.Len(),
 		m.gcBlockHeight, m.gcSafeBlockDistance)
 }
@@ -1,9 +1,17 @@
 package manager
 
 import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 )
 
 const (
@@ -32,6 +40,26 @@ const (
 	// DefaultGlobalGCMetaParallel defines the default max parallel gc meta db in SP
 	// system.
 	DefaultGlobalGCMetaParallel int = 1
+	// DefaultGlobalMigrateBucketParallel defines the default max parallel migrating
+	// buckets to SP system.
+	DefaultGlobalMigrateBucketParallel int = 1
+	// DefaultGlobalSPExitParallel defines the max parallel sp exit task, the SP exits
+	// only once, the queue holds the only sp exit task.
+	DefaultGlobalSPExitParallel int = 1
+	// DefaultGlobalRepairPieceParallel defines the default max parallel repairing the
+	// pieces of the unhealthy secondary SPs.
+	DefaultGlobalRepairPieceParallel int = 16
+	// DefaultGlobalRepairPieceScanInterval defines the default interval in seconds for
+	// scanning the objects of the unhealthy secondary SPs to generate repair piece tasks.
+	DefaultGlobalRepairPieceScanInterval int = 600
+	// DefaultAuditSampleRate defines the default probability of auditing each scanned
+	// object by the self challenge auditor.
+	DefaultAuditSampleRate float64 = 0.01
+	// DefaultAuditInterval defines the default interval in seconds of the audit rounds.
+	DefaultAuditInterval int = 60
+	// DefaultAuditScanNumber defines the default max number of scanned objects in each
+	// audit round for each role.
+	DefaultAuditScanNumber int64 = 1000
 	// DefaultGlobalDownloadObjectTaskCacheSize defines the default max cache the download
 	// object tasks in manager.
 	DefaultGlobalDownloadObjectTaskCacheSize int = 4096
@@ -63,7 +91,13 @@ const (
 )
 
 func NewManageModular(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig) (coremodule.Modular, error) {
-	manager := &ManageModular{baseApp: app}
+	manager := &ManageModular{
+		baseApp:         app,
+		pausedTaskTypes: make(map[coretask.TType]bool),
+		canceledTasks:   make(map[coretask.TKey]int64),
+		repairCursors:   make(map[string]uint64),
+		auditRand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
 	if err := DefaultManagerOptions(manager, cfg); err != nil {
 		return nil, err
 	}
@@ -71,6 +105,55 @@ func NewManageModular(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig) (cor
 }
 
 func DefaultManagerOptions(manager *ManageModular, cfg *gfspconfig.GfSpConfig) error {
+	defaultManagerConfig(cfg)
+	manager.statisticsOutputInterval = DefaultStatisticsOutputInterval
+	manager.maxUploadObjectNumber = int64(cfg.Parallel.GlobalMaxUploadingParallel)
+	manager.gcObjectTimeInterval = cfg.Parallel.GlobalBatchGcObjectTimeInterval
+	manager.gcObjectBlockInterval = cfg.Parallel.GlobalGcObjectBlockInterval
+	manager.gcSafeBlockDistance = cfg.Parallel.GlobalGcObjectSafeBlockDistance
+	manager.syncConsensusInfoInterval = cfg.Parallel.GlobalSyncConsensusInfoInterval
+	manager.discontinueBucketEnabled = cfg.Parallel.DiscontinueBucketEnabled
+	manager.discontinueBucketTimeInterval = cfg.Parallel.DiscontinueBucketTimeInterval
+	manager.discontinueBucketKeepAliveDays = cfg.Parallel.DiscontinueBucketKeepAliveDays
+	manager.repairScanInterval = cfg.Parallel.GlobalRepairPieceScanInterval
+	manager.auditEnabled = cfg.Auditor.Enable
+	manager.auditSampleRate = cfg.Auditor.SampleRate
+	manager.auditInterval = cfg.Auditor.AuditInterval
+	manager.auditScanNumber = cfg.Auditor.AuditScanNumber
+	manager.auditAutoRepair = cfg.Auditor.AutoRepair
+	manager.auditRepairHooks = map[string]AuditRepairHook{
+		corespdb.AuditMissingIntegrity: manager.repairAuditIntegrity,
+		corespdb.AuditStaleIntegrity:   manager.repairAuditIntegrity,
+	}
+	manager.uploadQueue = cfg.Customize.NewStrategyTQueueFunc(
+		manager.Name()+"-upload-object", cfg.Parallel.GlobalUploadObjectParallel)
+	manager.replicateQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
+		manager.Name()+"-replicate-piece", cfg.Parallel.GlobalReplicatePieceParallel)
+	manager.sealQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
+		manager.Name()+"-seal-object", cfg.Parallel.GlobalSealObjectParallel)
+	manager.receiveQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
+		manager.Name()+"-confirm-receive-piece", cfg.Parallel.GlobalReceiveObjectParallel)
+	manager.gcObjectQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
+		manager.Name()+"-gc-object", cfg.Parallel.GlobalGCObjectParallel)
+	manager.gcZombieQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
+		manager.Name()+"-gc-zombie", cfg.Parallel.GlobalGCZombieParallel)
+	manager.gcMetaQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
+		manager.Name()+"-gc-meta", cfg.Parallel.GlobalGCMetaParallel)
+	manager.migrateBucketQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
+		manager.Name()+"-migrate-bucket", cfg.Parallel.GlobalMigrateBucketParallel)
+	manager.spExitQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
+		manager.Name()+"-sp-exit", DefaultGlobalSPExitParallel)
+	manager.repairPieceQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
+		manager.Name()+"-repair-piece", cfg.Parallel.GlobalRepairPieceParallel)
+	manager.downloadQueue = cfg.Customize.NewStrategyTQueueFunc(
+		manager.Name()+"-cache-download-object", cfg.Parallel.GlobalDownloadObjectTaskCacheSize)
+	manager.challengeQueue = cfg.Customize.NewStrategyTQueueFunc(
+		manager.Name()+"-cache-challenge-piece", cfg.Parallel.GlobalChallengePieceTaskCacheSize)
+	return nil
+}
+
+// defaultManagerConfig fills the default values of the manager config.
+func defaultManagerConfig(cfg *gfspconfig.GfSpConfig) {
 	if cfg.Parallel.GlobalMaxUploadingParallel == 0 {
 		cfg.Parallel.GlobalMaxUploadingParallel = DefaultGlobalMaxUploadingNumber
 	}
@@ -95,6 +178,24 @@ func DefaultManagerOptions(manager *ManageModular, cfg *gfspconfig.GfSpConfig) e
 	if cfg.Parallel.GlobalGCMetaParallel == 0 {
 		cfg.Parallel.GlobalGCMetaParallel = DefaultGlobalGCMetaParallel
 	}
+	if cfg.Parallel.GlobalMigrateBucketParallel == 0 {
+		cfg.Parallel.GlobalMigrateBucketParallel = DefaultGlobalMigrateBucketParallel
+	}
+	if cfg.Parallel.GlobalRepairPieceParallel == 0 {
+		cfg.Parallel.GlobalRepairPieceParallel = DefaultGlobalRepairPieceParallel
+	}
+	if cfg.Parallel.GlobalRepairPieceScanInterval == 0 {
+		cfg.Parallel.GlobalRepairPieceScanInterval = DefaultGlobalRepairPieceScanInterval
+	}
+	if cfg.Auditor.SampleRate <= 0 || cfg.Auditor.SampleRate > 1 {
+		cfg.Auditor.SampleRate = DefaultAuditSampleRate
+	}
+	if cfg.Auditor.AuditInterval == 0 {
+		cfg.Auditor.AuditInterval = DefaultAuditInterval
+	}
+	if cfg.Auditor.AuditScanNumber == 0 {
+		cfg.Auditor.AuditScanNumber = DefaultAuditScanNumber
+	}
 	if cfg.Parallel.GlobalDownloadObjectTaskCacheSize == 0 {
 		cfg.Parallel.GlobalDownloadObjectTaskCacheSize = DefaultGlobalDownloadObjectTaskCacheSize
 	}
@@ -119,33 +220,27 @@ func DefaultManagerOptions(manager *ManageModular, cfg *gfspconfig.GfSpConfig) e
 	if cfg.Parallel.DiscontinueBucketKeepAliveDays == 0 {
 		cfg.Parallel.DiscontinueBucketKeepAliveDays = DefaultDiscontinueBucketKeepAliveDays
 	}
+}
 
-	manager.statisticsOutputInterval = DefaultStatisticsOutputInterval
-	manager.maxUploadObjectNumber = cfg.Parallel.GlobalMaxUploadingParallel
-	manager.gcObjectTimeInterval = cfg.Parallel.GlobalBatchGcObjectTimeInterval
-	manager.gcObjectBlockInterval = cfg.Parallel.GlobalGcObjectBlockInterval
-	manager.gcSafeBlockDistance = cfg.Parallel.GlobalGcObjectSafeBlockDistance
-	manager.syncConsensusInfoInterval = cfg.Parallel.GlobalSyncConsensusInfoInterval
-	manager.discontinueBucketEnabled = cfg.Parallel.DiscontinueBucketEnabled
-	manager.discontinueBucketTimeInterval = cfg.Parallel.DiscontinueBucketTimeInterval
-	manager.discontinueBucketKeepAliveDays = cfg.Parallel.DiscontinueBucketKeepAliveDays
-	manager.uploadQueue = cfg.Customize.NewStrategyTQueueFunc(
-		manager.Name()+"-upload-object", cfg.Parallel.GlobalUploadObjectParallel)
-	manager.replicateQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
-		manager.Name()+"-replicate-piece", cfg.Parallel.GlobalReplicatePieceParallel)
-	manager.sealQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
-		manager.Name()+"-seal-object", cfg.Parallel.GlobalSealObjectParallel)
-	manager.receiveQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
-		manager.Name()+"-confirm-receive-piece", cfg.Parallel.GlobalReceiveObjectParallel)
-	manager.gcObjectQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
-		manager.Name()+"-gc-object", cfg.Parallel.GlobalGCObjectParallel)
-	manager.gcZombieQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
-		manager.Name()+"-gc-zombie", cfg.Parallel.GlobalGCZombieParallel)
-	manager.gcMetaQueue = cfg.Customize.NewStrategyTQueueWithLimitFunc(
-		manager.Name()+"-gc-meta", cfg.Parallel.GlobalGCMetaParallel)
-	manager.downloadQueue = cfg.Customize.NewStrategyTQueueFunc(
-		manager.Name()+"-cache-download-object", cfg.Parallel.GlobalDownloadObjectTaskCacheSize)
-	manager.challengeQueue = cfg.Customize.NewStrategyTQueueFunc(
-		manager.Name()+"-cache-challenge-piece", cfg.Parallel.GlobalChallengePieceTaskCacheSize)
+// ReloadConfig applies the reloaded max uploading number and the capacities of task queues.
+func (m *ManageModular) ReloadConfig(ctx context.Context, cfg interface{}) error {
+	newCfg, ok := cfg.(*gfspconfig.GfSpConfig)
+	if !ok {
+		return gfspconfig.ErrInvalidReloadConfig
+	}
+	defaultManagerConfig(newCfg)
+	atomic.StoreInt64(&m.maxUploadObjectNumber, int64(newCfg.Parallel.GlobalMaxUploadingParallel))
+	m.uploadQueue.SetCap(newCfg.Parallel.GlobalUploadObjectParallel)
+	m.replicateQueue.SetCap(newCfg.Parallel.GlobalReplicatePieceParallel)
+	m.sealQueue.SetCap(newCfg.Parallel.GlobalSealObjectParallel)
+	m.receiveQueue.SetCap(newCfg.Parallel.GlobalReceiveObjectParallel)
+	m.gcObjectQueue.SetCap(newCfg.Parallel.GlobalGCObjectParallel)
+	m.gcZombieQueue.SetCap(newCfg.Parallel.GlobalGCZombieParallel)
+	m.gcMetaQueue.SetCap(newCfg.Parallel.GlobalGCMetaParallel)
+	m.migrateBucketQueue.SetCap(newCfg.Parallel.GlobalMigrateBucketParallel)
+	m.repairPieceQueue.SetCap(newCfg.Parallel.GlobalRepairPieceParallel)
+	m.downloadQueue.SetCap(newCfg.Parallel.GlobalDownloadObjectTaskCacheSize)
+	m.challengeQueue.SetCap(newCfg.Parallel.GlobalChallengePieceTaskCacheSize)
+	log.CtxInfow(ctx, "succeed to reload manager config")
 	return nil
 }
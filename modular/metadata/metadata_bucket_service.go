@@ -231,6 +231,40 @@ func (r *MetadataModular) GfSpListExpiredBucketsBySp(ctx context.Context, req *t
 	return resp, nil
 }
 
+// GfSpListBucketsByPrimarySp list the buckets whose primary sp is the given sp
+func (r *MetadataModular) GfSpListBucketsByPrimarySp(ctx context.Context, req *types.GfSpListBucketsByPrimarySpRequest) (resp *types.GfSpListBucketsByPrimarySpResponse, err error) {
+	ctx = log.Context(ctx, req)
+	buckets, err := r.baseApp.GfBsDB().ListBucketsByPrimarySp(req.GetPrimarySpAddress(), req.GetStartAfterBucketId(), req.GetLimit())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list buckets by primary sp", "error", err)
+		return
+	}
+
+	res := make([]*types.Bucket, 0)
+	for _, bucket := range buckets {
+		res = append(res, &types.Bucket{
+			BucketInfo: &storage_types.BucketInfo{
+				Owner:            bucket.Owner.String(),
+				BucketName:       bucket.BucketName,
+				Id:               math.NewUintFromBigInt(bucket.BucketID.Big()),
+				SourceType:       storage_types.SourceType(storage_types.SourceType_value[bucket.SourceType]),
+				CreateAt:         bucket.CreateTime,
+				PaymentAddress:   bucket.PaymentAddress.String(),
+				PrimarySpAddress: bucket.PrimarySpAddress.String(),
+				ChargedReadQuota: bucket.ChargedReadQuota,
+				Visibility:       storage_types.VisibilityType(storage_types.VisibilityType_value[bucket.Visibility]),
+				BucketStatus:     storage_types.BucketStatus(storage_types.BucketStatus_value[bucket.Status]),
+			},
+			Removed:      bucket.Removed,
+			DeleteAt:     bucket.DeleteAt,
+			DeleteReason: bucket.DeleteReason,
+		})
+	}
+	resp = &types.GfSpListBucketsByPrimarySpResponse{Buckets: res}
+	log.CtxInfow(ctx, "succeed to list buckets by primary sp")
+	return resp, nil
+}
+
 // GfSpGetBucketMeta get bucket metadata
 func (r *MetadataModular) GfSpGetBucketMeta(
 	ctx context.Context,
@@ -324,7 +358,7 @@ func (r *MetadataModular) GfSpGetBucketReadQuota(
 	if systemerrors.Is(err, gorm.ErrRecordNotFound) {
 		return &types.GfSpGetBucketReadQuotaResponse{
 			ChargedQuotaSize: req.GetBucketInfo().GetChargedReadQuota(),
-			SpFreeQuotaSize:  r.freeQuotaPerBucket,
+			SpFreeQuotaSize:  atomic.LoadUint64(&r.freeQuotaPerBucket),
 			ConsumedSize:     0,
 		}, nil
 	}
@@ -336,7 +370,7 @@ func (r *MetadataModular) GfSpGetBucketReadQuota(
 	}
 	return &types.GfSpGetBucketReadQuotaResponse{
 		ChargedQuotaSize: req.GetBucketInfo().GetChargedReadQuota(),
-		SpFreeQuotaSize:  r.freeQuotaPerBucket,
+		SpFreeQuotaSize:  atomic.LoadUint64(&r.freeQuotaPerBucket),
 		ConsumedSize:     bucketTraffic.ReadConsumedSize,
 	}, nil
 }
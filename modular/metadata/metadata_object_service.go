@@ -66,6 +66,7 @@ func (r *MetadataModular) GfSpListObjectsByBucketName(ctx context.Context, req *
 				LockedBalance: object.LockedBalance.String(),
 				Removed:       object.Removed,
 				UpdateAt:      object.UpdateAt,
+				UpdateTime:    object.UpdateTime,
 				DeleteAt:      object.DeleteAt,
 				DeleteReason:  object.DeleteReason,
 				Operator:      object.Operator.String(),
@@ -147,6 +148,7 @@ func (r *MetadataModular) GfSpListDeletedObjectsByBlockNumberRange(ctx context.C
 			LockedBalance: object.LockedBalance.String(),
 			Removed:       object.Removed,
 			UpdateAt:      object.UpdateAt,
+			UpdateTime:    object.UpdateTime,
 			DeleteAt:      object.DeleteAt,
 			DeleteReason:  object.DeleteReason,
 			Operator:      object.Operator.String(),
@@ -202,6 +204,8 @@ func (r *MetadataModular) GfSpGetObjectMeta(ctx context.Context, req *types.GfSp
 			},
 			LockedBalance: object.LockedBalance.String(),
 			Removed:       object.Removed,
+			UpdateAt:      object.UpdateAt,
+			UpdateTime:    object.UpdateTime,
 			DeleteAt:      object.DeleteAt,
 			DeleteReason:  object.DeleteReason,
 			Operator:      object.Operator.String(),
@@ -214,3 +218,75 @@ func (r *MetadataModular) GfSpGetObjectMeta(ctx context.Context, req *types.GfSp
 	log.CtxInfo(ctx, "succeed to get object meta")
 	return resp, nil
 }
+
+// GfSpListObjectsBySecondarySp list the objects which store the pieces in the sp as secondary
+func (r *MetadataModular) GfSpListObjectsBySecondarySp(ctx context.Context, req *types.GfSpListObjectsBySecondarySpRequest) (resp *types.GfSpListObjectsBySecondarySpResponse, err error) {
+	ctx = log.Context(ctx, req)
+	objects, err := r.baseApp.GfBsDB().ListObjectsBySecondarySp(req.GetSecondarySpAddress(), req.GetStartAfterObjectId(), req.GetLimit())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to list objects by secondary sp", "error", err)
+		return nil, err
+	}
+
+	res := make([]*types.Object, 0)
+	for _, object := range objects {
+		res = append(res, &types.Object{
+			ObjectInfo: &storage_types.ObjectInfo{
+				Owner:                object.Owner.String(),
+				BucketName:           object.BucketName,
+				ObjectName:           object.ObjectName,
+				Id:                   math.NewUintFromBigInt(object.ObjectID.Big()),
+				PayloadSize:          object.PayloadSize,
+				ContentType:          object.ContentType,
+				CreateAt:             object.CreateTime,
+				ObjectStatus:         storage_types.ObjectStatus(storage_types.ObjectStatus_value[object.ObjectStatus]),
+				RedundancyType:       storage_types.RedundancyType(storage_types.RedundancyType_value[object.RedundancyType]),
+				SourceType:           storage_types.SourceType(storage_types.SourceType_value[object.SourceType]),
+				Checksums:            object.Checksums,
+				SecondarySpAddresses: object.SecondarySpAddresses,
+				Visibility:           storage_types.VisibilityType(storage_types.VisibilityType_value[object.Visibility]),
+			},
+			LockedBalance: object.LockedBalance.String(),
+			Removed:       object.Removed,
+			UpdateAt:      object.UpdateAt,
+			UpdateTime:    object.UpdateTime,
+			DeleteAt:      object.DeleteAt,
+			DeleteReason:  object.DeleteReason,
+			Operator:      object.Operator.String(),
+			CreateTxHash:  object.CreateTxHash.String(),
+			UpdateTxHash:  object.UpdateTxHash.String(),
+			SealTxHash:    object.SealTxHash.String(),
+		})
+	}
+	resp = &types.GfSpListObjectsBySecondarySpResponse{Objects: res}
+	log.CtxInfow(ctx, "succeed to list objects by secondary sp")
+	return resp, nil
+}
+
+// GfSpGetPrefixStats get the object count and the total size of the directory and its immediate child directories
+func (r *MetadataModular) GfSpGetPrefixStats(ctx context.Context, req *types.GfSpGetPrefixStatsRequest) (resp *types.GfSpGetPrefixStatsResponse, err error) {
+	ctx = log.Context(ctx, req)
+	directory, children, err := r.baseApp.GfBsDB().GetPrefixStats(req.GetBucketName(), req.GetPrefix())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to get prefix stats", "error", err)
+		return nil, err
+	}
+
+	resp = &types.GfSpGetPrefixStatsResponse{Children: make([]*types.PrefixStats, 0)}
+	if directory != nil {
+		resp.Stats = &types.PrefixStats{
+			Prefix:      directory.FullName,
+			ObjectCount: directory.ObjectCount,
+			TotalSize:   directory.TotalSize,
+		}
+	}
+	for _, child := range children {
+		resp.Children = append(resp.Children, &types.PrefixStats{
+			Prefix:      child.FullName,
+			ObjectCount: child.ObjectCount,
+			TotalSize:   child.TotalSize,
+		})
+	}
+	log.CtxInfow(ctx, "succeed to get prefix stats")
+	return resp, nil
+}
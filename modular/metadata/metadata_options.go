@@ -1,6 +1,8 @@
 package metadata
 
 import (
+	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
@@ -91,3 +93,21 @@ func switchDB(flag bool, cfg *gfspconfig.GfSpConfig, metadata *MetadataModular)
 	cfg.Metadata.IsMasterDB = flag
 	log.Info("db switched successfully")
 }
+
+// ReloadConfig applies the reloaded max handling request number and the free read quota per bucket.
+func (r *MetadataModular) ReloadConfig(ctx context.Context, cfg interface{}) error {
+	newCfg, ok := cfg.(*gfspconfig.GfSpConfig)
+	if !ok {
+		return gfspconfig.ErrInvalidReloadConfig
+	}
+	if newCfg.Parallel.QuerySPParallelPerNode == 0 {
+		newCfg.Parallel.QuerySPParallelPerNode = DefaultQuerySPParallelPerNode
+	}
+	if newCfg.Bucket.FreeQuotaPerBucket == 0 {
+		newCfg.Bucket.FreeQuotaPerBucket = downloader.DefaultBucketFreeQuota
+	}
+	atomic.StoreInt64(&r.maxMetadataRequest, newCfg.Parallel.QuerySPParallelPerNode)
+	atomic.StoreUint64(&r.freeQuotaPerBucket, newCfg.Bucket.FreeQuotaPerBucket)
+	log.CtxInfow(ctx, "succeed to reload metadata config")
+	return nil
+}
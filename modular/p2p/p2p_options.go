@@ -1,12 +1,15 @@
 package p2p
 
 import (
+	"context"
 	"os"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
+	mpiecestore "github.com/bnb-chain/greenfield-storage-provider/model/piecestore"
 	"github.com/bnb-chain/greenfield-storage-provider/modular/p2p/p2pnode"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 )
 
 const (
@@ -45,6 +48,31 @@ func DefaultP2POptions(p2p *P2PModular, cfg *gfspconfig.GfSpConfig) error {
 	if err != nil {
 		return err
 	}
+	approvalCfg := cfg.P2P.SecondaryApproval
+	var storePath string
+	if cfg.PieceStore.Store.Storage == mpiecestore.DiskFileStore {
+		storePath = cfg.PieceStore.Store.BucketURL
+	}
+	node.SetSecondaryApprovalStrategy(p2pnode.NewDefaultSecondaryApprovalStrategy(p2p.baseApp,
+		p2pnode.WithDenySPs(approvalCfg.DenySPs),
+		p2pnode.WithApprovalQuota(approvalCfg.MaxApprovalPerSP, approvalCfg.ApprovalQuotaWindow),
+		p2pnode.WithMaxReceiveQueueDepth(cfg.Endpoint.ReceiverEndpoint, approvalCfg.MaxReceiveQueueDepth),
+		p2pnode.WithMinFreeStoreSize(storePath, approvalCfg.MinFreeStoreSize),
+		p2pnode.WithMinRemainingMemory(approvalCfg.MinRemainingMemory)))
 	p2p.node = node
 	return nil
 }
+
+// ReloadConfig applies the reloaded capacity of ask replicate approval queue.
+func (p *P2PModular) ReloadConfig(ctx context.Context, cfg interface{}) error {
+	newCfg, ok := cfg.(*gfspconfig.GfSpConfig)
+	if !ok {
+		return gfspconfig.ErrInvalidReloadConfig
+	}
+	if newCfg.Parallel.AskReplicateApprovalParallelPerNode == 0 {
+		newCfg.Parallel.AskReplicateApprovalParallelPerNode = DefaultAskReplicateApprovalParallelPerNode
+	}
+	p.replicateApprovalQueue.SetCap(newCfg.Parallel.AskReplicateApprovalParallelPerNode)
+	log.CtxInfow(ctx, "succeed to reload p2p config")
+	return nil
+}
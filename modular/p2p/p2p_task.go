@@ -2,18 +2,22 @@ package p2p
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
 	"github.com/bnb-chain/greenfield-storage-provider/core/module"
 	"github.com/bnb-chain/greenfield-storage-provider/core/task"
 	"github.com/bnb-chain/greenfield-storage-provider/core/taskqueue"
+	"github.com/bnb-chain/greenfield-storage-provider/modular/p2p/p2pnode"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 )
 
 var (
 	ErrRepeatedTask         = gfsperrors.Register(module.P2PModularName, http.StatusBadRequest, 70001, "request repeated")
 	ErrInsufficientApproval = gfsperrors.Register(module.P2PModularName, http.StatusNotFound, 70002, "insufficient approvals as secondary sp")
+	ErrReplicateUnsupported = gfsperrors.Register(module.P2PModularName, http.StatusNotImplemented, 70003, "secondary sp does not support replicating piece over p2p")
+	ErrReplicateTransport   = gfsperrors.Register(module.P2PModularName, http.StatusServiceUnavailable, 70004, "failed to replicate piece over p2p stream")
 )
 
 func (p *P2PModular) HandleReplicatePieceApproval(
@@ -47,6 +51,35 @@ func (p *P2PModular) HandleQueryBootstrap(ctx context.Context) ([]string, error)
 	return p.node.Bootstrap(), nil
 }
 
+func (p *P2PModular) HandleQuerySelfNode(ctx context.Context) (string, error) {
+	return p.node.SelfNode(), nil
+}
+
+func (p *P2PModular) HandleQueryUnhealthySp(ctx context.Context) ([]string, error) {
+	return p.node.PeersProvider().UnhealthySPs(), nil
+}
+
+func (p *P2PModular) HandleReplicatePiece(
+	ctx context.Context,
+	approval task.ApprovalReplicatePieceTask,
+	receive task.ReceivePieceTask,
+	data []byte) (
+	[]byte, []byte, error) {
+	integrity, signature, err := p.node.ReplicatePiece(ctx, approval, receive, data)
+	if errors.Is(err, p2pnode.ErrReplicateProtocolUnsupported) {
+		return nil, nil, ErrReplicateUnsupported
+	}
+	if errors.Is(err, p2pnode.ErrReplicateStreamFailed) {
+		log.CtxErrorw(ctx, "failed to replicate piece over p2p stream", "error", err)
+		return nil, nil, ErrReplicateTransport
+	}
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to replicate piece over p2p", "error", err)
+		return nil, nil, err
+	}
+	return integrity, signature, nil
+}
+
 func (p *P2PModular) QueryTasks(
 	ctx context.Context,
 	subKey task.TKey) (
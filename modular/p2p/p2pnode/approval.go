@@ -23,6 +23,9 @@ const GetApprovalResponse = "/approval/response/0.0.1"
 // ResponseChannelSize defines the approval response size
 const ResponseChannelSize = 12
 
+// SPExitingRefuseReason defines the reason of refusing the approval request by the exiting sp
+const SPExitingRefuseReason = "sp exiting"
+
 // ApprovalProtocol define the approval protocol and callback
 // maintains requests for getting approvals in memory
 type ApprovalProtocol struct {
@@ -144,7 +147,21 @@ func (a *ApprovalProtocol) onGetApprovalRequest(s network.Stream) {
 	}
 	log.CtxErrorw(ctx, "allow replicate piece approval", "expired_height", expiredHeight)
 	req.SetExpiredHeight(current + expiredHeight)
-	// TODO:: customized approval strategy, if refuse will fill back resp refuse field
+	if a.node.baseApp.SPExiting() {
+		// the exiting sp does not serve as secondary sp any more, it refuses rather than
+		// ignores the request, so the asking sp does not wait for it until timeout
+		log.CtxWarnw(ctx, "refuse replicate piece approval", "sp", req.GetAskSpOperatorAddress(),
+			"reason", SPExitingRefuseReason)
+		req.SetRefused(true)
+		req.SetRefuseReason(SPExitingRefuseReason)
+	} else if a.node.approvalStrategy != nil {
+		if approved, reason := a.node.approvalStrategy.Approve(ctx, req); !approved {
+			log.CtxWarnw(ctx, "refuse replicate piece approval", "sp", req.GetAskSpOperatorAddress(),
+				"reason", reason)
+			req.SetRefused(true)
+			req.SetRefuseReason(reason)
+		}
+	}
 	signature, err := a.node.baseApp.GfSpClient().SignReplicatePieceApproval(ctx, req)
 	if err != nil {
 		log.Errorw("failed to sign replicate piece approval", "local", s.Conn().LocalPeer(),
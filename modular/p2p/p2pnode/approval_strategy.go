@@ -0,0 +1,217 @@
+package p2pnode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	corercmgr "github.com/bnb-chain/greenfield-storage-provider/core/rcmgr"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+const (
+	// DefaultApprovalQuotaWindow defines the default quota window in seconds of the
+	// approvals for each asking SP.
+	DefaultApprovalQuotaWindow = 60
+)
+
+// SecondaryApprovalStrategy decides whether the SP approves the replicate piece request
+// as secondary SP, the refused reason is filled back to the asking SP, so the asking SP
+// can move on without waiting for the timeout.
+type SecondaryApprovalStrategy interface {
+	// Approve returns true if the SP accepts the request, otherwise returns the reason.
+	Approve(ctx context.Context, task coretask.ApprovalReplicatePieceTask) (bool, string)
+}
+
+var _ SecondaryApprovalStrategy = &DefaultSecondaryApprovalStrategy{}
+
+// DefaultSecondaryApprovalStrategy refuses the requests of the denied SPs and the SPs
+// exceeding the quota, and refuses all requests if the local resource, the receiver
+// queue or the piece store capacity is insufficient.
+type DefaultSecondaryApprovalStrategy struct {
+	baseApp *gfspapp.GfSpBaseApp
+
+	denySPs              map[string]struct{}
+	maxApprovalPerSP     int
+	approvalQuotaWindow  int64
+	receiverEndpoint     string
+	maxReceiveQueueDepth int
+	storePath            string
+	minFreeStoreSize     uint64
+	minRemainingMemory   int64
+
+	quotas map[string]*approvalQuota
+	mux    sync.Mutex
+}
+
+type approvalQuota struct {
+	windowStart int64
+	count       int
+}
+
+// SecondaryApprovalOption defines the option of DefaultSecondaryApprovalStrategy.
+type SecondaryApprovalOption func(*DefaultSecondaryApprovalStrategy)
+
+// WithDenySPs refuses the requests of the SP operator addresses.
+func WithDenySPs(sps []string) SecondaryApprovalOption {
+	return func(s *DefaultSecondaryApprovalStrategy) {
+		for _, sp := range sps {
+			s.denySPs[strings.ToLower(sp)] = struct{}{}
+		}
+	}
+}
+
+// WithApprovalQuota limits the number of approvals for each asking SP in the window.
+func WithApprovalQuota(maxApproval int, window int) SecondaryApprovalOption {
+	return func(s *DefaultSecondaryApprovalStrategy) {
+		if window <= 0 {
+			window = DefaultApprovalQuotaWindow
+		}
+		s.maxApprovalPerSP = maxApproval
+		s.approvalQuotaWindow = int64(window)
+	}
+}
+
+// WithMaxReceiveQueueDepth refuses the requests if the receiving piece tasks of the
+// receiver reach the depth.
+func WithMaxReceiveQueueDepth(endpoint string, depth int) SecondaryApprovalOption {
+	return func(s *DefaultSecondaryApprovalStrategy) {
+		s.receiverEndpoint = endpoint
+		s.maxReceiveQueueDepth = depth
+	}
+}
+
+// WithMinFreeStoreSize refuses the requests if the free bytes of the file system that
+// the piece store path locates are less than the size.
+func WithMinFreeStoreSize(path string, size uint64) SecondaryApprovalOption {
+	return func(s *DefaultSecondaryApprovalStrategy) {
+		s.storePath = path
+		s.minFreeStoreSize = size
+	}
+}
+
+// WithMinRemainingMemory refuses the requests if the remaining memory of the resource
+// manager system scope is less than the value.
+func WithMinRemainingMemory(memory int64) SecondaryApprovalOption {
+	return func(s *DefaultSecondaryApprovalStrategy) {
+		s.minRemainingMemory = memory
+	}
+}
+
+// NewDefaultSecondaryApprovalStrategy returns an instance of DefaultSecondaryApprovalStrategy.
+func NewDefaultSecondaryApprovalStrategy(baseApp *gfspapp.GfSpBaseApp,
+	opts ...SecondaryApprovalOption) *DefaultSecondaryApprovalStrategy {
+	s := &DefaultSecondaryApprovalStrategy{
+		baseApp:             baseApp,
+		denySPs:             make(map[string]struct{}),
+		approvalQuotaWindow: DefaultApprovalQuotaWindow,
+		quotas:              make(map[string]*approvalQuota),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Approve checks the deny list and the local capacity before the quota, so the refused
+// requests do not consume the quota of the asking SP.
+func (s *DefaultSecondaryApprovalStrategy) Approve(ctx context.Context,
+	task coretask.ApprovalReplicatePieceTask) (bool, string) {
+	askSP := strings.ToLower(task.GetAskSpOperatorAddress())
+	if _, ok := s.denySPs[askSP]; ok {
+		return false, "sp is denied"
+	}
+	if reason := s.checkResource(); reason != "" {
+		return false, reason
+	}
+	if reason := s.checkReceiveQueue(ctx); reason != "" {
+		return false, reason
+	}
+	if reason := s.checkStoreCapacity(); reason != "" {
+		return false, reason
+	}
+	if !s.takeQuota(askSP) {
+		return false, "sp exceeds the approval quota"
+	}
+	return true, ""
+}
+
+func (s *DefaultSecondaryApprovalStrategy) checkResource() string {
+	if s.minRemainingMemory <= 0 {
+		return ""
+	}
+	var remaining int64
+	err := s.baseApp.ResourceManager().ViewSystem(func(scope corercmgr.ResourceScope) error {
+		limit, err := scope.RemainingResource()
+		if err != nil {
+			return err
+		}
+		remaining = limit.GetMemoryLimit()
+		return nil
+	})
+	if err != nil {
+		log.Warnw("failed to view system remaining resource", "error", err)
+		return ""
+	}
+	if remaining < s.minRemainingMemory {
+		return fmt.Sprintf("insufficient memory, remaining: %d", remaining)
+	}
+	return ""
+}
+
+func (s *DefaultSecondaryApprovalStrategy) checkReceiveQueue(ctx context.Context) string {
+	if s.maxReceiveQueueDepth <= 0 || s.receiverEndpoint == "" {
+		return ""
+	}
+	tasks, err := s.baseApp.GfSpClient().QueryTasks(ctx, s.receiverEndpoint,
+		gfsptask.KeyPrefixGfSpReceivePieceTask)
+	if err != nil {
+		log.CtxWarnw(ctx, "failed to query receive piece tasks", "error", err)
+		return ""
+	}
+	if len(tasks) >= s.maxReceiveQueueDepth {
+		return fmt.Sprintf("receiver is busy, receiving: %d", len(tasks))
+	}
+	return ""
+}
+
+func (s *DefaultSecondaryApprovalStrategy) checkStoreCapacity() string {
+	if s.minFreeStoreSize == 0 || s.storePath == "" {
+		return ""
+	}
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(s.storePath, &stat); err != nil {
+		log.Warnw("failed to stat piece store file system", "path", s.storePath, "error", err)
+		return ""
+	}
+	free := uint64(stat.Bavail) * uint64(stat.Bsize)
+	if free < s.minFreeStoreSize {
+		return fmt.Sprintf("insufficient piece store capacity, free: %d", free)
+	}
+	return ""
+}
+
+func (s *DefaultSecondaryApprovalStrategy) takeQuota(askSP string) bool {
+	if s.maxApprovalPerSP <= 0 {
+		return true
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	now := time.Now().Unix()
+	quota, ok := s.quotas[askSP]
+	if !ok || now-quota.windowStart >= s.approvalQuotaWindow {
+		quota = &approvalQuota{windowStart: now}
+		s.quotas[askSP] = quota
+	}
+	if quota.count >= s.maxApprovalPerSP {
+		return false
+	}
+	quota.count++
+	return true
+}
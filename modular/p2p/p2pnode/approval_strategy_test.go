@@ -0,0 +1,33 @@
+package p2pnode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+)
+
+func TestDefaultSecondaryApprovalStrategy_Approve(t *testing.T) {
+	s := NewDefaultSecondaryApprovalStrategy(nil, WithDenySPs([]string{"0xDeny"}), WithApprovalQuota(2, 0))
+	ctx := context.Background()
+
+	denied := &gfsptask.GfSpReplicatePieceApprovalTask{AskSpOperatorAddress: "0xdeny"}
+	approved, reason := s.Approve(ctx, denied)
+	assert.False(t, approved)
+	assert.Equal(t, "sp is denied", reason)
+
+	task := &gfsptask.GfSpReplicatePieceApprovalTask{AskSpOperatorAddress: "0xAsk"}
+	for i := 0; i < 2; i++ {
+		approved, _ = s.Approve(ctx, task)
+		assert.True(t, approved)
+	}
+	approved, reason = s.Approve(ctx, task)
+	assert.False(t, approved)
+	assert.Equal(t, "sp exceeds the approval quota", reason)
+
+	other := &gfsptask.GfSpReplicatePieceApprovalTask{AskSpOperatorAddress: "0xOther"}
+	approved, _ = s.Approve(ctx, other)
+	assert.True(t, approved)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"strings"
+	"sync"
 	"time"
 
 	ggio "github.com/gogo/protobuf/io"
@@ -25,6 +26,8 @@ import (
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
 	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/metrics"
+	sptypes "github.com/bnb-chain/greenfield/x/sp/types"
 )
 
 // Node defines the p2p protocol node, encapsulates the go-lib.p2p
@@ -37,8 +40,15 @@ type Node struct {
 	persistentDB ds.Batching
 	approval     *ApprovalProtocol
 	stopCh       chan struct{}
+	// pingSent records the time of sending ping to the peer, it is used to measure
+	// the ping latency when receiving pong
+	pingSent sync.Map
+	// approvalStrategy decides whether to approve the replicate piece request as
+	// secondary SP, nil means approving all the valid requests.
+	approvalStrategy SecondaryApprovalStrategy
 
 	p2pPrivateKey                  crypto.PrivKey
+	p2pAddress                     string
 	p2pProtocolAddress             ma.Multiaddr
 	p2pPingPeriod                  int
 	secondaryApprovalExpiredHeight uint64
@@ -116,6 +126,8 @@ func NewNode(baseApp *gfspapp.GfSpBaseApp, privateKey string, address string,
 	if err != nil {
 		return nil, err
 	}
+	// the bootstrap nodes are optional, the peers are also restored from the persisted
+	// peers and discovered from the storage providers on greenfield
 	for i, addr := range bootstrapAddrs {
 		host.Peerstore().AddAddr(bootstrapIDs[i], addr, peerstore.PermanentAddrTTL)
 	}
@@ -126,6 +138,7 @@ func NewNode(baseApp *gfspapp.GfSpBaseApp, privateKey string, address string,
 		peers:                          NewPeerProvider(store),
 		persistentDB:                   ds,
 		p2pPrivateKey:                  privKey,
+		p2pAddress:                     address,
 		p2pProtocolAddress:             hostAddr,
 		p2pPingPeriod:                  pingPeriod,
 		p2pBootstrap:                   bootstrap,
@@ -144,30 +157,57 @@ func (n *Node) initProtocol() {
 	n.node.SetStreamHandler(PongProtocol, n.onPong)
 	// approval protocol
 	n.approval = NewApprovalProtocol(n)
+	// replicate piece protocol
+	n.node.SetStreamHandler(ReplicatePieceProtocol, n.onReplicatePiece)
 }
 
 func (n *Node) Bootstrap() []string {
 	return n.p2pBootstrap
 }
 
+// SelfNode returns the node info of self in the bootstrap node format, the ant address
+// is preferred if it is configured.
+func (n *Node) SelfNode() string {
+	address := n.p2pAddress
+	if len(n.p2pAntAddress) > 0 {
+		address = n.p2pAntAddress
+	}
+	return n.node.ID().String() + "@" + address
+}
+
 // Name return the p2p protocol node name
 func (n *Node) Name() string {
 	return P2PNode
 }
 
-// Start runs background task that trigger broadcast ping request
+// Start restores the persisted peers and runs background task that trigger broadcast
+// ping request
 func (n *Node) Start(ctx context.Context) error {
+	peers, err := n.baseApp.GfSpDB().ListP2PPeers()
+	if err != nil {
+		log.CtxWarnw(ctx, "failed to list persisted p2p peers", "error", err)
+	} else {
+		n.peers.Restore(peers)
+		log.CtxInfow(ctx, "succeed to restore p2p peers", "peers", len(peers))
+	}
 	go n.eventLoop()
 	return nil
 }
 
-// Stop recycle the resources and termination background goroutine
+// Stop persists the peers, recycle the resources and termination background goroutine
 func (n *Node) Stop(ctx context.Context) error {
 	close(n.stopCh)
+	n.persistPeers()
 	n.persistentDB.Close()
 	return nil
 }
 
+// SetSecondaryApprovalStrategy sets the strategy of approving the replicate piece
+// requests as secondary SP.
+func (n *Node) SetSecondaryApprovalStrategy(strategy SecondaryApprovalStrategy) {
+	n.approvalStrategy = strategy
+}
+
 // PeersProvider returns the p2p peers provider
 func (n *Node) PeersProvider() *PeerProvider {
 	return n.peers
@@ -194,12 +234,25 @@ func (n *Node) GetSecondaryReplicatePieceApproval(
 		return
 	}
 	task.SetAskSignature(signature)
-	n.broadcast(ctx, GetApprovalRequest, task.(*gfsptask.GfSpReplicatePieceApprovalTask))
+	asked := n.broadcast(ctx, GetApprovalRequest, task.(*gfsptask.GfSpReplicatePieceApprovalTask))
 	approvalCtx, cancelFunc := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancelFunc()
+	var refused int
 	for {
 		select {
 		case approval := <-approvalCh:
+			if approval.GetRefused() {
+				refused++
+				log.CtxWarnw(ctx, "secondary sp refused approval", "sp", approval.GetApprovedSpOperatorAddress(),
+					"reason", approval.GetRefuseReason())
+				// the rest of the asked sps are not enough, no need to wait for the timeout
+				if asked-refused < expectedAccept {
+					log.CtxWarnw(ctx, "failed to get sufficient approvals, too many refused", "expect",
+						expectedAccept, "asked", asked, "refused", refused, "accepted", len(accept))
+					return
+				}
+				continue
+			}
 			current, innerErr := n.baseApp.Consensus().CurrentHeight(approvalCtx)
 			if innerErr == nil {
 				if approval.GetExpiredHeight() < current {
@@ -228,10 +281,23 @@ func (n *Node) GetSecondaryReplicatePieceApproval(
 // eventLoop run the background task
 func (n *Node) eventLoop() {
 	ticker := time.NewTicker(time.Duration(n.p2pPingPeriod) * time.Second)
+	scoreTicker := time.NewTicker(SPScoreSyncInterval * time.Second)
+	defer scoreTicker.Stop()
+	persistTicker := time.NewTicker(PeerPersistInterval * time.Second)
+	defer persistTicker.Stop()
+	discoverTicker := time.NewTicker(PeerDiscoverInterval * time.Second)
+	defer discoverTicker.Stop()
+	n.discoverPeers(context.Background())
 	for {
 		select {
 		case <-n.stopCh:
 			return
+		case <-scoreTicker.C:
+			n.syncSPScores()
+		case <-persistTicker.C:
+			n.persistPeers()
+		case <-discoverTicker.C:
+			n.discoverPeers(context.Background())
 		case <-ticker.C:
 			ping := &gfspp2p.GfSpPing{
 				SpOperatorAddress: n.baseApp.OperateAddress(),
@@ -249,11 +315,13 @@ func (n *Node) eventLoop() {
 	}
 }
 
-// broadcast sends request to all p2p nodes
+// broadcast sends request to all p2p nodes, returns the number of the nodes that
+// the request is sent to
 func (n *Node) broadcast(
 	ctx context.Context,
 	pc protocol.ID,
-	data proto.Message) {
+	data proto.Message) int {
+	var sent int
 	for _, peerID := range n.node.Peerstore().PeersWithAddrs() {
 		if strings.Compare(n.node.ID().String(), peerID.String()) == 0 {
 			continue
@@ -262,8 +330,11 @@ func (n *Node) broadcast(
 		//for _, addr := range addrs {
 		//	log.CtxErrorw(ctx, "broadcast", "protocol", pc, "peer_addr", addr.String())
 		//}
-		n.sendToPeer(ctx, peerID, pc, data)
+		if err := n.sendToPeer(ctx, peerID, pc, data); err == nil {
+			sent++
+		}
 	}
+	return sent
 }
 
 // sendToPeer sends request to all special p2p node
@@ -297,5 +368,60 @@ func (n *Node) sendToPeer(
 		return err
 	}
 	s.Close()
+	if pc == PingProtocol {
+		n.pingSent.Store(peerID, time.Now())
+	}
 	return err
 }
+
+// syncSPScores records the ping latency and the p2p failures of the storage providers
+// to their scores.
+func (n *Node) syncSPScores() {
+	for sp, stat := range n.peers.SPPeerStats() {
+		score, err := n.baseApp.GfSpDB().UpdateSPP2PScore(sp, stat.PingRTT.Milliseconds(), int64(stat.Failure))
+		if err != nil {
+			log.Warnw("failed to update sp p2p score", "sp", sp, "error", err)
+			continue
+		}
+		metrics.SPScoreGauge.WithLabelValues(sp).Set(score.Score())
+	}
+}
+
+// persistPeers persists the peers to restore them when the node restarts.
+func (n *Node) persistPeers() {
+	if err := n.baseApp.GfSpDB().SetP2PPeers(n.peers.Snapshot()); err != nil {
+		log.Warnw("failed to persist p2p peers", "error", err)
+	}
+}
+
+// discoverPeers queries the p2p node info of the storage providers on greenfield that
+// have no known peer by their endpoints, and adds the nodes to the peer store, the
+// storage providers of the nodes are verified by the signed ping and pong.
+func (n *Node) discoverPeers(ctx context.Context) {
+	spList, err := n.baseApp.Consensus().QuerySPInfo(ctx)
+	if err != nil {
+		log.CtxWarnw(ctx, "failed to query sp info for discovering peers", "error", err)
+		return
+	}
+	var discovered int
+	for _, sp := range spList {
+		if sp.GetOperatorAddress() == n.baseApp.OperateAddress() ||
+			sp.GetStatus() != sptypes.STATUS_IN_SERVICE ||
+			len(n.peers.SPPeers(sp.GetOperatorAddress())) != 0 {
+			continue
+		}
+		node, err := n.baseApp.GfSpClient().QueryP2PNodeFromSP(ctx, sp.GetEndpoint())
+		if err != nil {
+			log.CtxDebugw(ctx, "failed to query p2p node from sp", "sp", sp.GetOperatorAddress(),
+				"endpoint", sp.GetEndpoint(), "error", err)
+			continue
+		}
+		peerIDs, addrs, err := MakeBootstrapMultiaddr([]string{node})
+		if err != nil || peerIDs[0] == n.node.ID() {
+			continue
+		}
+		n.node.Peerstore().AddAddr(peerIDs[0], addrs[0], peerstore.PermanentAddrTTL)
+		discovered++
+	}
+	log.CtxDebugw(ctx, "finish discovering peers", "discovered", discovered)
+}
@@ -30,6 +30,14 @@ const (
 	// MinSecondaryApprovalExpiredHeight defines the min expired height for secondary
 	// approval
 	MinSecondaryApprovalExpiredHeight = 900
+	// SPScoreSyncInterval defines the interval in seconds of recording the p2p stats of
+	// the storage providers to their scores
+	SPScoreSyncInterval = 60
+	// PeerPersistInterval defines the interval in seconds of persisting the peers
+	PeerPersistInterval = 60
+	// PeerDiscoverInterval defines the interval in seconds of discovering the peers of
+	// the storage providers on greenfield
+	PeerDiscoverInterval = 300
 )
 
 // MakeMultiaddr new multi addr by address
@@ -9,6 +9,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/peerstore"
 	ma "github.com/multiformats/go-multiaddr"
 
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 	"github.com/bnb-chain/greenfield-storage-provider/util/maps"
 )
@@ -20,6 +21,9 @@ const (
 	PrunePeersNumberMax = 10
 	// PeerSpUnspecified defines default sp operator address
 	PeerSpUnspecified = "PEER_SP_UNSPECIFIED"
+	// SpUnhealthyFailureMin defines the min continuous failures of all peers of the sp
+	// to judge the sp is unhealthy
+	SpUnhealthyFailureMin = 3
 )
 
 // Peer defines the peer info in memory
@@ -178,6 +182,169 @@ func (pr *PeerProvider) prunePeers() {
 	pr.spPeers = sp2Peers
 }
 
+// UnhealthySPs returns the storage providers that all the peers continuously fail to
+// interact, the storage provider without any known peer is not judged.
+func (pr *PeerProvider) UnhealthySPs() []string {
+	pr.mux.RLock()
+	defer pr.mux.RUnlock()
+	var unhealthy []string
+	for _, sp := range maps.SortKeys(pr.spPeers) {
+		peers := pr.spPeers[sp]
+		if sp == PeerSpUnspecified || len(peers) == 0 {
+			continue
+		}
+		healthy := false
+		for _, p := range peers {
+			if p.failCnt < SpUnhealthyFailureMin {
+				healthy = true
+				break
+			}
+		}
+		if !healthy {
+			unhealthy = append(unhealthy, sp)
+		}
+	}
+	return unhealthy
+}
+
+// SPPeers returns the peers of the storage provider, the peers with fewer continuous
+// failures are in front.
+func (pr *PeerProvider) SPPeers(sp string) []peer.ID {
+	pr.mux.RLock()
+	defer pr.mux.RUnlock()
+	peers := make([]*Peer, len(pr.spPeers[sp]))
+	copy(peers, pr.spPeers[sp])
+	sort.SliceStable(peers, func(i, j int) bool {
+		return peers[i].failCnt < peers[j].failCnt
+	})
+	peerIDs := make([]peer.ID, 0, len(peers))
+	for _, p := range peers {
+		peerIDs = append(peerIDs, p.peerID)
+	}
+	return peerIDs
+}
+
+// Snapshot returns the peers of the storage providers to persist, the addresses of the
+// peer are from the peer store if there is.
+func (pr *PeerProvider) Snapshot() []*corespdb.P2PPeer {
+	pr.mux.RLock()
+	defer pr.mux.RUnlock()
+	var snapshot []*corespdb.P2PPeer
+	for _, sp := range maps.SortKeys(pr.spPeers) {
+		for _, p := range pr.spPeers[sp] {
+			persisted := &corespdb.P2PPeer{
+				PeerID:    p.peerID.String(),
+				FailCount: p.failCnt,
+				LastSeen:  p.last,
+			}
+			if sp != PeerSpUnspecified {
+				persisted.SpAddress = sp
+			}
+			var addrs []ma.Multiaddr
+			if pr.peerStore != nil {
+				addrs = pr.peerStore.Addrs(p.peerID)
+			}
+			if len(addrs) == 0 && p.addr != nil {
+				addrs = []ma.Multiaddr{p.addr}
+			}
+			for _, addr := range addrs {
+				persisted.Addrs = append(persisted.Addrs, addr.String())
+			}
+			snapshot = append(snapshot, persisted)
+		}
+	}
+	return snapshot
+}
+
+// Restore adds the persisted peers and their addresses to the peer store, the storage
+// providers of the peers are kept until the storage providers are updated.
+func (pr *PeerProvider) Restore(persisted []*corespdb.P2PPeer) {
+	pr.mux.Lock()
+	defer pr.mux.Unlock()
+	for _, p := range persisted {
+		peerID, err := peer.Decode(p.PeerID)
+		if err != nil {
+			log.Warnw("failed to decode persisted peer id", "peer_id", p.PeerID, "error", err)
+			continue
+		}
+		if _, ok := pr.peers[peerID]; ok {
+			continue
+		}
+		var addrs []ma.Multiaddr
+		for _, a := range p.Addrs {
+			addr, err := ma.NewMultiaddr(a)
+			if err != nil {
+				log.Warnw("failed to parse persisted peer addr", "peer_id", p.PeerID, "addr", a, "error", err)
+				continue
+			}
+			addrs = append(addrs, addr)
+		}
+		if len(addrs) == 0 {
+			continue
+		}
+		if pr.peerStore != nil {
+			pr.peerStore.AddAddrs(peerID, addrs, peerstore.PermanentAddrTTL)
+		}
+		sp := p.SpAddress
+		if sp == "" {
+			sp = PeerSpUnspecified
+		}
+		node := &Peer{
+			peerID:  peerID,
+			sp:      sp,
+			addr:    addrs[0],
+			last:    p.LastSeen,
+			failCnt: p.FailCount,
+		}
+		pr.peers[peerID] = node
+		pr.spPeers[sp] = append(pr.spPeers[sp], node)
+	}
+}
+
+// SPPeerStat defines the p2p interaction stat of the storage provider, it is used to
+// score the storage provider.
+type SPPeerStat struct {
+	// PingRTT is the average ping latency of the peers that have been measured.
+	PingRTT time.Duration
+	// Failure is the min continuous fail count of the peers.
+	Failure int
+}
+
+// SPPeerStats returns the p2p interaction stats of the storage providers that have
+// known peers.
+func (pr *PeerProvider) SPPeerStats() map[string]*SPPeerStat {
+	pr.mux.RLock()
+	defer pr.mux.RUnlock()
+	stats := make(map[string]*SPPeerStat)
+	for sp, peers := range pr.spPeers {
+		if sp == PeerSpUnspecified || len(peers) == 0 {
+			continue
+		}
+		var (
+			stat     = &SPPeerStat{Failure: peers[0].failCnt}
+			total    time.Duration
+			measured int
+		)
+		for _, p := range peers {
+			if p.failCnt < stat.Failure {
+				stat.Failure = p.failCnt
+			}
+			if pr.peerStore == nil {
+				continue
+			}
+			if latency := pr.peerStore.LatencyEWMA(p.peerID); latency > 0 {
+				total += latency
+				measured++
+			}
+		}
+		if measured != 0 {
+			stat.PingRTT = total / time.Duration(measured)
+		}
+		stats[sp] = stat
+	}
+	return stats
+}
+
 // deletePeers deletes the peer from store
 // notice: no lock for delete peers, only be called by prunePeers
 func (pr *PeerProvider) deletePeers(peers []peer.ID) {
@@ -0,0 +1,84 @@
+package p2pnode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerProvider_UnhealthySPs(t *testing.T) {
+	pr := NewPeerProvider(nil)
+	pr.spPeers = map[string][]*Peer{
+		PeerSpUnspecified: {{peerID: peer.ID("p0"), failCnt: SpUnhealthyFailureMin}},
+		"sp_healthy": {
+			{peerID: peer.ID("p1"), failCnt: SpUnhealthyFailureMin},
+			{peerID: peer.ID("p2"), failCnt: 0},
+		},
+		"sp_unhealthy_b": {{peerID: peer.ID("p3"), failCnt: SpUnhealthyFailureMin}},
+		"sp_unhealthy_a": {
+			{peerID: peer.ID("p4"), failCnt: SpUnhealthyFailureMin},
+			{peerID: peer.ID("p5"), failCnt: SpUnhealthyFailureMin + 1},
+		},
+		"sp_without_peer": {},
+	}
+	assert.Equal(t, []string{"sp_unhealthy_a", "sp_unhealthy_b"}, pr.UnhealthySPs())
+}
+
+func TestPeerProvider_SPPeerStats(t *testing.T) {
+	pr := NewPeerProvider(nil)
+	pr.spPeers = map[string][]*Peer{
+		PeerSpUnspecified: {{peerID: peer.ID("p0"), failCnt: 1}},
+		"sp_a": {
+			{peerID: peer.ID("p1"), failCnt: 2},
+			{peerID: peer.ID("p2"), failCnt: 1},
+		},
+		"sp_without_peer": {},
+	}
+	stats := pr.SPPeerStats()
+	assert.Equal(t, 1, len(stats))
+	assert.Equal(t, 1, stats["sp_a"].Failure)
+	assert.Equal(t, time.Duration(0), stats["sp_a"].PingRTT)
+}
+
+func TestPeerProvider_SPPeers(t *testing.T) {
+	pr := NewPeerProvider(nil)
+	pr.spPeers = map[string][]*Peer{
+		"sp_a": {
+			{peerID: peer.ID("p1"), failCnt: 2},
+			{peerID: peer.ID("p2"), failCnt: 0},
+			{peerID: peer.ID("p3"), failCnt: 1},
+		},
+	}
+	assert.Equal(t, []peer.ID{"p2", "p3", "p1"}, pr.SPPeers("sp_a"))
+	assert.Equal(t, peer.ID("p1"), pr.spPeers["sp_a"][0].peerID)
+	assert.Empty(t, pr.SPPeers("sp_unknown"))
+}
+
+func TestPeerProvider_SnapshotRestore(t *testing.T) {
+	privKey, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	assert.Nil(t, err)
+	peerID, err := peer.IDFromPrivateKey(privKey)
+	assert.Nil(t, err)
+	addr, err := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/9933")
+	assert.Nil(t, err)
+
+	pr := NewPeerProvider(nil)
+	pr.UpdateSp([]string{"sp_a"})
+	pr.AddPeer(peerID, "sp_a", addr)
+	pr.DeletePeer(peerID)
+	snapshot := pr.Snapshot()
+	assert.Equal(t, 1, len(snapshot))
+	assert.Equal(t, peerID.String(), snapshot[0].PeerID)
+	assert.Equal(t, "sp_a", snapshot[0].SpAddress)
+	assert.Equal(t, []string{"/ip4/127.0.0.1/tcp/9933"}, snapshot[0].Addrs)
+	assert.Equal(t, 1, snapshot[0].FailCount)
+
+	restored := NewPeerProvider(nil)
+	restored.Restore(snapshot)
+	assert.Equal(t, []peer.ID{peerID}, restored.SPPeers("sp_a"))
+	assert.Equal(t, snapshot, restored.Snapshot())
+}
@@ -3,6 +3,7 @@ package p2pnode
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/libp2p/go-libp2p/core/network"
@@ -129,6 +130,9 @@ func (n *Node) onPong(s network.Stream) {
 		return
 	}
 	n.peers.AddPeer(peerID, pong.SpOperatorAddress, s.Conn().RemoteMultiaddr())
+	if sent, ok := n.pingSent.LoadAndDelete(peerID); ok {
+		n.node.Peerstore().RecordLatency(peerID, time.Since(sent.(time.Time)))
+	}
 
 	for _, node := range pong.Nodes {
 		pID, err := peer.Decode(node.NodeId)
@@ -0,0 +1,288 @@
+package p2pnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ggio "github.com/gogo/protobuf/io"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfspp2p"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsptask"
+	coretask "github.com/bnb-chain/greenfield-storage-provider/core/task"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+)
+
+// ReplicatePieceProtocol defines the protocol of replicating piece to the secondary SP,
+// the primary SP sends the header and the piece data by chunks in one stream, and waits
+// for the ack every chunk window, the secondary SP responses the result at the end.
+const ReplicatePieceProtocol = "/replicate/piece/0.0.1"
+
+const (
+	// ReplicatePieceChunkSize defines the max bytes of a piece data chunk
+	ReplicatePieceChunkSize = 1024 * 1024
+	// ReplicatePieceChunkWindow defines the number of chunks that can be sent before
+	// receiving the ack
+	ReplicatePieceChunkWindow = 4
+	// ReplicatePieceMsgMaxSize defines the max bytes of a message in the stream
+	ReplicatePieceMsgMaxSize = 2 * ReplicatePieceChunkSize
+	// ReplicatePieceStreamTimeout defines the default timeout in seconds of the stream
+	ReplicatePieceStreamTimeout = 60
+)
+
+var (
+	// ErrReplicateProtocolUnsupported is returned if no peer of the SP supports the
+	// replicate piece protocol, the caller should fall back to the http transport.
+	ErrReplicateProtocolUnsupported = errors.New("no peer of the sp supports replicate piece protocol")
+	// ErrReplicateStreamFailed is returned if the piece fails to be sent over the streams
+	// of all the peers, unlike the result error responded by the secondary SP, the caller
+	// may retry the piece by the other transport.
+	ErrReplicateStreamFailed     = errors.New("failed to replicate piece over p2p stream")
+	errReplicatePieceMismatchSp  = errors.New("replicate piece approval sp mismatch")
+	errReplicatePieceExpired     = errors.New("replicate piece approval expired")
+	errReplicatePieceInvalidTask = errors.New("replicate piece task params error")
+	errReplicatePieceInvalidSize = errors.New("replicate piece size mismatch")
+)
+
+// ReplicatePiece replicates the piece to the secondary SP by the peers that support the
+// replicate piece protocol, the piece index of the receive task less than zero means
+// done replicate piece, and the integrity hash and the signature are returned.
+func (n *Node) ReplicatePiece(
+	ctx context.Context,
+	approval coretask.ApprovalReplicatePieceTask,
+	receive coretask.ReceivePieceTask,
+	data []byte) (
+	[]byte, []byte, error) {
+	peerIDs := n.replicatePeers(approval.GetApprovedSpOperatorAddress())
+	if len(peerIDs) == 0 {
+		return nil, nil, ErrReplicateProtocolUnsupported
+	}
+	header := &gfspp2p.GfSpReplicatePieceHeader{
+		Approval:    approval.(*gfsptask.GfSpReplicatePieceApprovalTask),
+		ReceiveTask: receive.(*gfsptask.GfSpReceivePieceTask),
+		PieceSize:   uint64(len(data)),
+		ChunkWindow: ReplicatePieceChunkWindow,
+	}
+	var err error
+	for _, peerID := range peerIDs {
+		var result *gfspp2p.GfSpReplicatePieceResult
+		result, err = n.replicatePieceToPeer(ctx, peerID, header, data)
+		if err != nil {
+			log.CtxWarnw(ctx, "failed to replicate piece to peer", "peer_id", peerID,
+				"sp", approval.GetApprovedSpOperatorAddress(), "error", err)
+			n.peers.DeletePeer(peerID)
+			continue
+		}
+		if result.GetErr() != nil {
+			return nil, nil, result.GetErr()
+		}
+		return result.GetIntegrityHash(), result.GetSignature(), nil
+	}
+	return nil, nil, fmt.Errorf("%w: %v", ErrReplicateStreamFailed, err)
+}
+
+// replicatePeers returns the peers of the SP that support the replicate piece protocol,
+// the protocols of the peer are negotiated by the libp2p identify service.
+func (n *Node) replicatePeers(sp string) []peer.ID {
+	var peerIDs []peer.ID
+	for _, peerID := range n.peers.SPPeers(sp) {
+		protocols, err := n.node.Peerstore().SupportsProtocols(peerID, ReplicatePieceProtocol)
+		if err != nil || len(protocols) == 0 {
+			continue
+		}
+		peerIDs = append(peerIDs, peerID)
+	}
+	return peerIDs
+}
+
+func (n *Node) replicatePieceToPeer(
+	ctx context.Context,
+	peerID peer.ID,
+	header *gfspp2p.GfSpReplicatePieceHeader,
+	data []byte) (
+	*gfspp2p.GfSpReplicatePieceResult, error) {
+	s, err := n.node.NewStream(ctx, peerID, ReplicatePieceProtocol)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(ReplicatePieceStreamTimeout * time.Second)
+	}
+	if err = s.SetDeadline(deadline); err != nil {
+		s.Reset()
+		return nil, err
+	}
+	writer := ggio.NewDelimitedWriter(s)
+	reader := ggio.NewDelimitedReader(s, ReplicatePieceMsgMaxSize)
+	result, err := writeReplicatePiece(reader, writer, header, data)
+	if err != nil {
+		s.Reset()
+		return nil, err
+	}
+	return result, nil
+}
+
+// writeReplicatePiece sends the header and the piece data by chunks, waits for the ack
+// every chunk window, and reads the result responded by the receiver.
+func writeReplicatePiece(
+	reader ggio.Reader,
+	writer ggio.Writer,
+	header *gfspp2p.GfSpReplicatePieceHeader,
+	data []byte) (
+	*gfspp2p.GfSpReplicatePieceResult, error) {
+	if err := writer.WriteMsg(header); err != nil {
+		return nil, err
+	}
+	var chunks uint32
+	for offset := 0; offset < len(data); offset += ReplicatePieceChunkSize {
+		end := offset + ReplicatePieceChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := writer.WriteMsg(&gfspp2p.GfSpReplicatePieceChunk{Data: data[offset:end]}); err != nil {
+			return nil, err
+		}
+		chunks++
+		// wait for the receiver to consume the window before sending the rest chunks
+		if chunks%header.GetChunkWindow() == 0 && end < len(data) {
+			ack := &gfspp2p.GfSpReplicatePieceAck{}
+			if err := reader.ReadMsg(ack); err != nil {
+				return nil, err
+			}
+			if ack.GetReceived() != uint64(end) {
+				return nil, fmt.Errorf("replicate piece ack mismatch, sent: %d, received: %d",
+					end, ack.GetReceived())
+			}
+		}
+	}
+	result := &gfspp2p.GfSpReplicatePieceResult{}
+	if err := reader.ReadMsg(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// onReplicatePiece defines the replicate piece protocol callback, the piece is verified
+// as the gateway does for the http transport, and handed over to the receiver.
+func (n *Node) onReplicatePiece(s network.Stream) {
+	defer s.Close()
+	if err := s.SetDeadline(time.Now().Add(ReplicatePieceStreamTimeout * time.Second)); err != nil {
+		log.Errorw("failed to set replicate piece stream deadline", "error", err)
+		s.Reset()
+		return
+	}
+	reader := ggio.NewDelimitedReader(s, ReplicatePieceMsgMaxSize)
+	writer := ggio.NewDelimitedWriter(s)
+	result := &gfspp2p.GfSpReplicatePieceResult{}
+	integrity, signature, err := n.receiveReplicatePiece(context.Background(), reader, writer)
+	if err != nil {
+		log.Errorw("failed to receive replicate piece", "remote", s.Conn().RemotePeer(), "error", err)
+		result.Err = gfsperrors.MakeGfSpError(err)
+	} else {
+		result.IntegrityHash = integrity
+		result.Signature = signature
+	}
+	if err = writer.WriteMsg(result); err != nil {
+		log.Errorw("failed to write replicate piece result", "remote", s.Conn().RemotePeer(), "error", err)
+		s.Reset()
+	}
+}
+
+func (n *Node) receiveReplicatePiece(
+	ctx context.Context,
+	reader ggio.Reader,
+	writer ggio.Writer) (
+	[]byte, []byte, error) {
+	header := &gfspp2p.GfSpReplicatePieceHeader{}
+	if err := reader.ReadMsg(header); err != nil {
+		return nil, nil, err
+	}
+	receive := header.GetReceiveTask()
+	if err := n.verifyReplicatePiece(ctx, header.GetApproval(), receive); err != nil {
+		return nil, nil, err
+	}
+	ctx = log.WithValue(ctx, log.CtxKeyTask, receive.Key().String())
+	if receive.GetPieceIdx() < 0 {
+		return n.baseApp.GfSpClient().DoneReplicatePiece(ctx, receive)
+	}
+	if header.GetPieceSize() != uint64(receive.GetPieceSize()) {
+		return nil, nil, errReplicatePieceInvalidSize
+	}
+	data, err := readReplicatePieceData(reader, writer, header)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = n.baseApp.GfSpClient().ReplicatePiece(ctx, receive, data); err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, nil
+}
+
+// readReplicatePieceData reads the piece data by chunks, and acks the sender every chunk
+// window until the piece size of the header is received.
+func readReplicatePieceData(
+	reader ggio.Reader,
+	writer ggio.Writer,
+	header *gfspp2p.GfSpReplicatePieceHeader) (
+	[]byte, error) {
+	window := header.GetChunkWindow()
+	if window == 0 {
+		window = ReplicatePieceChunkWindow
+	}
+	var (
+		data   = make([]byte, 0, header.GetPieceSize())
+		chunks uint32
+	)
+	for uint64(len(data)) < header.GetPieceSize() {
+		chunk := &gfspp2p.GfSpReplicatePieceChunk{}
+		if err := reader.ReadMsg(chunk); err != nil {
+			return nil, err
+		}
+		if len(chunk.GetData()) == 0 || uint64(len(data)+len(chunk.GetData())) > header.GetPieceSize() {
+			return nil, errReplicatePieceInvalidSize
+		}
+		data = append(data, chunk.GetData()...)
+		chunks++
+		if chunks%window == 0 && uint64(len(data)) < header.GetPieceSize() {
+			if err := writer.WriteMsg(&gfspp2p.GfSpReplicatePieceAck{Received: uint64(len(data))}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return data, nil
+}
+
+// verifyReplicatePiece verifies the approval is signed by this SP and not expired, the
+// receive task is verified by the signature in the receiver.
+func (n *Node) verifyReplicatePiece(
+	ctx context.Context,
+	approval *gfsptask.GfSpReplicatePieceApprovalTask,
+	receive *gfsptask.GfSpReceivePieceTask) error {
+	if approval == nil || receive == nil || receive.GetObjectInfo() == nil ||
+		int(receive.GetReplicateIdx()) >= len(receive.GetObjectInfo().GetChecksums()) {
+		return errReplicatePieceInvalidTask
+	}
+	if approval.GetApprovedSpOperatorAddress() != n.baseApp.OperateAddress() {
+		return errReplicatePieceMismatchSp
+	}
+	if err := VerifySignature(n.baseApp.OperateAddress(), approval.GetSignBytes(),
+		approval.GetApprovedSignature()); err != nil {
+		return err
+	}
+	current, err := n.baseApp.Consensus().CurrentHeight(ctx)
+	if err != nil {
+		// ignore the system's inner error, let the request go
+		log.CtxErrorw(ctx, "failed to get current block height", "error", err)
+		return nil
+	}
+	if current > approval.GetExpiredHeight() {
+		return errReplicatePieceExpired
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package p2pnode
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	ggio "github.com/gogo/protobuf/io"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
+	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfspp2p"
+)
+
+// testReplicateWindow makes the test piece span several windows to exercise the ack.
+const testReplicateWindow = 2
+
+// runReplicateReceiver runs the receiver side of the replicate piece stream on the conn,
+// the conn is closed after the receiver returns to unblock the sender.
+func runReplicateReceiver(conn net.Conn, receiver func(ggio.Reader, ggio.Writer)) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer conn.Close()
+		receiver(ggio.NewDelimitedReader(conn, ReplicatePieceMsgMaxSize), ggio.NewDelimitedWriter(conn))
+	}()
+	return done
+}
+
+func TestReplicatePieceStream(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), ReplicatePieceChunkSize*testReplicateWindow*2+100)
+	cases := []struct {
+		name string
+		// receiver plays the secondary SP on the other end of the stream
+		receiver  func(t *testing.T, reader ggio.Reader, writer ggio.Writer)
+		wantErr   bool
+		wantHash  []byte
+		remoteErr bool
+	}{
+		{
+			name: "replicate piece",
+			receiver: func(t *testing.T, reader ggio.Reader, writer ggio.Writer) {
+				header := &gfspp2p.GfSpReplicatePieceHeader{}
+				assert.NoError(t, reader.ReadMsg(header))
+				received, err := readReplicatePieceData(reader, writer, header)
+				assert.NoError(t, err)
+				assert.Equal(t, data, received)
+				assert.NoError(t, writer.WriteMsg(&gfspp2p.GfSpReplicatePieceResult{IntegrityHash: []byte("hash")}))
+			},
+			wantHash: []byte("hash"),
+		},
+		{
+			name: "secondary sp refuses piece",
+			receiver: func(t *testing.T, reader ggio.Reader, writer ggio.Writer) {
+				header := &gfspp2p.GfSpReplicatePieceHeader{}
+				assert.NoError(t, reader.ReadMsg(header))
+				_, err := readReplicatePieceData(reader, writer, header)
+				assert.NoError(t, err)
+				assert.NoError(t, writer.WriteMsg(&gfspp2p.GfSpReplicatePieceResult{
+					Err: gfsperrors.MakeGfSpError(errReplicatePieceExpired)}))
+			},
+			remoteErr: true,
+		},
+		{
+			name: "ack mismatch",
+			receiver: func(t *testing.T, reader ggio.Reader, writer ggio.Writer) {
+				header := &gfspp2p.GfSpReplicatePieceHeader{}
+				assert.NoError(t, reader.ReadMsg(header))
+				for i := 0; i < testReplicateWindow; i++ {
+					assert.NoError(t, reader.ReadMsg(&gfspp2p.GfSpReplicatePieceChunk{}))
+				}
+				assert.NoError(t, writer.WriteMsg(&gfspp2p.GfSpReplicatePieceAck{Received: 1}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "piece size exceeds header",
+			receiver: func(t *testing.T, reader ggio.Reader, writer ggio.Writer) {
+				header := &gfspp2p.GfSpReplicatePieceHeader{}
+				assert.NoError(t, reader.ReadMsg(header))
+				header.PieceSize = ReplicatePieceChunkSize - 1
+				_, err := readReplicatePieceData(reader, writer, header)
+				assert.ErrorIs(t, err, errReplicatePieceInvalidSize)
+			},
+			wantErr: true,
+		},
+		{
+			name: "stream closed before result",
+			receiver: func(t *testing.T, reader ggio.Reader, writer ggio.Writer) {
+				header := &gfspp2p.GfSpReplicatePieceHeader{}
+				assert.NoError(t, reader.ReadMsg(header))
+				_, err := readReplicatePieceData(reader, writer, header)
+				assert.NoError(t, err)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, receiver := net.Pipe()
+			defer sender.Close()
+			done := runReplicateReceiver(receiver, func(reader ggio.Reader, writer ggio.Writer) {
+				tt.receiver(t, reader, writer)
+			})
+			header := &gfspp2p.GfSpReplicatePieceHeader{
+				PieceSize:   uint64(len(data)),
+				ChunkWindow: testReplicateWindow,
+			}
+			result, err := writeReplicatePiece(ggio.NewDelimitedReader(sender, ReplicatePieceMsgMaxSize),
+				ggio.NewDelimitedWriter(sender), header, data)
+			sender.Close()
+			<-done
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.remoteErr {
+				assert.Equal(t, gfsperrors.MakeGfSpError(errReplicatePieceExpired).GetDescription(),
+					result.GetErr().GetDescription())
+				return
+			}
+			assert.Nil(t, result.GetErr())
+			assert.Equal(t, tt.wantHash, result.GetIntegrityHash())
+		})
+	}
+}
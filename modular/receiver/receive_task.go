@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/bnb-chain/greenfield-common/go/hash"
 	"github.com/bnb-chain/greenfield-storage-provider/base/types/gfsperrors"
@@ -75,6 +76,22 @@ func (r *ReceiveModular) HandleReceivePieceTask(
 		err = ErrGfSpDB
 		return ErrGfSpDB
 	}
+	if task.GetHandover() {
+		params, paramsErr := r.baseApp.QueryStorageParamsByTimestamp(ctx, task.GetObjectInfo().GetCreateAt())
+		if paramsErr != nil {
+			log.CtxErrorw(ctx, "failed to query storage params by object create time", "error", paramsErr)
+			err = paramsErr
+			return paramsErr
+		}
+		segmentCount := r.baseApp.PieceOp().SegmentCount(task.GetObjectInfo().GetPayloadSize(),
+			params.VersionedParams.GetMaxSegmentSize())
+		// record the handover pieces before storing, so the stored pieces can be gc
+		if err = r.setHandoverPiece(task, segmentCount, false); err != nil {
+			log.CtxErrorw(ctx, "failed to set handover piece to db", "error", err)
+			err = ErrGfSpDB
+			return ErrGfSpDB
+		}
+	}
 	if err = r.baseApp.PieceStore().PutPiece(ctx, pieceKey, data); err != nil {
 		err = ErrPieceStore
 		return ErrPieceStore
@@ -110,8 +127,13 @@ func (r *ReceiveModular) HandleDoneReceivePieceTask(
 		err = ErrDanglingTask
 		return nil, nil, ErrDanglingTask
 	}
+	params, err := r.baseApp.QueryStorageParamsByTimestamp(ctx, task.GetObjectInfo().GetCreateAt())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query storage params by object create time", "error", err)
+		return nil, nil, err
+	}
 	segmentCount := r.baseApp.PieceOp().SegmentCount(task.GetObjectInfo().GetPayloadSize(),
-		task.GetStorageParams().VersionedParams.GetMaxSegmentSize())
+		params.VersionedParams.GetMaxSegmentSize())
 	checksums, err := r.baseApp.GfSpDB().GetAllReplicatePieceChecksum(
 		task.GetObjectInfo().Id.Uint64(), task.GetReplicateIdx(), segmentCount)
 	if err != nil {
@@ -147,6 +169,18 @@ func (r *ReceiveModular) HandleDoneReceivePieceTask(
 		log.CtxErrorw(ctx, "failed to delete all replicate piece checksum", "error", err)
 		// ignore the error,let the request go, the background task will gc the meta again later
 	}
+	// the handover pieces of the exiting sp are kept, the object has been sealed
+	// before and this sp is not listed as secondary on chain, the gc object task
+	// deletes the pieces once the exiting sp is not listed at the replicate idx.
+	if task.GetHandover() {
+		if err = r.setHandoverPiece(task, segmentCount, true); err != nil {
+			log.CtxErrorw(ctx, "failed to set handover piece to db", "error", err)
+			err = ErrGfSpDB
+			return nil, nil, ErrGfSpDB
+		}
+		log.CtxDebugw(ctx, "succeed to done receive handover piece")
+		return integrity, signature, nil
+	}
 	// the manager dispatch the task to confirm whether seal on chain as secondary sp.
 	task.SetError(nil)
 	if err = r.baseApp.GfSpClient().ReportTask(ctx, task); err != nil {
@@ -157,6 +191,24 @@ func (r *ReceiveModular) HandleDoneReceivePieceTask(
 	return integrity, signature, nil
 }
 
+// setHandoverPiece records the pieces of the replicate idx received from the exiting SP,
+// the exiting SP is the secondary SP at the replicate idx when the object is handed over.
+func (r *ReceiveModular) setHandoverPiece(task task.ReceivePieceTask, segmentCount uint32, done bool) error {
+	var srcSpAddress string
+	if secondaries := task.GetObjectInfo().GetSecondarySpAddresses(); int(task.GetReplicateIdx()) < len(secondaries) {
+		srcSpAddress = secondaries[task.GetReplicateIdx()]
+	}
+	return r.baseApp.GfSpDB().SetHandoverPiece(&corespdb.HandoverPiece{
+		ObjectID:       task.GetObjectInfo().Id.Uint64(),
+		ReplicateIdx:   task.GetReplicateIdx(),
+		SrcSpAddress:   srcSpAddress,
+		SegmentCount:   segmentCount,
+		RedundancyType: int32(task.GetObjectInfo().GetRedundancyType()),
+		Done:           done,
+		UpdateTime:     time.Now().Unix(),
+	})
+}
+
 func (r *ReceiveModular) QueryTasks(
 	ctx context.Context,
 	subKey task.TKey) (
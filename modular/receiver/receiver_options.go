@@ -1,9 +1,12 @@
 package receiver
 
 import (
+	"context"
+
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 )
 
 const (
@@ -28,3 +31,17 @@ func DefaultReceiverOptions(receiver *ReceiveModular, cfg *gfspconfig.GfSpConfig
 		receiver.Name()+"-receive-piece", cfg.Parallel.ReceivePieceParallelPerNode)
 	return nil
 }
+
+// ReloadConfig applies the reloaded capacity of receive queue.
+func (r *ReceiveModular) ReloadConfig(ctx context.Context, cfg interface{}) error {
+	newCfg, ok := cfg.(*gfspconfig.GfSpConfig)
+	if !ok {
+		return gfspconfig.ErrInvalidReloadConfig
+	}
+	if newCfg.Parallel.ReceivePieceParallelPerNode == 0 {
+		newCfg.Parallel.ReceivePieceParallelPerNode = DefaultReceivePieceParallelPerNode
+	}
+	r.receiveQueue.SetCap(newCfg.Parallel.ReceivePieceParallelPerNode)
+	log.CtxInfow(ctx, "succeed to reload receiver config")
+	return nil
+}
@@ -28,7 +28,7 @@ var _ module.Signer = &SignModular{}
 
 type SignModular struct {
 	baseApp *gfspapp.GfSpBaseApp
-	client  *GreenfieldChainSignClient
+	client  SignClient
 }
 
 func (s *SignModular) Name() string {
@@ -104,6 +104,18 @@ func (s *SignModular) SignReceivePieceTask(
 	return sig, nil
 }
 
+func (s *SignModular) SignMigrateBucketTask(
+	ctx context.Context,
+	task task.MigrateBucketTask) (
+	[]byte, error) {
+	msg := task.GetSignBytes()
+	sig, err := s.client.Sign(SignOperator, msg)
+	if err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
 func (s *SignModular) SignIntegrityHash(
 	ctx context.Context,
 	objectID uint64,
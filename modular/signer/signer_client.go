@@ -37,6 +37,21 @@ const (
 	SignGc SignType = "gc"
 )
 
+// SignClient is the interface to sign msg by the SP's private keys and broadcast
+// the tx to greenfield.
+type SignClient interface {
+	// GetAddr returns the public address of the private key.
+	GetAddr(scope SignType) (sdk.AccAddress, error)
+	// Sign returns a msg signature signed by private key.
+	Sign(scope SignType, msg []byte) ([]byte, error)
+	// SealObject seal the object on the greenfield chain.
+	SealObject(ctx context.Context, scope SignType, sealObject *storagetypes.MsgSealObject) ([]byte, error)
+	// DiscontinueBucket stops serving the bucket on the greenfield chain.
+	DiscontinueBucket(ctx context.Context, scope SignType, discontinueBucket *storagetypes.MsgDiscontinueBucket) ([]byte, error)
+}
+
+var _ SignClient = &GreenfieldChainSignClient{}
+
 // GreenfieldChainSignClient the greenfield chain client
 type GreenfieldChainSignClient struct {
 	mu sync.Mutex
@@ -0,0 +1,92 @@
+package signer
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/bnb-chain/greenfield-storage-provider/base/fakechain"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield/sdk/keys"
+	storagetypes "github.com/bnb-chain/greenfield/x/storage/types"
+)
+
+var _ SignClient = &FakeChainSignClient{}
+
+// FakeChainSignClient signs msg by the SP's private keys and applies the txs to
+// the fake chain ledger instead of broadcasting them to greenfield, it is used
+// to run SP locally without greenfield node.
+type FakeChainSignClient struct {
+	chain       *fakechain.FakeChain
+	keyManagers map[SignType]keys.KeyManager
+}
+
+// NewFakeChainSignClient return the FakeChainSignClient instance.
+func NewFakeChainSignClient(chain *fakechain.FakeChain, operatorPrivateKey, fundingPrivateKey,
+	sealPrivateKey, approvalPrivateKey, gcPrivateKey string) (*FakeChainSignClient, error) {
+	privateKeys := map[SignType]string{
+		SignOperator: operatorPrivateKey,
+		SignFunding:  fundingPrivateKey,
+		SignSeal:     sealPrivateKey,
+		SignApproval: approvalPrivateKey,
+		SignGc:       gcPrivateKey,
+	}
+	keyManagers := make(map[SignType]keys.KeyManager, len(privateKeys))
+	for scope, privateKey := range privateKeys {
+		km, err := keys.NewPrivateKeyManager(privateKey)
+		if err != nil {
+			log.Errorw("failed to new private key manager", "scope", scope, "error", err)
+			return nil, err
+		}
+		keyManagers[scope] = km
+	}
+	return &FakeChainSignClient{
+		chain:       chain,
+		keyManagers: keyManagers,
+	}, nil
+}
+
+// GetAddr returns the public address of the private key.
+func (client *FakeChainSignClient) GetAddr(scope SignType) (sdk.AccAddress, error) {
+	return client.keyManagers[scope].GetAddr(), nil
+}
+
+// Sign returns a msg signature signed by private key.
+func (client *FakeChainSignClient) Sign(scope SignType, msg []byte) ([]byte, error) {
+	return client.keyManagers[scope].Sign(msg)
+}
+
+// SealObject seal the object on the fake chain.
+func (client *FakeChainSignClient) SealObject(ctx context.Context, scope SignType,
+	sealObject *storagetypes.MsgSealObject) ([]byte, error) {
+	var secondarySPAccs []sdk.AccAddress
+	for _, sp := range sealObject.SecondarySpAddresses {
+		opAddr, err := sdk.AccAddressFromHexUnsafe(sp)
+		if err != nil {
+			log.CtxErrorw(ctx, "failed to parse address", "error", err, "address", sp)
+			return nil, err
+		}
+		secondarySPAccs = append(secondarySPAccs, opAddr)
+	}
+	msgSealObject := storagetypes.NewMsgSealObject(client.keyManagers[scope].GetAddr(),
+		sealObject.BucketName, sealObject.ObjectName, secondarySPAccs, sealObject.SecondarySpSignatures)
+	txHash, err := client.chain.BroadcastSealObject(ctx, msgSealObject)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to broadcast tx to fake chain", "err", err, "seal_info", msgSealObject.String())
+		return nil, ErrSealObjectOnChain
+	}
+	return txHash, nil
+}
+
+// DiscontinueBucket stops serving the bucket on the fake chain.
+func (client *FakeChainSignClient) DiscontinueBucket(ctx context.Context, scope SignType,
+	discontinueBucket *storagetypes.MsgDiscontinueBucket) ([]byte, error) {
+	msgDiscontinueBucket := storagetypes.NewMsgDiscontinueBucket(client.keyManagers[scope].GetAddr(),
+		discontinueBucket.BucketName, discontinueBucket.Reason)
+	txHash, err := client.chain.BroadcastDiscontinueBucket(ctx, msgDiscontinueBucket)
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to broadcast tx to fake chain", "err", err, "discontinue_bucket", msgDiscontinueBucket.String())
+		return nil, ErrDiscontinueBucketOnChain
+	}
+	return txHash, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/bnb-chain/greenfield-storage-provider/base/fakechain"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
@@ -33,12 +34,6 @@ func NewSignModular(app *gfspapp.GfSpBaseApp, cfg *gfspconfig.GfSpConfig) (corem
 }
 
 func DefaultSignerOptions(signer *SignModular, cfg *gfspconfig.GfSpConfig) error {
-	if len(cfg.Chain.ChainAddress) == 0 {
-		return fmt.Errorf("chain address missing")
-	}
-	if cfg.Chain.GasLimit == 0 {
-		cfg.Chain.GasLimit = DefaultGasLimit
-	}
 	if val, ok := os.LookupEnv(SpOperatorPrivKey); ok {
 		cfg.SpAccount.OperatorPrivateKey = val
 	}
@@ -54,6 +49,23 @@ func DefaultSignerOptions(signer *SignModular, cfg *gfspconfig.GfSpConfig) error
 	if val, ok := os.LookupEnv(SpGcPrivKey); ok {
 		cfg.SpAccount.GcPrivateKey = val
 	}
+	// the txs are applied to the fake chain ledger if SP runs on the fake chain
+	if chain, ok := signer.baseApp.Consensus().(*fakechain.FakeChain); ok {
+		client, err := NewFakeChainSignClient(chain, cfg.SpAccount.OperatorPrivateKey,
+			cfg.SpAccount.FundingPrivateKey, cfg.SpAccount.SealPrivateKey,
+			cfg.SpAccount.ApprovalPrivateKey, cfg.SpAccount.GcPrivateKey)
+		if err != nil {
+			return err
+		}
+		signer.client = client
+		return nil
+	}
+	if len(cfg.Chain.ChainAddress) == 0 {
+		return fmt.Errorf("chain address missing")
+	}
+	if cfg.Chain.GasLimit == 0 {
+		cfg.Chain.GasLimit = DefaultGasLimit
+	}
 	client, err := NewGreenfieldChainSignClient(cfg.Chain.ChainAddress[0], cfg.Chain.ChainID,
 		cfg.Chain.GasLimit, cfg.SpAccount.OperatorPrivateKey, cfg.SpAccount.FundingPrivateKey,
 		cfg.SpAccount.SealPrivateKey, cfg.SpAccount.ApprovalPrivateKey, cfg.SpAccount.GcPrivateKey)
@@ -55,15 +55,19 @@ func (u *UploadModular) HandleUploadObjectTask(
 	ctx context.Context,
 	task coretask.UploadObjectTask,
 	stream io.Reader) error {
-	if err := u.uploadQueue.Push(task); err != nil {
+	params, err := u.baseApp.QueryStorageParamsByTimestamp(ctx, task.GetObjectInfo().GetCreateAt())
+	if err != nil {
+		log.CtxErrorw(ctx, "failed to query storage params by object create time", "error", err)
+		return err
+	}
+	if err = u.uploadQueue.Push(task); err != nil {
 		log.CtxErrorw(ctx, "failed to push challenge piece queue", "error", err)
 		return ErrExceedTask
 	}
 	segmentSize := u.baseApp.PieceOp().MaxSegmentSize(
 		task.GetObjectInfo().GetPayloadSize(),
-		task.GetStorageParams().VersionedParams.GetMaxSegmentSize())
+		params.VersionedParams.GetMaxSegmentSize())
 	var (
-		err       error
 		segIdx    uint32 = 0
 		pieceKey  string
 		signature []byte
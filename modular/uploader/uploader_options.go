@@ -1,9 +1,12 @@
 package uploader
 
 import (
+	"context"
+
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspapp"
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	coremodule "github.com/bnb-chain/greenfield-storage-provider/core/module"
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 )
 
 const (
@@ -28,3 +31,17 @@ func DefaultUploaderOptions(uploader *UploadModular, cfg *gfspconfig.GfSpConfig)
 		uploader.Name()+"-upload-object", cfg.Parallel.UploadObjectParallelPerNode)
 	return nil
 }
+
+// ReloadConfig applies the reloaded capacity of upload queue.
+func (u *UploadModular) ReloadConfig(ctx context.Context, cfg interface{}) error {
+	newCfg, ok := cfg.(*gfspconfig.GfSpConfig)
+	if !ok {
+		return gfspconfig.ErrInvalidReloadConfig
+	}
+	if newCfg.Parallel.UploadObjectParallelPerNode == 0 {
+		newCfg.Parallel.UploadObjectParallelPerNode = DefaultUploadObjectParallelPerNode
+	}
+	u.uploadQueue.SetCap(newCfg.Parallel.UploadObjectParallelPerNode)
+	log.CtxInfow(ctx, "succeed to reload uploader config")
+	return nil
+}
@@ -43,6 +43,8 @@ var MetricsItems = []prometheus.Collector{
 	SealObjectSucceedCounter,
 	SealObjectFailedCounter,
 	GCObjectCounter,
+	GCFailedPieceCounter,
+	SPScoreGauge,
 	ReplicatePieceSizeCounter,
 	ReplicateSucceedCounter,
 	ReplicateFailedCounter,
@@ -53,6 +55,7 @@ var MetricsItems = []prometheus.Collector{
 	ExecutorGCObjectTaskCounter,
 	ExecutorGCZombieTaskCounter,
 	ExecutorGCMetaTaskCounter,
+	ExecutorRepairPieceTaskCounter,
 	// Manager metrics category
 	UploadObjectTaskTimeHistogram,
 	ReplicateAndSealTaskTimeHistogram,
@@ -68,6 +71,11 @@ var MetricsItems = []prometheus.Collector{
 	DispatchSealObjectTaskCounter,
 	DispatchReceivePieceTaskCounter,
 	DispatchGcObjectTaskCounter,
+	RepairPieceTaskSucceedCounter,
+	RepairPieceTaskFailedCounter,
+	AuditObjectCounter,
+	AuditFindingCounter,
+	AuditRepairedCounter,
 	// Signer metrics category
 	SealObjectTimeHistogram,
 	// SPDB metrics category
@@ -196,6 +204,14 @@ var (
 		Name: "delete_object_number",
 		Help: "Track deleted object number.",
 	}, []string{"delete_object_number"})
+	GCFailedPieceCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gc_failed_piece_number",
+		Help: "Track the number of pieces that gc object task failed to delete.",
+	}, []string{"gc_failed_piece_number"})
+	SPScoreGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sp_score",
+		Help: "Track the score of the secondary sp candidates.",
+	}, []string{"sp_address"})
 	ReplicatePieceSizeCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "replicate_piece_size",
 		Help: "Track replicate piece data size.",
@@ -237,6 +253,10 @@ var (
 		Name: "gc_meta_task_count",
 		Help: "Track gc meta task number.",
 	}, []string{"gc_meta_task_count"})
+	ExecutorRepairPieceTaskCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "repair_piece_task_count",
+		Help: "Track repair piece task number.",
+	}, []string{"repair_piece_task_count"})
 
 	// manager mertics
 	UploadObjectTaskTimeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -299,6 +319,26 @@ var (
 		Name: "dispatch_gc_object_task",
 		Help: "Track gc object task total number",
 	}, []string{"dispatch_gc_object_task"})
+	RepairPieceTaskSucceedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "repair_piece_task_success",
+		Help: "Track repair piece task success total number",
+	}, []string{"repair_piece_task_success"})
+	RepairPieceTaskFailedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "repair_piece_task_failure",
+		Help: "Track repair piece task failure total number",
+	}, []string{"repair_piece_task_failure"})
+	AuditObjectCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "audit_object",
+		Help: "Track self challenge audited object total number",
+	}, []string{"audit_object"})
+	AuditFindingCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "audit_finding",
+		Help: "Track self challenge audit finding total number by finding type",
+	}, []string{"finding_type"})
+	AuditRepairedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "audit_repaired",
+		Help: "Track self challenge audit finding repaired total number by finding type",
+	}, []string{"finding_type"})
 
 	// singer metrics
 	SealObjectTimeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
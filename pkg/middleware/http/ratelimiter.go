@@ -52,14 +52,19 @@ type apiLimiter struct {
 	cfg        APILimiterConfig
 }
 
-var limiter *apiLimiter
+var (
+	limiter    *apiLimiter
+	limiterMux sync.RWMutex
+)
 
+// NewAPILimiter creates the api limiter by the config, it replaces the running api
+// limiter if it is called again.
 func NewAPILimiter(cfg *APILimiterConfig) error {
 	localStore := smemory.NewStoreWithOptions(slimiter.StoreOptions{
 		Prefix:          "sp_api_rate_limiter",
 		CleanUpInterval: 5 * time.Second,
 	})
-	limiter = &apiLimiter{
+	newLimiter := &apiLimiter{
 		store: localStore,
 		cfg: APILimiterConfig{
 			APILimits:   make(map[string]MemoryLimiterConfig),
@@ -73,11 +78,11 @@ func NewAPILimiter(cfg *APILimiterConfig) error {
 	var rate slimiter.Rate
 
 	for k, v := range cfg.PathPattern {
-		limiter.cfg.PathPattern[strings.ToLower(k)] = v
+		newLimiter.cfg.PathPattern[strings.ToLower(k)] = v
 	}
 
 	for k, v := range cfg.HostPattern {
-		limiter.cfg.HostPattern[strings.ToLower(k)] = v
+		newLimiter.cfg.HostPattern[strings.ToLower(k)] = v
 	}
 
 	for k, v := range cfg.APILimits {
@@ -86,12 +91,21 @@ func NewAPILimiter(cfg *APILimiterConfig) error {
 			return err
 		}
 
-		limiter.limiterMap.Store(strings.ToLower(k), slimiter.New(localStore, rate))
+		newLimiter.limiterMap.Store(strings.ToLower(k), slimiter.New(localStore, rate))
 	}
 
+	limiterMux.Lock()
+	limiter = newLimiter
+	limiterMux.Unlock()
 	return nil
 }
 
+func currentLimiter() *apiLimiter {
+	limiterMux.RLock()
+	defer limiterMux.RUnlock()
+	return limiter
+}
+
 func (a *apiLimiter) findLimiter(host, path, key string) *slimiter.Limiter {
 	newLimiter, ok := a.limiterMap.Load(key)
 	if ok {
@@ -172,6 +186,7 @@ func (t *apiLimiter) HTTPAllow(ctx context.Context, r *http.Request) bool {
 
 func Limit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter := currentLimiter()
 		if !limiter.Allow(context.Background(), r) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusTooManyRequests)
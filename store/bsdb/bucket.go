@@ -2,6 +2,7 @@ package bsdb
 
 import (
 	"errors"
+	"math/big"
 	"strconv"
 
 	"github.com/forbole/juno/v4/common"
@@ -104,6 +105,29 @@ func (b *BsDBImpl) ListExpiredBucketsBySp(createAt int64, primarySpAddress strin
 	return buckets, err
 }
 
+// ListBucketsByPrimarySp lists the unremoved buckets whose primary sp is the given sp,
+// the buckets are in the ascending order of the bucket id after the startAfterBucketID
+func (b *BsDBImpl) ListBucketsByPrimarySp(primarySpAddress string, startAfterBucketID uint64, limit int64) ([]*Bucket, error) {
+	var (
+		buckets []*Bucket
+		err     error
+	)
+
+	if limit < 1 || limit > ListBySpDefaultSize {
+		limit = ListBySpDefaultSize
+	}
+
+	err = b.db.Table((&Bucket{}).TableName()).
+		Select("*").
+		Where("primary_sp_address = ? and bucket_id > ? and removed = false",
+			common.HexToAddress(primarySpAddress), common.BigToHash(new(big.Int).SetUint64(startAfterBucketID))).
+		Limit(int(limit)).
+		Order("bucket_id asc").
+		Find(&buckets).Error
+
+	return buckets, err
+}
+
 func (b *BsDBImpl) GetBucketMetaByName(bucketName string, isFullList bool) (*BucketFullMeta, error) {
 	var (
 		bucketFullMeta *BucketFullMeta
@@ -12,6 +12,8 @@ const (
 	GetUserBucketsLimitSize = 100
 	// ListObjectsLimitSize defines the default limit of ListObjectsByBucketName response
 	ListObjectsLimitSize = 1000
+	// ListBySpDefaultSize defines the default size of ListObjectsBySecondarySp and ListBucketsByPrimarySp response
+	ListBySpDefaultSize = 1000
 )
 
 // define table name constant of block syncer db
@@ -32,6 +34,12 @@ const (
 	MasterDBTableName = "master_db"
 	// PrefixTreeTableName defines the name of prefix tree node table
 	PrefixTreeTableName = "slash_prefix_tree_nodes"
+	// NotificationOutboxTableName defines the name of notification outbox table
+	NotificationOutboxTableName = "notification_outbox"
+	// NotificationDeliveryTableName defines the name of notification delivery table
+	NotificationDeliveryTableName = "notification_delivery"
+	// NotificationDeadLetterTableName defines the name of notification dead letter table
+	NotificationDeadLetterTableName = "notification_dead_letter"
 )
 
 // define the list objects const
@@ -289,6 +289,52 @@ func (mr *MockMetadataMockRecorder) ListExpiredBucketsBySp(createAt, primarySpAd
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredBucketsBySp", reflect.TypeOf((*MockMetadata)(nil).ListExpiredBucketsBySp), createAt, primarySpAddress, limit)
 }
 
+// ListBucketsByPrimarySp mocks base method.
+func (m *MockMetadata) ListBucketsByPrimarySp(primarySpAddress string, startAfterBucketID uint64, limit int64) ([]*Bucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBucketsByPrimarySp", primarySpAddress, startAfterBucketID, limit)
+	ret0, _ := ret[0].([]*Bucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBucketsByPrimarySp indicates an expected call of ListBucketsByPrimarySp.
+func (mr *MockMetadataMockRecorder) ListBucketsByPrimarySp(primarySpAddress, startAfterBucketID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBucketsByPrimarySp", reflect.TypeOf((*MockMetadata)(nil).ListBucketsByPrimarySp), primarySpAddress, startAfterBucketID, limit)
+}
+
+// GetPrefixStats mocks base method.
+func (m *MockMetadata) GetPrefixStats(bucketName, prefix string) (*SlashPrefixTreeNode, []*SlashPrefixTreeNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrefixStats", bucketName, prefix)
+	ret0, _ := ret[0].(*SlashPrefixTreeNode)
+	ret1, _ := ret[1].([]*SlashPrefixTreeNode)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPrefixStats indicates an expected call of GetPrefixStats.
+func (mr *MockMetadataMockRecorder) GetPrefixStats(bucketName, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrefixStats", reflect.TypeOf((*MockMetadata)(nil).GetPrefixStats), bucketName, prefix)
+}
+
+// ListObjectsBySecondarySp mocks base method.
+func (m *MockMetadata) ListObjectsBySecondarySp(secondarySpAddress string, startAfterObjectID uint64, limit int64) ([]*Object, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjectsBySecondarySp", secondarySpAddress, startAfterObjectID, limit)
+	ret0, _ := ret[0].([]*Object)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListObjectsBySecondarySp indicates an expected call of ListObjectsBySecondarySp.
+func (mr *MockMetadataMockRecorder) ListObjectsBySecondarySp(secondarySpAddress, startAfterObjectID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjectsBySecondarySp", reflect.TypeOf((*MockMetadata)(nil).ListObjectsBySecondarySp), secondarySpAddress, startAfterObjectID, limit)
+}
+
 // ListObjectsByBucketName mocks base method.
 func (m *MockMetadata) ListObjectsByBucketName(bucketName, continuationToken, prefix, delimiter string, maxKeys int) ([]*ListObjectsResult, error) {
 	m.ctrl.T.Helper()
@@ -582,6 +628,52 @@ func (mr *MockBSDBMockRecorder) ListExpiredBucketsBySp(createAt, primarySpAddres
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredBucketsBySp", reflect.TypeOf((*MockBSDB)(nil).ListExpiredBucketsBySp), createAt, primarySpAddress, limit)
 }
 
+// ListBucketsByPrimarySp mocks base method.
+func (m *MockBSDB) ListBucketsByPrimarySp(primarySpAddress string, startAfterBucketID uint64, limit int64) ([]*Bucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBucketsByPrimarySp", primarySpAddress, startAfterBucketID, limit)
+	ret0, _ := ret[0].([]*Bucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBucketsByPrimarySp indicates an expected call of ListBucketsByPrimarySp.
+func (mr *MockBSDBMockRecorder) ListBucketsByPrimarySp(primarySpAddress, startAfterBucketID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBucketsByPrimarySp", reflect.TypeOf((*MockBSDB)(nil).ListBucketsByPrimarySp), primarySpAddress, startAfterBucketID, limit)
+}
+
+// GetPrefixStats mocks base method.
+func (m *MockBSDB) GetPrefixStats(bucketName, prefix string) (*SlashPrefixTreeNode, []*SlashPrefixTreeNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrefixStats", bucketName, prefix)
+	ret0, _ := ret[0].(*SlashPrefixTreeNode)
+	ret1, _ := ret[1].([]*SlashPrefixTreeNode)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPrefixStats indicates an expected call of GetPrefixStats.
+func (mr *MockBSDBMockRecorder) GetPrefixStats(bucketName, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrefixStats", reflect.TypeOf((*MockBSDB)(nil).GetPrefixStats), bucketName, prefix)
+}
+
+// ListObjectsBySecondarySp mocks base method.
+func (m *MockBSDB) ListObjectsBySecondarySp(secondarySpAddress string, startAfterObjectID uint64, limit int64) ([]*Object, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjectsBySecondarySp", secondarySpAddress, startAfterObjectID, limit)
+	ret0, _ := ret[0].([]*Object)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListObjectsBySecondarySp indicates an expected call of ListObjectsBySecondarySp.
+func (mr *MockBSDBMockRecorder) ListObjectsBySecondarySp(secondarySpAddress, startAfterObjectID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjectsBySecondarySp", reflect.TypeOf((*MockBSDB)(nil).ListObjectsBySecondarySp), secondarySpAddress, startAfterObjectID, limit)
+}
+
 // ListObjectsByBucketName mocks base method.
 func (m *MockBSDB) ListObjectsByBucketName(bucketName, continuationToken, prefix, delimiter string, maxKeys int) ([]*ListObjectsResult, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,60 @@
+package bsdb
+
+import (
+	"github.com/forbole/juno/v4/common"
+)
+
+// NotificationOutbox is the object lifecycle event written in the same transaction as the
+// block, the event is delivered to the subscriptions after it is dispatched.
+type NotificationOutbox struct {
+	ID uint64 `gorm:"column:id;primaryKey"`
+	// EventID is the unique id of the event, it is derived from the block and the event,
+	// so the event is written only once if the block is replayed
+	EventID    string      `gorm:"column:event_id;type:varchar(64);uniqueIndex:idx_event_id"`
+	EventType  string      `gorm:"column:event_type;type:varchar(32)"`
+	BucketName string      `gorm:"column:bucket_name;type:varchar(64)"`
+	ObjectName string      `gorm:"column:object_name;type:varchar(1024)"`
+	ObjectID   common.Hash `gorm:"column:object_id;size:32"`
+	Owner      string      `gorm:"column:owner;type:varchar(64)"`
+	Height     int64       `gorm:"column:height"`
+	TxHash     common.Hash `gorm:"column:tx_hash;size:32"`
+	BlockTime  int64       `gorm:"column:block_time"`
+	// Payload is the json body delivered to the webhooks
+	Payload    string `gorm:"column:payload;type:text"`
+	Dispatched bool   `gorm:"column:dispatched;default:false;index:idx_dispatched"`
+}
+
+// TableName is used to set NotificationOutbox table name in database
+func (*NotificationOutbox) TableName() string {
+	return NotificationOutboxTableName
+}
+
+// NotificationDelivery is the pending delivery of the event to the subscription.
+type NotificationDelivery struct {
+	ID             uint64 `gorm:"column:id;primaryKey"`
+	OutboxID       uint64 `gorm:"column:outbox_id;uniqueIndex:idx_outbox_subscription,priority:1"`
+	SubscriptionID uint64 `gorm:"column:subscription_id;uniqueIndex:idx_outbox_subscription,priority:2"`
+	Attempts       int    `gorm:"column:attempts"`
+	NextRetryAt    int64  `gorm:"column:next_retry_at;index:idx_next_retry_at"`
+	LastError      string `gorm:"column:last_error;type:text"`
+}
+
+// TableName is used to set NotificationDelivery table name in database
+func (*NotificationDelivery) TableName() string {
+	return NotificationDeliveryTableName
+}
+
+// NotificationDeadLetter is the delivery that still fails after the max attempts.
+type NotificationDeadLetter struct {
+	ID             uint64 `gorm:"column:id;primaryKey"`
+	OutboxID       uint64 `gorm:"column:outbox_id;index:idx_outbox_id"`
+	SubscriptionID uint64 `gorm:"column:subscription_id"`
+	Attempts       int    `gorm:"column:attempts"`
+	LastError      string `gorm:"column:last_error;type:text"`
+	CreateTime     int64  `gorm:"column:create_time"`
+}
+
+// TableName is used to set NotificationDeadLetter table name in database
+func (*NotificationDeadLetter) TableName() string {
+	return NotificationDeadLetterTableName
+}
@@ -1,6 +1,10 @@
 package bsdb
 
 import (
+	"math/big"
+	"strings"
+
+	"github.com/forbole/juno/v4/common"
 	"gorm.io/gorm"
 )
 
@@ -105,3 +109,27 @@ func (b *BsDBImpl) GetObjectByName(objectName string, bucketName string, isFullL
 		Take(&object).Error
 	return object, err
 }
+
+// ListObjectsBySecondarySp lists the unremoved objects which store the pieces in the sp as secondary,
+// the objects are in the ascending order of the object id after the startAfterObjectID
+func (b *BsDBImpl) ListObjectsBySecondarySp(secondarySpAddress string, startAfterObjectID uint64, limit int64) ([]*Object, error) {
+	var (
+		objects []*Object
+		err     error
+	)
+
+	if limit < 1 || limit > ListBySpDefaultSize {
+		limit = ListBySpDefaultSize
+	}
+
+	// the secondary sp addresses are stored as the text of array, compare them in lower case
+	// to be independent of the address format in the different dialects
+	err = b.db.Table((&Object{}).TableName()).
+		Select("*").
+		Where("lower(secondary_sp_addresses) like ? and object_id > ? and removed = false",
+			"%"+strings.ToLower(secondarySpAddress)+"%", common.BigToHash(new(big.Int).SetUint64(startAfterObjectID))).
+		Limit(int(limit)).
+		Order("object_id asc").
+		Find(&objects).Error
+	return objects, err
+}
@@ -25,7 +25,7 @@ type Permission struct {
 	// UpdateTimestamp defines the update time of permission
 	UpdateTimestamp int64 `gorm:"update_timestamp"`
 	// ExpirationTime defines the expiration time of permission
-	ExpirationTime int64 `gorm:"expiration_time;type:bigint(64)"`
+	ExpirationTime int64 `gorm:"expiration_time"`
 	// Removed defines the permission is deleted or not
 	Removed bool `gorm:"removed"`
 }
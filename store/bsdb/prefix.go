@@ -1,6 +1,7 @@
 package bsdb
 
 import (
+	"errors"
 	"path/filepath"
 	"strings"
 
@@ -45,6 +46,48 @@ func (b *BsDBImpl) ListObjects(bucketName, continuationToken, prefix string, max
 	return res, err
 }
 
+// GetPrefixStats get the stats of the directory and the stats of its immediate child directories
+// by bucket name, the root directory of the bucket is used if the prefix is empty. The returned
+// directory is nil if it does not exist.
+func (b *BsDBImpl) GetPrefixStats(bucketName, prefix string) (*SlashPrefixTreeNode, []*SlashPrefixTreeNode, error) {
+	var (
+		directory *SlashPrefixTreeNode
+		children  []*SlashPrefixTreeNode
+		err       error
+	)
+	if prefix == "" || prefix == "/" {
+		// the root directory has no node, its stats are summed from the nodes under it
+		directory = &SlashPrefixTreeNode{FullName: "/", IsFolder: true, BucketName: bucketName}
+		err = b.db.Table((&SlashPrefixTreeNode{}).TableName()).
+			Select("COALESCE(SUM(object_count), 0) AS object_count, COALESCE(SUM(total_size), 0) AS total_size").
+			Where("bucket_name = ? AND path_name = ?", bucketName, "/").
+			Take(directory).Error
+		prefix = "/"
+	} else {
+		if !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		err = b.db.Table((&SlashPrefixTreeNode{}).TableName()).
+			Where("bucket_name = ? AND full_name = ? AND is_object = ?", bucketName, prefix, false).
+			Take(&directory).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = b.db.Table((&SlashPrefixTreeNode{}).TableName()).
+		Where("bucket_name = ? AND path_name = ? AND is_object = ?", bucketName, prefix, false).
+		Order("full_name").
+		Find(&children).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return directory, children, nil
+}
+
 // processPath takes in a string that is a path name, and returns two strings:
 // the directory part of the path, and the file part of the path. If the path does not contain
 // a "/", then the directory is "/" and the file is the path.
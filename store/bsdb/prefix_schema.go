@@ -15,8 +15,13 @@ type SlashPrefixTreeNode struct {
 	IsFolder bool   `gorm:"column:is_folder;default:false"`
 
 	BucketName string      `gorm:"column:bucket_name;type:varchar(64);index:idx_bucket_full_object,priority:1;index:idx_bucket_path,priority:1"`
-	ObjectID   common.Hash `gorm:"column:object_id;type:BINARY(32);index:idx_object_id"`
+	ObjectID   common.Hash `gorm:"column:object_id;size:32;index:idx_object_id"`
 	ObjectName string      `gorm:"column:object_name;type:varchar(1024)"`
+
+	// ObjectCount and TotalSize are the number and the total payload size of the objects under
+	// the directory node, they are 1 and the payload size of the object for the object node
+	ObjectCount int64 `gorm:"column:object_count;default:0"`
+	TotalSize   int64 `gorm:"column:total_size;default:0"`
 }
 
 // TableName is used to set SlashPrefixTreeNode table name in database
@@ -1,14 +1,12 @@
 package bsdb
 
 import (
-	"fmt"
-
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 
 	"github.com/bnb-chain/greenfield-storage-provider/base/gfspconfig"
 	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
 	"github.com/bnb-chain/greenfield-storage-provider/store/config"
+	"github.com/bnb-chain/greenfield-storage-provider/store/sqldb"
 )
 
 var _ BSDB = &BsDBImpl{}
@@ -36,9 +34,12 @@ func NewBsDB(cfg *gfspconfig.GfSpConfig, isBackup bool) (*BsDBImpl, error) {
 
 // InitDB init a block syncer db instance
 func InitDB(config *config.SQLDBConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		config.User, config.Passwd, config.Address, config.Database)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	dialector, err := sqldb.NewDialector(config)
+	if err != nil {
+		log.Errorw("failed to new db dialector", "error", err)
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
 		log.Errorw("gorm failed to open db", "error", err)
 		return nil, err
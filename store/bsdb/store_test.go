@@ -0,0 +1,115 @@
+package bsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/forbole/juno/v4/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	"github.com/bnb-chain/greenfield-storage-provider/store/config"
+)
+
+const (
+	// testMySQLAddress defines env variable name for the mysql address of schema test
+	testMySQLAddress = "BSDB_TEST_MYSQL_ADDRESS"
+	// testPostgresAddress defines env variable name for the postgres address of schema test
+	testPostgresAddress = "BSDB_TEST_POSTGRES_ADDRESS"
+)
+
+// setupBsDB returns the BsDB of the dialect with the tables created, the mysql and postgres
+// tests are skipped if the db address env is not set, the user, password and database are
+// loaded from the BS_DB_* env vars. The unit test workflow of CI sets these env vars to its
+// mysql and postgres service containers.
+func setupBsDB(t *testing.T, dialect string) *BsDBImpl {
+	cfg := &config.SQLDBConfig{Dialect: dialect}
+	switch dialect {
+	case config.MySQLDialect:
+		cfg.Address = os.Getenv(testMySQLAddress)
+	case config.PostgresDialect:
+		cfg.Address = os.Getenv(testPostgresAddress)
+	case config.SQLiteDialect:
+		cfg.Database = filepath.Join(t.TempDir(), "bsdb.db")
+	}
+	if dialect != config.SQLiteDialect {
+		if cfg.Address == "" {
+			t.Skipf("skip %s schema test, db address is not set", dialect)
+		}
+		cfg.User = os.Getenv(model.BsDBUser)
+		cfg.Passwd = os.Getenv(model.BsDBPasswd)
+		cfg.Database = os.Getenv(model.BsDBDataBase)
+	}
+	db, err := InitDB(cfg)
+	require.NoError(t, err)
+	tables := []interface{}{&Epoch{}, &Permission{}, &SlashPrefixTreeNode{},
+		&NotificationOutbox{}, &NotificationDelivery{}, &NotificationDeadLetter{}}
+	require.NoError(t, db.AutoMigrate(tables...))
+	t.Cleanup(func() {
+		_ = db.Migrator().DropTable(tables...)
+	})
+	return &BsDBImpl{db: db}
+}
+
+func TestBsDBSchema(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupBsDB(t, dialect)
+
+			require.NoError(t, db.db.Create(&Epoch{OneRowID: true, BlockHeight: 100,
+				BlockHash: common.HexToHash("0x01")}).Error)
+			height, err := db.GetLatestBlockNumber()
+			assert.Nil(t, err)
+			assert.Equal(t, int64(100), height)
+
+			outbox := &NotificationOutbox{EventID: "event", ObjectID: common.HexToHash("0x02"), Payload: "{}"}
+			require.NoError(t, db.db.Create(outbox).Error)
+			var stored NotificationOutbox
+			require.NoError(t, db.db.Take(&stored, "event_id = ?", "event").Error)
+			assert.Equal(t, outbox.ObjectID, stored.ObjectID)
+		})
+	}
+}
+
+func TestBsDBGetPrefixStats(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupBsDB(t, dialect)
+
+			nodes := []*SlashPrefixTreeNode{
+				{PathName: "/", FullName: "a/", Name: "a/", IsFolder: true, ObjectCount: 2, TotalSize: 30},
+				{PathName: "a/", FullName: "a/b/", Name: "b/", IsFolder: true, ObjectCount: 1, TotalSize: 20},
+				{PathName: "a/", FullName: "a/x.txt", Name: "x.txt", IsObject: true, ObjectCount: 1, TotalSize: 10,
+					ObjectID: common.HexToHash("0x01")},
+				{PathName: "a/b/", FullName: "a/b/y.txt", Name: "y.txt", IsObject: true, ObjectCount: 1, TotalSize: 20,
+					ObjectID: common.HexToHash("0x02")},
+				{PathName: "/", FullName: "z.txt", Name: "z.txt", IsObject: true, ObjectCount: 1, TotalSize: 40,
+					ObjectID: common.HexToHash("0x03")},
+			}
+			for _, node := range nodes {
+				node.BucketName = "bucket"
+			}
+			require.NoError(t, db.db.Create(nodes).Error)
+
+			root, children, err := db.GetPrefixStats("bucket", "")
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), root.ObjectCount)
+			assert.Equal(t, int64(70), root.TotalSize)
+			require.Len(t, children, 1)
+			assert.Equal(t, "a/", children[0].FullName)
+
+			directory, children, err := db.GetPrefixStats("bucket", "a")
+			require.NoError(t, err)
+			assert.Equal(t, "a/", directory.FullName)
+			assert.Equal(t, int64(30), directory.TotalSize)
+			require.Len(t, children, 1)
+			assert.Equal(t, int64(20), children[0].TotalSize)
+
+			directory, _, err = db.GetPrefixStats("bucket", "none/")
+			require.NoError(t, err)
+			assert.Nil(t, directory)
+		})
+	}
+}
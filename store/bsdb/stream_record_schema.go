@@ -26,7 +26,7 @@ type StreamRecord struct {
 	// SettleTimestamp defines the unix timestamp when the stream account will be settled
 	SettleTimestamp int64 `gorm:"column:settle_timestamp"`
 	// OutFlows defines the accumulated outflow rates of the stream account
-	OutFlows []byte `gorm:"out_flows;type:longblob"`
+	OutFlows []byte `gorm:"out_flows"`
 }
 
 // TableName is used to set StreamRecord table name in database
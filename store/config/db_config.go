@@ -1,7 +1,18 @@
 package config
 
+const (
+	// MySQLDialect defines the dialect of MySQL, it is the default dialect.
+	MySQLDialect = "mysql"
+	// PostgresDialect defines the dialect of PostgreSQL.
+	PostgresDialect = "postgres"
+	// SQLiteDialect defines the dialect of SQLite, the Database is the path of db file.
+	SQLiteDialect = "sqlite"
+)
+
 // SQLDBConfig is sql db config
 type SQLDBConfig struct {
+	// Dialect is one of mysql, postgres and sqlite, the default is mysql
+	Dialect         string
 	User            string
 	Passwd          string
 	Address         string
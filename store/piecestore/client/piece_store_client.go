@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"sync"
 	"time"
 
 	corepiecestore "github.com/bnb-chain/greenfield-storage-provider/core/piecestore"
@@ -101,3 +102,51 @@ func (client *StoreClient) DeletePiece(ctx context.Context, key string) error {
 	err = client.ps.Delete(ctx, key)
 	return err
 }
+
+// DeletePieces deletes pieces from piece store in batch, returns the errors of the pieces
+// that are failed to delete.
+func (client *StoreClient) DeletePieces(ctx context.Context, keys []string) map[string]error {
+	startTime := time.Now()
+	sizes := client.pieceSizes(ctx, keys)
+	failed := client.ps.DeleteBatch(ctx, keys)
+	var deletedSize int64
+	for key, size := range sizes {
+		if _, ok := failed[key]; !ok {
+			deletedSize += size
+		}
+	}
+	metrics.DeletePieceTimeHistogram.WithLabelValues(client.name).Observe(time.Since(startTime).Seconds())
+	metrics.DeletePieceTotalNumberCounter.WithLabelValues(client.name).Add(float64(len(keys)))
+	metrics.PieceUsageAmountGauge.WithLabelValues(client.name).Add(0 - float64(deletedSize))
+	return failed
+}
+
+// pieceSizes returns the sizes of the existing pieces, which are used to track the usage
+// amount of piece store.
+func (client *StoreClient) pieceSizes(ctx context.Context, keys []string) map[string]int64 {
+	var (
+		mux   sync.Mutex
+		wg    sync.WaitGroup
+		sizes = make(map[string]int64, len(keys))
+		limit = make(chan struct{}, storage.DeleteObjectsParallel)
+	)
+	for _, key := range keys {
+		limit <- struct{}{}
+		wg.Add(1)
+		go func(key string) {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+			info, err := client.ps.GetPieceInfo(ctx, key)
+			if err != nil {
+				return
+			}
+			mux.Lock()
+			sizes[key] = info.Size()
+			mux.Unlock()
+		}(key)
+	}
+	wg.Wait()
+	return sizes
+}
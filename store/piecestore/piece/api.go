@@ -26,6 +26,11 @@ func (p *PieceStore) Delete(ctx context.Context, key string) error {
 	return p.storeAPI.DeleteObject(ctx, key)
 }
 
+// DeleteBatch deletes pieces in PieceStore, returns the errors of the pieces that are failed to delete
+func (p *PieceStore) DeleteBatch(ctx context.Context, keys []string) map[string]error {
+	return p.storeAPI.DeleteObjects(ctx, keys)
+}
+
 // GetPieceInfo returns piece info in PieceStore
 func (p *PieceStore) GetPieceInfo(ctx context.Context, key string) (storage.Object, error) {
 	return p.storeAPI.HeadObject(ctx, key)
@@ -141,6 +141,10 @@ func (d *diskFileStore) DeleteObject(ctx context.Context, key string) error {
 	return err
 }
 
+func (d *diskFileStore) DeleteObjects(ctx context.Context, keys []string) map[string]error {
+	return deleteObjectsParallel(ctx, keys, d.DeleteObject)
+}
+
 func (d *diskFileStore) HeadBucket(ctx context.Context) error {
 	if _, err := os.Stat(d.root); err != nil {
 		if os.IsNotExist(err) {
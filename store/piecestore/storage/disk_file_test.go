@@ -228,6 +228,21 @@ func TestDiskFile_Delete(t *testing.T) {
 	}
 }
 
+func TestDiskFile_DeleteObjects(t *testing.T) {
+	store := &diskFileStore{root: t.TempDir() + dirSuffix}
+	keys := make([]string, DeleteObjectsParallel*2)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("piece_%d", i)
+		assert.Nil(t, store.PutObject(context.TODO(), keys[i], strings.NewReader("Hello")))
+	}
+	failed := store.DeleteObjects(context.TODO(), append(keys, "non_existed_object"))
+	assert.Equal(t, 0, len(failed))
+	for _, key := range keys {
+		_, err := store.HeadObject(context.TODO(), key)
+		assert.True(t, os.IsNotExist(err))
+	}
+}
+
 func TestDiskFile_HeadSuccess(t *testing.T) {
 	f := createTempFile(t)
 	cases := []struct {
@@ -21,6 +21,9 @@ type ObjectStorage interface {
 	PutObject(ctx context.Context, key string, reader io.Reader) error
 	// DeleteObject deletes an object
 	DeleteObject(ctx context.Context, key string) error
+	// DeleteObjects deletes the objects specified by keys in batch, the missing objects are
+	// regarded as deleted, returns the errors of the keys that are failed to delete
+	DeleteObjects(ctx context.Context, keys []string) map[string]error
 
 	// HeadBucket determines if a bucket exists and have permission to access it
 	HeadBucket(ctx context.Context) error
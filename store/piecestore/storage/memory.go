@@ -85,6 +85,15 @@ func (m *memoryStore) DeleteObject(ctx context.Context, key string) error {
 	return nil
 }
 
+func (m *memoryStore) DeleteObjects(ctx context.Context, keys []string) map[string]error {
+	m.Lock()
+	defer m.Unlock()
+	for _, key := range keys {
+		delete(m.objects, key)
+	}
+	return map[string]error{}
+}
+
 func (m *memoryStore) HeadBucket(ctx context.Context) error {
 	return nil
 }
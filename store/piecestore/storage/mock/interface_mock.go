@@ -65,6 +65,20 @@ func (mr *MockObjectStorageMockRecorder) DeleteObject(ctx, key interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObject", reflect.TypeOf((*MockObjectStorage)(nil).DeleteObject), ctx, key)
 }
 
+// DeleteObjects mocks base method.
+func (m *MockObjectStorage) DeleteObjects(ctx context.Context, keys []string) map[string]error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObjects", ctx, keys)
+	ret0, _ := ret[0].(map[string]error)
+	return ret0
+}
+
+// DeleteObjects indicates an expected call of DeleteObjects.
+func (mr *MockObjectStorageMockRecorder) DeleteObjects(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObjects", reflect.TypeOf((*MockObjectStorage)(nil).DeleteObjects), ctx, keys)
+}
+
 // GetObject mocks base method.
 func (m *MockObjectStorage) GetObject(ctx context.Context, key string, offset, limit int64) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
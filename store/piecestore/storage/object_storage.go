@@ -29,6 +29,10 @@ var storageMap = map[string]StorageFn{
 	mpiecestore.MemoryStore:   newMemoryStore,
 }
 
+// DeleteObjectsParallel defines the max number of objects that are deleted concurrently by
+// the object storage without batch delete api.
+const DeleteObjectsParallel = 16
+
 type DefaultObjectStorage struct{}
 
 func (s DefaultObjectStorage) CreateBucket(ctx context.Context) error {
@@ -82,3 +86,34 @@ func getSecretKeyFromEnv(accessKey, secretKey, sessionToken string) *objectStora
 	}
 	return key
 }
+
+// deleteObjectsParallel deletes the objects one by one concurrently by deleteFn, returns the
+// errors of the keys that are failed to delete.
+func deleteObjectsParallel(
+	ctx context.Context,
+	keys []string,
+	deleteFn func(ctx context.Context, key string) error) map[string]error {
+	var (
+		mux    sync.Mutex
+		wg     sync.WaitGroup
+		failed = make(map[string]error)
+		limit  = make(chan struct{}, DeleteObjectsParallel)
+	)
+	for _, key := range keys {
+		limit <- struct{}{}
+		wg.Add(1)
+		go func(key string) {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+			if err := deleteFn(ctx, key); err != nil {
+				mux.Lock()
+				failed[key] = err
+				mux.Unlock()
+			}
+		}(key)
+	}
+	wg.Wait()
+	return failed
+}
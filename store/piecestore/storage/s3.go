@@ -143,6 +143,43 @@ func (s *s3Store) DeleteObject(ctx context.Context, key string) error {
 	return err
 }
 
+// S3MaxDeleteObjects defines the max number of objects deleted in one multi-object delete
+// request of s3.
+const S3MaxDeleteObjects = 1000
+
+func (s *s3Store) DeleteObjects(ctx context.Context, keys []string) map[string]error {
+	failed := make(map[string]error)
+	for start := 0; start < len(keys); start += S3MaxDeleteObjects {
+		end := start + S3MaxDeleteObjects
+		if end > len(keys) {
+			end = len(keys)
+		}
+		objects := make([]*s3.ObjectIdentifier, 0, end-start)
+		for _, key := range keys[start:end] {
+			objects = append(objects, &s3.ObjectIdentifier{Key: aws.String(key)})
+		}
+		resp, err := s.api.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucketName),
+			Delete: &s3.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			log.Errorw("S3 failed to delete objects", "error", err)
+			for _, key := range keys[start:end] {
+				failed[key] = err
+			}
+			continue
+		}
+		for _, deleteErr := range resp.Errors {
+			if aws.StringValue(deleteErr.Code) == s3.ErrCodeNoSuchKey {
+				continue
+			}
+			failed[aws.StringValue(deleteErr.Key)] = fmt.Errorf("%s: %s",
+				aws.StringValue(deleteErr.Code), aws.StringValue(deleteErr.Message))
+		}
+	}
+	return failed
+}
+
 func (s *s3Store) HeadBucket(ctx context.Context) error {
 	if _, err := s.api.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
 		Bucket: aws.String(s.bucketName),
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -42,6 +43,7 @@ type mockS3Client struct {
 	putObjectResp    s3.PutObjectOutput
 	deleteObjectReq  s3.DeleteObjectInput
 	deleteObjectResp s3.DeleteObjectOutput
+	deleteObjectsErr error
 	listObjectsResp  s3.ListObjectsOutput
 }
 
@@ -73,6 +75,24 @@ func (m mockS3Client) DeleteObjectWithContext(aws.Context, *s3.DeleteObjectInput
 	return &m.deleteObjectResp, nil
 }
 
+func (m mockS3Client) DeleteObjectsWithContext(_ aws.Context, input *s3.DeleteObjectsInput, _ ...request.Option) (
+	*s3.DeleteObjectsOutput, error) {
+	if m.deleteObjectsErr != nil {
+		return nil, m.deleteObjectsErr
+	}
+	resp := &s3.DeleteObjectsOutput{}
+	for _, object := range input.Delete.Objects {
+		switch *object.Key {
+		case "non_existed_object":
+			resp.Errors = append(resp.Errors, &s3.Error{Key: object.Key, Code: aws.String(s3.ErrCodeNoSuchKey)})
+		case "denied_object":
+			resp.Errors = append(resp.Errors, &s3.Error{Key: object.Key, Code: aws.String("AccessDenied"),
+				Message: aws.String("Access Denied")})
+		}
+	}
+	return resp, nil
+}
+
 func (m mockS3Client) HeadObjectWithContext(aws.Context, *s3.HeadObjectInput, ...request.Option) (
 	*s3.HeadObjectOutput, error) {
 	return &m.headObjectResp, nil
@@ -222,6 +242,24 @@ func TestS3_DeleteSuccess(t *testing.T) {
 	}
 }
 
+func TestS3_DeleteObjects(t *testing.T) {
+	store := setupS3Test(t)
+	store.api = mockS3Client{}
+	failed := store.DeleteObjects(context.TODO(), []string{mockKey, "non_existed_object", "denied_object"})
+	assert.Equal(t, 1, len(failed))
+	assert.Equal(t, errors.New("AccessDenied: Access Denied"), failed["denied_object"])
+
+	mockErr := errors.New("mock error")
+	store.api = mockS3Client{deleteObjectsErr: mockErr}
+	keys := make([]string, S3MaxDeleteObjects+1)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s_%d", mockKey, i)
+	}
+	failed = store.DeleteObjects(context.TODO(), keys)
+	assert.Equal(t, len(keys), len(failed))
+	assert.Equal(t, mockErr, failed[keys[S3MaxDeleteObjects]])
+}
+
 func TestS3_HeadSuccess(t *testing.T) {
 	store := setupS3Test(t)
 	cases := []struct {
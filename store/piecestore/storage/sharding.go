@@ -6,6 +6,7 @@ import (
 	"hash/fnv"
 	"io"
 	"strings"
+	"sync"
 )
 
 type sharded struct {
@@ -63,6 +64,33 @@ func (s *sharded) DeleteObject(ctx context.Context, key string) error {
 	return s.pick(key).DeleteObject(ctx, key)
 }
 
+func (s *sharded) DeleteObjects(ctx context.Context, keys []string) map[string]error {
+	shardKeys := make(map[ObjectStorage][]string)
+	for _, key := range keys {
+		store := s.pick(key)
+		shardKeys[store] = append(shardKeys[store], key)
+	}
+	var (
+		mux    sync.Mutex
+		wg     sync.WaitGroup
+		failed = make(map[string]error)
+	)
+	for store, keys := range shardKeys {
+		wg.Add(1)
+		go func(store ObjectStorage, keys []string) {
+			defer wg.Done()
+			shardFailed := store.DeleteObjects(ctx, keys)
+			mux.Lock()
+			defer mux.Unlock()
+			for key, err := range shardFailed {
+				failed[key] = err
+			}
+		}(store, keys)
+	}
+	wg.Wait()
+	return failed
+}
+
 func (s *sharded) HeadBucket(ctx context.Context) error {
 	for _, o := range s.stores {
 		if err := o.HeadBucket(ctx); err != nil {
@@ -0,0 +1,52 @@
+package sqldb
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+// SetAuditFinding is used to set(maybe overwrite) the finding of the self challenge auditor.
+func (s *SpDBImpl) SetAuditFinding(finding *corespdb.AuditFinding) error {
+	result := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&AuditFindingTable{
+		ObjectID:      finding.ObjectID,
+		RedundancyIdx: finding.RedundancyIdx,
+		FindingType:   finding.FindingType,
+		BucketName:    finding.BucketName,
+		ObjectName:    finding.ObjectName,
+		SegmentIdx:    finding.SegmentIdx,
+		Detail:        finding.Detail,
+		Repaired:      finding.Repaired,
+		UpdateTime:    finding.UpdateTime,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to set audit finding: %s", result.Error)
+	}
+	return nil
+}
+
+// ListAuditFindings is used to query the latest updated findings of the self challenge auditor.
+func (s *SpDBImpl) ListAuditFindings(limit int) ([]*corespdb.AuditFinding, error) {
+	var queryReturns []*AuditFindingTable
+	result := s.db.Order("update_time desc, object_id").Limit(limit).Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query audit finding table: %s", result.Error)
+	}
+	findings := make([]*corespdb.AuditFinding, 0, len(queryReturns))
+	for _, queryReturn := range queryReturns {
+		findings = append(findings, &corespdb.AuditFinding{
+			ObjectID:      queryReturn.ObjectID,
+			BucketName:    queryReturn.BucketName,
+			ObjectName:    queryReturn.ObjectName,
+			RedundancyIdx: queryReturn.RedundancyIdx,
+			SegmentIdx:    queryReturn.SegmentIdx,
+			FindingType:   queryReturn.FindingType,
+			Detail:        queryReturn.Detail,
+			Repaired:      queryReturn.Repaired,
+			UpdateTime:    queryReturn.UpdateTime,
+		})
+	}
+	return findings, nil
+}
@@ -0,0 +1,19 @@
+package sqldb
+
+// AuditFindingTable table schema
+type AuditFindingTable struct {
+	ObjectID      uint64 `gorm:"primary_key;autoIncrement:false"`
+	RedundancyIdx int32  `gorm:"primary_key;autoIncrement:false"`
+	FindingType   string `gorm:"primary_key"`
+	BucketName    string
+	ObjectName    string
+	SegmentIdx    uint32
+	Detail        string
+	Repaired      bool
+	UpdateTime    int64 `gorm:"index:idx_update_time"`
+}
+
+// TableName is used to set AuditFindingTable Schema's table name in database
+func (AuditFindingTable) TableName() string {
+	return AuditFindingTableName
+}
@@ -8,6 +8,8 @@ const (
 	ObjectTableName = "object"
 	// GCObjectTaskTableName defines the gc object task table name
 	GCObjectTaskTableName = "gc_object_task"
+	// GCFailedPieceTableName defines the gc failed piece table name
+	GCFailedPieceTableName = "gc_failed_piece"
 	// PieceHashTableName defines the piece hash table name
 	PieceHashTableName = "piece_hash"
 	// IntegrityMetaTableName defines the integrity meta table name
@@ -24,4 +26,24 @@ const (
 	ServiceConfigTableName = "service_config"
 	// OffChainAuthKeyTableName defines the off chain auth key table name
 	OffChainAuthKeyTableName = "off_chain_auth_key"
+	// MigrateBucketProgressTableName defines the migrate bucket progress table name
+	MigrateBucketProgressTableName = "migrate_bucket_progress"
+	// SPExitProgressTableName defines the sp exit progress table name
+	SPExitProgressTableName = "sp_exit_progress"
+	// SPExitRecordTableName defines the sp exit record table name
+	SPExitRecordTableName = "sp_exit_record"
+	// HandoverPieceTableName defines the handover piece table name
+	HandoverPieceTableName = "handover_piece"
+	// AuditFindingTableName defines the self challenge audit finding table name
+	AuditFindingTableName = "audit_finding"
+	// VersionedParamsTableName defines the versioned storage params table name
+	VersionedParamsTableName = "versioned_params"
+	// SPScoreTableName defines the sp score table name
+	SPScoreTableName = "sp_score"
+	// P2PPeerTableName defines the p2p peer table name
+	P2PPeerTableName = "p2p_peer"
+	// NotificationSubscriptionTableName defines the notification subscription table name
+	NotificationSubscriptionTableName = "notification_subscription"
+	// SchemaVersionTableName defines the schema version table name, which is used for recording the applied migrations
+	SchemaVersionTableName = "schema_version"
 )
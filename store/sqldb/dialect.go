@@ -0,0 +1,106 @@
+package sqldb
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+
+	"github.com/bnb-chain/greenfield-storage-provider/store/config"
+)
+
+const (
+	// DefaultPostgresPort defines the default port of PostgreSQL if the address has no port
+	DefaultPostgresPort = "5432"
+	// DefaultSQLiteBusyTimeout defines the default milliseconds to wait for the locked SQLite db file
+	DefaultSQLiteBusyTimeout = 5000
+)
+
+// NewDialector returns the gorm dialector by the dialect of db config, mysql is
+// used if the dialect is empty.
+func NewDialector(cfg *config.SQLDBConfig) (gorm.Dialector, error) {
+	var dsn string
+	switch strings.ToLower(cfg.Dialect) {
+	case "", config.MySQLDialect:
+		dsn = fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.User, cfg.Passwd, cfg.Address, cfg.Database)
+	case config.PostgresDialect:
+		host, port, err := net.SplitHostPort(cfg.Address)
+		if err != nil {
+			host, port = cfg.Address, DefaultPostgresPort
+		}
+		dsn = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s",
+			host, port, cfg.User, cfg.Passwd, cfg.Database)
+	case config.SQLiteDialect:
+		dsn = cfg.Database
+	}
+	return NewDialectorByDSN(cfg.Dialect, dsn)
+}
+
+// NewDialectorByDSN returns the gorm dialector by the dialect and the dsn, mysql is used if
+// the dialect is empty. The dsn of SQLite is the path of db file.
+func NewDialectorByDSN(dialect, dsn string) (gorm.Dialector, error) {
+	switch strings.ToLower(dialect) {
+	case "", config.MySQLDialect:
+		return mysql.Open(dsn), nil
+	case config.PostgresDialect:
+		return postgresDialector{Dialector: &postgres.Dialector{Config: &postgres.Config{DSN: dsn}}}, nil
+	case config.SQLiteDialect:
+		if !strings.Contains(dsn, "?") {
+			dsn = fmt.Sprintf("%s?_busy_timeout=%d", dsn, DefaultSQLiteBusyTimeout)
+		}
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported sql db dialect: %s", dialect)
+	}
+}
+
+// IsSQLite returns whether the db config uses the SQLite dialect.
+func IsSQLite(cfg *config.SQLDBConfig) bool {
+	return strings.ToLower(cfg.Dialect) == config.SQLiteDialect
+}
+
+// postgresDialector translates the MySQL only column types, e.g. BINARY(32) of the hashes in
+// the juno models, to the PostgreSQL types when the tables are created. SQLite accepts these
+// types by its type affinity, so it needs no translation. The PostgreSQL dialector is embedded
+// by its concrete type, so its SavePoint and RollbackTo are promoted and the nested transactions
+// keep using the savepoints.
+type postgresDialector struct {
+	*postgres.Dialector
+}
+
+// Migrator returns the PostgreSQL migrator that gets the column types from the dialector.
+func (d postgresDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return postgres.Migrator{Migrator: migrator.Migrator{Config: migrator.Config{
+		DB:                          db,
+		Dialector:                   d,
+		CreateIndexAfterCreateTable: true,
+	}}}
+}
+
+// DataTypeOf returns the PostgreSQL column type of the field.
+func (d postgresDialector) DataTypeOf(field *schema.Field) string {
+	return PortableDataType(d.Dialector.DataTypeOf(field))
+}
+
+// PortableDataType translates the MySQL only column type to the type supported by PostgreSQL,
+// the other types are returned as is.
+func PortableDataType(dataType string) string {
+	lower := strings.ToLower(dataType)
+	switch {
+	case strings.HasPrefix(lower, "binary("), strings.HasPrefix(lower, "varbinary("), strings.HasSuffix(lower, "blob"):
+		return "bytea"
+	case strings.HasPrefix(lower, "bigint("):
+		return "bigint"
+	case strings.HasSuffix(lower, "text"):
+		return "text"
+	default:
+		return dataType
+	}
+}
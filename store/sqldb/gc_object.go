@@ -4,7 +4,9 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
 	"github.com/bnb-chain/greenfield-storage-provider/core/task"
 )
 
@@ -36,3 +38,58 @@ func (s *SpDBImpl) DeleteGCObjectProgress(taskKey string) error {
 // GetAllGCObjectTask is unused.
 // TODO: will be implemented in the future, may be used in startup.
 func (s *SpDBImpl) GetAllGCObjectTask(taskKey string) []task.GCObjectTask { return nil }
+
+// SetGCFailedPieces is used to set(maybe overwrite) the pieces that gc object task failed to delete.
+func (s *SpDBImpl) SetGCFailedPieces(pieces []*corespdb.GCFailedPiece) error {
+	if len(pieces) == 0 {
+		return nil
+	}
+	insertPieces := make([]*GCFailedPieceTable, 0, len(pieces))
+	for _, piece := range pieces {
+		insertPieces = append(insertPieces, &GCFailedPieceTable{
+			PieceKey:         piece.PieceKey,
+			ObjectID:         piece.ObjectID,
+			RetryCount:       piece.RetryCount,
+			ErrorDescription: piece.ErrorDescription,
+			UpdateTime:       piece.UpdateTime,
+		})
+	}
+	result := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&insertPieces)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set gc failed pieces: %s", result.Error)
+	}
+	return nil
+}
+
+// ListGCFailedPieces is used to query the earliest updated pieces whose retry count is less than max retry.
+func (s *SpDBImpl) ListGCFailedPieces(maxRetry int, limit int) ([]*corespdb.GCFailedPiece, error) {
+	var queryReturns []*GCFailedPieceTable
+	result := s.db.Where("retry_count < ?", maxRetry).Order("update_time, piece_key").
+		Limit(limit).Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query gc failed piece table: %s", result.Error)
+	}
+	pieces := make([]*corespdb.GCFailedPiece, 0, len(queryReturns))
+	for _, queryReturn := range queryReturns {
+		pieces = append(pieces, &corespdb.GCFailedPiece{
+			PieceKey:         queryReturn.PieceKey,
+			ObjectID:         queryReturn.ObjectID,
+			RetryCount:       queryReturn.RetryCount,
+			ErrorDescription: queryReturn.ErrorDescription,
+			UpdateTime:       queryReturn.UpdateTime,
+		})
+	}
+	return pieces, nil
+}
+
+// DeleteGCFailedPieces is used to delete the failed pieces that are deleted by retry.
+func (s *SpDBImpl) DeleteGCFailedPieces(pieceKeys []string) error {
+	if len(pieceKeys) == 0 {
+		return nil
+	}
+	result := s.db.Where("piece_key IN ?", pieceKeys).Delete(&GCFailedPieceTable{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete gc failed pieces: %s", result.Error)
+	}
+	return nil
+}
@@ -11,3 +11,17 @@ type GCObjectTaskTable struct {
 func (GCObjectTaskTable) TableName() string {
 	return GCObjectTaskTableName
 }
+
+// GCFailedPieceTable table schema
+type GCFailedPieceTable struct {
+	PieceKey         string `gorm:"primary_key"`
+	ObjectID         uint64 `gorm:"index:idx_object_id"`
+	RetryCount       int
+	ErrorDescription string
+	UpdateTime       int64 `gorm:"index:idx_gc_failed_piece_update_time"`
+}
+
+// TableName is used to set GCFailedPieceTable Schema's table name in database
+func (GCFailedPieceTable) TableName() string {
+	return GCFailedPieceTableName
+}
@@ -0,0 +1,71 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+// SetMigrateBucketProgress is used to set(maybe overwrite) the progress of migrating bucket.
+func (s *SpDBImpl) SetMigrateBucketProgress(progress *corespdb.MigrateBucketProgress) error {
+	result := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&MigrateBucketProgressTable{
+		BucketName:             progress.BucketName,
+		BucketID:               progress.BucketID,
+		SrcSpEndpoint:          progress.SrcSpEndpoint,
+		LastMigratedObjectName: progress.LastMigratedObjectName,
+		MigratedObjectNumber:   progress.MigratedObjectNumber,
+		Finished:               progress.Finished,
+		UpdateTime:             progress.UpdateTime,
+		OwnerSignature:         progress.OwnerSignature,
+		ApprovalExpiry:         progress.ApprovalExpiry,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to set migrate bucket progress: %s", result.Error)
+	}
+	return nil
+}
+
+// GetMigrateBucketProgress is used to query the progress of migrating bucket by bucket name.
+func (s *SpDBImpl) GetMigrateBucketProgress(bucketName string) (*corespdb.MigrateBucketProgress, error) {
+	queryReturn := &MigrateBucketProgressTable{}
+	result := s.db.First(queryReturn, "bucket_name = ?", bucketName)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query migrate bucket progress table: %s", result.Error)
+	}
+	return toMigrateBucketProgress(queryReturn), nil
+}
+
+// ListUnfinishedMigrateBucketProgress is used to query the progress of all unfinished migrating buckets.
+func (s *SpDBImpl) ListUnfinishedMigrateBucketProgress() ([]*corespdb.MigrateBucketProgress, error) {
+	var queryReturns []*MigrateBucketProgressTable
+	result := s.db.Where("finished = ?", false).Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query migrate bucket progress table: %s", result.Error)
+	}
+	progresses := make([]*corespdb.MigrateBucketProgress, 0, len(queryReturns))
+	for _, queryReturn := range queryReturns {
+		progresses = append(progresses, toMigrateBucketProgress(queryReturn))
+	}
+	return progresses, nil
+}
+
+func toMigrateBucketProgress(table *MigrateBucketProgressTable) *corespdb.MigrateBucketProgress {
+	return &corespdb.MigrateBucketProgress{
+		BucketName:             table.BucketName,
+		BucketID:               table.BucketID,
+		SrcSpEndpoint:          table.SrcSpEndpoint,
+		LastMigratedObjectName: table.LastMigratedObjectName,
+		MigratedObjectNumber:   table.MigratedObjectNumber,
+		Finished:               table.Finished,
+		UpdateTime:             table.UpdateTime,
+		OwnerSignature:         table.OwnerSignature,
+		ApprovalExpiry:         table.ApprovalExpiry,
+	}
+}
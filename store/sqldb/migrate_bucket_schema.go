@@ -0,0 +1,19 @@
+package sqldb
+
+// MigrateBucketProgressTable table schema
+type MigrateBucketProgressTable struct {
+	BucketName             string `gorm:"primary_key"`
+	BucketID               uint64
+	SrcSpEndpoint          string
+	LastMigratedObjectName string
+	MigratedObjectNumber   uint64
+	Finished               bool `gorm:"index:idx_finished"`
+	UpdateTime             int64
+	OwnerSignature         []byte
+	ApprovalExpiry         int64
+}
+
+// TableName is used to set MigrateBucketProgressTable Schema's table name in database
+func (MigrateBucketProgressTable) TableName() string {
+	return MigrateBucketProgressTableName
+}
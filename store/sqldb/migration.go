@@ -0,0 +1,255 @@
+package sqldb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-storage-provider/pkg/log"
+	"github.com/bnb-chain/greenfield-storage-provider/store/config"
+)
+
+const (
+	// MigrationLockName defines the name of the MySQL lock that serializes the migrations of
+	// the SP processes sharing the db
+	MigrationLockName = "greenfield_spdb_migration"
+	// MigrationLockKey defines the key of the PostgreSQL advisory lock that serializes the
+	// migrations of the SP processes sharing the db
+	MigrationLockKey = 0x6766737064626d67
+	// MigrationLockTimeout defines the timeout to wait for the migration lock
+	MigrationLockTimeout = 10 * time.Minute
+	// MigrationLockRetryInterval defines the interval of retrying to acquire the migration lock
+	MigrationLockRetryInterval = time.Second
+)
+
+var (
+	// ErrUnknownSchemaVersion is returned if the db schema is newer than the latest migration,
+	// the db has been migrated by a newer SP release.
+	ErrUnknownSchemaVersion = errors.New("unknown newer spdb schema version")
+	// ErrInvalidSchemaVersion is returned if the target version of migration does not exist.
+	ErrInvalidSchemaVersion = errors.New("invalid spdb schema version")
+	// ErrMigrationLockTimeout is returned if the migration lock is not acquired in time.
+	ErrMigrationLockTimeout = errors.New("timeout to acquire the spdb migration lock")
+)
+
+// Migration defines a versioned schema change of SPDB, Up applies the change
+// and Down reverts it. The migrations must be appended with increasing version
+// and the released migrations must not be modified.
+type Migration struct {
+	Version     uint32
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error
+}
+
+// MigrationStatus is the applied status of a migration.
+type MigrationStatus struct {
+	Version     uint32
+	Description string
+	Applied     bool
+	AppliedTime time.Time
+}
+
+// Migrator applies the versioned migrations to SPDB and records the applied
+// versions in the schema version table.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []*Migration
+}
+
+// NewMigrator returns the migrator of SPDB migrations.
+func NewMigrator(db *gorm.DB) *Migrator {
+	return &Migrator{db: db, migrations: spdbMigrations}
+}
+
+// LatestVersion returns the version of the latest migration.
+func (m *Migrator) LatestVersion() uint32 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// CurrentVersion returns the version of the latest applied migration, returns 0 if
+// no migration has been applied.
+func (m *Migrator) CurrentVersion() (uint32, error) {
+	if err := m.db.AutoMigrate(&SchemaVersionTable{}); err != nil {
+		return 0, fmt.Errorf("failed to create schema version table: %s", err)
+	}
+	queryReturn := &SchemaVersionTable{}
+	result := m.db.Order("version desc").Limit(1).Find(queryReturn)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to query schema version table: %s", result.Error)
+	}
+	return queryReturn.Version, nil
+}
+
+// Check returns ErrUnknownSchemaVersion if the db schema is newer than the latest
+// migration, SP refuses to run against it.
+func (m *Migrator) Check() error {
+	current, err := m.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	if current > m.LatestVersion() {
+		log.Errorw("spdb schema is newer than the latest migration", "current_version", current,
+			"latest_version", m.LatestVersion())
+		return ErrUnknownSchemaVersion
+	}
+	return nil
+}
+
+// Status returns the applied status of all migrations.
+func (m *Migrator) Status() ([]*MigrationStatus, error) {
+	if _, err := m.CurrentVersion(); err != nil {
+		return nil, err
+	}
+	var records []SchemaVersionTable
+	if result := m.db.Find(&records); result.Error != nil {
+		return nil, fmt.Errorf("failed to query schema version table: %s", result.Error)
+	}
+	applied := make(map[uint32]SchemaVersionTable, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	status := make([]*MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		status = append(status, &MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Applied:     ok,
+			AppliedTime: record.AppliedTime,
+		})
+	}
+	return status, nil
+}
+
+// withLock runs fn with the migrator pinned to one connection that holds the db level
+// migration lock, so the SP processes sharing the db apply the migrations one by one and
+// the later ones see the versions applied by the former. SQLite is used by a single SP
+// process and needs no lock.
+func (m *Migrator) withLock(fn func(locked *Migrator) error) error {
+	var tryLock, unlock string
+	switch m.db.Dialector.Name() {
+	case config.MySQLDialect:
+		tryLock = fmt.Sprintf("SELECT GET_LOCK('%s', 0)", MigrationLockName)
+		unlock = fmt.Sprintf("SELECT RELEASE_LOCK('%s')", MigrationLockName)
+	case config.PostgresDialect:
+		tryLock = fmt.Sprintf("SELECT CASE WHEN pg_try_advisory_lock(%d) THEN 1 ELSE 0 END", int64(MigrationLockKey))
+		unlock = fmt.Sprintf("SELECT pg_advisory_unlock(%d)", int64(MigrationLockKey))
+	default:
+		return fn(m)
+	}
+	return m.db.Connection(func(conn *gorm.DB) error {
+		deadline := time.Now().Add(MigrationLockTimeout)
+		for {
+			var acquired sql.NullInt64
+			if err := conn.Raw(tryLock).Scan(&acquired).Error; err != nil {
+				return fmt.Errorf("failed to acquire spdb migration lock: %s", err)
+			}
+			if acquired.Int64 == 1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				return ErrMigrationLockTimeout
+			}
+			log.Infow("waiting for spdb migration lock held by another process")
+			time.Sleep(MigrationLockRetryInterval)
+		}
+		defer func() {
+			if err := conn.Exec(unlock).Error; err != nil {
+				log.Errorw("failed to release spdb migration lock", "error", err)
+			}
+		}()
+		return fn(&Migrator{db: conn, migrations: m.migrations})
+	})
+}
+
+// Up applies the migrations until the target version, the latest version is the
+// target if target is 0. The migrations are applied under the db level migration lock.
+func (m *Migrator) Up(target uint32) error {
+	return m.withLock(func(locked *Migrator) error {
+		return locked.up(target)
+	})
+}
+
+func (m *Migrator) up(target uint32) error {
+	if target == 0 {
+		target = m.LatestVersion()
+	}
+	if target > m.LatestVersion() {
+		return ErrInvalidSchemaVersion
+	}
+	if err := m.Check(); err != nil {
+		return err
+	}
+	current, err := m.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	for _, migration := range m.migrations {
+		if migration.Version <= current || migration.Version > target {
+			continue
+		}
+		if err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaVersionTable{
+				Version:     migration.Version,
+				Description: migration.Description,
+				AppliedTime: time.Now(),
+			}).Error
+		}); err != nil {
+			log.Errorw("failed to apply spdb migration", "version", migration.Version, "error", err)
+			return fmt.Errorf("failed to apply migration %d: %s", migration.Version, err)
+		}
+		log.Infow("succeed to apply spdb migration", "version", migration.Version,
+			"description", migration.Description)
+	}
+	return nil
+}
+
+// Down reverts the applied migrations newer than the target version, all the
+// migrations are reverted if target is 0. The migrations are reverted under the db
+// level migration lock.
+func (m *Migrator) Down(target uint32) error {
+	return m.withLock(func(locked *Migrator) error {
+		return locked.down(target)
+	})
+}
+
+func (m *Migrator) down(target uint32) error {
+	if err := m.Check(); err != nil {
+		return err
+	}
+	current, err := m.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	if target > current {
+		return ErrInvalidSchemaVersion
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version > current || migration.Version <= target {
+			continue
+		}
+		if err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaVersionTable{Version: migration.Version}).Error
+		}); err != nil {
+			log.Errorw("failed to revert spdb migration", "version", migration.Version, "error", err)
+			return fmt.Errorf("failed to revert migration %d: %s", migration.Version, err)
+		}
+		log.Infow("succeed to revert spdb migration", "version", migration.Version,
+			"description", migration.Description)
+	}
+	return nil
+}
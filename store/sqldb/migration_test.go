@@ -0,0 +1,101 @@
+package sqldb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-storage-provider/store/config"
+)
+
+type mockMigrationTable struct {
+	ID uint64 `gorm:"primary_key"`
+}
+
+func setupMigrator(t *testing.T) *Migrator {
+	cfg := &config.SQLDBConfig{
+		Dialect:  config.SQLiteDialect,
+		Database: filepath.Join(t.TempDir(), "spdb.db"),
+	}
+	OverrideConfigVacancy(cfg)
+	db, err := OpenDB(cfg)
+	require.NoError(t, err)
+	migrator := NewMigrator(db)
+	migrator.migrations = append(append([]*Migration{}, spdbMigrations...), &Migration{
+		Version:     migrator.LatestVersion() + 1,
+		Description: "create the mock table",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&mockMigrationTable{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&mockMigrationTable{})
+		},
+	})
+	return migrator
+}
+
+func TestMigratorUpAndDown(t *testing.T) {
+	migrator := setupMigrator(t)
+	latest := migrator.LatestVersion()
+
+	assert.Nil(t, migrator.Up(latest-1))
+	current, err := migrator.CurrentVersion()
+	assert.Nil(t, err)
+	assert.Equal(t, latest-1, current)
+	assert.True(t, migrator.db.Migrator().HasTable(&JobTable{}))
+	assert.False(t, migrator.db.Migrator().HasTable(&mockMigrationTable{}))
+
+	assert.Nil(t, migrator.Up(0))
+	status, err := migrator.Status()
+	assert.Nil(t, err)
+	for _, migration := range status {
+		assert.True(t, migration.Applied)
+	}
+	assert.True(t, migrator.db.Migrator().HasTable(&mockMigrationTable{}))
+	assert.Equal(t, ErrInvalidSchemaVersion, migrator.Up(latest+1))
+
+	assert.Nil(t, migrator.Down(0))
+	current, err = migrator.CurrentVersion()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), current)
+	assert.False(t, migrator.db.Migrator().HasTable(&JobTable{}))
+}
+
+func TestMigratorRefuseNewerSchema(t *testing.T) {
+	migrator := setupMigrator(t)
+	assert.Nil(t, migrator.Up(0))
+
+	// the migrator of older release does not know the latest migration
+	older := &Migrator{db: migrator.db, migrations: spdbMigrations}
+	assert.Equal(t, ErrUnknownSchemaVersion, older.Check())
+	assert.Equal(t, ErrUnknownSchemaVersion, older.Up(0))
+	assert.Equal(t, ErrUnknownSchemaVersion, older.Down(0))
+}
+
+func TestMigrationsMatchTableSchemas(t *testing.T) {
+	migrator := setupMigrator(t)
+	migrator.migrations = spdbMigrations
+	require.NoError(t, migrator.Up(0))
+
+	// the migrations declare the snapshots of the tables, the current table schemas must not
+	// have columns that no migration adds
+	tables := []interface{}{
+		&JobTable{}, &ObjectTable{}, &GCObjectTaskTable{}, &SpInfoTable{}, &StorageParamsTable{},
+		&PieceHashTable{}, &IntegrityMetaTable{}, &BucketTrafficTable{}, &ReadRecordTable{},
+		&ServiceConfigTable{}, &OffChainAuthKeyTable{}, &MigrateBucketProgressTable{},
+		&SPExitProgressTable{}, &SPExitRecordTable{}, &AuditFindingTable{}, &VersionedParamsTable{},
+		&GCFailedPieceTable{}, &SPScoreTable{}, &P2PPeerTable{}, &NotificationSubscriptionTable{},
+		&HandoverPieceTable{},
+	}
+	for _, table := range tables {
+		stmt := &gorm.Statement{DB: migrator.db}
+		require.NoError(t, stmt.Parse(table))
+		for _, field := range stmt.Schema.Fields {
+			assert.True(t, migrator.db.Migrator().HasColumn(table, field.DBName),
+				"column %s.%s is not migrated", stmt.Schema.Table, field.DBName)
+		}
+	}
+}
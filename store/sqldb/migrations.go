@@ -0,0 +1,361 @@
+package sqldb
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// spdbMigrations defines the SPDB migrations in version order, new schema change
+// is appended as a new migration instead of modifying the released ones. Each
+// migration declares the snapshot of the tables it changes rather than using the
+// table schemas of the current release, so a later change of the table schemas
+// does not change the released migrations.
+var spdbMigrations = []*Migration{
+	{
+		Version:     1,
+		Description: "create the initial tables",
+		// the tables may exist if they are created before the migrations are introduced
+		Up: createInitialTables,
+		Down: dropTables(JobTableName, ObjectTableName, GCObjectTaskTableName, SpInfoTableName,
+			StorageParamsTableName, PieceHashTableName, IntegrityMetaTableName, BucketTrafficTableName,
+			ReadRecordTableName, ServiceConfigTableName, OffChainAuthKeyTableName),
+	},
+	{
+		Version:     2,
+		Description: "create the migrate bucket progress table",
+		Up: func(tx *gorm.DB) error {
+			type migrateBucketProgress struct {
+				BucketName             string `gorm:"primary_key"`
+				BucketID               uint64
+				SrcSpEndpoint          string
+				LastMigratedObjectName string
+				MigratedObjectNumber   uint64
+				Finished               bool `gorm:"index:idx_finished"`
+				UpdateTime             int64
+			}
+			return tx.Table(MigrateBucketProgressTableName).AutoMigrate(&migrateBucketProgress{})
+		},
+		Down: dropTables(MigrateBucketProgressTableName),
+	},
+	{
+		Version:     3,
+		Description: "create the sp exit progress and record tables",
+		Up: func(tx *gorm.DB) error {
+			type spExitProgress struct {
+				SpAddress             string `gorm:"primary_key"`
+				LastSecondaryObjectID uint64
+				LastPrimaryBucketID   uint64
+				HandoverObjectNumber  uint64
+				FailedObjectNumber    uint64
+				HandoverBucketNumber  uint64
+				FailedBucketNumber    uint64
+				Finished              bool
+				StartTime             int64
+				UpdateTime            int64
+			}
+			type spExitRecord struct {
+				ResourceType  string `gorm:"primary_key"`
+				ResourceID    uint64 `gorm:"primary_key;autoIncrement:false"`
+				ResourceName  string
+				DestSpAddress string
+				Succeed       bool `gorm:"index:idx_succeed"`
+				ErrorMessage  string
+				UpdateTime    int64
+			}
+			if err := tx.Table(SPExitProgressTableName).AutoMigrate(&spExitProgress{}); err != nil {
+				return err
+			}
+			return tx.Table(SPExitRecordTableName).AutoMigrate(&spExitRecord{})
+		},
+		Down: dropTables(SPExitProgressTableName, SPExitRecordTableName),
+	},
+	{
+		Version:     4,
+		Description: "create the audit finding table",
+		Up: func(tx *gorm.DB) error {
+			type auditFinding struct {
+				ObjectID      uint64 `gorm:"primary_key;autoIncrement:false"`
+				RedundancyIdx int32  `gorm:"primary_key;autoIncrement:false"`
+				FindingType   string `gorm:"primary_key"`
+				BucketName    string
+				ObjectName    string
+				SegmentIdx    uint32
+				Detail        string
+				Repaired      bool
+				UpdateTime    int64 `gorm:"index:idx_update_time"`
+			}
+			return tx.Table(AuditFindingTableName).AutoMigrate(&auditFinding{})
+		},
+		Down: dropTables(AuditFindingTableName),
+	},
+	{
+		Version:     5,
+		Description: "create the versioned params table",
+		Up: func(tx *gorm.DB) error {
+			type versionedParams struct {
+				EffectiveTime           int64 `gorm:"primary_key;autoIncrement:false"`
+				EndTime                 int64
+				MaxSegmentSize          uint64
+				RedundantDataChunkNum   uint32
+				RedundantParityChunkNum uint32
+				MinChargeSize           uint64
+			}
+			return tx.Table(VersionedParamsTableName).AutoMigrate(&versionedParams{})
+		},
+		Down: dropTables(VersionedParamsTableName),
+	},
+	{
+		Version:     6,
+		Description: "create the gc failed piece table",
+		Up: func(tx *gorm.DB) error {
+			type gcFailedPiece struct {
+				PieceKey         string `gorm:"primary_key"`
+				ObjectID         uint64 `gorm:"index:idx_object_id"`
+				RetryCount       int
+				ErrorDescription string
+				UpdateTime       int64 `gorm:"index:idx_gc_failed_piece_update_time"`
+			}
+			return tx.Table(GCFailedPieceTableName).AutoMigrate(&gcFailedPiece{})
+		},
+		Down: dropTables(GCFailedPieceTableName),
+	},
+	{
+		Version:     7,
+		Description: "create the sp score table",
+		Up: func(tx *gorm.DB) error {
+			type spScore struct {
+				SpAddress        string `gorm:"primary_key"`
+				ReplicateSucceed uint64
+				ReplicateFailed  uint64
+				ReplicateBytes   uint64
+				ReplicateCost    int64
+				PingRTT          int64
+				P2PFailure       int64
+				UpdateTime       int64
+			}
+			return tx.Table(SPScoreTableName).AutoMigrate(&spScore{})
+		},
+		Down: dropTables(SPScoreTableName),
+	},
+	{
+		Version:     8,
+		Description: "create the p2p peer table",
+		Up: func(tx *gorm.DB) error {
+			type p2pPeer struct {
+				PeerID    string `gorm:"primary_key"`
+				SpAddress string
+				Addrs     string
+				FailCount int
+				LastSeen  int64
+			}
+			return tx.Table(P2PPeerTableName).AutoMigrate(&p2pPeer{})
+		},
+		Down: dropTables(P2PPeerTableName),
+	},
+	{
+		Version:     9,
+		Description: "create the notification subscription table",
+		Up: func(tx *gorm.DB) error {
+			type notificationSubscription struct {
+				ID         uint64 `gorm:"primary_key;autoIncrement"`
+				Account    string `gorm:"index:idx_account"`
+				BucketName string `gorm:"index:idx_target"`
+				Owner      string `gorm:"index:idx_target"`
+				URL        string
+				Secret     string
+				EventTypes string
+				CreateTime int64
+			}
+			return tx.Table(NotificationSubscriptionTableName).AutoMigrate(&notificationSubscription{})
+		},
+		Down: dropTables(NotificationSubscriptionTableName),
+	},
+	{
+		Version:     10,
+		Description: "add the owner approval columns to the migrate bucket progress table",
+		Up: func(tx *gorm.DB) error {
+			// the columns exist if the table is created by the releases that migrated the
+			// current table schema in version 2
+			migrator := tx.Table(MigrateBucketProgressTableName).Migrator()
+			for _, column := range migrateBucketApprovalColumns {
+				if migrator.HasColumn(&migrateBucketApproval{}, column) {
+					continue
+				}
+				if err := migrator.AddColumn(&migrateBucketApproval{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			migrator := tx.Table(MigrateBucketProgressTableName).Migrator()
+			for _, column := range migrateBucketApprovalColumns {
+				if err := migrator.DropColumn(&migrateBucketApproval{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     11,
+		Description: "create the handover piece table",
+		Up: func(tx *gorm.DB) error {
+			type handoverPiece struct {
+				ObjectID       uint64 `gorm:"primary_key;autoIncrement:false"`
+				ReplicateIdx   uint32 `gorm:"primary_key;autoIncrement:false"`
+				SrcSpAddress   string
+				SegmentCount   uint32
+				RedundancyType int32
+				Done           bool
+				UpdateTime     int64 `gorm:"index:idx_handover_update_time"`
+			}
+			return tx.Table(HandoverPieceTableName).AutoMigrate(&handoverPiece{})
+		},
+		Down: dropTables(HandoverPieceTableName),
+	},
+}
+
+// migrateBucketApproval is the snapshot of the columns added to the migrate bucket progress
+// table by version 10.
+type migrateBucketApproval struct {
+	BucketName     string `gorm:"primary_key"`
+	OwnerSignature []byte
+	ApprovalExpiry int64
+}
+
+var migrateBucketApprovalColumns = []string{"OwnerSignature", "ApprovalExpiry"}
+
+// dropTables returns the migration that drops the tables in the reverse order.
+func dropTables(tables ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for i := len(tables) - 1; i >= 0; i-- {
+			if err := tx.Migrator().DropTable(tables[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// createInitialTables creates the tables of the initial schema.
+func createInitialTables(tx *gorm.DB) error {
+	type job struct {
+		JobID        uint64 `gorm:"primary_key;autoIncrement"`
+		JobType      int32
+		JobState     int32
+		JobErrorCode uint32
+		CreatedTime  time.Time
+		ModifiedTime time.Time
+	}
+	type object struct {
+		ObjectID             uint64 `gorm:"primary_key"`
+		JobID                uint64 `gorm:"index:job_to_object"`
+		Owner                string
+		BucketName           string
+		ObjectName           string
+		PayloadSize          uint64
+		Visibility           int32
+		ContentType          string
+		CreatedAtHeight      int64
+		ObjectStatus         int32
+		RedundancyType       int32
+		SourceType           int32
+		SpIntegrityHash      string
+		SecondarySpAddresses string
+	}
+	type gcObjectTask struct {
+		TaskKey                string `gorm:"primary_key"`
+		CurrentDeletingBlockID uint64
+		LastDeletedObjectID    uint64
+	}
+	type spInfo struct {
+		OperatorAddress string `gorm:"primary_key"`
+		IsOwn           bool   `gorm:"primary_key"`
+		FundingAddress  string
+		SealAddress     string
+		ApprovalAddress string
+		TotalDeposit    string
+		Status          int32
+		Endpoint        string
+		Moniker         string
+		Identity        string
+		Website         string
+		SecurityContact string
+		Details         string
+	}
+	type storageParams struct {
+		ID                      int64 `gorm:"primary_key;autoIncrement"`
+		MaxSegmentSize          uint64
+		RedundantDataChunkNum   uint32
+		RedundantParityChunkNum uint32
+		MaxPayloadSize          uint64
+	}
+	type pieceHash struct {
+		ObjectID       uint64 `gorm:"primary_key"`
+		ReplicateIndex uint32 `gorm:"primary_key"`
+		PieceIndex     uint32 `gorm:"primary_key"`
+		PieceChecksum  string
+	}
+	type integrityMeta struct {
+		ObjectID          uint64 `gorm:"primary_key"`
+		IntegrityChecksum string
+		PieceChecksumList string
+		Signature         string
+	}
+	type bucketTraffic struct {
+		BucketID         uint64 `gorm:"primary_key"`
+		Month            string `gorm:"primary_key"`
+		BucketName       string
+		ReadConsumedSize uint64
+		ReadQuotaSize    uint64
+		ModifiedTime     time.Time
+	}
+	type readRecord struct {
+		ReadRecordID    uint64 `gorm:"primary_key;autoIncrement"`
+		BucketID        uint64 `gorm:"index:bucket_to_read_record"`
+		ObjectID        uint64 `gorm:"index:object_to_read_record"`
+		UserAddress     string `gorm:"index:user_to_read_record"`
+		ReadTimestampUs int64  `gorm:"index:time_to_read_record"`
+		BucketName      string
+		ObjectName      string
+		ReadSize        uint64
+	}
+	type serviceConfig struct {
+		ConfigVersion string `gorm:"primary_key"`
+		ServiceConfig string
+	}
+	type offChainAuthKey struct {
+		UserAddress      string `gorm:"primary_key"`
+		Domain           string `gorm:"primary_key"`
+		CurrentNonce     int32
+		CurrentPublicKey string
+		NextNonce        int32
+		ExpiryDate       time.Time
+		CreatedTime      time.Time
+		ModifiedTime     time.Time
+	}
+	tables := []struct {
+		name  string
+		value interface{}
+	}{
+		{JobTableName, &job{}},
+		{ObjectTableName, &object{}},
+		{GCObjectTaskTableName, &gcObjectTask{}},
+		{SpInfoTableName, &spInfo{}},
+		{StorageParamsTableName, &storageParams{}},
+		{PieceHashTableName, &pieceHash{}},
+		{IntegrityMetaTableName, &integrityMeta{}},
+		{BucketTrafficTableName, &bucketTraffic{}},
+		{ReadRecordTableName, &readRecord{}},
+		{ServiceConfigTableName, &serviceConfig{}},
+		{OffChainAuthKeyTableName, &offChainAuthKey{}},
+	}
+	for _, table := range tables {
+		if err := tx.Table(table.name).AutoMigrate(table.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
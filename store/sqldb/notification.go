@@ -0,0 +1,98 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+// CreateNotificationSubscription is used to create the subscription and return the subscription id.
+func (s *SpDBImpl) CreateNotificationSubscription(subscription *corespdb.NotificationSubscription) (uint64, error) {
+	insertSubscription := &NotificationSubscriptionTable{
+		Account:    subscription.Account,
+		BucketName: subscription.BucketName,
+		Owner:      subscription.Owner,
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		EventTypes: strings.Join(subscription.EventTypes, ","),
+		CreateTime: subscription.CreateTime,
+	}
+	result := s.db.Create(insertSubscription)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to create notification subscription: %s", result.Error)
+	}
+	return insertSubscription.ID, nil
+}
+
+// DeleteNotificationSubscription is used to delete the subscription of the account by id.
+func (s *SpDBImpl) DeleteNotificationSubscription(id uint64, account string) error {
+	result := s.db.Where("id = ? and account = ?", id, account).Delete(&NotificationSubscriptionTable{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete notification subscription: %s", result.Error)
+	}
+	return nil
+}
+
+// GetNotificationSubscription is used to query the subscription by id.
+func (s *SpDBImpl) GetNotificationSubscription(id uint64) (*corespdb.NotificationSubscription, error) {
+	queryReturn := &NotificationSubscriptionTable{}
+	result := s.db.First(queryReturn, "id = ?", id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query notification subscription table: %s", result.Error)
+	}
+	return toNotificationSubscription(queryReturn), nil
+}
+
+// ListNotificationSubscriptions is used to query the subscriptions managed by the account.
+func (s *SpDBImpl) ListNotificationSubscriptions(account string) ([]*corespdb.NotificationSubscription, error) {
+	var queryReturns []*NotificationSubscriptionTable
+	result := s.db.Where("account = ?", account).Order("id").Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query notification subscription table: %s", result.Error)
+	}
+	return toNotificationSubscriptions(queryReturns), nil
+}
+
+// ListNotificationSubscriptionsByTarget is used to query the subscriptions of the bucket
+// or of the owner.
+func (s *SpDBImpl) ListNotificationSubscriptionsByTarget(bucketName string, owner string) ([]*corespdb.NotificationSubscription, error) {
+	var queryReturns []*NotificationSubscriptionTable
+	result := s.db.Where("(bucket_name = ? and owner = '') or (owner = ? and bucket_name = '')", bucketName, owner).
+		Order("id").Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query notification subscription table: %s", result.Error)
+	}
+	return toNotificationSubscriptions(queryReturns), nil
+}
+
+func toNotificationSubscriptions(queryReturns []*NotificationSubscriptionTable) []*corespdb.NotificationSubscription {
+	subscriptions := make([]*corespdb.NotificationSubscription, 0, len(queryReturns))
+	for _, queryReturn := range queryReturns {
+		subscriptions = append(subscriptions, toNotificationSubscription(queryReturn))
+	}
+	return subscriptions
+}
+
+func toNotificationSubscription(queryReturn *NotificationSubscriptionTable) *corespdb.NotificationSubscription {
+	var eventTypes []string
+	if queryReturn.EventTypes != "" {
+		eventTypes = strings.Split(queryReturn.EventTypes, ",")
+	}
+	return &corespdb.NotificationSubscription{
+		ID:         queryReturn.ID,
+		Account:    queryReturn.Account,
+		BucketName: queryReturn.BucketName,
+		Owner:      queryReturn.Owner,
+		URL:        queryReturn.URL,
+		Secret:     queryReturn.Secret,
+		EventTypes: eventTypes,
+		CreateTime: queryReturn.CreateTime,
+	}
+}
@@ -0,0 +1,19 @@
+package sqldb
+
+// NotificationSubscriptionTable table schema
+type NotificationSubscriptionTable struct {
+	ID         uint64 `gorm:"primary_key;autoIncrement"`
+	Account    string `gorm:"index:idx_account"`
+	BucketName string `gorm:"index:idx_target"`
+	Owner      string `gorm:"index:idx_target"`
+	URL        string
+	Secret     string
+	// EventTypes are the subscribed event types joined by comma
+	EventTypes string
+	CreateTime int64
+}
+
+// TableName is used to set NotificationSubscriptionTable Schema's table name in database
+func (NotificationSubscriptionTable) TableName() string {
+	return NotificationSubscriptionTableName
+}
@@ -5,8 +5,8 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
 	"github.com/bnb-chain/greenfield-storage-provider/util"
@@ -45,18 +45,6 @@ func (s *SpDBImpl) GetObjectIntegrity(objectID uint64) (*corespdb.IntegrityMeta,
 	return meta, nil
 }
 
-func MysqlErrCode(err error) int {
-	mysqlErr, ok := err.(*mysql.MySQLError)
-	if !ok {
-		return 0
-	}
-	return int(mysqlErr.Number)
-}
-
-var (
-	ErrDuplicateEntryCode = 1062
-)
-
 // SetObjectIntegrity puts(overwrites) integrity hash info to db
 func (s *SpDBImpl) SetObjectIntegrity(meta *corespdb.IntegrityMeta) error {
 	insertIntegrityMetaRecord := &IntegrityMetaTable{
@@ -65,11 +53,9 @@ func (s *SpDBImpl) SetObjectIntegrity(meta *corespdb.IntegrityMeta) error {
 		IntegrityChecksum: hex.EncodeToString(meta.IntegrityChecksum),
 		Signature:         hex.EncodeToString(meta.Signature),
 	}
-	result := s.db.Create(insertIntegrityMetaRecord)
-	if result.Error != nil && MysqlErrCode(result.Error) == ErrDuplicateEntryCode {
-		return nil
-	}
-	if result.Error != nil || result.RowsAffected != 1 {
+	// the existing record is kept if the object id is duplicated
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(insertIntegrityMetaRecord)
+	if result.Error != nil {
 		return fmt.Errorf("failed to insert integrity meta record: %s", result.Error)
 	}
 	return nil
@@ -112,11 +98,8 @@ func (s *SpDBImpl) SetReplicatePieceChecksum(objectID uint64, replicateIdx uint3
 		PieceIndex:     pieceIdx,
 		PieceChecksum:  hex.EncodeToString(checksum),
 	}
-	result = s.db.Create(insertPieceHash)
-	if result.Error != nil && MysqlErrCode(result.Error) == ErrDuplicateEntryCode {
-		return nil
-	}
-	if result.Error != nil || result.RowsAffected != 1 {
+	result = s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(insertPieceHash)
+	if result.Error != nil {
 		return fmt.Errorf("failed to insert piece hash record: %s", result.Error)
 	}
 	return nil
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
 )
 
 // InsertAuthKey insert a new record into OffChainAuthKeyTable
@@ -81,3 +83,25 @@ func (s *SpDBImpl) GetAuthKey(userAddress string, domain string) (*OffChainAuthK
 	}
 	return queryKeyReturn, nil
 }
+
+// GetOffChainAuthPublicKey is used to query the public key registered by the user for the domain,
+// unlike GetAuthKey, it does not insert the initial record if there is no record.
+func (s *SpDBImpl) GetOffChainAuthPublicKey(userAddress string, domain string) (*corespdb.OffChainAuthPublicKey, error) {
+	queryKeyReturn := &OffChainAuthKeyTable{}
+	result := s.db.First(queryKeyReturn, "user_address = ? and domain = ?", userAddress, domain)
+	if errIsNotFound(result.Error) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query OffChainAuthKey table: %s", result.Error)
+	}
+	if queryKeyReturn.CurrentPublicKey == "" {
+		return nil, nil
+	}
+	return &corespdb.OffChainAuthPublicKey{
+		UserAddress: queryKeyReturn.UserAddress,
+		Domain:      queryKeyReturn.Domain,
+		PublicKey:   queryKeyReturn.CurrentPublicKey,
+		ExpiryDate:  queryKeyReturn.ExpiryDate.Unix(),
+	}, nil
+}
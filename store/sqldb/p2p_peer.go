@@ -0,0 +1,62 @@
+package sqldb
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+// SetP2PPeers is used to replace all the persisted p2p peers in a transaction.
+func (s *SpDBImpl) SetP2PPeers(peers []*corespdb.P2PPeer) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&P2PPeerTable{}).Error; err != nil {
+			return err
+		}
+		if len(peers) == 0 {
+			return nil
+		}
+		insertPeers := make([]*P2PPeerTable, 0, len(peers))
+		for _, peer := range peers {
+			insertPeers = append(insertPeers, &P2PPeerTable{
+				PeerID:    peer.PeerID,
+				SpAddress: peer.SpAddress,
+				Addrs:     strings.Join(peer.Addrs, ","),
+				FailCount: peer.FailCount,
+				LastSeen:  peer.LastSeen,
+			})
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&insertPeers).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set p2p peers: %s", err)
+	}
+	return nil
+}
+
+// ListP2PPeers is used to query all the persisted p2p peers.
+func (s *SpDBImpl) ListP2PPeers() ([]*corespdb.P2PPeer, error) {
+	var queryReturns []*P2PPeerTable
+	result := s.db.Order("peer_id").Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query p2p peer table: %s", result.Error)
+	}
+	peers := make([]*corespdb.P2PPeer, 0, len(queryReturns))
+	for _, queryReturn := range queryReturns {
+		var addrs []string
+		if queryReturn.Addrs != "" {
+			addrs = strings.Split(queryReturn.Addrs, ",")
+		}
+		peers = append(peers, &corespdb.P2PPeer{
+			PeerID:    queryReturn.PeerID,
+			SpAddress: queryReturn.SpAddress,
+			Addrs:     addrs,
+			FailCount: queryReturn.FailCount,
+			LastSeen:  queryReturn.LastSeen,
+		})
+	}
+	return peers, nil
+}
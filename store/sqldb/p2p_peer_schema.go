@@ -0,0 +1,16 @@
+package sqldb
+
+// P2PPeerTable table schema
+type P2PPeerTable struct {
+	PeerID    string `gorm:"primary_key"`
+	SpAddress string
+	// Addrs are the multi addresses joined by comma
+	Addrs     string
+	FailCount int
+	LastSeen  int64
+}
+
+// TableName is used to set P2PPeerTable Schema's table name in database
+func (P2PPeerTable) TableName() string {
+	return P2PPeerTableName
+}
@@ -0,0 +1,17 @@
+package sqldb
+
+import (
+	"time"
+)
+
+// SchemaVersionTable table schema, each applied migration has one record
+type SchemaVersionTable struct {
+	Version     uint32 `gorm:"primary_key;autoIncrement:false"`
+	Description string
+	AppliedTime time.Time
+}
+
+// TableName is used to set SchemaVersionTable Schema's table name in database
+func (SchemaVersionTable) TableName() string {
+	return SchemaVersionTableName
+}
@@ -0,0 +1,162 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+// SetSPExitProgress is used to set(maybe overwrite) the progress of sp exit.
+func (s *SpDBImpl) SetSPExitProgress(progress *corespdb.SPExitProgress) error {
+	result := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&SPExitProgressTable{
+		SpAddress:             progress.SpAddress,
+		LastSecondaryObjectID: progress.LastSecondaryObjectID,
+		LastPrimaryBucketID:   progress.LastPrimaryBucketID,
+		HandoverObjectNumber:  progress.HandoverObjectNumber,
+		FailedObjectNumber:    progress.FailedObjectNumber,
+		HandoverBucketNumber:  progress.HandoverBucketNumber,
+		FailedBucketNumber:    progress.FailedBucketNumber,
+		Finished:              progress.Finished,
+		StartTime:             progress.StartTime,
+		UpdateTime:            progress.UpdateTime,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to set sp exit progress: %s", result.Error)
+	}
+	return nil
+}
+
+// GetSPExitProgress is used to query the progress of sp exit by the sp operator address.
+func (s *SpDBImpl) GetSPExitProgress(spAddress string) (*corespdb.SPExitProgress, error) {
+	queryReturn := &SPExitProgressTable{}
+	result := s.db.First(queryReturn, "sp_address = ?", spAddress)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query sp exit progress table: %s", result.Error)
+	}
+	return &corespdb.SPExitProgress{
+		SpAddress:             queryReturn.SpAddress,
+		LastSecondaryObjectID: queryReturn.LastSecondaryObjectID,
+		LastPrimaryBucketID:   queryReturn.LastPrimaryBucketID,
+		HandoverObjectNumber:  queryReturn.HandoverObjectNumber,
+		FailedObjectNumber:    queryReturn.FailedObjectNumber,
+		HandoverBucketNumber:  queryReturn.HandoverBucketNumber,
+		FailedBucketNumber:    queryReturn.FailedBucketNumber,
+		Finished:              queryReturn.Finished,
+		StartTime:             queryReturn.StartTime,
+		UpdateTime:            queryReturn.UpdateTime,
+	}, nil
+}
+
+// SetSPExitRecord is used to set(maybe overwrite) the handover result of the object or bucket.
+func (s *SpDBImpl) SetSPExitRecord(record *corespdb.SPExitRecord) error {
+	result := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&SPExitRecordTable{
+		ResourceType:  record.ResourceType,
+		ResourceID:    record.ResourceID,
+		ResourceName:  record.ResourceName,
+		DestSpAddress: record.DestSpAddress,
+		Succeed:       record.Succeed,
+		ErrorMessage:  record.ErrorMessage,
+		UpdateTime:    record.UpdateTime,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to set sp exit record: %s", result.Error)
+	}
+	return nil
+}
+
+// GetSPExitRecord is used to query the handover result of the object or bucket.
+func (s *SpDBImpl) GetSPExitRecord(resourceType string, resourceID uint64) (*corespdb.SPExitRecord, error) {
+	queryReturn := &SPExitRecordTable{}
+	result := s.db.First(queryReturn, "resource_type = ? and resource_id = ?", resourceType, resourceID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query sp exit record table: %s", result.Error)
+	}
+	return toSPExitRecord(queryReturn), nil
+}
+
+// ListFailedSPExitRecords is used to query the failed handover results.
+func (s *SpDBImpl) ListFailedSPExitRecords(limit int) ([]*corespdb.SPExitRecord, error) {
+	var queryReturns []*SPExitRecordTable
+	result := s.db.Where("succeed = ?", false).Order("resource_type, resource_id").
+		Limit(limit).Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query sp exit record table: %s", result.Error)
+	}
+	records := make([]*corespdb.SPExitRecord, 0, len(queryReturns))
+	for _, queryReturn := range queryReturns {
+		records = append(records, toSPExitRecord(queryReturn))
+	}
+	return records, nil
+}
+
+func toSPExitRecord(table *SPExitRecordTable) *corespdb.SPExitRecord {
+	return &corespdb.SPExitRecord{
+		ResourceType:  table.ResourceType,
+		ResourceID:    table.ResourceID,
+		ResourceName:  table.ResourceName,
+		DestSpAddress: table.DestSpAddress,
+		Succeed:       table.Succeed,
+		ErrorMessage:  table.ErrorMessage,
+		UpdateTime:    table.UpdateTime,
+	}
+}
+
+// SetHandoverPiece is used to set(maybe overwrite) the record of the pieces received from the exiting sp.
+func (s *SpDBImpl) SetHandoverPiece(piece *corespdb.HandoverPiece) error {
+	result := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&HandoverPieceTable{
+		ObjectID:       piece.ObjectID,
+		ReplicateIdx:   piece.ReplicateIdx,
+		SrcSpAddress:   piece.SrcSpAddress,
+		SegmentCount:   piece.SegmentCount,
+		RedundancyType: piece.RedundancyType,
+		Done:           piece.Done,
+		UpdateTime:     piece.UpdateTime,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to set handover piece: %s", result.Error)
+	}
+	return nil
+}
+
+// ListHandoverPieces is used to query the earliest updated handover pieces that are updated before the update time.
+func (s *SpDBImpl) ListHandoverPieces(updateTime int64, limit int) ([]*corespdb.HandoverPiece, error) {
+	var queryReturns []*HandoverPieceTable
+	result := s.db.Where("update_time < ?", updateTime).Order("update_time, object_id, replicate_idx").
+		Limit(limit).Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query handover piece table: %s", result.Error)
+	}
+	pieces := make([]*corespdb.HandoverPiece, 0, len(queryReturns))
+	for _, queryReturn := range queryReturns {
+		pieces = append(pieces, &corespdb.HandoverPiece{
+			ObjectID:       queryReturn.ObjectID,
+			ReplicateIdx:   queryReturn.ReplicateIdx,
+			SrcSpAddress:   queryReturn.SrcSpAddress,
+			SegmentCount:   queryReturn.SegmentCount,
+			RedundancyType: queryReturn.RedundancyType,
+			Done:           queryReturn.Done,
+			UpdateTime:     queryReturn.UpdateTime,
+		})
+	}
+	return pieces, nil
+}
+
+// DeleteHandoverPiece is used to delete the record of the handover pieces.
+func (s *SpDBImpl) DeleteHandoverPiece(objectID uint64, replicateIdx uint32) error {
+	result := s.db.Where("object_id = ? and replicate_idx = ?", objectID, replicateIdx).
+		Delete(&HandoverPieceTable{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete handover piece: %s", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package sqldb
+
+// SPExitProgressTable table schema
+type SPExitProgressTable struct {
+	SpAddress             string `gorm:"primary_key"`
+	LastSecondaryObjectID uint64
+	LastPrimaryBucketID   uint64
+	HandoverObjectNumber  uint64
+	FailedObjectNumber    uint64
+	HandoverBucketNumber  uint64
+	FailedBucketNumber    uint64
+	Finished              bool
+	StartTime             int64
+	UpdateTime            int64
+}
+
+// TableName is used to set SPExitProgressTable Schema's table name in database
+func (SPExitProgressTable) TableName() string {
+	return SPExitProgressTableName
+}
+
+// SPExitRecordTable table schema
+type SPExitRecordTable struct {
+	ResourceType  string `gorm:"primary_key"`
+	ResourceID    uint64 `gorm:"primary_key;autoIncrement:false"`
+	ResourceName  string
+	DestSpAddress string
+	Succeed       bool `gorm:"index:idx_succeed"`
+	ErrorMessage  string
+	UpdateTime    int64
+}
+
+// TableName is used to set SPExitRecordTable Schema's table name in database
+func (SPExitRecordTable) TableName() string {
+	return SPExitRecordTableName
+}
+
+// HandoverPieceTable table schema
+type HandoverPieceTable struct {
+	ObjectID       uint64 `gorm:"primary_key;autoIncrement:false"`
+	ReplicateIdx   uint32 `gorm:"primary_key;autoIncrement:false"`
+	SrcSpAddress   string
+	SegmentCount   uint32
+	RedundancyType int32
+	Done           bool
+	UpdateTime     int64 `gorm:"index:idx_handover_update_time"`
+}
+
+// TableName is used to set HandoverPieceTable Schema's table name in database
+func (HandoverPieceTable) TableName() string {
+	return HandoverPieceTableName
+}
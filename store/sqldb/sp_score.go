@@ -0,0 +1,82 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+// UpdateSPReplicateScore is used to accumulate the replicate result of the sp.
+func (s *SpDBImpl) UpdateSPReplicateScore(spAddress string, succeed bool, bytes uint64, cost int64) (
+	*corespdb.SPScore, error) {
+	return s.updateSPScore(spAddress, func(score *SPScoreTable) {
+		if succeed {
+			score.ReplicateSucceed++
+		} else {
+			score.ReplicateFailed++
+		}
+		score.ReplicateBytes += bytes
+		score.ReplicateCost += cost
+	})
+}
+
+// UpdateSPP2PScore is used to set(maybe overwrite) the ping rtt and the p2p failure count of the sp.
+func (s *SpDBImpl) UpdateSPP2PScore(spAddress string, pingRTT int64, p2pFailure int64) (*corespdb.SPScore, error) {
+	return s.updateSPScore(spAddress, func(score *SPScoreTable) {
+		score.PingRTT = pingRTT
+		score.P2PFailure = p2pFailure
+	})
+}
+
+// updateSPScore reads, updates and writes back the score of the sp in a transaction, the
+// replicate and p2p factors are updated by the different modules.
+func (s *SpDBImpl) updateSPScore(spAddress string, update func(score *SPScoreTable)) (*corespdb.SPScore, error) {
+	score := &SPScoreTable{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("sp_address = ?", spAddress).First(score)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			score = &SPScoreTable{SpAddress: spAddress}
+		} else if result.Error != nil {
+			return result.Error
+		}
+		update(score)
+		score.UpdateTime = time.Now().Unix()
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(score).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sp score: %s", err)
+	}
+	return toSPScore(score), nil
+}
+
+// ListSPScores is used to query the scores of all the sps.
+func (s *SpDBImpl) ListSPScores() ([]*corespdb.SPScore, error) {
+	var queryReturns []*SPScoreTable
+	result := s.db.Order("sp_address").Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query sp score table: %s", result.Error)
+	}
+	scores := make([]*corespdb.SPScore, 0, len(queryReturns))
+	for _, queryReturn := range queryReturns {
+		scores = append(scores, toSPScore(queryReturn))
+	}
+	return scores, nil
+}
+
+func toSPScore(score *SPScoreTable) *corespdb.SPScore {
+	return &corespdb.SPScore{
+		SpAddress:        score.SpAddress,
+		ReplicateSucceed: score.ReplicateSucceed,
+		ReplicateFailed:  score.ReplicateFailed,
+		ReplicateBytes:   score.ReplicateBytes,
+		ReplicateCost:    score.ReplicateCost,
+		PingRTT:          score.PingRTT,
+		P2PFailure:       score.P2PFailure,
+		UpdateTime:       score.UpdateTime,
+	}
+}
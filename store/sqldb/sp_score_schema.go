@@ -0,0 +1,18 @@
+package sqldb
+
+// SPScoreTable table schema
+type SPScoreTable struct {
+	SpAddress        string `gorm:"primary_key"`
+	ReplicateSucceed uint64
+	ReplicateFailed  uint64
+	ReplicateBytes   uint64
+	ReplicateCost    int64
+	PingRTT          int64
+	P2PFailure       int64
+	UpdateTime       int64
+}
+
+// TableName is used to set SPScoreTable Schema's table name in database
+func (SPScoreTable) TableName() string {
+	return SPScoreTableName
+}
@@ -1,11 +1,9 @@
 package sqldb
 
 import (
-	"fmt"
 	"os"
 	"time"
 
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 
 	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
@@ -32,11 +30,29 @@ func NewSpDB(config *config.SQLDBConfig) (*SpDBImpl, error) {
 	return &SpDBImpl{db: db}, err
 }
 
-// InitDB init a db instance
+// InitDB init a db instance, the pending migrations are applied and the db with
+// unknown newer schema is refused
 func InitDB(config *config.SQLDBConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		config.User, config.Passwd, config.Address, config.Database)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := OpenDB(config)
+	if err != nil {
+		return nil, err
+	}
+	// Up refuses the db with unknown newer schema before applying the migrations
+	if err = NewMigrator(db).Up(0); err != nil {
+		log.Errorw("failed to migrate spdb", "error", err)
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenDB opens a db instance without migrating the schema
+func OpenDB(config *config.SQLDBConfig) (*gorm.DB, error) {
+	dialector, err := NewDialector(config)
+	if err != nil {
+		log.Errorw("failed to new db dialector", "error", err)
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
 		log.Errorw("gorm failed to open db", "error", err)
 		return nil, err
@@ -50,50 +66,9 @@ func InitDB(config *config.SQLDBConfig) (*gorm.DB, error) {
 	sqlDB.SetConnMaxIdleTime(time.Duration(config.ConnMaxIdleTime) * time.Second)
 	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
-	// create if not exist
-	if err := db.AutoMigrate(&JobTable{}); err != nil {
-		log.Errorw("failed to create job table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&ObjectTable{}); err != nil {
-		log.Errorw("failed to create object table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&GCObjectTaskTable{}); err != nil {
-		log.Errorw("failed to gc object task table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&SpInfoTable{}); err != nil {
-		log.Errorw("failed to create sp info table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&StorageParamsTable{}); err != nil {
-		log.Errorw("failed to storage params table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&PieceHashTable{}); err != nil {
-		log.Errorw("failed to create piece hash table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&IntegrityMetaTable{}); err != nil {
-		log.Errorw("failed to create integrity meta table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&BucketTrafficTable{}); err != nil {
-		log.Errorw("failed to create bucket traffic table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&ReadRecordTable{}); err != nil {
-		log.Errorw("failed to create read record table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&ServiceConfigTable{}); err != nil {
-		log.Errorw("failed to create service config table", "error", err)
-		return nil, err
-	}
-	if err := db.AutoMigrate(&OffChainAuthKeyTable{}); err != nil {
-		log.Errorw("failed to create off-chain authKey table", "error", err)
-		return nil, err
+	if IsSQLite(config) {
+		// SQLite allows only one writer, serialize the connections to avoid the locked db file
+		sqlDB.SetMaxOpenConns(1)
 	}
 	return db, nil
 }
@@ -0,0 +1,501 @@
+package sqldb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+	"github.com/bnb-chain/greenfield-storage-provider/model"
+	merrors "github.com/bnb-chain/greenfield-storage-provider/model/errors"
+	"github.com/bnb-chain/greenfield-storage-provider/store/config"
+)
+
+const (
+	// testMySQLAddress defines env variable name for the mysql address of schema test
+	testMySQLAddress = "SPDB_TEST_MYSQL_ADDRESS"
+	// testPostgresAddress defines env variable name for the postgres address of schema test
+	testPostgresAddress = "SPDB_TEST_POSTGRES_ADDRESS"
+)
+
+// setupSpDB returns the SpDB of the dialect, the mysql and postgres tests are
+// skipped if the db address env is not set, the user, password and database
+// are loaded from the SP_DB_* env vars. The unit test workflow of CI sets these
+// env vars to its mysql and postgres service containers.
+func setupSpDB(t *testing.T, dialect string) *SpDBImpl {
+	cfg := &config.SQLDBConfig{Dialect: dialect}
+	switch dialect {
+	case config.MySQLDialect:
+		cfg.Address = os.Getenv(testMySQLAddress)
+	case config.PostgresDialect:
+		cfg.Address = os.Getenv(testPostgresAddress)
+	case config.SQLiteDialect:
+		cfg.Database = filepath.Join(t.TempDir(), "spdb.db")
+	}
+	if dialect != config.SQLiteDialect {
+		if cfg.Address == "" {
+			t.Skipf("skip %s schema test, db address is not set", dialect)
+		}
+		cfg.User = os.Getenv(model.SpDBUser)
+		cfg.Passwd = os.Getenv(model.SpDBPasswd)
+		cfg.Database = os.Getenv(model.SpDBDataBase)
+	}
+	db, err := NewSpDB(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.db.Migrator().DropTable(&JobTable{}, &ObjectTable{}, &GCObjectTaskTable{}, &SpInfoTable{},
+			&StorageParamsTable{}, &PieceHashTable{}, &IntegrityMetaTable{}, &BucketTrafficTable{},
+			&ReadRecordTable{}, &ServiceConfigTable{}, &OffChainAuthKeyTable{}, &MigrateBucketProgressTable{},
+			&SPExitProgressTable{}, &SPExitRecordTable{}, &HandoverPieceTable{}, &AuditFindingTable{}, &VersionedParamsTable{},
+			&GCFailedPieceTable{}, &SPScoreTable{}, &P2PPeerTable{}, &NotificationSubscriptionTable{},
+			&SchemaVersionTable{})
+	})
+	return db
+}
+
+func TestSpDBSchema(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			meta := &corespdb.IntegrityMeta{
+				ObjectID:          1,
+				IntegrityChecksum: []byte("mock-checksum"),
+				PieceChecksumList: [][]byte{[]byte("mock-piece")},
+				Signature:         []byte("mock-signature"),
+			}
+			assert.Nil(t, db.SetObjectIntegrity(meta))
+			// the duplicated object integrity is ignored
+			assert.Nil(t, db.SetObjectIntegrity(meta))
+			result, err := db.GetObjectIntegrity(1)
+			assert.Nil(t, err)
+			assert.Equal(t, meta.IntegrityChecksum, result.IntegrityChecksum)
+
+			assert.Nil(t, db.SetReplicatePieceChecksum(1, 0, 0, []byte("mock-piece")))
+			assert.Nil(t, db.SetReplicatePieceChecksum(1, 0, 0, []byte("mock-piece")))
+			checksum, err := db.GetReplicatePieceChecksum(1, 0, 0)
+			assert.Nil(t, err)
+			assert.Equal(t, []byte("mock-piece"), checksum)
+
+			assert.Nil(t, db.SetAllServiceConfigs("v1", "mock-config"))
+			assert.Nil(t, db.SetAllServiceConfigs("v2", "mock-config"))
+			version, _, err := db.GetAllServiceConfigs()
+			assert.Nil(t, err)
+			assert.Equal(t, "v2", version)
+		})
+	}
+}
+
+func TestPortableDataType(t *testing.T) {
+	cases := map[string]string{
+		"BINARY(32)":    "bytea",
+		"varbinary(32)": "bytea",
+		"longblob":      "bytea",
+		"bigint(64)":    "bigint",
+		"MEDIUMTEXT":    "text",
+		"VARCHAR(64)":   "VARCHAR(64)",
+		"json":          "json",
+	}
+	for dataType, expected := range cases {
+		assert.Equal(t, expected, PortableDataType(dataType), dataType)
+	}
+}
+
+func TestPostgresDialectorSavePoint(t *testing.T) {
+	dialector, err := NewDialectorByDSN(config.PostgresDialect, "host=localhost")
+	require.NoError(t, err)
+	// the nested transactions need the savepoints of the dialector
+	_, ok := dialector.(gorm.SavePointerDialectorInterface)
+	assert.True(t, ok)
+	assert.Equal(t, config.PostgresDialect, dialector.Name())
+}
+
+func TestSpDBCheckQuotaAndAddReadRecord(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			record := &corespdb.ReadRecord{
+				BucketID:        1,
+				ObjectID:        1,
+				UserAddress:     "mock-user",
+				BucketName:      "mock-bucket",
+				ObjectName:      "mock-object",
+				ReadSize:        60,
+				ReadTimestampUs: time.Now().UnixMicro(),
+			}
+			assert.Nil(t, db.CheckQuotaAndAddReadRecord(record, &corespdb.BucketQuota{ReadQuotaSize: 100}))
+			assert.Equal(t, merrors.ErrCheckQuotaEnough,
+				db.CheckQuotaAndAddReadRecord(record, &corespdb.BucketQuota{ReadQuotaSize: 100}))
+			// the changed quota on chain is applied
+			assert.Nil(t, db.CheckQuotaAndAddReadRecord(record, &corespdb.BucketQuota{ReadQuotaSize: 200}))
+
+			traffic, err := db.GetBucketTraffic(1, TimeToYearMonth(TimestampUsToTime(record.ReadTimestampUs)))
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(120), traffic.ReadConsumedSize)
+			assert.Equal(t, uint64(200), traffic.ReadQuotaSize)
+			records, err := db.GetBucketReadRecord(1, &corespdb.TrafficTimeRange{
+				StartTimestampUs: 0,
+				EndTimestampUs:   time.Now().Add(time.Hour).UnixMicro(),
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, 2, len(records))
+		})
+	}
+}
+
+func TestSpDBMigrateBucketProgress(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			progress, err := db.GetMigrateBucketProgress("mock-bucket")
+			assert.Nil(t, err)
+			assert.Nil(t, progress)
+
+			progress = &corespdb.MigrateBucketProgress{
+				BucketName:     "mock-bucket",
+				BucketID:       1,
+				SrcSpEndpoint:  "mock-endpoint",
+				OwnerSignature: []byte("mock-signature"),
+				ApprovalExpiry: 100,
+			}
+			assert.Nil(t, db.SetMigrateBucketProgress(progress))
+			progress.LastMigratedObjectName = "mock-object"
+			progress.MigratedObjectNumber = 1
+			assert.Nil(t, db.SetMigrateBucketProgress(progress))
+			result, err := db.GetMigrateBucketProgress("mock-bucket")
+			assert.Nil(t, err)
+			assert.Equal(t, progress, result)
+
+			progresses, err := db.ListUnfinishedMigrateBucketProgress()
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(progresses))
+			progress.Finished = true
+			assert.Nil(t, db.SetMigrateBucketProgress(progress))
+			progresses, err = db.ListUnfinishedMigrateBucketProgress()
+			assert.Nil(t, err)
+			assert.Equal(t, 0, len(progresses))
+		})
+	}
+}
+
+func TestSpDBSPExit(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			progress, err := db.GetSPExitProgress("mock-sp")
+			assert.Nil(t, err)
+			assert.Nil(t, progress)
+
+			progress = &corespdb.SPExitProgress{SpAddress: "mock-sp", StartTime: 1}
+			assert.Nil(t, db.SetSPExitProgress(progress))
+			progress.LastSecondaryObjectID = 10
+			progress.HandoverObjectNumber = 9
+			progress.FailedObjectNumber = 1
+			assert.Nil(t, db.SetSPExitProgress(progress))
+			result, err := db.GetSPExitProgress("mock-sp")
+			assert.Nil(t, err)
+			assert.Equal(t, progress, result)
+
+			record := &corespdb.SPExitRecord{
+				ResourceType: corespdb.SPExitObjectResource,
+				ResourceID:   10,
+				ResourceName: "mock-object",
+				ErrorMessage: "mock-error",
+			}
+			assert.Nil(t, db.SetSPExitRecord(record))
+			assert.Nil(t, db.SetSPExitRecord(&corespdb.SPExitRecord{
+				ResourceType: corespdb.SPExitBucketResource,
+				ResourceID:   10,
+				ResourceName: "mock-bucket",
+				Succeed:      true,
+			}))
+			records, err := db.ListFailedSPExitRecords(10)
+			assert.Nil(t, err)
+			assert.Equal(t, []*corespdb.SPExitRecord{record}, records)
+			bucketRecord, err := db.GetSPExitRecord(corespdb.SPExitBucketResource, 10)
+			assert.Nil(t, err)
+			assert.True(t, bucketRecord.Succeed)
+			bucketRecord, err = db.GetSPExitRecord(corespdb.SPExitBucketResource, 11)
+			assert.Nil(t, err)
+			assert.Nil(t, bucketRecord)
+			// the retried handover overwrites the failed record
+			record.Succeed = true
+			record.ErrorMessage = ""
+			assert.Nil(t, db.SetSPExitRecord(record))
+			records, err = db.ListFailedSPExitRecords(10)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, len(records))
+
+			piece := &corespdb.HandoverPiece{ObjectID: 10, ReplicateIdx: 1, SrcSpAddress: "mock-sp",
+				SegmentCount: 2, UpdateTime: 1}
+			assert.Nil(t, db.SetHandoverPiece(piece))
+			assert.Nil(t, db.SetHandoverPiece(&corespdb.HandoverPiece{ObjectID: 11, UpdateTime: 3}))
+			piece.Done = true
+			assert.Nil(t, db.SetHandoverPiece(piece))
+			pieces, err := db.ListHandoverPieces(2, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, []*corespdb.HandoverPiece{piece}, pieces)
+			assert.Nil(t, db.DeleteHandoverPiece(10, 1))
+			pieces, err = db.ListHandoverPieces(4, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(pieces))
+			assert.Equal(t, uint64(11), pieces[0].ObjectID)
+		})
+	}
+}
+
+func TestSpDBAuditFinding(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			missing := &corespdb.AuditFinding{
+				ObjectID:      1,
+				BucketName:    "mock-bucket",
+				ObjectName:    "mock-object",
+				RedundancyIdx: -1,
+				SegmentIdx:    2,
+				FindingType:   corespdb.AuditMissingPiece,
+				Detail:        "mock-error",
+				UpdateTime:    1,
+			}
+			stale := &corespdb.AuditFinding{
+				ObjectID:      2,
+				BucketName:    "mock-bucket",
+				ObjectName:    "mock-object-2",
+				RedundancyIdx: 0,
+				FindingType:   corespdb.AuditStaleIntegrity,
+				UpdateTime:    2,
+			}
+			assert.Nil(t, db.SetAuditFinding(missing))
+			assert.Nil(t, db.SetAuditFinding(stale))
+			findings, err := db.ListAuditFindings(10)
+			assert.Nil(t, err)
+			assert.Equal(t, []*corespdb.AuditFinding{stale, missing}, findings)
+			// the repaired finding overwrites the same finding of the object
+			stale.Repaired = true
+			stale.UpdateTime = 3
+			assert.Nil(t, db.SetAuditFinding(stale))
+			findings, err = db.ListAuditFindings(1)
+			assert.Nil(t, err)
+			assert.Equal(t, []*corespdb.AuditFinding{stale}, findings)
+		})
+	}
+}
+
+func TestSpDBVersionedParams(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			first := &corespdb.VersionedParams{
+				EffectiveTime:           10,
+				EndTime:                 20,
+				MaxSegmentSize:          16 * 1024 * 1024,
+				RedundantDataChunkNum:   4,
+				RedundantParityChunkNum: 2,
+			}
+			second := &corespdb.VersionedParams{
+				EffectiveTime:           30,
+				EndTime:                 30,
+				MaxSegmentSize:          32 * 1024 * 1024,
+				RedundantDataChunkNum:   4,
+				RedundantParityChunkNum: 2,
+			}
+			assert.Nil(t, db.SetVersionedParams(first))
+			assert.Nil(t, db.SetVersionedParams(second))
+
+			params, err := db.GetVersionedParamsByTimestamp(5)
+			assert.Nil(t, err)
+			assert.Nil(t, params)
+			params, err = db.GetVersionedParamsByTimestamp(25)
+			assert.Nil(t, err)
+			assert.Equal(t, first, params)
+			// the verified range is extended by overwriting the end time
+			second.EndTime = 40
+			assert.Nil(t, db.SetVersionedParams(second))
+			params, err = db.GetVersionedParamsByTimestamp(50)
+			assert.Nil(t, err)
+			assert.Equal(t, second, params)
+			list, err := db.ListVersionedParams()
+			assert.Nil(t, err)
+			assert.Equal(t, []*corespdb.VersionedParams{first, second}, list)
+		})
+	}
+}
+
+func TestSpDBGCFailedPieces(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			failed := &corespdb.GCFailedPiece{
+				PieceKey:         "s1_s0",
+				ObjectID:         1,
+				ErrorDescription: "mock-error",
+				UpdateTime:       1,
+			}
+			exhausted := &corespdb.GCFailedPiece{
+				PieceKey:   "s2_s0",
+				ObjectID:   2,
+				RetryCount: 3,
+				UpdateTime: 2,
+			}
+			assert.Nil(t, db.SetGCFailedPieces([]*corespdb.GCFailedPiece{failed, exhausted}))
+			pieces, err := db.ListGCFailedPieces(3, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, []*corespdb.GCFailedPiece{failed}, pieces)
+			// the retry count is increased by overwriting the piece
+			failed.RetryCount, failed.UpdateTime = 1, 3
+			assert.Nil(t, db.SetGCFailedPieces([]*corespdb.GCFailedPiece{failed}))
+			pieces, err = db.ListGCFailedPieces(4, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, []*corespdb.GCFailedPiece{exhausted, failed}, pieces)
+			assert.Nil(t, db.DeleteGCFailedPieces([]string{failed.PieceKey, exhausted.PieceKey}))
+			pieces, err = db.ListGCFailedPieces(4, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, len(pieces))
+		})
+	}
+}
+
+func TestSpDBSPScore(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			score, err := db.UpdateSPReplicateScore("sp1", true, 1024, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(1), score.ReplicateSucceed)
+			score, err = db.UpdateSPReplicateScore("sp1", false, 1024, 30)
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(1), score.ReplicateFailed)
+			assert.Equal(t, uint64(2048), score.ReplicateBytes)
+			assert.Equal(t, int64(40), score.ReplicateCost)
+			// the p2p factors do not overwrite the replicate factors
+			score, err = db.UpdateSPP2PScore("sp1", 50, 2)
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(1), score.ReplicateSucceed)
+			assert.Equal(t, int64(50), score.PingRTT)
+			_, err = db.UpdateSPP2PScore("sp0", 10, 0)
+			assert.Nil(t, err)
+
+			scores, err := db.ListSPScores()
+			assert.Nil(t, err)
+			assert.Equal(t, 2, len(scores))
+			assert.Equal(t, "sp0", scores[0].SpAddress)
+			assert.Equal(t, int64(2), scores[1].P2PFailure)
+			assert.True(t, scores[0].Score() > scores[1].Score())
+		})
+	}
+}
+
+func TestSpDBP2PPeer(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			assert.Nil(t, db.SetP2PPeers([]*corespdb.P2PPeer{
+				{PeerID: "peer1", SpAddress: "sp1", Addrs: []string{"/ip4/127.0.0.1/tcp/9933"}, FailCount: 1},
+				{PeerID: "peer0", Addrs: []string{"/ip4/127.0.0.1/tcp/9934", "/ip4/127.0.0.2/tcp/9934"}},
+			}))
+			peers, err := db.ListP2PPeers()
+			assert.Nil(t, err)
+			assert.Equal(t, 2, len(peers))
+			assert.Equal(t, "peer0", peers[0].PeerID)
+			assert.Equal(t, 2, len(peers[0].Addrs))
+			assert.Equal(t, "sp1", peers[1].SpAddress)
+			assert.Equal(t, 1, peers[1].FailCount)
+
+			// the peers are replaced
+			assert.Nil(t, db.SetP2PPeers([]*corespdb.P2PPeer{{PeerID: "peer2", SpAddress: "sp2"}}))
+			peers, err = db.ListP2PPeers()
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(peers))
+			assert.Equal(t, "peer2", peers[0].PeerID)
+			assert.Empty(t, peers[0].Addrs)
+
+			assert.Nil(t, db.SetP2PPeers(nil))
+			peers, err = db.ListP2PPeers()
+			assert.Nil(t, err)
+			assert.Empty(t, peers)
+		})
+	}
+}
+
+func TestSpDBNotificationSubscription(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			bucketID, err := db.CreateNotificationSubscription(&corespdb.NotificationSubscription{
+				Account: "owner1", BucketName: "bucket1", URL: "http://localhost/hook", Secret: "secret",
+				EventTypes: []string{"create_object", "delete_object"},
+			})
+			assert.Nil(t, err)
+			ownerID, err := db.CreateNotificationSubscription(&corespdb.NotificationSubscription{
+				Account: "owner1", Owner: "owner1", URL: "http://localhost/hook",
+			})
+			assert.Nil(t, err)
+			_, err = db.CreateNotificationSubscription(&corespdb.NotificationSubscription{
+				Account: "owner2", BucketName: "bucket2", URL: "http://localhost/hook",
+			})
+			assert.Nil(t, err)
+
+			subscription, err := db.GetNotificationSubscription(bucketID)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"create_object", "delete_object"}, subscription.EventTypes)
+			assert.False(t, subscription.Subscribe("seal_object"))
+			subscriptions, err := db.ListNotificationSubscriptions("owner1")
+			assert.Nil(t, err)
+			assert.Equal(t, 2, len(subscriptions))
+			subscriptions, err = db.ListNotificationSubscriptionsByTarget("bucket1", "owner1")
+			assert.Nil(t, err)
+			assert.Equal(t, 2, len(subscriptions))
+			assert.True(t, subscriptions[1].Subscribe("seal_object"))
+
+			// the subscription is only deleted by the account that manages it
+			assert.Nil(t, db.DeleteNotificationSubscription(ownerID, "owner2"))
+			subscriptions, err = db.ListNotificationSubscriptionsByTarget("bucket2", "owner1")
+			assert.Nil(t, err)
+			assert.Equal(t, 2, len(subscriptions))
+			assert.Nil(t, db.DeleteNotificationSubscription(ownerID, "owner1"))
+			subscription, err = db.GetNotificationSubscription(ownerID)
+			assert.Nil(t, err)
+			assert.Nil(t, subscription)
+		})
+	}
+}
+
+func TestSpDBOffChainAuthPublicKey(t *testing.T) {
+	for _, dialect := range []string{config.MySQLDialect, config.PostgresDialect, config.SQLiteDialect} {
+		t.Run(dialect, func(t *testing.T) {
+			db := setupSpDB(t, dialect)
+
+			publicKey, err := db.GetOffChainAuthPublicKey("user", "https://app.com")
+			assert.Nil(t, err)
+			assert.Nil(t, publicKey)
+			// the initial record has no public key
+			_, err = db.GetAuthKey("user", "https://app.com")
+			assert.Nil(t, err)
+			publicKey, err = db.GetOffChainAuthPublicKey("user", "https://app.com")
+			assert.Nil(t, err)
+			assert.Nil(t, publicKey)
+
+			expiryDate := time.Now().Add(time.Hour)
+			assert.Nil(t, db.UpdateAuthKey("user", "https://app.com", 0, 1, "public-key", expiryDate))
+			publicKey, err = db.GetOffChainAuthPublicKey("user", "https://app.com")
+			assert.Nil(t, err)
+			assert.Equal(t, "public-key", publicKey.PublicKey)
+			assert.Equal(t, expiryDate.Unix(), publicKey.ExpiryDate)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
 	merrors "github.com/bnb-chain/greenfield-storage-provider/model/errors"
@@ -14,7 +15,6 @@ import (
 )
 
 // CheckQuotaAndAddReadRecord check current quota, and add read record
-// TODO: Traffic statistics may be inaccurate in extreme cases, optimize it in the future
 func (s *SpDBImpl) CheckQuotaAndAddReadRecord(record *corespdb.ReadRecord, quota *corespdb.BucketQuota) error {
 	startTime := time.Now()
 	defer func() {
@@ -23,11 +23,7 @@ func (s *SpDBImpl) CheckQuotaAndAddReadRecord(record *corespdb.ReadRecord, quota
 	}()
 
 	yearMonth := TimeToYearMonth(TimestampUsToTime(record.ReadTimestampUs))
-	bucketTraffic, err := s.GetBucketTraffic(record.BucketID, yearMonth)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return err
-	}
-	if bucketTraffic == nil {
+	return s.db.Transaction(func(tx *gorm.DB) error {
 		// insert, if not existed
 		insertBucketTraffic := &BucketTrafficTable{
 			BucketID:         record.BucketID,
@@ -37,73 +33,54 @@ func (s *SpDBImpl) CheckQuotaAndAddReadRecord(record *corespdb.ReadRecord, quota
 			ReadQuotaSize:    quota.ReadQuotaSize,
 			ModifiedTime:     time.Now(),
 		}
-		result := s.db.Create(insertBucketTraffic)
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(insertBucketTraffic)
 		if result.Error != nil {
 			return fmt.Errorf("failed to insert bucket traffic table: %s", result.Error)
 		}
-		if result.RowsAffected != 1 {
-			log.Infow("insert traffic", "RowsAffected", result.RowsAffected, "record", record, "quota", quota)
-		}
-		bucketTraffic = &corespdb.BucketTraffic{
-			BucketID:         insertBucketTraffic.BucketID,
-			YearMonth:        insertBucketTraffic.Month,
-			BucketName:       insertBucketTraffic.BucketName,
-			ReadConsumedSize: insertBucketTraffic.ReadConsumedSize,
-			ReadQuotaSize:    insertBucketTraffic.ReadQuotaSize,
-		}
-	}
-	if bucketTraffic.ReadQuotaSize != quota.ReadQuotaSize {
+
 		// update if chain quota has changed
-		result := s.db.Model(&BucketTrafficTable{}).
-			Where("bucket_id = ? and month = ?", bucketTraffic.BucketID, bucketTraffic.YearMonth).
-			Updates(BucketTrafficTable{
-				ReadQuotaSize: quota.ReadQuotaSize,
-				ModifiedTime:  time.Now(),
+		result = tx.Model(&BucketTrafficTable{}).
+			Where("bucket_id = ? and month = ? and read_quota_size <> ?", record.BucketID, yearMonth, quota.ReadQuotaSize).
+			Updates(map[string]interface{}{
+				"read_quota_size": quota.ReadQuotaSize,
+				"modified_time":   time.Now(),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update bucket traffic table: %s", result.Error)
+		}
+
+		// check quota and update bucket traffic in one statement, no row is updated if the quota is not enough
+		result = tx.Model(&BucketTrafficTable{}).
+			Where("bucket_id = ? and month = ? and read_consumed_size + ? <= read_quota_size",
+				record.BucketID, yearMonth, record.ReadSize).
+			Updates(map[string]interface{}{
+				"read_consumed_size": gorm.Expr("read_consumed_size + ?", record.ReadSize),
+				"modified_time":      time.Now(),
 			})
 		if result.Error != nil {
 			return fmt.Errorf("failed to update bucket traffic table: %s", result.Error)
 		}
-		// TODO:: change the transaction way to update
 		if result.RowsAffected != 1 {
-			log.Infow("update traffic", "RowsAffected", result.RowsAffected, "record", record, "quota", quota)
+			log.Infow("quota is not enough", "RowsAffected", result.RowsAffected, "record", record, "quota", quota)
+			return merrors.ErrCheckQuotaEnough
 		}
-		bucketTraffic.ReadQuotaSize = quota.ReadQuotaSize
-	}
 
-	// check quota
-	if bucketTraffic.ReadConsumedSize+record.ReadSize > quota.ReadQuotaSize {
-		return merrors.ErrCheckQuotaEnough
-	}
-
-	// update bucket traffic
-	result := s.db.Model(&BucketTrafficTable{}).
-		Where("bucket_id = ? and month = ?", bucketTraffic.BucketID, bucketTraffic.YearMonth).
-		Updates(BucketTrafficTable{
-			ReadConsumedSize: bucketTraffic.ReadConsumedSize + record.ReadSize,
-			ModifiedTime:     time.Now(),
-		})
-	if result.Error != nil {
-		return fmt.Errorf("failed to update bucket traffic table: %s", result.Error)
-	}
-	if result.RowsAffected != 1 {
-		log.Infow("update traffic", "RowsAffected", result.RowsAffected, "record", record, "quota", quota)
-	}
-
-	// add read record
-	insertReadRecord := &ReadRecordTable{
-		BucketID:        record.BucketID,
-		ObjectID:        record.ObjectID,
-		UserAddress:     record.UserAddress,
-		ReadTimestampUs: record.ReadTimestampUs,
-		BucketName:      record.BucketName,
-		ObjectName:      record.ObjectName,
-		ReadSize:        record.ReadSize,
-	}
-	result = s.db.Create(insertReadRecord)
-	if result.Error != nil || result.RowsAffected != 1 {
-		return fmt.Errorf("failed to insert read record table: %s", result.Error)
-	}
-	return nil
+		// add read record
+		insertReadRecord := &ReadRecordTable{
+			BucketID:        record.BucketID,
+			ObjectID:        record.ObjectID,
+			UserAddress:     record.UserAddress,
+			ReadTimestampUs: record.ReadTimestampUs,
+			BucketName:      record.BucketName,
+			ObjectName:      record.ObjectName,
+			ReadSize:        record.ReadSize,
+		}
+		result = tx.Create(insertReadRecord)
+		if result.Error != nil || result.RowsAffected != 1 {
+			return fmt.Errorf("failed to insert read record table: %s", result.Error)
+		}
+		return nil
+	})
 }
 
 // GetBucketTraffic return bucket traffic info
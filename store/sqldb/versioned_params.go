@@ -0,0 +1,66 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	corespdb "github.com/bnb-chain/greenfield-storage-provider/core/spdb"
+)
+
+// GetVersionedParamsByTimestamp is used to query the cached versioned params with the latest
+// effective time that is not after the timestamp.
+func (s *SpDBImpl) GetVersionedParamsByTimestamp(timestamp int64) (*corespdb.VersionedParams, error) {
+	queryReturn := &VersionedParamsTable{}
+	result := s.db.Where("effective_time <= ?", timestamp).Order("effective_time desc").First(queryReturn)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query versioned params table: %s", result.Error)
+	}
+	return toVersionedParams(queryReturn), nil
+}
+
+// SetVersionedParams is used to set(maybe overwrite) the versioned params by the effective time.
+func (s *SpDBImpl) SetVersionedParams(params *corespdb.VersionedParams) error {
+	result := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&VersionedParamsTable{
+		EffectiveTime:           params.EffectiveTime,
+		EndTime:                 params.EndTime,
+		MaxSegmentSize:          params.MaxSegmentSize,
+		RedundantDataChunkNum:   params.RedundantDataChunkNum,
+		RedundantParityChunkNum: params.RedundantParityChunkNum,
+		MinChargeSize:           params.MinChargeSize,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to set versioned params: %s", result.Error)
+	}
+	return nil
+}
+
+// ListVersionedParams is used to query all the cached versioned params in the order of effective time.
+func (s *SpDBImpl) ListVersionedParams() ([]*corespdb.VersionedParams, error) {
+	var queryReturns []*VersionedParamsTable
+	result := s.db.Order("effective_time").Find(&queryReturns)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to query versioned params table: %s", result.Error)
+	}
+	params := make([]*corespdb.VersionedParams, 0, len(queryReturns))
+	for _, queryReturn := range queryReturns {
+		params = append(params, toVersionedParams(queryReturn))
+	}
+	return params, nil
+}
+
+func toVersionedParams(table *VersionedParamsTable) *corespdb.VersionedParams {
+	return &corespdb.VersionedParams{
+		EffectiveTime:           table.EffectiveTime,
+		EndTime:                 table.EndTime,
+		MaxSegmentSize:          table.MaxSegmentSize,
+		RedundantDataChunkNum:   table.RedundantDataChunkNum,
+		RedundantParityChunkNum: table.RedundantParityChunkNum,
+		MinChargeSize:           table.MinChargeSize,
+	}
+}
@@ -0,0 +1,16 @@
+package sqldb
+
+// VersionedParamsTable table schema
+type VersionedParamsTable struct {
+	EffectiveTime           int64 `gorm:"primary_key;autoIncrement:false"`
+	EndTime                 int64
+	MaxSegmentSize          uint64
+	RedundantDataChunkNum   uint32
+	RedundantParityChunkNum uint32
+	MinChargeSize           uint64
+}
+
+// TableName is used to set VersionedParamsTable Schema's table name in database
+func (VersionedParamsTable) TableName() string {
+	return VersionedParamsTableName
+}